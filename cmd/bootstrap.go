@@ -6,16 +6,26 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	computerSetup "github.com/aallbrig/allbctl/pkg/computersetup"
+	"github.com/aallbrig/allbctl/pkg/computersetup/providers"
+	"github.com/aallbrig/allbctl/pkg/model"
 	"github.com/aallbrig/allbctl/pkg/osagnostic"
 	"github.com/aallbrig/allbctl/pkg/status"
 	"github.com/aallbrig/allbctl/pkg/telemetry"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
+// bootstrapConfigKey is the config file section that declares this machine
+const bootstrapConfigKey = "bootstrap"
+
 var (
-	registerSSHKeys bool
+	registerSSHKeys    bool
+	planOutFile        string
+	installParallelism int
+	resumeInstall      bool
 )
 
 var BootstrapCmd = &cobra.Command{
@@ -26,8 +36,26 @@ var BootstrapCmd = &cobra.Command{
 	Short: "Manage workstation bootstrap configuration",
 	Long: `Manage workstation bootstrap configuration including directories, tools, SSH keys, and dotfiles.
 
+The machine is described by the built-in configuration for your operating system,
+or by a 'bootstrap:' section in ~/.allbctl.yaml when one is present:
+
+  bootstrap:
+    directories: [~/src, ~/bin]
+    tools:
+      - command: git
+      - command: gh
+        packages: {apt: gh, pacman: github-cli, brew: gh, winget: GitHub.cli}
+    dotfiles:
+      - repo: https://github.com/you/dotfiles
+        install_script: ./install.sh
+    env:
+      - name: GITHUB_TOKEN
+        help: Create a token at https://github.com/settings/tokens
+
 Available subcommands:
   status - Check current bootstrap status
+  plan - Show the changes install would make, optionally saving them to a plan file
+  apply - Apply a saved plan file
   install - Apply bootstrap configuration to setup this machine
   reset - Reset bootstrap configuration`,
 	Run: func(cmd *cobra.Command, args []string) {
@@ -38,13 +66,32 @@ Available subcommands:
 var bootstrapStatusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Check workstation bootstrap status",
-	Long:  `Check the status of workstation bootstrap configuration including directories, tools, SSH keys, and dotfiles.`,
-	Run: func(cmd *cobra.Command, args []string) {
+	Long: `Check the status of workstation bootstrap configuration including directories, tools, SSH keys, and dotfiles.
+
+Each check reports one of:
+  ok       in place
+  missing  absent; 'bootstrap install' can add it
+  drifted  present but not as expected (e.g. unpushed dotfiles); see the hints
+  error    could not be checked or cannot be installed automatically
+  skipped  not checked
+
+Examples:
+  allbctl bootstrap status             # Human-readable report
+  allbctl bootstrap status -o json     # Machine-readable results for scripts`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
 		if ctx == nil {
 			ctx = context.Background()
 		}
-		printBootstrapStatus(ctx)
+		if !isStructuredOutput() {
+			printBootstrapStatus(ctx)
+			return nil
+		}
+		report, err := collectBootstrapStatus(ctx)
+		if err != nil {
+			return err
+		}
+		return printStructured(report)
 	},
 }
 
@@ -54,7 +101,18 @@ var bootstrapInstallCmd = &cobra.Command{
 	Long: `Install and configure workstation bootstrap including directories, tools, SSH keys, and dotfiles.
 
 By default, SSH key generation and GitHub registration are SKIPPED.
-Use --register-ssh-keys flag to enable SSH key generation and GitHub registration.`,
+Use --register-ssh-keys flag to enable SSH key generation and GitHub registration.
+
+Independent steps run concurrently (up to --parallel at a time). Steps wait for
+their prerequisites, e.g. SSH key registration waits for gh and dotfiles wait for
+git and SSH; if a prerequisite fails, the steps that need it are skipped.
+Package installs always run one at a time.
+
+Each install records when every step started and finished in a journal under the
+user cache directory. If an install is interrupted (e.g. while running the dotfiles
+install script), 'allbctl bootstrap install --resume' skips the steps that finished
+and re-runs the ones that failed or never finished. 'allbctl bootstrap status'
+shows the timeline of the last attempt.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := cmd.Context()
 		if ctx == nil {
@@ -62,10 +120,9 @@ Use --register-ssh-keys flag to enable SSH key generation and GitHub registratio
 		}
 
 		os := osagnostic.NewOperatingSystem()
-		identifier := computerSetup.MachineIdentifier{}
-		configProvider := identifier.ConfigurationProviderForOperatingSystem(os.Name)
-		if configProvider == nil {
-			fmt.Printf("No configuration provider for %s\n", os.Name)
+		configProvider, err := bootstrapConfigProvider(os.Name)
+		if err != nil {
+			fmt.Println(err)
 			return
 		}
 
@@ -81,14 +138,165 @@ Use --register-ssh-keys flag to enable SSH key generation and GitHub registratio
 			"config_count", len(configs),
 		)
 
+		journalPath, err := computerSetup.DefaultInstallJournalPath()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
 		tweaker := computerSetup.NewMachineTweaker(configs)
-		_, out := tweaker.ApplyConfiguration()
+		tweaker.Workers = installParallelism
+		tweaker.Journal = computerSetup.NewInstallJournal(journalPath)
+		if resumeInstall {
+			previous, err := computerSetup.ReadInstallAttempt(journalPath)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			if previous == nil {
+				fmt.Println("No install journal found; nothing to resume. Run 'allbctl bootstrap install'.")
+				return
+			}
+			if !previous.Interrupted() && len(previous.Incomplete()) == 0 {
+				fmt.Println("The last install finished without errors; nothing to resume.")
+				return
+			}
+			fmt.Printf("Resuming install started %s (%d step(s) already completed)\n\n",
+				previous.StartedAt.Local().Format("2006-01-02 15:04:05"), len(previous.Completed()))
+			tweaker.Resume = previous
+		}
+
+		results, errs := tweaker.ApplyConfiguration()
+		out := bytes.NewBufferString("")
+		for _, result := range results {
+			writeResultSection(out, result)
+		}
+		if len(errs) > 0 {
+			out.WriteString(fmt.Sprintf("Install finished with errors: %s\n", resultSummary(results)))
+		} else {
+			out.WriteString(fmt.Sprintf("Install complete: %s\n", resultSummary(results)))
+		}
 		fmt.Print(out.String())
 
 		telemetry.Logger.InfoContext(ctx, "bootstrap.install.finish",
 			"os", os.Name,
 			"config_count", len(configs),
+			"error_count", len(errs),
+			"resumed", resumeInstall,
 		)
+
+		if err := tweaker.Journal.Err(); err != nil {
+			log.Printf("warning: %v", err)
+		}
+	},
+}
+
+var bootstrapPlanCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Show the changes bootstrap install would make",
+	Long: `Compute the changes needed to bring this machine in line with the bootstrap
+configuration, without changing anything: directories to create, packages to install
+(and the exact command), repos to clone, scripts to run and SSH keys to register.
+
+Save the plan with --out and review it before running 'allbctl bootstrap apply <file>'.
+Like install, SSH key registration is only planned with --register-ssh-keys.
+
+Examples:
+  allbctl bootstrap plan                   # Review what install would do
+  allbctl bootstrap plan --out plan.json   # Save the plan for 'bootstrap apply'`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		os := osagnostic.NewOperatingSystem()
+		configProvider, err := bootstrapConfigProvider(os.Name)
+		if err != nil {
+			return err
+		}
+		configs := configProvider.GetConfiguration()
+		if !registerSSHKeys {
+			configs = computerSetup.FilterOutSSHKeyRegistration(configs)
+		}
+
+		plan, errs := computerSetup.NewMachineTweaker(configs).Plan()
+		plan.Source = bootstrapConfigSource()
+
+		telemetry.Logger.InfoContext(ctx, "bootstrap.plan",
+			"os", os.Name,
+			"source", plan.Source,
+			"action_count", len(plan.Actions),
+			"satisfied_count", len(plan.Satisfied),
+			"error_count", len(errs),
+		)
+
+		printBootstrapPlan(plan)
+		if len(errs) > 0 {
+			fmt.Println()
+			for _, err := range errs {
+				fmt.Printf("  ❌ %v\n", err)
+			}
+			return fmt.Errorf("could not plan %d configuration(s)", len(errs))
+		}
+
+		if planOutFile != "" {
+			if err := computerSetup.WritePlanFile(planOutFile, plan); err != nil {
+				return err
+			}
+			fmt.Printf("\nSaved plan to %s. Apply it with: allbctl bootstrap apply %s\n", planOutFile, planOutFile)
+		}
+		return nil
+	},
+}
+
+var bootstrapApplyCmd = &cobra.Command{
+	Use:   "apply <plan-file>",
+	Short: "Apply a plan saved by bootstrap plan",
+	Long: `Execute exactly the actions in a plan file written by 'allbctl bootstrap plan --out',
+in order. The configuration is not re-read, so the machine ends up with what you reviewed.
+
+Examples:
+  allbctl bootstrap plan --out plan.json
+  allbctl bootstrap apply plan.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		plan, err := computerSetup.ReadPlanFile(args[0])
+		if err != nil {
+			return err
+		}
+		if len(plan.Actions) == 0 {
+			fmt.Println("Plan has no changes. Nothing to apply.")
+			return nil
+		}
+
+		telemetry.Logger.InfoContext(ctx, "bootstrap.apply.start",
+			"plan", args[0],
+			"action_count", len(plan.Actions),
+		)
+
+		results, errs := computerSetup.ApplyPlan(plan)
+		out := bytes.NewBufferString("")
+		for _, result := range results {
+			writeResult(out, result, "", "")
+		}
+		fmt.Print(out.String())
+
+		telemetry.Logger.InfoContext(ctx, "bootstrap.apply.finish",
+			"plan", args[0],
+			"error_count", len(errs),
+		)
+
+		if len(errs) > 0 {
+			return fmt.Errorf("%d of %d actions failed", len(errs), len(plan.Actions))
+		}
+		fmt.Printf("\nApply complete: %d actions.\n", len(plan.Actions))
+		return nil
 	},
 }
 
@@ -112,18 +320,19 @@ var bootstrapResetCmd = &cobra.Command{
 		out.WriteString("\n")
 
 		os := osagnostic.NewOperatingSystem()
-		identifier := computerSetup.MachineIdentifier{}
-		configProvider := identifier.ConfigurationProviderForOperatingSystem(os.Name)
-		if configProvider == nil {
-			log.Fatalf("No configuration provider found for operating system %s", os.Name)
+		configProvider, err := bootstrapConfigProvider(os.Name)
+		if err != nil {
+			log.Fatal(err)
 		}
 
 		tweaker := computerSetup.NewMachineTweaker(configProvider.GetConfiguration())
 
 		telemetry.Logger.InfoContext(ctx, "bootstrap.reset.start", "os", os.Name)
 
-		_, statusOut := tweaker.ResetConfiguration()
-		out.WriteString(statusOut.String())
+		results, _ := tweaker.ResetConfiguration()
+		for _, result := range results {
+			writeResultSection(out, result)
+		}
 
 		telemetry.Logger.InfoContext(ctx, "bootstrap.reset.finish", "os", os.Name)
 
@@ -131,39 +340,195 @@ var bootstrapResetCmd = &cobra.Command{
 	},
 }
 
-func printBootstrapStatus(ctx context.Context) {
+// BootstrapStatusReport is the result of checking every bootstrap configuration
+type BootstrapStatusReport struct {
+	OS      string          `json:"os"`
+	Source  string          `json:"source"` // "built-in" or the config file path
+	Status  model.Status    `json:"status"` // the most severe result status
+	Results []*model.Result `json:"results"`
+	// LastInstall is the journal of the most recent 'bootstrap install', if any
+	LastInstall *computerSetup.InstallAttempt `json:"last_install,omitempty"`
+}
+
+// collectBootstrapStatus validates every configuration without changing anything
+func collectBootstrapStatus(ctx context.Context) (*BootstrapStatusReport, error) {
 	os := osagnostic.NewOperatingSystem()
-	identifier := computerSetup.MachineIdentifier{}
-	configProvider := identifier.ConfigurationProviderForOperatingSystem(os.Name)
-	if configProvider == nil {
-		fmt.Printf("No configuration provider for %s\n", os.Name)
-		return
+	configProvider, err := bootstrapConfigProvider(os.Name)
+	if err != nil {
+		return nil, err
 	}
 
-	tweaker := computerSetup.NewMachineTweaker(configProvider.GetConfiguration())
-	_, out := tweaker.ConfigurationStatus()
+	configs := configProvider.GetConfiguration()
+	results, _ := computerSetup.NewMachineTweaker(configs).ConfigurationStatus()
+	overall, _ := model.NewGroupResult("bootstrap", results)
 
 	telemetry.Logger.InfoContext(ctx, "bootstrap.status",
 		"os", os.Name,
-		"config_count", len(configProvider.GetConfiguration()),
+		"source", bootstrapConfigSource(),
+		"config_count", len(configs),
+		"status", overall.Status,
 	)
 
-	fmt.Println("Workstation Bootstrap Status:")
-	fmt.Println()
+	report := &BootstrapStatusReport{
+		OS:      os.Name,
+		Source:  bootstrapConfigSource(),
+		Status:  overall.Status,
+		Results: results,
+	}
+	// The timeline is informational; an unreadable journal doesn't fail status
+	if journalPath, err := computerSetup.DefaultInstallJournalPath(); err == nil {
+		report.LastInstall, _ = computerSetup.ReadInstallAttempt(journalPath)
+	}
+	return report, nil
+}
+
+func printBootstrapStatus(ctx context.Context) {
+	report, err := collectBootstrapStatus(ctx)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Print(formatBootstrapStatus(report))
+}
+
+// formatBootstrapStatus renders a status report as indented text
+func formatBootstrapStatus(report *BootstrapStatusReport) string {
+	out := bytes.NewBufferString("")
+	for _, result := range report.Results {
+		writeResultSection(out, result)
+	}
+
+	text := bytes.NewBufferString("Workstation Bootstrap Status:\n")
+	if report.Source != "built-in" {
+		text.WriteString(fmt.Sprintf("  (configuration from %s)\n", report.Source))
+	}
+	text.WriteString("\n")
 	// Indent the output
-	lines := strings.Split(out.String(), "\n")
-	for _, line := range lines {
+	for _, line := range strings.Split(out.String(), "\n") {
 		if line != "" {
-			fmt.Printf("  %s\n", line)
+			text.WriteString(fmt.Sprintf("  %s\n", line))
+		}
+	}
+	text.WriteString(fmt.Sprintf("\n%s\n", resultSummary(report.Results)))
+	if report.LastInstall != nil {
+		text.WriteString("\n")
+		writeInstallTimeline(text, report.LastInstall)
+	}
+	return text.String()
+}
+
+// writeInstallTimeline lists each step of an install attempt with its start
+// offset and duration, flagging steps that never finished
+func writeInstallTimeline(out *bytes.Buffer, attempt *computerSetup.InstallAttempt) {
+	state := "finished"
+	if attempt.Interrupted() {
+		state = "interrupted; continue with 'allbctl bootstrap install --resume'"
+	}
+	resumed := ""
+	if attempt.Resumed {
+		resumed = " (resumed)"
+	}
+	out.WriteString(fmt.Sprintf("Last install%s: started %s, %s\n",
+		resumed, attempt.StartedAt.Local().Format("2006-01-02 15:04:05"), state))
+
+	for _, step := range attempt.Steps {
+		offset := step.StartedAt.Sub(attempt.StartedAt).Round(time.Second)
+		label := strings.ToUpper(string(step.Status))
+		duration := ""
+		if step.Finished() {
+			duration = fmt.Sprintf(" (%s)", step.FinishedAt.Sub(step.StartedAt).Round(100*time.Millisecond))
+		} else {
+			label = "UNFINISHED"
+		}
+		labelColor, ok := resultLabels[step.Status]
+		if !ok {
+			labelColor = resultLabels[model.StatusError]
+		}
+
+		out.WriteString(fmt.Sprintf("  +%-6s ", offset))
+		_, _ = labelColor.Fprintf(out, "%-10s", label)
+		out.WriteString(fmt.Sprintf(" %s%s\n", step.Key, duration))
+	}
+}
+
+// bootstrapConfigProvider returns the machine configuration for this machine.
+// A `bootstrap:` section in ~/.allbctl.yaml takes precedence over the built-in
+// provider for the operating system.
+func bootstrapConfigProvider(osName string) (model.IMachineConfigurationProvider, error) {
+	if viper.IsSet(bootstrapConfigKey) {
+		var config providers.MachineConfig
+		if err := viper.UnmarshalKey(bootstrapConfigKey, &config); err != nil {
+			return nil, fmt.Errorf("cannot read %s from %s: %w", bootstrapConfigKey, viper.ConfigFileUsed(), err)
+		}
+		if err := config.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", viper.ConfigFileUsed(), err)
 		}
+		return providers.NewConfigFileProvider(config), nil
+	}
+
+	identifier := computerSetup.MachineIdentifier{}
+	configProvider := identifier.ConfigurationProviderForOperatingSystem(osName)
+	if configProvider == nil {
+		return nil, fmt.Errorf("no configuration provider for %s", osName)
 	}
+	return configProvider, nil
+}
+
+// bootstrapConfigSource describes where the bootstrap configuration comes from
+func bootstrapConfigSource() string {
+	if viper.IsSet(bootstrapConfigKey) {
+		return viper.ConfigFileUsed()
+	}
+	return "built-in"
+}
+
+// planActionSymbols marks each action type in printed plans, Terraform style
+var planActionSymbols = map[model.ActionType]string{
+	model.ActionCreateDirectory: "+",
+	model.ActionInstallPackage:  "+",
+	model.ActionUpgradePackage:  "~",
+	model.ActionCloneRepo:       "+",
+	model.ActionRunScript:       "~",
+	model.ActionRegisterSSHKey:  "+",
+	model.ActionManual:          "!",
+}
+
+func printBootstrapPlan(plan *computerSetup.MachinePlan) {
+	if len(plan.Actions) == 0 {
+		fmt.Printf("No changes. This machine matches the bootstrap configuration (%d checks).\n", len(plan.Satisfied))
+		return
+	}
+
+	fmt.Println("allbctl bootstrap will perform the following actions:")
+	fmt.Println()
+	for _, action := range plan.Actions {
+		symbol, ok := planActionSymbols[action.Type]
+		if !ok {
+			symbol = "?"
+		}
+		fmt.Printf("  %s %s\n", symbol, action.Description)
+		if action.Command != "" {
+			fmt.Printf("      $ %s\n", action.Command)
+		}
+	}
+
+	manual := plan.ManualActionCount()
+	fmt.Printf("\nPlan: %d to apply, %d manual, %d already satisfied.\n",
+		len(plan.Actions)-manual, manual, len(plan.Satisfied))
 }
 
 func init() {
 	BootstrapCmd.AddCommand(bootstrapStatusCmd)
+	BootstrapCmd.AddCommand(bootstrapPlanCmd)
+	BootstrapCmd.AddCommand(bootstrapApplyCmd)
 	BootstrapCmd.AddCommand(bootstrapInstallCmd)
 	BootstrapCmd.AddCommand(bootstrapResetCmd)
 
 	// Add flags to install command
 	bootstrapInstallCmd.Flags().BoolVar(&registerSSHKeys, "register-ssh-keys", false, "Generate SSH keys and register with GitHub (requires gh CLI)")
+	bootstrapInstallCmd.Flags().BoolVar(&resumeInstall, "resume", false, "Continue an interrupted install, skipping the steps it completed")
+	bootstrapInstallCmd.Flags().IntVar(&installParallelism, "parallel", computerSetup.DefaultWorkers, "Maximum number of configuration steps to run at once (1 = one at a time)")
+	bootstrapPlanCmd.Flags().BoolVar(&registerSSHKeys, "register-ssh-keys", false, "Include SSH key generation and GitHub registration in the plan")
+	bootstrapStatusCmd.Flags().VarP(&outputFormat, "output", "o", "Output format: text, json or yaml")
+	bootstrapPlanCmd.Flags().StringVar(&planOutFile, "out", "", "Save the plan to this file for 'bootstrap apply'")
 }
@@ -1,11 +1,17 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	computerSetup "github.com/aallbrig/allbctl/pkg/computersetup"
+	"github.com/aallbrig/allbctl/pkg/model"
 )
 
 // ---------------------------------------------------------------------------
@@ -22,7 +28,7 @@ func TestBootstrapCmdRegistered(t *testing.T) {
 }
 
 func TestBootstrapSubcommandsRegistered(t *testing.T) {
-	want := []string{"status", "install", "reset"}
+	want := []string{"status", "plan", "apply <plan-file>", "install", "reset"}
 	registered := make(map[string]bool)
 	for _, sub := range BootstrapCmd.Commands() {
 		registered[sub.Use] = true
@@ -98,3 +104,176 @@ func TestBootstrapInstallFlagRegistered(t *testing.T) {
 		t.Error("bootstrap install --register-ssh-keys flag not registered")
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Config file provider selection
+// ---------------------------------------------------------------------------
+
+func TestBootstrapConfigProvider_BuiltIn(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	if _, err := bootstrapConfigProvider("linux"); err != nil {
+		t.Errorf("bootstrapConfigProvider(linux) error: %v", err)
+	}
+	if _, err := bootstrapConfigProvider("plan9"); err == nil {
+		t.Error("bootstrapConfigProvider(plan9) expected error, got nil")
+	}
+	if got := bootstrapConfigSource(); got != "built-in" {
+		t.Errorf("bootstrapConfigSource() = %q, want built-in", got)
+	}
+}
+
+func TestBootstrapConfigProvider_FromConfigFile(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	viper.SetConfigType("yaml")
+	config := "bootstrap:\n  directories: [~/work]\n  tools:\n    - command: jq\n"
+	if err := viper.ReadConfig(strings.NewReader(config)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The config file wins over the OS provider, even on an unsupported OS
+	provider, err := bootstrapConfigProvider("plan9")
+	if err != nil {
+		t.Fatalf("bootstrapConfigProvider() error: %v", err)
+	}
+	var names []string
+	for _, c := range provider.GetConfiguration() {
+		names = append(names, c.Name())
+	}
+	if strings.Join(names, ",") != "Expected Directories,Required Tools" {
+		t.Errorf("configuration groups = %v, want [Expected Directories Required Tools]", names)
+	}
+}
+
+func TestBootstrapConfigProvider_InvalidConfig(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	viper.SetConfigType("yaml")
+	if err := viper.ReadConfig(strings.NewReader("bootstrap:\n  tools:\n    - packages: {apt: jq}\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bootstrapConfigProvider("linux"); err == nil {
+		t.Error("bootstrapConfigProvider() with a tool missing its command expected error, got nil")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Plan rendering
+// ---------------------------------------------------------------------------
+
+func TestPrintBootstrapPlan(t *testing.T) {
+	plan := &computerSetup.MachinePlan{
+		Actions: []model.PlannedAction{
+			{Type: model.ActionCreateDirectory, Description: "create directory /home/me/src"},
+			{Type: model.ActionInstallPackage, Description: "install gh via apt (provides gh)", Command: "sudo apt-get install -y gh"},
+			{Type: model.ActionManual, Description: "set environment variable GITHUB_TOKEN"},
+		},
+		Satisfied: []string{"Installable Command: git"},
+	}
+
+	output := captureOutput(func() { printBootstrapPlan(plan) })
+
+	for _, want := range []string{
+		"+ create directory /home/me/src",
+		"$ sudo apt-get install -y gh",
+		"! set environment variable GITHUB_TOKEN",
+		"Plan: 2 to apply, 1 manual, 1 already satisfied.",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("printBootstrapPlan() output missing %q\noutput:\n%s", want, output)
+		}
+	}
+}
+
+func TestPrintBootstrapPlan_NoChanges(t *testing.T) {
+	output := captureOutput(func() {
+		printBootstrapPlan(&computerSetup.MachinePlan{Satisfied: []string{"a", "b"}})
+	})
+	if !strings.Contains(output, "No changes") {
+		t.Errorf("printBootstrapPlan() with no actions should report no changes, got:\n%s", output)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Result rendering
+// ---------------------------------------------------------------------------
+
+func TestFormatBootstrapStatus(t *testing.T) {
+	dotfiles := model.NewResult("Dotfiles Setup", model.StatusDrifted, "cloned: /home/me/src/dotfiles")
+	dotfiles.Children = []*model.Result{
+		model.NewResult("Dotfiles Setup", model.StatusDrifted, "1 local commit(s) not pushed to origin").
+			WithHints("cd /home/me/src/dotfiles && git push"),
+	}
+	tools, _ := model.NewGroupResult("Required Tools", []*model.Result{
+		model.NewResult("Installable Command: git", model.StatusOK, "/usr/bin/git"),
+		model.NewResult("Installable Command: gh", model.StatusMissing, "not found on PATH").
+			WithHints("apt-get install -y gh"),
+	})
+	report := &BootstrapStatusReport{
+		Source:  "/home/me/.allbctl.yaml",
+		Results: []*model.Result{tools, dotfiles},
+	}
+
+	output := formatBootstrapStatus(report)
+
+	for _, want := range []string{
+		"Workstation Bootstrap Status:",
+		"(configuration from /home/me/.allbctl.yaml)",
+		"Required Tools\n  -----",
+		"OK      Installable Command: git: /usr/bin/git",
+		"MISSING Installable Command: gh: not found on PATH",
+		"→ apt-get install -y gh",
+		"DRIFTED cloned: /home/me/src/dotfiles",
+		"    DRIFTED 1 local commit(s) not pushed to origin",
+		"1 ok, 1 missing, 1 drifted",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("formatBootstrapStatus() output missing %q\noutput:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "Required Tools:") {
+		t.Errorf("group line should not repeat the section header\noutput:\n%s", output)
+	}
+}
+
+func TestBootstrapStatusOutputFlagRegistered(t *testing.T) {
+	if bootstrapStatusCmd.Flags().Lookup("output") == nil {
+		t.Error("bootstrap status --output flag not registered")
+	}
+}
+
+func TestWriteInstallTimeline_Interrupted(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	attempt := &computerSetup.InstallAttempt{
+		StartedAt: start,
+		Steps: []computerSetup.JournalStep{
+			{Key: "Required Tools/Installable Command: git", StartedAt: start, FinishedAt: start.Add(2 * time.Second), Status: model.StatusOK},
+			{Key: "Dotfiles/Dotfiles Setup", StartedAt: start.Add(3 * time.Second)},
+		},
+	}
+	out := bytes.NewBufferString("")
+
+	writeInstallTimeline(out, attempt)
+
+	for _, want := range []string{
+		"install --resume",
+		"+0s",
+		"OK         Required Tools/Installable Command: git (2s)",
+		"+3s",
+		"UNFINISHED Dotfiles/Dotfiles Setup",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("writeInstallTimeline() output missing %q\noutput:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestBootstrapInstallResumeFlagRegistered(t *testing.T) {
+	if bootstrapInstallCmd.Flags().Lookup("resume") == nil {
+		t.Error("bootstrap install --resume flag not registered")
+	}
+}
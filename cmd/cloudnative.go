@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os/exec"
+	"io"
+	"os"
+	"sort"
 	"strings"
 	"sync"
 
@@ -13,6 +15,8 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
 	"github.com/spf13/cobra"
+
+	"github.com/aallbrig/allbctl/pkg/runner"
 )
 
 var (
@@ -37,8 +41,10 @@ Examples:
   allbctl status cn                    # Short alias
   allbctl status cloud-native aws      # Show detailed AWS resource info
   allbctl status cn aws --region us-east-1  # AWS resources in specific region`,
-	Run: func(cmd *cobra.Command, args []string) {
-		printCloudNativeSummary()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		clis := detectCloudCLIs(ctx)
+		return renderOutput(clis, func() { printCloudCLIList(os.Stdout, clis) })
 	},
 }
 
@@ -57,8 +63,17 @@ Examples:
   allbctl status cloud-native aws --region us-east-1        # All profiles, specific region
   allbctl status cloud-native aws --profile production      # Specific profile, all regions
   allbctl status cloud-native aws --profile prod --region us-east-1  # Specific profile and region`,
-	Run: func(cmd *cobra.Command, args []string) {
-		printAWSDetails()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		details, err := gatherAWSDetails(ctx)
+		if isStructuredOutput() {
+			if err != nil {
+				return err
+			}
+			return printStructured(details)
+		}
+		printAWSDetails(details, err)
+		return nil
 	},
 }
 
@@ -67,8 +82,13 @@ var CloudNativeGCPCmd = &cobra.Command{
 	Use:   "gcp",
 	Short: "Display detailed GCP resource information",
 	Long:  `Display detailed GCP resource information (implementation pending).`,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if isStructuredOutput() {
+			return printStructured(findCloudCLI(ctx, "gcloud"))
+		}
 		fmt.Println("GCP detailed view: implementation todo")
+		return nil
 	},
 }
 
@@ -77,8 +97,13 @@ var CloudNativeAzureCmd = &cobra.Command{
 	Use:   "azure",
 	Short: "Display detailed Azure resource information",
 	Long:  `Display detailed Azure resource information (implementation pending).`,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if isStructuredOutput() {
+			return printStructured(findCloudCLI(ctx, "az"))
+		}
 		fmt.Println("Azure detailed view: implementation todo")
+		return nil
 	},
 }
 
@@ -88,8 +113,13 @@ var CloudNativeK8sCmd = &cobra.Command{
 	Aliases: []string{"k8s"},
 	Short:   "Display detailed Kubernetes resource information",
 	Long:    `Display detailed Kubernetes resource information (implementation pending).`,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if isStructuredOutput() {
+			return printStructured(findCloudCLI(ctx, "kubectl"))
+		}
 		fmt.Println("Kubernetes detailed view: implementation todo")
+		return nil
 	},
 }
 
@@ -107,17 +137,17 @@ func init() {
 
 // CloudCLIInfo holds information about a cloud CLI
 type CloudCLIInfo struct {
-	Name             string
-	Version          string
-	KustomizeVersion string // For kubectl
-	ProfileCount     int
-	Profiles         []string
-	Connected        bool
+	Name             string   `json:"name"`
+	Version          string   `json:"version,omitempty"`
+	KustomizeVersion string   `json:"kustomize_version,omitempty"` // For kubectl
+	ProfileCount     int      `json:"profile_count"`
+	Profiles         []string `json:"profiles"`
+	Connected        bool     `json:"connected"`
 }
 
 // checkAWSConnectivity checks if AWS CLI can connect to AWS (any profile connected = true)
-func checkAWSConnectivity() bool {
-	profiles := getAWSProfiles()
+func checkAWSConnectivity(ctx context.Context) bool {
+	profiles := getAWSProfiles(ctx)
 	if len(profiles) == 0 {
 		return false
 	}
@@ -131,7 +161,7 @@ func checkAWSConnectivity() bool {
 		wg.Add(1)
 		go func(p string) {
 			defer wg.Done()
-			if checkAWSProfileConnectivity(p) {
+			if checkAWSProfileConnectivity(ctx, p) {
 				mu.Lock()
 				anyConnected = true
 				mu.Unlock()
@@ -144,16 +174,14 @@ func checkAWSConnectivity() bool {
 }
 
 // checkAWSProfileConnectivity checks if a specific AWS profile can connect
-func checkAWSProfileConnectivity(profile string) bool {
-	cmd := exec.Command("aws", "sts", "get-caller-identity", "--profile", profile)
-	err := cmd.Run()
+func checkAWSProfileConnectivity(ctx context.Context, profile string) bool {
+	err := runner.Run(ctx, "aws", "sts", "get-caller-identity", "--profile", profile)
 	return err == nil
 }
 
 // checkGCloudConnectivity checks if gcloud CLI can connect to GCP
-func checkGCloudConnectivity() bool {
-	cmd := exec.Command("gcloud", "auth", "list", "--filter=status:ACTIVE", "--format=value(account)")
-	output, err := cmd.Output()
+func checkGCloudConnectivity(ctx context.Context) bool {
+	output, err := runner.Output(ctx, "gcloud", "auth", "list", "--filter=status:ACTIVE", "--format=value(account)")
 	if err != nil {
 		return false
 	}
@@ -161,23 +189,20 @@ func checkGCloudConnectivity() bool {
 }
 
 // checkAzureConnectivity checks if Azure CLI can connect to Azure
-func checkAzureConnectivity() bool {
-	cmd := exec.Command("az", "account", "show")
-	err := cmd.Run()
+func checkAzureConnectivity(ctx context.Context) bool {
+	err := runner.Run(ctx, "az", "account", "show")
 	return err == nil
 }
 
 // checkKubectlConnectivity checks if kubectl can connect to a cluster
-func checkKubectlConnectivity() bool {
-	cmd := exec.Command("kubectl", "cluster-info")
-	err := cmd.Run()
+func checkKubectlConnectivity(ctx context.Context) bool {
+	err := runner.Run(ctx, "kubectl", "cluster-info")
 	return err == nil
 }
 
 // getKustomizeVersion gets the kustomize version from kubectl
-func getKustomizeVersion() string {
-	cmd := exec.Command("kubectl", "version", "--client", "-o", "json")
-	output, err := cmd.Output()
+func getKustomizeVersion(ctx context.Context) string {
+	output, err := runner.Output(ctx, "kubectl", "version", "--client", "-o", "json")
 	if err != nil {
 		return ""
 	}
@@ -197,8 +222,8 @@ func getKustomizeVersion() string {
 }
 
 // detectCloudCLIs detects installed cloud CLIs and their info
-func detectCloudCLIs() []CloudCLIInfo {
-	var clis []CloudCLIInfo
+func detectCloudCLIs(ctx context.Context) []CloudCLIInfo {
+	clis := []CloudCLIInfo{}
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 
@@ -206,16 +231,16 @@ func detectCloudCLIs() []CloudCLIInfo {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if exists("aws") {
+		if exists(ctx, "aws") {
 			info := CloudCLIInfo{Name: "aws"}
-			if version := getCloudCLIVersion("aws"); version != "" {
+			if version := getCloudCLIVersion(ctx, "aws"); version != "" {
 				info.Version = version
 			}
-			if profiles := getAWSProfiles(); len(profiles) >= 0 {
+			if profiles := getAWSProfiles(ctx); len(profiles) >= 0 {
 				info.ProfileCount = len(profiles)
 				info.Profiles = profiles
 			}
-			info.Connected = checkAWSConnectivity()
+			info.Connected = checkAWSConnectivity(ctx)
 			mu.Lock()
 			clis = append(clis, info)
 			mu.Unlock()
@@ -226,16 +251,16 @@ func detectCloudCLIs() []CloudCLIInfo {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if exists("gcloud") {
+		if exists(ctx, "gcloud") {
 			info := CloudCLIInfo{Name: "gcloud"}
-			if version := getCloudCLIVersion("gcloud"); version != "" {
+			if version := getCloudCLIVersion(ctx, "gcloud"); version != "" {
 				info.Version = version
 			}
-			if profiles := getGCloudProfiles(); len(profiles) >= 0 {
+			if profiles := getGCloudProfiles(ctx); len(profiles) >= 0 {
 				info.ProfileCount = len(profiles)
 				info.Profiles = profiles
 			}
-			info.Connected = checkGCloudConnectivity()
+			info.Connected = checkGCloudConnectivity(ctx)
 			mu.Lock()
 			clis = append(clis, info)
 			mu.Unlock()
@@ -246,16 +271,16 @@ func detectCloudCLIs() []CloudCLIInfo {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if exists("az") {
+		if exists(ctx, "az") {
 			info := CloudCLIInfo{Name: "az"}
-			if version := getCloudCLIVersion("az"); version != "" {
+			if version := getCloudCLIVersion(ctx, "az"); version != "" {
 				info.Version = version
 			}
-			if profiles := getAzureProfiles(); len(profiles) >= 0 {
+			if profiles := getAzureProfiles(ctx); len(profiles) >= 0 {
 				info.ProfileCount = len(profiles)
 				info.Profiles = profiles
 			}
-			info.Connected = checkAzureConnectivity()
+			info.Connected = checkAzureConnectivity(ctx)
 			mu.Lock()
 			clis = append(clis, info)
 			mu.Unlock()
@@ -266,19 +291,19 @@ func detectCloudCLIs() []CloudCLIInfo {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if exists("kubectl") {
+		if exists(ctx, "kubectl") {
 			info := CloudCLIInfo{Name: "kubectl"}
-			if version := getCloudCLIVersion("kubectl"); version != "" {
+			if version := getCloudCLIVersion(ctx, "kubectl"); version != "" {
 				info.Version = version
 			}
-			if kustomizeVersion := getKustomizeVersion(); kustomizeVersion != "" {
+			if kustomizeVersion := getKustomizeVersion(ctx); kustomizeVersion != "" {
 				info.KustomizeVersion = kustomizeVersion
 			}
-			if contexts := getKubeContexts(); len(contexts) >= 0 {
+			if contexts := getKubeContexts(ctx); len(contexts) >= 0 {
 				info.ProfileCount = len(contexts)
 				info.Profiles = contexts
 			}
-			info.Connected = checkKubectlConnectivity()
+			info.Connected = checkKubectlConnectivity(ctx)
 			mu.Lock()
 			clis = append(clis, info)
 			mu.Unlock()
@@ -286,27 +311,40 @@ func detectCloudCLIs() []CloudCLIInfo {
 	}()
 
 	wg.Wait()
+
+	// Goroutines finish in any order; sort so output is stable between runs
+	sort.Slice(clis, func(i, j int) bool { return clis[i].Name < clis[j].Name })
 	return clis
 }
 
+// findCloudCLI returns the detected info for a single cloud CLI, or nil when it is not installed
+func findCloudCLI(ctx context.Context, name string) *CloudCLIInfo {
+	for _, cli := range detectCloudCLIs(ctx) {
+		if cli.Name == name {
+			return &cli
+		}
+	}
+	return nil
+}
+
 // getCloudCLIVersion gets the version of a cloud CLI
-func getCloudCLIVersion(cli string) string {
-	var cmd *exec.Cmd
+func getCloudCLIVersion(ctx context.Context, cli string) string {
+	var argv []string
 
 	switch cli {
 	case "aws":
-		cmd = exec.Command("aws", "--version")
+		argv = []string{"aws", "--version"}
 	case "gcloud":
-		cmd = exec.Command("gcloud", "version", "--format", "value(version)")
+		argv = []string{"gcloud", "version", "--format", "value(version)"}
 	case "az":
-		cmd = exec.Command("az", "version", "--query", "\"azure-cli\"", "-o", "tsv")
+		argv = []string{"az", "version", "--query", "\"azure-cli\"", "-o", "tsv"}
 	case "kubectl":
-		cmd = exec.Command("kubectl", "version", "--client")
+		argv = []string{"kubectl", "version", "--client"}
 	default:
 		return ""
 	}
 
-	output, err := cmd.CombinedOutput()
+	output, err := runner.CombinedOutput(ctx, argv[0], argv[1:]...)
 	if err != nil {
 		return ""
 	}
@@ -357,9 +395,8 @@ func extractCloudCLIVersion(cli, output string) string {
 }
 
 // getAWSProfiles returns list of AWS profiles
-func getAWSProfiles() []string {
-	cmd := exec.Command("aws", "configure", "list-profiles")
-	output, err := cmd.Output()
+func getAWSProfiles(ctx context.Context) []string {
+	output, err := runner.Output(ctx, "aws", "configure", "list-profiles")
 	if err != nil {
 		return []string{}
 	}
@@ -381,10 +418,9 @@ func getAWSProfiles() []string {
 }
 
 // getDefaultAWSProfile returns the default AWS profile name
-func getDefaultAWSProfile() string {
+func getDefaultAWSProfile(ctx context.Context) string {
 	// Check AWS_PROFILE environment variable first
-	cmd := exec.Command("sh", "-c", "echo $AWS_PROFILE")
-	output, err := cmd.Output()
+	output, err := runner.Output(ctx, "sh", "-c", "echo $AWS_PROFILE")
 	if err == nil {
 		profile := strings.TrimSpace(string(output))
 		if profile != "" {
@@ -397,9 +433,8 @@ func getDefaultAWSProfile() string {
 }
 
 // getDefaultRegionForProfile returns the default region for a specific AWS profile
-func getDefaultRegionForProfile(profile string) string {
-	cmd := exec.Command("aws", "configure", "get", "region", "--profile", profile)
-	output, err := cmd.Output()
+func getDefaultRegionForProfile(ctx context.Context, profile string) string {
+	output, err := runner.Output(ctx, "aws", "configure", "get", "region", "--profile", profile)
 	if err != nil {
 		return ""
 	}
@@ -407,9 +442,8 @@ func getDefaultRegionForProfile(profile string) string {
 }
 
 // getGCloudProfiles returns list of GCP accounts
-func getGCloudProfiles() []string {
-	cmd := exec.Command("gcloud", "auth", "list", "--format", "value(account)")
-	output, err := cmd.Output()
+func getGCloudProfiles(ctx context.Context) []string {
+	output, err := runner.Output(ctx, "gcloud", "auth", "list", "--format", "value(account)")
 	if err != nil {
 		return []string{}
 	}
@@ -431,9 +465,8 @@ func getGCloudProfiles() []string {
 }
 
 // getAzureProfiles returns list of Azure accounts
-func getAzureProfiles() []string {
-	cmd := exec.Command("az", "account", "list", "--query", "[].user.name", "-o", "tsv")
-	output, err := cmd.Output()
+func getAzureProfiles(ctx context.Context) []string {
+	output, err := runner.Output(ctx, "az", "account", "list", "--query", "[].user.name", "-o", "tsv")
 	if err != nil {
 		return []string{}
 	}
@@ -455,9 +488,8 @@ func getAzureProfiles() []string {
 }
 
 // getKubeContexts returns list of kubectl contexts
-func getKubeContexts() []string {
-	cmd := exec.Command("kubectl", "config", "get-contexts", "-o", "name")
-	output, err := cmd.Output()
+func getKubeContexts(ctx context.Context) []string {
+	output, err := runner.Output(ctx, "kubectl", "config", "get-contexts", "-o", "name")
 	if err != nil {
 		return []string{}
 	}
@@ -478,76 +510,93 @@ func getKubeContexts() []string {
 	return result
 }
 
-// printCloudNativeSummary prints a summary of all cloud CLIs
-func printCloudNativeSummary() {
-	clis := detectCloudCLIs()
-
+// printCloudCLIList prints a summary of all cloud CLIs
+func printCloudCLIList(w io.Writer, clis []CloudCLIInfo) {
 	if len(clis) == 0 {
 		// No output if no CLIs detected
 		return
 	}
 
-	fmt.Println("Cloud Native:")
+	fmt.Fprintln(w, "Cloud Native:")
 	for _, cli := range clis {
 		// Print CLI name and version
 		if cli.Version != "" {
-			fmt.Printf("  %s (%s)", cli.Name, cli.Version)
+			fmt.Fprintf(w, "  %s (%s)", cli.Name, cli.Version)
 		} else {
-			fmt.Printf("  %s", cli.Name)
+			fmt.Fprintf(w, "  %s", cli.Name)
 		}
 
 		// For kubectl, also show kustomize version
 		if cli.Name == "kubectl" && cli.KustomizeVersion != "" {
-			fmt.Printf(" [kustomize: %s]", cli.KustomizeVersion)
+			fmt.Fprintf(w, " [kustomize: %s]", cli.KustomizeVersion)
 		}
 
 		// Print profile/context count (kubectl uses contexts, others use profiles)
 		if cli.Name == "kubectl" {
 			if cli.ProfileCount == 0 {
-				fmt.Printf(" - 0 contexts")
+				fmt.Fprintf(w, " - 0 contexts")
 			} else if cli.ProfileCount == 1 {
-				fmt.Printf(" - 1 context")
+				fmt.Fprintf(w, " - 1 context")
 			} else {
-				fmt.Printf(" - %d contexts", cli.ProfileCount)
+				fmt.Fprintf(w, " - %d contexts", cli.ProfileCount)
 			}
 		} else {
 			if cli.ProfileCount == 0 {
-				fmt.Printf(" - 0 profiles")
+				fmt.Fprintf(w, " - 0 profiles")
 			} else if cli.ProfileCount == 1 {
-				fmt.Printf(" - 1 profile")
+				fmt.Fprintf(w, " - 1 profile")
 			} else {
-				fmt.Printf(" - %d profiles", cli.ProfileCount)
+				fmt.Fprintf(w, " - %d profiles", cli.ProfileCount)
 			}
 		}
 
 		// Print connectivity status
 		if cli.Connected {
-			fmt.Printf(" ✓\n")
+			fmt.Fprintf(w, " ✓\n")
 		} else {
-			fmt.Printf(" ✗\n")
+			fmt.Fprintf(w, " ✗\n")
 		}
 	}
 }
 
-// printAWSDetails prints detailed AWS resource information
-func printAWSDetails() {
+// AWSDetails is the structured form of `status cloud-native aws`
+type AWSDetails struct {
+	Version  string              `json:"version,omitempty"`
+	Profiles []AWSProfileDetails `json:"profiles"`
+}
+
+// AWSProfileDetails holds connectivity and per-region resource counts for one profile
+type AWSProfileDetails struct {
+	Name          string             `json:"name"`
+	Default       bool               `json:"default"`
+	Connected     bool               `json:"connected"`
+	DefaultRegion string             `json:"default_region,omitempty"`
+	Error         string             `json:"error,omitempty"`
+	Regions       []AWSRegionDetails `json:"regions,omitempty"`
+}
+
+// AWSRegionDetails holds resource counts by type for a region that has resources
+type AWSRegionDetails struct {
+	Region    string           `json:"region"`
+	Default   bool             `json:"default"`
+	Total     int64            `json:"total"`
+	Resources map[string]int64 `json:"resources"`
+}
+
+// gatherAWSDetails collects AWS resource information for the selected profiles.
+// The returned details may be partially filled (e.g. just the CLI version) when an error is returned.
+func gatherAWSDetails(ctx context.Context) (*AWSDetails, error) {
 	// Check if AWS CLI is available
-	if !exists("aws") {
-		fmt.Println("AWS CLI not found")
-		return
+	if !exists(ctx, "aws") {
+		return nil, fmt.Errorf("AWS CLI not found")
 	}
 
-	// Get AWS version
-	version := getCloudCLIVersion("aws")
-	if version != "" {
-		fmt.Printf("AWS CLI: %s\n\n", version)
-	}
+	details := &AWSDetails{Version: getCloudCLIVersion(ctx, "aws")}
 
 	// Get profiles
-	allProfiles := getAWSProfiles()
+	allProfiles := getAWSProfiles(ctx)
 	if len(allProfiles) == 0 {
-		fmt.Println("No AWS profiles configured")
-		return
+		return details, fmt.Errorf("No AWS profiles configured")
 	}
 
 	// Filter profiles if --profile flag is specified
@@ -562,100 +611,156 @@ func printAWSDetails() {
 			}
 		}
 		if !profileExists {
-			fmt.Printf("Profile '%s' not found. Available profiles: %v\n", profileFlag, allProfiles)
-			return
+			return details, fmt.Errorf("Profile '%s' not found. Available profiles: %v", profileFlag, allProfiles)
 		}
 		profiles = []string{profileFlag}
 	} else {
 		profiles = allProfiles
 	}
 
-	fmt.Printf("Profiles: %d\n", len(profiles))
-
 	// Get default profile if showing multiple profiles
 	var defaultProfile string
 	if len(profiles) > 1 {
-		defaultProfile = getDefaultAWSProfile()
+		defaultProfile = getDefaultAWSProfile(ctx)
 	}
 
 	// Process each profile in parallel
+	details.Profiles = make([]AWSProfileDetails, len(profiles))
 	var wg sync.WaitGroup
-	for _, profile := range profiles {
+	for i, profile := range profiles {
 		wg.Add(1)
-		go func(p string) {
+		go func(idx int, p string) {
 			defer wg.Done()
 			isDefault := len(profiles) > 1 && p == defaultProfile
-			printAWSProfileResources(p, isDefault)
-		}(profile)
+			details.Profiles[idx] = gatherAWSProfileResources(ctx, p, isDefault)
+		}(i, profile)
 	}
 	wg.Wait()
+
+	return details, nil
 }
 
-// printAWSProfileResources prints AWS resources for a specific profile
-func printAWSProfileResources(profile string, isDefaultProfile bool) {
-	// Check connectivity first
-	connected := checkAWSProfileConnectivity(profile)
-	connectStatus := "✓"
-	if !connected {
-		connectStatus = "✗"
+// printAWSDetails prints detailed AWS resource information
+func printAWSDetails(details *AWSDetails, err error) {
+	if details != nil && details.Version != "" {
+		fmt.Printf("AWS CLI: %s\n\n", details.Version)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return
 	}
 
-	// Build profile header
-	profileHeader := fmt.Sprintf("Profile: %s", profile)
-	if isDefaultProfile {
-		profileHeader += " (default)"
+	fmt.Printf("Profiles: %d\n", len(details.Profiles))
+	for _, profile := range details.Profiles {
+		printAWSProfileResources(profile)
 	}
-	profileHeader += fmt.Sprintf(" [%s]", connectStatus)
-	fmt.Printf("\n%s\n", profileHeader)
+}
 
-	if !connected {
-		fmt.Printf("  Unable to connect to AWS with this profile\n")
-		return
+// gatherAWSProfileResources collects AWS resources for a specific profile
+func gatherAWSProfileResources(ctx context.Context, profile string, isDefaultProfile bool) AWSProfileDetails {
+	result := AWSProfileDetails{Name: profile, Default: isDefaultProfile}
+
+	// Check connectivity first
+	result.Connected = checkAWSProfileConnectivity(ctx, profile)
+	if !result.Connected {
+		return result
 	}
 
 	// Get default region for this profile
-	defaultRegion := getDefaultRegionForProfile(profile)
-	if defaultRegion != "" {
-		fmt.Printf("  Default region: %s\n", defaultRegion)
-	}
+	result.DefaultRegion = getDefaultRegionForProfile(ctx, profile)
 
 	// Load AWS config for this profile
-	cfg, err := config.LoadDefaultConfig(context.TODO(),
+	cfg, err := config.LoadDefaultConfig(ctx,
 		config.WithSharedConfigProfile(profile),
 	)
 	if err != nil {
-		fmt.Printf("  Error loading config: %v\n", err)
-		return
+		result.Error = fmt.Sprintf("Error loading config: %v", err)
+		return result
 	}
 
 	// Get regions to check
-	regions := getAWSRegions(cfg)
+	regions := getAWSRegions(ctx, cfg)
 	if regionFlag != "" {
 		regions = []string{regionFlag}
 	}
 
 	// Query each region in parallel
 	var wg sync.WaitGroup
+	var mu sync.Mutex
 	for _, region := range regions {
 		wg.Add(1)
 		go func(r string) {
 			defer wg.Done()
-			isDefaultRegion := r == defaultRegion
-			queryAWSRegion(profile, r, isDefaultRegion)
+			if regionDetails := queryAWSRegion(ctx, profile, r, r == result.DefaultRegion); regionDetails != nil {
+				mu.Lock()
+				result.Regions = append(result.Regions, *regionDetails)
+				mu.Unlock()
+			}
 		}(region)
 	}
 	wg.Wait()
+
+	sort.Slice(result.Regions, func(i, j int) bool { return result.Regions[i].Region < result.Regions[j].Region })
+	return result
+}
+
+// printAWSProfileResources prints AWS resources for a specific profile
+func printAWSProfileResources(profile AWSProfileDetails) {
+	connectStatus := "✓"
+	if !profile.Connected {
+		connectStatus = "✗"
+	}
+
+	// Build profile header
+	profileHeader := fmt.Sprintf("Profile: %s", profile.Name)
+	if profile.Default {
+		profileHeader += " (default)"
+	}
+	profileHeader += fmt.Sprintf(" [%s]", connectStatus)
+	fmt.Printf("\n%s\n", profileHeader)
+
+	if !profile.Connected {
+		fmt.Printf("  Unable to connect to AWS with this profile\n")
+		return
+	}
+
+	if profile.DefaultRegion != "" {
+		fmt.Printf("  Default region: %s\n", profile.DefaultRegion)
+	}
+
+	if profile.Error != "" {
+		fmt.Printf("  %s\n", profile.Error)
+		return
+	}
+
+	for _, region := range profile.Regions {
+		regionLabel := fmt.Sprintf("  Region: %s", region.Region)
+		if region.Default {
+			regionLabel += " (default)"
+		}
+		regionLabel += fmt.Sprintf(" (Total: %d resources)", region.Total)
+		fmt.Printf("%s\n", regionLabel)
+
+		resourceTypes := make([]string, 0, len(region.Resources))
+		for resourceType := range region.Resources {
+			resourceTypes = append(resourceTypes, resourceType)
+		}
+		sort.Strings(resourceTypes)
+		for _, resourceType := range resourceTypes {
+			fmt.Printf("    %s: %d\n", resourceType, region.Resources[resourceType])
+		}
+	}
 }
 
 // getAWSRegions returns list of AWS regions to check
-func getAWSRegions(cfg aws.Config) []string {
+func getAWSRegions(ctx context.Context, cfg aws.Config) []string {
 	// Try to get all regions dynamically from EC2
 	ec2Client := ec2.NewFromConfig(cfg)
 	input := &ec2.DescribeRegionsInput{
 		AllRegions: aws.Bool(true),
 	}
 
-	result, err := ec2Client.DescribeRegions(context.TODO(), input)
+	result, err := ec2Client.DescribeRegions(ctx, input)
 	if err == nil && len(result.Regions) > 0 {
 		regions := make([]string, 0, len(result.Regions))
 		for _, region := range result.Regions {
@@ -699,15 +804,16 @@ func getAWSRegions(cfg aws.Config) []string {
 	}
 }
 
-// queryAWSRegion queries AWS Resource Groups Tagging API for resource counts in a region
-func queryAWSRegion(profile, region string, isDefaultRegion bool) {
+// queryAWSRegion queries AWS Resource Groups Tagging API for resource counts in a region.
+// Returns nil when the region could not be queried or holds no resources.
+func queryAWSRegion(ctx context.Context, profile, region string, isDefaultRegion bool) *AWSRegionDetails {
 	// Load config with specific region
-	cfg, err := config.LoadDefaultConfig(context.TODO(),
+	cfg, err := config.LoadDefaultConfig(ctx,
 		config.WithRegion(region),
 		config.WithSharedConfigProfile(profile),
 	)
 	if err != nil {
-		return
+		return nil
 	}
 
 	// Create Resource Groups Tagging API client
@@ -724,10 +830,10 @@ func queryAWSRegion(profile, region string, isDefaultRegion bool) {
 			ResourceTypeFilters: []string{}, // Empty means all resource types
 		}
 
-		resp, err := client.GetResources(context.TODO(), input)
+		resp, err := client.GetResources(ctx, input)
 		if err != nil {
 			// Silently skip regions with errors (e.g., permission issues, service not available)
-			return
+			return nil
 		}
 
 		// Count resources by type
@@ -751,20 +857,16 @@ func queryAWSRegion(profile, region string, isDefaultRegion bool) {
 		total += count
 	}
 
-	// Only print if there are resources
-	if total > 0 {
-		regionLabel := fmt.Sprintf("  Region: %s", region)
-		if isDefaultRegion {
-			regionLabel += " (default)"
-		}
-		regionLabel += fmt.Sprintf(" (Total: %d resources)", total)
-		fmt.Printf("%s\n", regionLabel)
+	// Only report regions that have resources
+	if total == 0 {
+		return nil
+	}
 
-		for resourceType, count := range resourceCounts {
-			if count > 0 {
-				fmt.Printf("    %s: %d\n", resourceType, count)
-			}
-		}
+	return &AWSRegionDetails{
+		Region:    region,
+		Default:   isDefaultRegion,
+		Total:     total,
+		Resources: resourceCounts,
 	}
 }
 
@@ -795,53 +897,12 @@ type AWSResourceCount struct {
 }
 
 // printCloudNativeForStatus prints cloud-native summary in status command format
-func printCloudNativeForStatus() {
-	clis := detectCloudCLIs()
-
+func printCloudNativeForStatus(w io.Writer, clis []CloudCLIInfo) {
 	if len(clis) == 0 {
 		// No output if no CLIs detected
 		return
 	}
 
-	fmt.Println("Cloud Native:")
-	for _, cli := range clis {
-		// Print CLI name and version
-		if cli.Version != "" {
-			fmt.Printf("  %s (%s)", cli.Name, cli.Version)
-		} else {
-			fmt.Printf("  %s", cli.Name)
-		}
-
-		// For kubectl, also show kustomize version
-		if cli.Name == "kubectl" && cli.KustomizeVersion != "" {
-			fmt.Printf(" [kustomize: %s]", cli.KustomizeVersion)
-		}
-
-		// Print profile/context count (kubectl uses contexts, others use profiles)
-		if cli.Name == "kubectl" {
-			if cli.ProfileCount == 0 {
-				fmt.Printf(" - 0 contexts")
-			} else if cli.ProfileCount == 1 {
-				fmt.Printf(" - 1 context")
-			} else {
-				fmt.Printf(" - %d contexts", cli.ProfileCount)
-			}
-		} else {
-			if cli.ProfileCount == 0 {
-				fmt.Printf(" - 0 profiles")
-			} else if cli.ProfileCount == 1 {
-				fmt.Printf(" - 1 profile")
-			} else {
-				fmt.Printf(" - %d profiles", cli.ProfileCount)
-			}
-		}
-
-		// Print connectivity status
-		if cli.Connected {
-			fmt.Printf(" ✓\n")
-		} else {
-			fmt.Printf(" ✗\n")
-		}
-	}
-	fmt.Println()
+	printCloudCLIList(w, clis)
+	fmt.Fprintln(w)
 }
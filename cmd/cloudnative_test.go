@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"testing"
 )
 
@@ -54,57 +55,62 @@ func TestExtractCloudCLIVersion(t *testing.T) {
 }
 
 func TestDetectCloudCLIs(t *testing.T) {
+	ctx := context.Background()
 	// This test verifies the function runs without errors
 	// Actual CLI detection depends on system state
-	clis := detectCloudCLIs()
+	clis := detectCloudCLIs(ctx)
 
 	// Should return a slice (may be empty if no CLIs installed)
 	if clis == nil {
-		t.Error("detectCloudCLIs() returned nil; want non-nil slice")
+		t.Error("detectCloudCLIs(ctx) returned nil; want non-nil slice")
 	}
 }
 
 func TestGetAWSProfiles(t *testing.T) {
+	ctx := context.Background()
 	// This test verifies the function runs without errors
 	// Actual profiles depend on system configuration
-	profiles := getAWSProfiles()
+	profiles := getAWSProfiles(ctx)
 
 	// Should return a slice (may be empty if no profiles configured)
 	if profiles == nil {
-		t.Error("getAWSProfiles() returned nil; want non-nil slice")
+		t.Error("getAWSProfiles(ctx) returned nil; want non-nil slice")
 	}
 }
 
 func TestGetGCloudProfiles(t *testing.T) {
+	ctx := context.Background()
 	// This test verifies the function runs without errors
 	// Actual profiles depend on system configuration
-	profiles := getGCloudProfiles()
+	profiles := getGCloudProfiles(ctx)
 
 	// Should return a slice (may be empty if gcloud not installed)
 	if profiles == nil {
-		t.Error("getGCloudProfiles() returned nil; want non-nil slice")
+		t.Error("getGCloudProfiles(ctx) returned nil; want non-nil slice")
 	}
 }
 
 func TestGetAzureProfiles(t *testing.T) {
+	ctx := context.Background()
 	// This test verifies the function runs without errors
 	// Actual profiles depend on system configuration
-	profiles := getAzureProfiles()
+	profiles := getAzureProfiles(ctx)
 
 	// Should return a slice (may be empty if az not installed)
 	if profiles == nil {
-		t.Error("getAzureProfiles() returned nil; want non-nil slice")
+		t.Error("getAzureProfiles(ctx) returned nil; want non-nil slice")
 	}
 }
 
 func TestGetKubeContexts(t *testing.T) {
+	ctx := context.Background()
 	// This test verifies the function runs without errors
 	// Actual contexts depend on system configuration
-	contexts := getKubeContexts()
+	contexts := getKubeContexts(ctx)
 
 	// Should return a slice (may be empty if kubectl not installed)
 	if contexts == nil {
-		t.Error("getKubeContexts() returned nil; want non-nil slice")
+		t.Error("getKubeContexts(ctx) returned nil; want non-nil slice")
 	}
 }
 
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"io"
 	"os"
 	"runtime"
@@ -44,6 +45,8 @@ func TestAllStatusSubcommandsRegistered(t *testing.T) {
 		"git",
 		"ports",
 		"cloud-native",
+		"history",
+		"diff",
 	}
 
 	registered := map[string]bool{}
@@ -64,9 +67,10 @@ func TestAllStatusSubcommandsRegistered(t *testing.T) {
 
 // TestPrintContainersInfo verifies the function produces output without panicking.
 func TestPrintContainersInfo(t *testing.T) {
-	output := captureOutput(PrintContainersInfo)
+	ctx := context.Background()
+	output := captureOutput(func() { PrintContainersInfo(ctx) })
 	if !strings.Contains(output, "Containers") {
-		t.Errorf("PrintContainersInfo() output missing 'Containers'\noutput:\n%s", output)
+		t.Errorf("PrintContainersInfo(ctx) output missing 'Containers'\noutput:\n%s", output)
 	}
 }
 
@@ -86,9 +90,10 @@ func TestContainersCmdRegistered(t *testing.T) {
 
 // TestPrintDatabaseSummaryForStatus verifies the function doesn't panic.
 func TestPrintDatabaseSummaryForStatus(t *testing.T) {
-	output := captureOutput(PrintDatabaseSummaryForStatus)
+	ctx := context.Background()
+	output := captureOutput(func() { PrintDatabaseSummaryForStatus(ctx) })
 	// May print nothing if no databases found — just verify no panic.
-	t.Logf("PrintDatabaseSummaryForStatus() output length: %d", len(output))
+	t.Logf("PrintDatabaseSummaryForStatus(ctx) output length: %d", len(output))
 }
 
 // TestDbCmdFlags verifies --detail / -d flags exist.
@@ -116,25 +121,27 @@ func TestDbCmdRegistered(t *testing.T) {
 
 // TestPrintPortsInfo verifies the function produces output without panicking.
 func TestPrintPortsInfo(t *testing.T) {
-	output := captureOutput(PrintPortsInfo)
+	ctx := context.Background()
+	output := captureOutput(func() { PrintPortsInfo(ctx) })
 	if !strings.Contains(output, "Ports") {
-		t.Errorf("PrintPortsInfo() output missing 'Ports'\noutput:\n%s", output)
+		t.Errorf("PrintPortsInfo(ctx) output missing 'Ports'\noutput:\n%s", output)
 	}
 }
 
 // TestGatherPortsInfo verifies gatherPortsInfo returns a non-nil struct.
 func TestGatherPortsInfo(t *testing.T) {
-	info := gatherPortsInfo()
+	ctx := context.Background()
+	info := gatherPortsInfo(ctx)
 	if info == nil {
-		t.Fatal("gatherPortsInfo() returned nil")
+		t.Fatal("gatherPortsInfo(ctx) returned nil")
 	}
 	if info.TCPPorts < 0 {
-		t.Errorf("gatherPortsInfo().TCPPorts = %d, want >= 0", info.TCPPorts)
+		t.Errorf("gatherPortsInfo(ctx).TCPPorts = %d, want >= 0", info.TCPPorts)
 	}
 	if info.UDPPorts < 0 {
-		t.Errorf("gatherPortsInfo().UDPPorts = %d, want >= 0", info.UDPPorts)
+		t.Errorf("gatherPortsInfo(ctx).UDPPorts = %d, want >= 0", info.UDPPorts)
 	}
-	t.Logf("gatherPortsInfo(): TCP=%d UDP=%d total=%d", info.TCPPorts, info.UDPPorts, len(info.Ports))
+	t.Logf("gatherPortsInfo(ctx): TCP=%d UDP=%d total=%d", info.TCPPorts, info.UDPPorts, len(info.Ports))
 }
 
 // ---------------------------------------------------------------------------
@@ -143,30 +150,32 @@ func TestGatherPortsInfo(t *testing.T) {
 
 // TestPrintSystemctlInfo verifies the function handles non-Linux gracefully.
 func TestPrintSystemctlInfo(t *testing.T) {
-	output := captureOutput(PrintSystemctlInfo)
+	ctx := context.Background()
+	output := captureOutput(func() { PrintSystemctlInfo(ctx, os.Stdout) })
 	if runtime.GOOS != "linux" {
 		if !strings.Contains(output, "only available on Linux") {
-			t.Errorf("PrintSystemctlInfo() on non-Linux should say 'only available on Linux'\noutput:\n%s", output)
+			t.Errorf("PrintSystemctlInfo(ctx) on non-Linux should say 'only available on Linux'\noutput:\n%s", output)
 		}
 	} else {
 		// On Linux: either shows services or "not found" — just must not be empty.
 		if output == "" {
-			t.Error("PrintSystemctlInfo() produced no output on Linux")
+			t.Error("PrintSystemctlInfo(ctx) produced no output on Linux")
 		}
 	}
-	t.Logf("PrintSystemctlInfo() output length: %d", len(output))
+	t.Logf("PrintSystemctlInfo(ctx) output length: %d", len(output))
 }
 
 // TestGatherSystemctlInfo verifies gatherSystemctlInfo never returns nil.
 func TestGatherSystemctlInfo(t *testing.T) {
-	info := gatherSystemctlInfo()
+	ctx := context.Background()
+	info := gatherSystemctlInfo(ctx)
 	if info == nil {
-		t.Fatal("gatherSystemctlInfo() returned nil")
+		t.Fatal("gatherSystemctlInfo(ctx) returned nil")
 	}
 	if info.SystemRunning < 0 {
-		t.Errorf("gatherSystemctlInfo().SystemRunning = %d, want >= 0", info.SystemRunning)
+		t.Errorf("gatherSystemctlInfo(ctx).SystemRunning = %d, want >= 0", info.SystemRunning)
 	}
-	t.Logf("gatherSystemctlInfo(): sysRunning=%d sysFailed=%d userRunning=%d userFailed=%d",
+	t.Logf("gatherSystemctlInfo(ctx): sysRunning=%d sysFailed=%d userRunning=%d userFailed=%d",
 		info.SystemRunning, info.SystemFailed, info.UserRunning, info.UserFailed)
 }
 
@@ -176,22 +185,24 @@ func TestGatherSystemctlInfo(t *testing.T) {
 
 // TestPrintGitConfigInfo verifies the function produces output without panicking.
 func TestPrintGitConfigInfo(t *testing.T) {
-	output := captureOutput(PrintGitConfigInfo)
+	ctx := context.Background()
+	output := captureOutput(func() { PrintGitConfigInfo(ctx) })
 	// Either shows git config or "Git is not installed" — must produce something.
 	if output == "" {
-		t.Error("PrintGitConfigInfo() produced no output")
+		t.Error("PrintGitConfigInfo(ctx) produced no output")
 	}
-	t.Logf("PrintGitConfigInfo() output length: %d", len(output))
+	t.Logf("PrintGitConfigInfo(ctx) output length: %d", len(output))
 }
 
 // TestGatherGitConfigInfo verifies gatherGitConfigInfo never returns nil.
 func TestGatherGitConfigInfo(t *testing.T) {
-	info := gatherGitConfigInfo()
+	ctx := context.Background()
+	info := gatherGitConfigInfo(ctx)
 	if info == nil {
-		t.Fatal("gatherGitConfigInfo() returned nil")
+		t.Fatal("gatherGitConfigInfo(ctx) returned nil")
 	}
 	// Fields may be empty strings if not configured — that's fine.
-	t.Logf("gatherGitConfigInfo(): name=%q email=%q editor=%q",
+	t.Logf("gatherGitConfigInfo(ctx): name=%q email=%q editor=%q",
 		info.UserName, info.UserEmail, info.CoreEditor)
 }
 
@@ -201,25 +212,27 @@ func TestGatherGitConfigInfo(t *testing.T) {
 
 // TestPrintSecurityInfo verifies the function produces output without panicking.
 func TestPrintSecurityInfo(t *testing.T) {
-	output := captureOutput(PrintSecurityInfo)
+	ctx := context.Background()
+	output := captureOutput(func() { PrintSecurityInfo(ctx) })
 	if !strings.Contains(output, "SSH Keys") {
-		t.Errorf("PrintSecurityInfo() output missing 'SSH Keys'\noutput:\n%s", output)
+		t.Errorf("PrintSecurityInfo(ctx) output missing 'SSH Keys'\noutput:\n%s", output)
 	}
 }
 
 // TestGatherSecurityInfo verifies gatherSecurityInfo returns an initialized struct.
 func TestGatherSecurityInfo(t *testing.T) {
-	info := gatherSecurityInfo()
+	ctx := context.Background()
+	info := gatherSecurityInfo(ctx)
 	if info == nil {
-		t.Fatal("gatherSecurityInfo() returned nil")
+		t.Fatal("gatherSecurityInfo(ctx) returned nil")
 	}
 	// Slices may be empty if no keys are loaded — that's valid.
 	if info.SSHKeys == nil {
-		t.Error("gatherSecurityInfo().SSHKeys is nil, expected initialized slice")
+		t.Error("gatherSecurityInfo(ctx).SSHKeys is nil, expected initialized slice")
 	}
 	if info.GPGKeys == nil {
-		t.Error("gatherSecurityInfo().GPGKeys is nil, expected initialized slice")
+		t.Error("gatherSecurityInfo(ctx).GPGKeys is nil, expected initialized slice")
 	}
-	t.Logf("gatherSecurityInfo(): sshKeys=%d gpgKeys=%d keyring=%q",
+	t.Logf("gatherSecurityInfo(ctx): sshKeys=%d gpgKeys=%d keyring=%q",
 		len(info.SSHKeys), len(info.GPGKeys), info.KeyringInfo)
 }
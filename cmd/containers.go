@@ -1,89 +1,120 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
+	"io"
+	"os"
 	"runtime"
 	"strings"
 
 	"github.com/spf13/cobra"
+
+	"github.com/aallbrig/allbctl/pkg/runner"
 )
 
 var ContainersCmd = &cobra.Command{
 	Use:   "containers",
 	Short: "Display container and virtualization information",
-	Long:  `Display information about containers (Docker, Podman) and virtualization status.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		PrintContainersInfo()
+	Long: `Display information about containers (Docker, Podman) and virtualization status.
+
+Use --watch to keep the view open; containers starting or stopping are highlighted.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if watchMode {
+			return watchCommand(cmd, watchFrame(func(ctx context.Context, w io.Writer) { printContainersReport(w, gatherContainersReport(ctx)) }))
+		}
+		report := gatherContainersReport(ctx)
+		return renderOutput(report, func() { printContainersReport(os.Stdout, report) })
 	},
 }
 
 type ContainerInfo struct {
-	Runtime     string
-	Running     int
-	Images      int
-	ImagesList  []string
-	Virtualized bool
-	VirtType    string
+	Runtime     string   `json:"runtime,omitempty"`
+	Running     int      `json:"running"`
+	Images      int      `json:"images"`
+	ImagesList  []string `json:"images_list,omitempty"`
+	Virtualized bool     `json:"virtualized"`
+	VirtType    string   `json:"virt_type,omitempty"`
+}
+
+// ContainersReport groups the detected container runtimes with virtualization status
+type ContainersReport struct {
+	Docker      *ContainerInfo `json:"docker,omitempty"`
+	Podman      *ContainerInfo `json:"podman,omitempty"`
+	Virtualized bool           `json:"virtualized"`
+	VirtType    string         `json:"virt_type,omitempty"`
+}
+
+func gatherContainersReport(ctx context.Context) *ContainersReport {
+	virtInfo := checkVirtualization(ctx)
+	return &ContainersReport{
+		Docker:      checkDocker(ctx),
+		Podman:      checkPodman(ctx),
+		Virtualized: virtInfo.Virtualized,
+		VirtType:    virtInfo.VirtType,
+	}
+}
+
+func PrintContainersInfo(ctx context.Context) {
+	printContainersReport(os.Stdout, gatherContainersReport(ctx))
 }
 
-func PrintContainersInfo() {
-	fmt.Println("Containers/Virtualization:")
-	fmt.Println()
+func printContainersReport(w io.Writer, report *ContainersReport) {
+	fmt.Fprintln(w, "Containers/Virtualization:")
+	fmt.Fprintln(w)
 
 	// Check Docker
-	dockerInfo := checkDocker()
+	dockerInfo := report.Docker
 	if dockerInfo != nil {
-		fmt.Printf("  Docker:\n")
-		fmt.Printf("    Running Containers: %d\n", dockerInfo.Running)
-		fmt.Printf("    Images: %d\n", dockerInfo.Images)
+		fmt.Fprintf(w, "  Docker:\n")
+		fmt.Fprintf(w, "    Running Containers: %d\n", dockerInfo.Running)
+		fmt.Fprintf(w, "    Images: %d\n", dockerInfo.Images)
 		if len(dockerInfo.ImagesList) > 0 && len(dockerInfo.ImagesList) <= 10 {
-			fmt.Printf("    Image List:\n")
+			fmt.Fprintf(w, "    Image List:\n")
 			for _, img := range dockerInfo.ImagesList {
-				fmt.Printf("      - %s\n", img)
+				fmt.Fprintf(w, "      - %s\n", img)
 			}
 		}
-		fmt.Println()
+		fmt.Fprintln(w)
 	}
 
 	// Check Podman
-	podmanInfo := checkPodman()
+	podmanInfo := report.Podman
 	if podmanInfo != nil {
-		fmt.Printf("  Podman:\n")
-		fmt.Printf("    Running Containers: %d\n", podmanInfo.Running)
-		fmt.Printf("    Images: %d\n", podmanInfo.Images)
+		fmt.Fprintf(w, "  Podman:\n")
+		fmt.Fprintf(w, "    Running Containers: %d\n", podmanInfo.Running)
+		fmt.Fprintf(w, "    Images: %d\n", podmanInfo.Images)
 		if len(podmanInfo.ImagesList) > 0 && len(podmanInfo.ImagesList) <= 10 {
-			fmt.Printf("    Image List:\n")
+			fmt.Fprintf(w, "    Image List:\n")
 			for _, img := range podmanInfo.ImagesList {
-				fmt.Printf("      - %s\n", img)
+				fmt.Fprintf(w, "      - %s\n", img)
 			}
 		}
-		fmt.Println()
+		fmt.Fprintln(w)
 	}
 
 	// Check virtualization
-	virtInfo := checkVirtualization()
-	if virtInfo.Virtualized {
-		fmt.Printf("  Virtualization: %s\n", virtInfo.VirtType)
+	if report.Virtualized {
+		fmt.Fprintf(w, "  Virtualization: %s\n", report.VirtType)
 	} else {
-		fmt.Printf("  Virtualization: None detected (bare metal)\n")
+		fmt.Fprintf(w, "  Virtualization: None detected (bare metal)\n")
 	}
 
 	if dockerInfo == nil && podmanInfo == nil {
-		fmt.Println("  No container runtimes detected")
+		fmt.Fprintln(w, "  No container runtimes detected")
 	}
 }
 
-func checkDocker() *ContainerInfo {
-	if !exists("docker") {
+func checkDocker(ctx context.Context) *ContainerInfo {
+	if !exists(ctx, "docker") {
 		return nil
 	}
 
 	info := &ContainerInfo{Runtime: "docker"}
 
 	// Count running containers
-	cmd := exec.Command("docker", "ps", "-q")
-	out, err := cmd.Output()
+	out, err := runner.Output(ctx, "docker", "ps", "-q")
 	if err == nil {
 		lines := strings.Split(strings.TrimSpace(string(out)), "\n")
 		if len(lines) == 1 && lines[0] == "" {
@@ -94,8 +125,7 @@ func checkDocker() *ContainerInfo {
 	}
 
 	// Count images and get list
-	cmd = exec.Command("docker", "images", "--format", "{{.Repository}}:{{.Tag}}")
-	out, err = cmd.Output()
+	out, err = runner.Output(ctx, "docker", "images", "--format", "{{.Repository}}:{{.Tag}}")
 	if err == nil {
 		lines := strings.Split(strings.TrimSpace(string(out)), "\n")
 		if len(lines) == 1 && lines[0] == "" {
@@ -109,16 +139,15 @@ func checkDocker() *ContainerInfo {
 	return info
 }
 
-func checkPodman() *ContainerInfo {
-	if !exists("podman") {
+func checkPodman(ctx context.Context) *ContainerInfo {
+	if !exists(ctx, "podman") {
 		return nil
 	}
 
 	info := &ContainerInfo{Runtime: "podman"}
 
 	// Count running containers
-	cmd := exec.Command("podman", "ps", "-q")
-	out, err := cmd.Output()
+	out, err := runner.Output(ctx, "podman", "ps", "-q")
 	if err == nil {
 		lines := strings.Split(strings.TrimSpace(string(out)), "\n")
 		if len(lines) == 1 && lines[0] == "" {
@@ -129,8 +158,7 @@ func checkPodman() *ContainerInfo {
 	}
 
 	// Count images and get list
-	cmd = exec.Command("podman", "images", "--format", "{{.Repository}}:{{.Tag}}")
-	out, err = cmd.Output()
+	out, err = runner.Output(ctx, "podman", "images", "--format", "{{.Repository}}:{{.Tag}}")
 	if err == nil {
 		lines := strings.Split(strings.TrimSpace(string(out)), "\n")
 		if len(lines) == 1 && lines[0] == "" {
@@ -144,7 +172,7 @@ func checkPodman() *ContainerInfo {
 	return info
 }
 
-func checkVirtualization() *ContainerInfo {
+func checkVirtualization(ctx context.Context) *ContainerInfo {
 	info := &ContainerInfo{Virtualized: false}
 
 	if runtime.GOOS != "linux" {
@@ -152,9 +180,8 @@ func checkVirtualization() *ContainerInfo {
 	}
 
 	// Use systemd-detect-virt
-	if exists("systemd-detect-virt") {
-		cmd := exec.Command("systemd-detect-virt")
-		out, err := cmd.Output()
+	if exists(ctx, "systemd-detect-virt") {
+		out, err := runner.Output(ctx, "systemd-detect-virt")
 		if err == nil {
 			virtType := strings.TrimSpace(string(out))
 			if virtType != "none" {
@@ -1,13 +1,17 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
+
+	"github.com/aallbrig/allbctl/pkg/runner"
 )
 
 var (
@@ -28,15 +32,28 @@ Examples:
   allbctl status db postgres         # Show only PostgreSQL info
   allbctl status db --detail         # Show detailed info for all databases
   allbctl status db sqlite3 --detail # Show detailed SQLite3 info with .db files`,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if isStructuredOutput() {
+			if len(args) > 0 {
+				info := detectDatabase(ctx, args[0])
+				if info == nil {
+					return fmt.Errorf("database '%s' not detected on this system", args[0])
+				}
+				return printStructured(info)
+			}
+			return printStructured(detectAllDatabases(ctx))
+		}
+
 		if len(args) > 0 {
 			// Show specific database
 			dbName := args[0]
-			showDatabaseInfo(dbName, dbDetailFlag)
+			showDatabaseInfo(ctx, dbName, dbDetailFlag)
 		} else {
 			// Show all databases
-			showAllDatabases(dbDetailFlag)
+			showAllDatabases(ctx, dbDetailFlag)
 		}
+		return nil
 	},
 }
 
@@ -45,15 +62,16 @@ func init() {
 }
 
 type DatabaseInfo struct {
-	Name          string
-	ClientBinary  string
-	ServerBinary  string
-	ClientVersion string
-	ServerVersion string
-	IsRunning     bool
-	DatabaseFiles []string
-	EnvVars       map[string]string
-	OtherBinaries []string
+	Name          string            `json:"name"`
+	ClientBinary  string            `json:"client_binary"`
+	ServerBinary  string            `json:"server_binary,omitempty"`
+	ClientVersion string            `json:"client_version,omitempty"`
+	ServerVersion string            `json:"server_version,omitempty"`
+	IsRunning     bool              `json:"is_running"`
+	DatabaseFiles []string          `json:"database_files,omitempty"`
+	EnvVars       map[string]string `json:"env_vars,omitempty"`
+	OtherBinaries []string          `json:"other_binaries,omitempty"`
+	Update        *UpdateInfo       `json:"update,omitempty"` // newer client release, set by withDatabaseUpdates
 }
 
 // Database configurations
@@ -125,7 +143,7 @@ var databaseConfigs = map[string]struct {
 	},
 }
 
-func detectDatabase(dbName string) *DatabaseInfo {
+func detectDatabase(ctx context.Context, dbName string) *DatabaseInfo {
 	config, exists := databaseConfigs[dbName]
 	if !exists {
 		return nil
@@ -140,12 +158,11 @@ func detectDatabase(dbName string) *DatabaseInfo {
 	}
 
 	// Check client binary
-	if clientPath, err := exec.LookPath(config.clientBinary); err == nil {
+	if clientPath, err := runner.LookPath(ctx, config.clientBinary); err == nil {
 		info.ClientBinary = clientPath
 		// Get version
 		if len(config.versionArgs) > 0 {
-			cmd := exec.Command(config.clientBinary, config.versionArgs...)
-			if output, err := cmd.CombinedOutput(); err == nil {
+			if output, err := runner.CombinedOutput(ctx, config.clientBinary, config.versionArgs...); err == nil {
 				info.ClientVersion = strings.TrimSpace(string(output))
 			}
 		}
@@ -155,12 +172,11 @@ func detectDatabase(dbName string) *DatabaseInfo {
 
 	// Check server binary
 	if config.serverBinary != "" {
-		if serverPath, err := exec.LookPath(config.serverBinary); err == nil {
+		if serverPath, err := runner.LookPath(ctx, config.serverBinary); err == nil {
 			info.ServerBinary = serverPath
 			// Get server version (often same as client)
 			if len(config.versionArgs) > 0 {
-				cmd := exec.Command(config.serverBinary, config.versionArgs...)
-				if output, err := cmd.CombinedOutput(); err == nil {
+				if output, err := runner.CombinedOutput(ctx, config.serverBinary, config.versionArgs...); err == nil {
 					info.ServerVersion = strings.TrimSpace(string(output))
 				}
 			}
@@ -168,8 +184,7 @@ func detectDatabase(dbName string) *DatabaseInfo {
 
 		// Check if server is running
 		if len(config.serverCheck) > 0 {
-			cmd := exec.Command(config.serverCheck[0], config.serverCheck[1:]...)
-			if err := cmd.Run(); err == nil {
+			if err := runner.Run(ctx, config.serverCheck[0], config.serverCheck[1:]...); err == nil {
 				info.IsRunning = true
 			}
 		}
@@ -230,8 +245,8 @@ func findDatabaseFiles(extensions []string) []string {
 	return files
 }
 
-func showDatabaseInfo(dbName string, detailed bool) {
-	info := detectDatabase(dbName)
+func showDatabaseInfo(ctx context.Context, dbName string, detailed bool) {
+	info := detectDatabase(ctx, dbName)
 	if info == nil {
 		fmt.Printf("Database '%s' not detected on this system\n", dbName)
 		os.Exit(1)
@@ -240,16 +255,11 @@ func showDatabaseInfo(dbName string, detailed bool) {
 	printDatabaseInfo(info, detailed)
 }
 
-func showAllDatabases(detailed bool) {
+func showAllDatabases(ctx context.Context, detailed bool) {
 	var detectedDatabases []string
-	var allInfo []*DatabaseInfo
-
-	// Detect all databases
-	for dbName := range databaseConfigs {
-		if info := detectDatabase(dbName); info != nil {
-			detectedDatabases = append(detectedDatabases, dbName)
-			allInfo = append(allInfo, info)
-		}
+	allInfo := detectAllDatabases(ctx)
+	for _, info := range allInfo {
+		detectedDatabases = append(detectedDatabases, info.Name)
 	}
 
 	if len(detectedDatabases) == 0 {
@@ -259,17 +269,17 @@ func showAllDatabases(detailed bool) {
 
 	// Print summary or detailed info
 	if detailed {
-		for i, info := range allInfo {
+		for i := range allInfo {
 			if i > 0 {
 				fmt.Println()
 			}
-			printDatabaseInfo(info, true)
+			printDatabaseInfo(&allInfo[i], true)
 		}
 		// Print summary at the end
 		fmt.Printf("\nDatabases detected: %s\n", strings.Join(detectedDatabases, ", "))
 	} else {
-		for _, info := range allInfo {
-			printDatabaseSummary(info)
+		for i := range allInfo {
+			printDatabaseSummary(&allInfo[i])
 		}
 	}
 }
@@ -355,33 +365,63 @@ func printDatabaseInfo(info *DatabaseInfo, detailed bool) {
 	}
 }
 
+// detectAllDatabases returns every detected database, sorted by name
+func detectAllDatabases(ctx context.Context) []DatabaseInfo {
+	names := make([]string, 0, len(databaseConfigs))
+	for dbName := range databaseConfigs {
+		names = append(names, dbName)
+	}
+	sort.Strings(names)
+
+	detected := []DatabaseInfo{}
+	for _, dbName := range names {
+		if info := detectDatabase(ctx, dbName); info != nil {
+			detected = append(detected, *info)
+		}
+	}
+	return detected
+}
+
 // PrintDatabaseSummaryForStatus prints a one-line summary for the main status command
-func PrintDatabaseSummaryForStatus() {
+func PrintDatabaseSummaryForStatus(ctx context.Context) {
+	printDatabaseSummaryLine(os.Stdout, withDatabaseUpdates(detectAllDatabases(ctx)))
+}
+
+// withDatabaseUpdates looks up newer releases of the detected database clients
+func withDatabaseUpdates(databases []DatabaseInfo) []DatabaseInfo {
+	for i, info := range databases {
+		if version := extractDatabaseVersion(info.Name, info.ClientVersion); version != "" {
+			databases[i].Update = checkVersionUpdate(info.Name, version)
+		}
+	}
+	return databases
+}
+
+// printDatabaseSummaryLine renders the "Databases:" line from detected databases
+func printDatabaseSummaryLine(w io.Writer, databases []DatabaseInfo) {
 	var detected []string
 
-	for dbName := range databaseConfigs {
-		if info := detectDatabase(dbName); info != nil {
-			// Extract version number
-			version := extractDatabaseVersion(dbName, info.ClientVersion)
-
-			var dbStr string
-			if version != "" {
-				versionStr := formatVersionWithUpdate(dbName, version)
-				dbStr = fmt.Sprintf("%s (%s)", dbName, versionStr)
-			} else {
-				dbStr = dbName
-			}
+	for _, info := range databases {
+		// Extract version number
+		version := extractDatabaseVersion(info.Name, info.ClientVersion)
 
-			if info.IsRunning {
-				dbStr += " [running]"
-			}
+		var dbStr string
+		if version != "" {
+			versionStr := formatVersion(version, info.Update)
+			dbStr = fmt.Sprintf("%s (%s)", info.Name, versionStr)
+		} else {
+			dbStr = info.Name
+		}
 
-			detected = append(detected, dbStr)
+		if info.IsRunning {
+			dbStr += " [running]"
 		}
+
+		detected = append(detected, dbStr)
 	}
 
 	if len(detected) > 0 {
-		fmt.Printf("Databases: %s\n", strings.Join(detected, ", "))
+		fmt.Fprintf(w, "Databases: %s\n", strings.Join(detected, ", "))
 	}
 }
 
@@ -0,0 +1,433 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/aallbrig/allbctl/pkg/model"
+	"github.com/aallbrig/allbctl/pkg/pkgmgr"
+	"github.com/aallbrig/allbctl/pkg/telemetry"
+)
+
+// Severity is how serious a doctor finding is
+type Severity string
+
+// Finding severities, from least to most serious
+const (
+	severityInfo  Severity = "info"
+	severityWarn  Severity = "warn"
+	severityError Severity = "error"
+)
+
+// severityRank orders severities for sorting and --fail-on
+var severityRank = map[Severity]int{
+	severityInfo:  0,
+	severityWarn:  1,
+	severityError: 2,
+}
+
+// Finding is one problem 'allbctl doctor' found
+type Finding struct {
+	Check    string   `json:"check"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	// Hint suggests how to resolve the finding
+	Hint string `json:"hint,omitempty"`
+	// Fix resolves the finding when run; 'allbctl fix' applies it
+	Fix *model.Fix `json:"fix,omitempty"`
+}
+
+// DoctorCheck looks for one kind of problem, such as failed systemd units.
+// Checks run concurrently, each under its own deadline.
+type DoctorCheck interface {
+	// Name identifies the check, e.g. "systemd"
+	Name() string
+	// Run returns what the check found; no findings means healthy. An error
+	// means the check itself could not run, and is reported as a finding.
+	Run(ctx context.Context) ([]Finding, error)
+}
+
+// doctorCheckFunc adapts a function to DoctorCheck
+type doctorCheckFunc struct {
+	name string
+	run  func(ctx context.Context) ([]Finding, error)
+}
+
+func (c doctorCheckFunc) Name() string { return c.name }
+
+func (c doctorCheckFunc) Run(ctx context.Context) ([]Finding, error) { return c.run(ctx) }
+
+// doctorCheckEntry is a registered check and its deadline
+type doctorCheckEntry struct {
+	check   DoctorCheck
+	timeout time.Duration
+}
+
+// doctorChecks is every check 'allbctl doctor' runs, in report order
+var doctorChecks []doctorCheckEntry
+
+func registerDoctorCheck(check DoctorCheck, timeout time.Duration) {
+	doctorChecks = append(doctorChecks, doctorCheckEntry{check: check, timeout: timeout})
+}
+
+func init() {
+	registerDoctorCheck(doctorCheckFunc{"bootstrap", checkBootstrapHealth}, 30*time.Second)
+	registerDoctorCheck(doctorCheckFunc{"systemd", checkSystemdHealth}, 10*time.Second)
+	registerDoctorCheck(doctorCheckFunc{"runtimes", checkRuntimeHealth}, 30*time.Second)
+	registerDoctorCheck(doctorCheckFunc{"internet", checkInternetHealth}, 5*time.Second)
+}
+
+// DoctorReport is the result of 'allbctl doctor'
+type DoctorReport struct {
+	Healthy  bool             `json:"healthy"`
+	Findings []Finding        `json:"findings"`
+	Checks   []SectionOutcome `json:"checks"`
+}
+
+var (
+	doctorSkipFlag   []string
+	doctorFailOnFlag string
+)
+
+// DoctorCmd runs the health checks
+var DoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check this machine for problems and suggest fixes",
+	Long: `Run health checks and report what needs attention, each finding with a
+severity and a suggested fix. 'allbctl fix' applies the fixes that can be run.
+
+Checks:
+  bootstrap  missing tools, dotfiles drift and anything else 'bootstrap status' flags
+  systemd    failed system and user services (Linux)
+  runtimes   language runtimes with a newer release available
+  internet   no internet connectivity
+
+Exit status is 0 when no finding is an error, and 1 otherwise, so CI images and
+login scripts can gate on a healthy machine. --fail-on warn also fails on
+warnings.
+
+Examples:
+  allbctl doctor
+  allbctl doctor --skip runtimes,internet    # Offline-friendly
+  allbctl doctor -o json | jq '.findings[] | select(.severity == "error")'`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		failOn := Severity(doctorFailOnFlag)
+		if failOn != severityWarn && failOn != severityError {
+			return fmt.Errorf("invalid --fail-on %q (want warn or error)", doctorFailOnFlag)
+		}
+		checks, err := selectDoctorChecks(doctorChecks, doctorSkipFlag)
+		if err != nil {
+			return err
+		}
+
+		report := runDoctor(ctx, checks, failOn)
+		if err := renderOutput(report, func() { printDoctorReport(report) }); err != nil {
+			return err
+		}
+		if !report.Healthy {
+			return &exitCodeError{code: 1}
+		}
+		return nil
+	},
+}
+
+func init() {
+	DoctorCmd.Flags().VarP(&outputFormat, "output", "o", "Output format: text, json or yaml")
+	DoctorCmd.Flags().StringSliceVar(&doctorSkipFlag, "skip", nil, "Checks to leave out")
+	DoctorCmd.Flags().StringVar(&doctorFailOnFlag, "fail-on", string(severityError), "Lowest severity that makes doctor exit non-zero: warn or error")
+}
+
+// selectDoctorChecks drops the skipped checks
+func selectDoctorChecks(entries []doctorCheckEntry, skip []string) ([]doctorCheckEntry, error) {
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.check.Name()
+	}
+	skipped := map[string]bool{}
+	for _, name := range skip {
+		if !slices.Contains(names, name) {
+			return nil, fmt.Errorf("unknown doctor check %q (available: %s)", name, strings.Join(names, ", "))
+		}
+		skipped[name] = true
+	}
+
+	var selected []doctorCheckEntry
+	for _, entry := range entries {
+		if !skipped[entry.check.Name()] {
+			selected = append(selected, entry)
+		}
+	}
+	return selected, nil
+}
+
+// runDoctor runs the checks concurrently. Findings are ordered most serious
+// first, then by check order. The machine is healthy when no finding is at
+// least as serious as failOn.
+func runDoctor(ctx context.Context, entries []doctorCheckEntry, failOn Severity) *DoctorReport {
+	outcomes := make([]SectionOutcome, len(entries))
+	findings := make([][]Finding, len(entries))
+
+	var wg sync.WaitGroup
+	for i, entry := range entries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			outcomes[i], findings[i] = runDoctorCheck(ctx, entry)
+		}()
+	}
+	wg.Wait()
+
+	report := &DoctorReport{Healthy: true, Findings: []Finding{}, Checks: outcomes}
+	for _, f := range findings {
+		report.Findings = append(report.Findings, f...)
+	}
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		return severityRank[report.Findings[i].Severity] > severityRank[report.Findings[j].Severity]
+	})
+	for _, f := range report.Findings {
+		if severityRank[f.Severity] >= severityRank[failOn] {
+			report.Healthy = false
+		}
+	}
+	return report
+}
+
+// runDoctorCheck runs one check under its deadline. A check that fails,
+// panics or times out becomes a warning, since the problem it looks for may
+// or may not be there.
+func runDoctorCheck(ctx context.Context, entry doctorCheckEntry) (SectionOutcome, []Finding) {
+	name := entry.check.Name()
+	ctx, cancel := context.WithTimeout(ctx, entry.timeout)
+	defer cancel()
+	ctx, span := otel.Tracer("github.com/aallbrig/allbctl").Start(ctx, "doctor.check."+name,
+		trace.WithAttributes(attribute.String("check", name)),
+	)
+	defer span.End()
+
+	type ran struct {
+		findings []Finding
+		err      error
+	}
+	// Buffered so a check that finishes after the deadline does not block forever
+	done := make(chan ran, 1)
+	start := time.Now()
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- ran{err: fmt.Errorf("panic: %v", r)}
+			}
+		}()
+		findings, err := entry.check.Run(ctx)
+		done <- ran{findings: findings, err: err}
+	}()
+
+	outcome := SectionOutcome{Name: name, Status: sectionOK}
+	var findings []Finding
+	select {
+	case result := <-done:
+		findings = result.findings
+		if result.err != nil {
+			outcome.Status = sectionError
+			outcome.Error = result.err.Error()
+		}
+	case <-ctx.Done():
+		outcome.Status = sectionTimedOut
+		outcome.Error = fmt.Sprintf("timed out after %s", entry.timeout)
+	}
+	outcome.DurationMS = time.Since(start).Milliseconds()
+
+	if outcome.Status != sectionOK {
+		span.SetStatus(codes.Error, outcome.Error)
+		findings = append(findings, Finding{Severity: severityWarn, Message: "check could not run: " + outcome.Error})
+	}
+	for i := range findings {
+		findings[i].Check = name
+	}
+	telemetry.Logger.DebugContext(ctx, "doctor.check",
+		"check", name,
+		"status", outcome.Status,
+		"findings", len(findings),
+		"duration_ms", outcome.DurationMS,
+	)
+	return outcome, findings
+}
+
+// severityColors matches results.go: red for errors, yellow for warnings
+var severityColors = map[Severity]*color.Color{
+	severityInfo:  color.New(color.FgCyan),
+	severityWarn:  color.New(color.FgYellow),
+	severityError: color.New(color.FgRed),
+}
+
+func printDoctorReport(report *DoctorReport) {
+	if len(report.Findings) == 0 {
+		fmt.Printf("No problems found (%d checks)\n", len(report.Checks))
+		return
+	}
+
+	counts := map[Severity]int{}
+	for _, f := range report.Findings {
+		counts[f.Severity]++
+		fmt.Printf("%s  %-10s %s\n", severityColors[f.Severity].Sprintf("%-5s", f.Severity), f.Check, f.Message)
+		switch {
+		case f.Fix != nil:
+			fmt.Printf("%-17s fix: %s\n", "", f.Fix.Command)
+		case f.Hint != "":
+			fmt.Printf("%-17s hint: %s\n", "", f.Hint)
+		}
+	}
+
+	var parts []string
+	for _, severity := range []Severity{severityError, severityWarn, severityInfo} {
+		if counts[severity] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[severity], severity))
+		}
+	}
+	fmt.Printf("\n%s\n", strings.Join(parts, ", "))
+}
+
+// checkBootstrapHealth reports what 'bootstrap status' flags: missing tools,
+// dotfiles drift and the like
+func checkBootstrapHealth(ctx context.Context) ([]Finding, error) {
+	report, err := collectBootstrapStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var findings []Finding
+	for _, result := range report.Results {
+		findings = append(findings, resultFindings(result, nil, "")...)
+	}
+	return findings, nil
+}
+
+// resultFindings turns the leaf results that are not ok into findings, like
+// countResults counts them. A leaf without hints of its own takes the nearest
+// group's; fixes are only taken from the leaf itself.
+func resultFindings(r *model.Result, path []string, hint string) []Finding {
+	path = append(path, r.Name)
+	if len(r.Hints) > 0 {
+		hint = r.Hints[0]
+	}
+	if len(r.Children) > 0 && r.Status != model.StatusDrifted {
+		var findings []Finding
+		for _, child := range r.Children {
+			findings = append(findings, resultFindings(child, path, hint)...)
+		}
+		return findings
+	}
+
+	var severity Severity
+	switch r.Status {
+	case model.StatusMissing, model.StatusError:
+		severity = severityError
+	case model.StatusOutdated, model.StatusDrifted:
+		severity = severityWarn
+	default:
+		return nil
+	}
+	if hint == "" && (r.Status == model.StatusMissing || r.Status == model.StatusOutdated) {
+		hint = "allbctl bootstrap install"
+	}
+	message := r.Message
+	if message == "" {
+		message = string(r.Status)
+	}
+	finding := Finding{
+		Severity: severity,
+		Message:  fmt.Sprintf("%s: %s", strings.Join(path, " / "), message),
+		Hint:     hint,
+	}
+	if len(r.Fixes) > 0 {
+		finding.Fix = &r.Fixes[0]
+	}
+	return []Finding{finding}
+}
+
+// checkSystemdHealth reports failed systemd services, with a restart as the fix
+func checkSystemdHealth(ctx context.Context) ([]Finding, error) {
+	if runtime.GOOS != "linux" || !exists(ctx, "systemctl") {
+		return nil, nil
+	}
+	var findings []Finding
+	systemctl := "systemctl"
+	if os.Geteuid() != 0 && exists(ctx, "sudo") {
+		systemctl = "sudo systemctl"
+	}
+	for _, unit := range failedSystemdUnits(ctx, false) {
+		findings = append(findings, Finding{
+			Severity: severityError,
+			Message:  fmt.Sprintf("system service %s failed", unit),
+			Hint:     fmt.Sprintf("journalctl -u %s", unit),
+			Fix:      &model.Fix{Description: "restart " + unit, Command: fmt.Sprintf("%s restart %s", systemctl, pkgmgr.ShellQuote(unit))},
+		})
+	}
+	for _, unit := range failedSystemdUnits(ctx, true) {
+		findings = append(findings, Finding{
+			Severity: severityWarn,
+			Message:  fmt.Sprintf("user service %s failed", unit),
+			Hint:     fmt.Sprintf("journalctl --user -u %s", unit),
+			Fix:      &model.Fix{Description: "restart " + unit, Command: fmt.Sprintf("systemctl --user restart %s", pkgmgr.ShellQuote(unit))},
+		})
+	}
+	return findings, nil
+}
+
+// runtimeUpdateChecks are the runtimes checkVersionUpdate knows the release
+// feeds of; others would each cost a GitHub API call
+var runtimeUpdateChecks = map[string]bool{"Node.js": true, "Python": true, "Go": true, "Java": true, "Ruby": true}
+
+// checkRuntimeHealth reports language runtimes with a newer release
+func checkRuntimeHealth(ctx context.Context) ([]Finding, error) {
+	var findings []Finding
+	for _, rt := range detectRuntimes(ctx) {
+		if ctx.Err() != nil {
+			return findings, ctx.Err()
+		}
+		if !runtimeUpdateChecks[rt.Name] {
+			continue
+		}
+		current := extractVersionNumber(rt.Version)
+		update := checkVersionUpdate(rt.Name, current)
+		if update == nil || update.Available == "" || update.Available == current {
+			continue
+		}
+		findings = append(findings, Finding{
+			Severity: severityInfo,
+			Message:  fmt.Sprintf("%s %s is installed; %s is available", rt.Name, current, update.Available),
+			Hint:     fmt.Sprintf("upgrade %s with its version manager or package manager", rt.Name),
+		})
+	}
+	return findings, nil
+}
+
+// checkInternetHealth reports a machine that cannot reach the internet
+func checkInternetHealth(ctx context.Context) ([]Finding, error) {
+	if checkInternetConnectivity(ctx) {
+		return nil, nil
+	}
+	return []Finding{{
+		Severity: severityWarn,
+		Message:  "no internet connectivity (cannot reach 8.8.8.8:53)",
+		Hint:     "check the network connection, VPN and firewall; 'allbctl status network' shows interfaces and DNS",
+	}}, nil
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aallbrig/allbctl/pkg/model"
+)
+
+func TestRunDoctor(t *testing.T) {
+	entries := []doctorCheckEntry{
+		{check: doctorCheckFunc{"runtimes", func(context.Context) ([]Finding, error) {
+			return []Finding{{Severity: severityInfo, Message: "Go 1.25.1 is installed; 1.26.3 is available"}}, nil
+		}}, timeout: time.Second},
+		{check: doctorCheckFunc{"systemd", func(context.Context) ([]Finding, error) {
+			return []Finding{{Severity: severityError, Message: "system service nginx.service failed", Hint: "journalctl -u nginx.service"}}, nil
+		}}, timeout: time.Second},
+		{check: doctorCheckFunc{"internet", func(ctx context.Context) ([]Finding, error) {
+			<-ctx.Done()
+			return nil, nil
+		}}, timeout: 10 * time.Millisecond},
+		{check: doctorCheckFunc{"broken", func(context.Context) ([]Finding, error) {
+			return nil, errors.New("no permission")
+		}}, timeout: time.Second},
+	}
+
+	report := runDoctor(context.Background(), entries, severityError)
+	if report.Healthy {
+		t.Error("runDoctor() with an error finding should not be healthy")
+	}
+	if len(report.Findings) != 4 {
+		t.Fatalf("runDoctor() findings = %+v, want 4", report.Findings)
+	}
+	if got := report.Findings[0]; got.Check != "systemd" || got.Severity != severityError {
+		t.Errorf("first finding = %+v, want the systemd error", got)
+	}
+	if got := report.Findings[3]; got.Check != "runtimes" || got.Severity != severityInfo {
+		t.Errorf("last finding = %+v, want the runtimes info", got)
+	}
+	if got := report.Checks[2]; got.Status != sectionTimedOut {
+		t.Errorf("internet check outcome = %+v, want timed out", got)
+	}
+	for _, f := range report.Findings[1:3] {
+		if f.Severity != severityWarn || !strings.Contains(f.Message, "check could not run") {
+			t.Errorf("failed check finding = %+v, want a could-not-run warning", f)
+		}
+	}
+
+	// Warnings and info alone are healthy, unless --fail-on warn
+	if report := runDoctor(context.Background(), entries[2:3], severityError); !report.Healthy {
+		t.Error("runDoctor() with only warnings should be healthy")
+	}
+	if report := runDoctor(context.Background(), entries[2:3], severityWarn); report.Healthy {
+		t.Error("runDoctor() with warnings and --fail-on warn should not be healthy")
+	}
+}
+
+func TestResultFindings(t *testing.T) {
+	tools, _ := model.NewGroupResult("$HOME/.zshrc", []*model.Result{
+		model.NewResult("fzf", model.StatusMissing, "not available"),
+		model.NewResult("git", model.StatusOK, "available"),
+	})
+	shell, _ := model.NewGroupResult("Shell Config Tools", []*model.Result{tools})
+	shell.WithHints("install the missing tools with your package manager")
+	dotfiles := model.NewResult("Dotfiles Setup", model.StatusDrifted, "2 uncommitted change(s) in dotfiles").
+		WithHints("cd ~/src/dotfiles && git status")
+	gh := model.NewResult("Installable Command: gh", model.StatusMissing, "not found on PATH")
+	src := model.NewResult("Expected Directory /home/me/src", model.StatusMissing, "not found").
+		WithFix("create /home/me/src", "mkdir -p /home/me/src")
+
+	var findings []Finding
+	for _, r := range []*model.Result{shell, dotfiles, gh, src} {
+		findings = append(findings, resultFindings(r, nil, "")...)
+	}
+	want := []Finding{
+		{Severity: severityError, Message: "Shell Config Tools / $HOME/.zshrc / fzf: not available", Hint: "install the missing tools with your package manager"},
+		{Severity: severityWarn, Message: "Dotfiles Setup: 2 uncommitted change(s) in dotfiles", Hint: "cd ~/src/dotfiles && git status"},
+		{Severity: severityError, Message: "Installable Command: gh: not found on PATH", Hint: "allbctl bootstrap install"},
+		{Severity: severityError, Message: "Expected Directory /home/me/src: not found", Hint: "mkdir -p /home/me/src", Fix: &src.Fixes[0]},
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("resultFindings() = %+v, want %d findings", findings, len(want))
+	}
+	for i := range want {
+		if findings[i] != want[i] {
+			t.Errorf("resultFindings()[%d] = %+v, want %+v", i, findings[i], want[i])
+		}
+	}
+}
+
+func TestSelectDoctorChecks(t *testing.T) {
+	selected, err := selectDoctorChecks(doctorChecks, []string{"runtimes", "internet"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range selected {
+		if name := entry.check.Name(); name == "runtimes" || name == "internet" {
+			t.Errorf("selectDoctorChecks() kept skipped check %q", name)
+		}
+	}
+	if _, err := selectDoctorChecks(doctorChecks, []string{"nope"}); err == nil || !strings.Contains(err.Error(), `unknown doctor check "nope"`) {
+		t.Errorf("selectDoctorChecks() error = %v, want unknown doctor check", err)
+	}
+}
@@ -0,0 +1,292 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aallbrig/allbctl/pkg/model"
+	"github.com/aallbrig/allbctl/pkg/osagnostic"
+	"github.com/aallbrig/allbctl/pkg/runner"
+	"github.com/aallbrig/allbctl/pkg/telemetry"
+)
+
+// Fix outcomes
+const (
+	fixApplied = "applied"
+	fixFailed  = "failed"
+	fixSkipped = "skipped"
+	fixPlanned = "planned" // --dry-run
+)
+
+// FixResult is what happened to one fix
+type FixResult struct {
+	model.Fix
+	// Findings are the messages of the findings the fix resolves
+	Findings []string `json:"findings"`
+	Status   string   `json:"status"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// FixReport is the result of 'allbctl fix'
+type FixReport struct {
+	Fixes []FixResult `json:"fixes"`
+	// Resolved and Remaining compare the findings before and after the fixes
+	Resolved  []Finding `json:"resolved"`
+	Remaining []Finding `json:"remaining"`
+}
+
+// fixShell runs a fix command, attached to the terminal so fixes like
+// 'gh auth login' or sudo can prompt. Tests replace it.
+var fixShell = func(ctx context.Context, command string) error {
+	shell := runner.Cmd{Name: "sh", Args: []string{"-c", command}}
+	if runtime.GOOS == "windows" {
+		shell = runner.Cmd{Name: "cmd", Args: []string{"/C", command}}
+	}
+	shell.Stdin = os.Stdin
+	shell.Stdout = os.Stdout
+	shell.Stderr = os.Stderr
+	return runner.FromContext(ctx).Run(ctx, shell)
+}
+
+// fixLedger opens the ledger fixes that install packages are recorded in, so
+// 'bootstrap reset' removes them like packages bootstrap installed. Tests replace it.
+var fixLedger = osagnostic.DefaultPackageLedger
+
+var (
+	fixYes    bool
+	fixDryRun bool
+	fixSkip   []string
+)
+
+// FixCmd applies the fixes doctor finds
+var FixCmd = &cobra.Command{
+	Use:   "fix",
+	Short: "Apply the fixes for what 'allbctl doctor' finds",
+	Long: `Run the doctor checks, then apply each finding's fix: cloning or re-installing
+dotfiles, creating directories, installing missing tools, restarting failed
+services and so on. Each fix is shown with its command and applied only when
+confirmed, unless --yes is given. Findings without a runnable fix are left for
+you; 'allbctl doctor' shows their hints.
+
+Afterwards the checks run again, and the report says which findings were
+resolved and which remain. Exit status is 1 when a fix failed.
+
+Examples:
+  allbctl fix --dry-run          # Show what would be run
+  allbctl fix                    # Confirm each fix
+  allbctl fix --yes --skip runtimes`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		if isStructuredOutput() && !fixYes && !fixDryRun {
+			return fmt.Errorf("--output %s needs --yes or --dry-run, since fixes are confirmed interactively", outputFormat)
+		}
+		checks, err := selectDoctorChecks(doctorChecks, fixSkip)
+		if err != nil {
+			return err
+		}
+
+		// Prompts and fix output would break structured output on stdout
+		out := io.Writer(os.Stdout)
+		if isStructuredOutput() {
+			out = os.Stderr
+		}
+		report := runFix(ctx, checks, fixSession{
+			in:     bufio.NewReader(os.Stdin),
+			out:    out,
+			yes:    fixYes,
+			dryRun: fixDryRun,
+		})
+		if err := renderOutput(report, func() { printFixReport(report) }); err != nil {
+			return err
+		}
+		for _, f := range report.Fixes {
+			if f.Status == fixFailed {
+				return &exitCodeError{code: 1}
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	FixCmd.Flags().VarP(&outputFormat, "output", "o", "Output format: text, json or yaml")
+	FixCmd.Flags().BoolVarP(&fixYes, "yes", "y", false, "Apply every fix without asking")
+	FixCmd.Flags().BoolVar(&fixDryRun, "dry-run", false, "Show the fixes without applying them")
+	FixCmd.Flags().StringSliceVar(&fixSkip, "skip", nil, "Doctor checks to leave out")
+}
+
+// fixSession is how fixes are confirmed and where their progress goes
+type fixSession struct {
+	in     *bufio.Reader
+	out    io.Writer
+	yes    bool
+	dryRun bool
+}
+
+// runFix finds the fixes, applies the confirmed ones and checks again
+func runFix(ctx context.Context, checks []doctorCheckEntry, session fixSession) *FixReport {
+	before := runDoctor(ctx, checks, severityError)
+	report := &FixReport{Fixes: collectFixes(before.Findings), Resolved: []Finding{}, Remaining: before.Findings}
+
+	quit := false
+	for i := range report.Fixes {
+		fix := &report.Fixes[i]
+		switch {
+		case session.dryRun:
+			fix.Status = fixPlanned
+			continue
+		case quit:
+			fix.Status = fixSkipped
+			continue
+		}
+
+		fmt.Fprintf(session.out, "[%d/%d] %s\n", i+1, len(report.Fixes), strings.Join(fix.Findings, "\n      "))
+		fmt.Fprintf(session.out, "      %s: $ %s\n", fix.Description, fix.Command)
+		if !session.yes {
+			answer := session.confirm()
+			if answer == "q" {
+				quit = true
+			}
+			if answer != "y" {
+				fix.Status = fixSkipped
+				continue
+			}
+		}
+
+		err := fixShell(ctx, fix.Command)
+		fix.Status = fixApplied
+		if err != nil {
+			fix.Status = fixFailed
+			fix.Error = err.Error()
+			fmt.Fprintf(session.out, "      failed: %v\n", err)
+		} else if fix.Installs != nil {
+			recordFixInstall(session.out, *fix.Installs)
+		}
+		telemetry.Logger.InfoContext(ctx, "fix.apply",
+			"command", fix.Command,
+			"status", fix.Status,
+		)
+	}
+
+	if session.dryRun {
+		return report
+	}
+	for _, f := range report.Fixes {
+		if f.Status == fixApplied || f.Status == fixFailed {
+			report.Resolved, report.Remaining = compareFindings(before.Findings, runDoctor(ctx, checks, severityError).Findings)
+			break
+		}
+	}
+	return report
+}
+
+// recordFixInstall adds a package a fix installed to the package ledger
+func recordFixInstall(out io.Writer, install model.PackageInstall) {
+	entry := osagnostic.LedgerEntry{Command: install.Command, Manager: install.Manager, Package: install.Package, InstalledAt: time.Now().UTC()}
+	ledger, err := fixLedger()
+	if err == nil {
+		err = ledger.Record(entry)
+	}
+	if err != nil {
+		fmt.Fprintf(out, "      warning: %v; 'bootstrap reset' will not remove %s\n", err, install.Package)
+	}
+}
+
+// confirm asks whether to apply a fix and returns "y", "n" or "q"
+func (s fixSession) confirm() string {
+	fmt.Fprint(s.out, "      Apply? [y/N/q] ")
+	line, err := s.in.ReadString('\n')
+	if err != nil && line == "" {
+		// No more input: treat it as quitting, so a closed stdin applies nothing
+		fmt.Fprintln(s.out)
+		return "q"
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return "y"
+	case "q", "quit":
+		return "q"
+	default:
+		return "n"
+	}
+}
+
+// collectFixes gathers the findings' fixes in finding order. Findings that
+// share a command, like several dotfiles needing ./fresh.sh, share one fix.
+func collectFixes(findings []Finding) []FixResult {
+	fixes := []FixResult{}
+	byCommand := map[string]int{}
+	for _, f := range findings {
+		if f.Fix == nil {
+			continue
+		}
+		message := fmt.Sprintf("%s  %s", f.Check, f.Message)
+		if i, ok := byCommand[f.Fix.Command]; ok {
+			fixes[i].Findings = append(fixes[i].Findings, message)
+			continue
+		}
+		byCommand[f.Fix.Command] = len(fixes)
+		fixes = append(fixes, FixResult{Fix: *f.Fix, Findings: []string{message}})
+	}
+	return fixes
+}
+
+// compareFindings splits the findings from before the fixes into those gone
+// afterwards and those still there, plus any new ones
+func compareFindings(before, after []Finding) (resolved, remaining []Finding) {
+	key := func(f Finding) string { return f.Check + "\x00" + f.Message }
+	still := map[string]bool{}
+	for _, f := range after {
+		still[key(f)] = true
+	}
+	resolved = []Finding{}
+	for _, f := range before {
+		if !still[key(f)] {
+			resolved = append(resolved, f)
+		}
+	}
+	return resolved, after
+}
+
+func printFixReport(report *FixReport) {
+	if len(report.Fixes) == 0 {
+		if len(report.Remaining) == 0 {
+			fmt.Println("Nothing to fix")
+		} else {
+			fmt.Printf("Nothing to fix automatically; %d finding(s) need attention (see 'allbctl doctor')\n", len(report.Remaining))
+		}
+		return
+	}
+
+	counts := map[string]int{}
+	for _, f := range report.Fixes {
+		counts[f.Status]++
+		if f.Status == fixPlanned {
+			fmt.Printf("%s\n  %s: $ %s\n", strings.Join(f.Findings, "\n"), f.Description, f.Command)
+		}
+	}
+	if counts[fixPlanned] > 0 {
+		fmt.Printf("\n%d fix(es) would be applied\n", counts[fixPlanned])
+		return
+	}
+
+	fmt.Println()
+	for _, f := range report.Resolved {
+		fmt.Printf("%s  %-10s %s\n", resultLabels[model.StatusOK].Sprintf("%-5s", "fixed"), f.Check, f.Message)
+	}
+	fmt.Printf("%d applied, %d failed, %d skipped; %d finding(s) resolved, %d remaining\n",
+		counts[fixApplied], counts[fixFailed], counts[fixSkipped], len(report.Resolved), len(report.Remaining))
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aallbrig/allbctl/pkg/model"
+	"github.com/aallbrig/allbctl/pkg/osagnostic"
+)
+
+func TestRunFix(t *testing.T) {
+	created := false
+	checks := []doctorCheckEntry{{check: doctorCheckFunc{"bootstrap", func(context.Context) ([]Finding, error) {
+		var findings []Finding
+		if !created {
+			findings = append(findings, Finding{Severity: severityError, Message: "~/src: not found",
+				Fix: &model.Fix{Description: "create ~/src", Command: "mkdir -p ~/src"}})
+		}
+		return append(findings,
+			Finding{Severity: severityWarn, Message: ".zshrc not symlinked into $HOME",
+				Fix: &model.Fix{Description: "re-run the dotfiles install script", Command: "cd ~/src/dotfiles && ./fresh.sh"}},
+			Finding{Severity: severityWarn, Message: ".vimrc not symlinked into $HOME",
+				Fix: &model.Fix{Description: "re-run the dotfiles install script", Command: "cd ~/src/dotfiles && ./fresh.sh"}},
+			Finding{Severity: severityWarn, Message: "gh ssh-key list failed", Hint: "gh auth status"},
+			Finding{Severity: severityInfo, Message: "Go 1.25.1 is installed; 1.26.3 is available",
+				Fix: &model.Fix{Description: "upgrade go", Command: "false"}},
+		), nil
+	}}, timeout: time.Second}}
+
+	defer func(shell func(context.Context, string) error) { fixShell = shell }(fixShell)
+	var ran []string
+	fixShell = func(ctx context.Context, command string) error {
+		ran = append(ran, command)
+		switch command {
+		case "mkdir -p ~/src":
+			created = true
+		case "false":
+			return errors.New("exit status 1")
+		}
+		return nil
+	}
+
+	var out bytes.Buffer
+	report := runFix(context.Background(), checks, fixSession{
+		in:  bufio.NewReader(strings.NewReader("y\nn\nyes\n")),
+		out: &out,
+	})
+
+	if strings.Join(ran, "; ") != "mkdir -p ~/src; false" {
+		t.Errorf("ran %q, want the confirmed fixes only", ran)
+	}
+	wantStatus := []string{fixApplied, fixSkipped, fixFailed}
+	if len(report.Fixes) != len(wantStatus) {
+		t.Fatalf("runFix() fixes = %+v, want fresh.sh shared by both dotfiles findings", report.Fixes)
+	}
+	for i, want := range wantStatus {
+		if report.Fixes[i].Status != want {
+			t.Errorf("fix %q status = %s, want %s", report.Fixes[i].Command, report.Fixes[i].Status, want)
+		}
+	}
+	if got := report.Fixes[1].Findings; len(got) != 2 {
+		t.Errorf("fresh.sh fix findings = %q, want both symlink findings", got)
+	}
+	if len(report.Resolved) != 1 || report.Resolved[0].Message != "~/src: not found" || len(report.Remaining) != 4 {
+		t.Errorf("runFix() resolved %+v, remaining %d; want ~/src resolved and 4 remaining", report.Resolved, len(report.Remaining))
+	}
+	if !strings.Contains(out.String(), "create ~/src: $ mkdir -p ~/src") {
+		t.Errorf("runFix() output should show each fix's command\n%s", out.String())
+	}
+}
+
+func TestRunFix_DryRunAndQuit(t *testing.T) {
+	checks := []doctorCheckEntry{{check: doctorCheckFunc{"systemd", func(context.Context) ([]Finding, error) {
+		return []Finding{
+			{Severity: severityError, Message: "system service a.service failed", Fix: &model.Fix{Command: "systemctl restart a.service"}},
+			{Severity: severityError, Message: "system service b.service failed", Fix: &model.Fix{Command: "systemctl restart b.service"}},
+		}, nil
+	}}, timeout: time.Second}}
+
+	defer func(shell func(context.Context, string) error) { fixShell = shell }(fixShell)
+	fixShell = func(context.Context, string) error {
+		t.Error("no fix should run")
+		return nil
+	}
+
+	report := runFix(context.Background(), checks, fixSession{dryRun: true, out: &bytes.Buffer{}})
+	for _, f := range report.Fixes {
+		if f.Status != fixPlanned {
+			t.Errorf("--dry-run fix %q status = %s, want planned", f.Command, f.Status)
+		}
+	}
+
+	// Quitting, or running out of input, skips the rest
+	for _, input := range []string{"q\n", ""} {
+		report = runFix(context.Background(), checks, fixSession{in: bufio.NewReader(strings.NewReader(input)), out: &bytes.Buffer{}})
+		for _, f := range report.Fixes {
+			if f.Status != fixSkipped {
+				t.Errorf("input %q: fix %q status = %s, want skipped", input, f.Command, f.Status)
+			}
+		}
+	}
+}
+
+func TestRunFix_RecordsInstalls(t *testing.T) {
+	install := func(cmd string) Finding {
+		return Finding{Severity: severityError, Message: "Installable Command: " + cmd + ": not found on PATH",
+			Fix: &model.Fix{Description: "install " + cmd + " with apt", Command: "sudo apt-get install -y " + cmd,
+				Installs: &model.PackageInstall{Command: cmd, Manager: "apt", Package: cmd}}}
+	}
+	checks := []doctorCheckEntry{{check: doctorCheckFunc{"bootstrap", func(context.Context) ([]Finding, error) {
+		return []Finding{install("jq"), install("tmux")}, nil
+	}}, timeout: time.Second}}
+
+	defer func(shell func(context.Context, string) error) { fixShell = shell }(fixShell)
+	fixShell = func(_ context.Context, command string) error {
+		if strings.HasSuffix(command, "tmux") {
+			return errors.New("exit status 100")
+		}
+		return nil
+	}
+	ledger := osagnostic.NewPackageLedger(filepath.Join(t.TempDir(), "packages.json"))
+	defer func(open func() (*osagnostic.PackageLedger, error)) { fixLedger = open }(fixLedger)
+	fixLedger = func() (*osagnostic.PackageLedger, error) { return ledger, nil }
+
+	runFix(context.Background(), checks, fixSession{yes: true, out: &bytes.Buffer{}})
+
+	entries, err := ledger.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Command != "jq" || entries[0].Manager != "apt" || entries[0].Package != "jq" {
+		t.Errorf("ledger = %+v, want only jq, whose install fix succeeded", entries)
+	}
+}
+
+func TestRunFix_QuotesPaths(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fixes run through sh")
+	}
+	dir := filepath.Join(t.TempDir(), "my projects", "it's $(touch pwned)")
+	checks := []doctorCheckEntry{{check: doctorCheckFunc{"bootstrap", func(context.Context) ([]Finding, error) {
+		result, _ := osagnostic.NewExpectedDirectory(dir).Validate()
+		return resultFindings(result, nil, ""), nil
+	}}, timeout: time.Second}}
+
+	report := runFix(context.Background(), checks, fixSession{yes: true, out: &bytes.Buffer{}})
+
+	want := `mkdir -p '` + filepath.Dir(filepath.Dir(dir)) + `/my projects/it'\''s $(touch pwned)'`
+	if len(report.Fixes) != 1 || report.Fixes[0].Command != want || report.Fixes[0].Status != fixApplied {
+		t.Fatalf("runFix() fixes = %+v, want %s applied", report.Fixes, want)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Errorf("fix did not create %s: %v", dir, err)
+	}
+	if _, err := os.Stat("pwned"); err == nil {
+		t.Error("the fix command ran a command substitution from the path")
+	}
+}
@@ -0,0 +1,443 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/aallbrig/allbctl/pkg/model"
+	"github.com/aallbrig/allbctl/pkg/pkgmgr"
+	"github.com/aallbrig/allbctl/pkg/runner"
+	"github.com/aallbrig/allbctl/pkg/telemetry"
+)
+
+// fleetConfigKey is the config file section that lists the fleet
+const fleetConfigKey = "fleet"
+
+// fleetReportVersion is the wire format version of 'allbctl fleet report'.
+// Bump it when FleetReport changes incompatibly.
+const fleetReportVersion = 1
+
+// fleetReportSections are the status sections a fleet report collects
+var fleetReportSections = []string{"system", "runtimes", "packages", "projects"}
+
+// FleetConfig is the `fleet:` section of ~/.allbctl.yaml
+type FleetConfig struct {
+	// Hosts are ssh destinations, e.g. "dev-vm" or "me@10.0.0.5"
+	Hosts []string `mapstructure:"hosts"`
+	// Command is how to run allbctl on the hosts (default "allbctl")
+	Command string `mapstructure:"command"`
+}
+
+// FleetReport is what 'allbctl fleet report' prints for 'fleet status' to
+// read over SSH
+type FleetReport struct {
+	Version        int                    `json:"version"`
+	Status         *SystemSnapshot        `json:"status"`
+	Bootstrap      *BootstrapStatusReport `json:"bootstrap,omitempty"`
+	BootstrapError string                 `json:"bootstrap_error,omitempty"`
+}
+
+// FleetHostStatus is one host's result in 'allbctl fleet status'
+type FleetHostStatus struct {
+	Host       string       `json:"host"`
+	Report     *FleetReport `json:"report,omitempty"`
+	Error      string       `json:"error,omitempty"`
+	DurationMS int64        `json:"duration_ms"`
+}
+
+// fleetRunner runs command with args on host and returns its standard output.
+// Tests replace it with a stub.
+var fleetRunner = runOverSSH
+
+var (
+	fleetCommand string
+	fleetTimeout time.Duration
+)
+
+// FleetCmd groups the fleet subcommands
+var FleetCmd = &cobra.Command{
+	Use:   "fleet",
+	Short: "Compare allbctl status across machines",
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help() //nolint:errcheck // Help errors are not critical
+	},
+}
+
+var fleetStatusCmd = &cobra.Command{
+	Use:   "status [host...]",
+	Short: "Compare status across hosts over SSH",
+	Long: `Run allbctl on each host over SSH, in parallel, and show the results side by
+side: OS, runtime versions, pending package updates, dirty repos and bootstrap
+drift. Values that differ between hosts are highlighted.
+
+Hosts are ssh destinations; without arguments they come from the config file:
+
+  fleet:
+    hosts: [dev-vm, me@build-box]
+    command: ~/go/bin/allbctl    # default: allbctl
+
+Each host must have allbctl installed and accept non-interactive ssh (keys or
+an agent; BatchMode is on). Hosts exchange a JSON report ('allbctl fleet
+report'), not text.
+
+Examples:
+  allbctl fleet status dev-vm build-box
+  allbctl fleet status -o json | jq '.[] | select(.error)'`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		config, err := loadFleetConfig()
+		if err != nil {
+			return err
+		}
+		hosts := args
+		if len(hosts) == 0 {
+			hosts = config.Hosts
+		}
+		if len(hosts) == 0 {
+			return fmt.Errorf("no hosts: pass them as arguments or list them under fleet.hosts in the config file")
+		}
+		command := config.Command
+		if cmd.Flags().Changed("command") || command == "" {
+			command = fleetCommand
+		}
+
+		statuses := collectFleetStatus(ctx, hosts, command, fleetTimeout)
+		return renderOutput(statuses, func() { printFleetStatus(statuses) })
+	},
+}
+
+var fleetReportCmd = &cobra.Command{
+	Use:    "report",
+	Short:  "Print this machine's fleet report as JSON",
+	Long:   `Print the JSON report 'allbctl fleet status' reads from each host over SSH.`,
+	Hidden: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		report, err := collectFleetReport(ctx)
+		if err != nil {
+			return err
+		}
+		return writeStructured(os.Stdout, outputJSON, report)
+	},
+}
+
+func init() {
+	FleetCmd.AddCommand(fleetStatusCmd)
+	FleetCmd.AddCommand(fleetReportCmd)
+	fleetStatusCmd.Flags().VarP(&outputFormat, "output", "o", "Output format: text, json or yaml")
+	fleetStatusCmd.Flags().StringVar(&fleetCommand, "command", "allbctl", "How to run allbctl on the hosts (default: fleet.command from config, or allbctl)")
+	fleetStatusCmd.Flags().DurationVar(&fleetTimeout, "timeout", 3*time.Minute, "How long to wait for each host")
+}
+
+// loadFleetConfig reads the `fleet:` section of the config file, if any
+func loadFleetConfig() (FleetConfig, error) {
+	var config FleetConfig
+	if !viper.IsSet(fleetConfigKey) {
+		return config, nil
+	}
+	if err := viper.UnmarshalKey(fleetConfigKey, &config); err != nil {
+		return config, fmt.Errorf("cannot read %s from %s: %w", fleetConfigKey, viper.ConfigFileUsed(), err)
+	}
+	return config, nil
+}
+
+// collectFleetReport gathers the report this machine sends to 'fleet status'
+func collectFleetReport(ctx context.Context) (*FleetReport, error) {
+	sections, err := selectStatusSections(statusSections, fleetReportSections, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	report := &FleetReport{
+		Version: fleetReportVersion,
+		Status:  collectSystemSnapshot(ctx, sections),
+	}
+	// A broken bootstrap config should not hide the rest of the report
+	if report.Bootstrap, err = collectBootstrapStatus(ctx); err != nil {
+		report.BootstrapError = err.Error()
+	}
+	return report, nil
+}
+
+// collectFleetStatus asks every host for its report in parallel, and returns
+// the results in the order the hosts were given
+func collectFleetStatus(ctx context.Context, hosts []string, command string, timeout time.Duration) []FleetHostStatus {
+	statuses := make([]FleetHostStatus, len(hosts))
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			statuses[i] = fetchFleetReport(ctx, host, command, timeout)
+		}()
+	}
+	wg.Wait()
+	return statuses
+}
+
+// fetchFleetReport runs 'allbctl fleet report' on host and decodes it
+func fetchFleetReport(ctx context.Context, host, command string, timeout time.Duration) FleetHostStatus {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	status := FleetHostStatus{Host: host}
+	start := time.Now()
+	output, err := fleetRunner(ctx, host, command, []string{"fleet", "report"})
+	status.DurationMS = time.Since(start).Milliseconds()
+
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		status.Error = fmt.Sprintf("timed out after %s", timeout)
+	case err != nil:
+		status.Error = err.Error()
+	default:
+		var report FleetReport
+		if err := json.Unmarshal(output, &report); err != nil {
+			status.Error = fmt.Sprintf("unexpected output from %s: %v", command, err)
+		} else if report.Version != fleetReportVersion || report.Status == nil {
+			status.Error = fmt.Sprintf("unsupported report version %d (want %d); run the same allbctl version on every host", report.Version, fleetReportVersion)
+		} else {
+			status.Report = &report
+		}
+	}
+
+	telemetry.Logger.InfoContext(ctx, "fleet.host",
+		"host", host,
+		"duration_ms", status.DurationMS,
+		"error", status.Error,
+	)
+	return status
+}
+
+// runOverSSH runs command with args on host through the ssh client. ssh
+// joins everything after the host into one line for the remote shell, so
+// args are quoted; command is used as written, since the config may give a
+// path like ~/go/bin/allbctl.
+func runOverSSH(ctx context.Context, host, command string, args []string) ([]byte, error) {
+	if strings.HasPrefix(host, "-") {
+		// ssh would take it as an option, e.g. -oProxyCommand=...
+		return nil, fmt.Errorf("invalid host %q: ssh destinations cannot start with '-'", host)
+	}
+	remote := command
+	for _, arg := range args {
+		remote += " " + pkgmgr.ShellQuote(arg)
+	}
+	var stdout bytes.Buffer
+	var stderr strings.Builder
+	err := runner.FromContext(ctx).Run(ctx, runner.Cmd{
+		Name:   "ssh",
+		Args:   []string{"-o", "BatchMode=yes", "-o", "ConnectTimeout=10", "--", host, remote},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	if err != nil {
+		message := lastLine(stderr.String())
+		code, exited := runner.ExitCode(err)
+		switch {
+		case strings.Contains(message, "unknown command"):
+			return nil, fmt.Errorf("allbctl on %s does not support 'fleet report'; upgrade it", host)
+		case message != "":
+			return nil, errors.New(message)
+		case exited:
+			return nil, fmt.Errorf("ssh exited with status %d", code)
+		default:
+			return nil, fmt.Errorf("running ssh: %w", err)
+		}
+	}
+	return stdout.Bytes(), nil
+}
+
+// lastLine returns the last non-blank line of s
+func lastLine(s string) string {
+	lines := nonEmptyLines(s)
+	if len(lines) == 0 {
+		return ""
+	}
+	return lines[len(lines)-1]
+}
+
+// fleetRow is one line of the comparison table
+type fleetRow struct {
+	label  string
+	values []string
+}
+
+// fleetRows builds the comparison table, one value per host
+func fleetRows(statuses []FleetHostStatus) []fleetRow {
+	value := func(fn func(r *FleetReport) string) []string {
+		values := make([]string, len(statuses))
+		for i, s := range statuses {
+			if s.Report == nil {
+				values[i] = "-"
+			} else {
+				values[i] = fn(s.Report)
+			}
+		}
+		return values
+	}
+
+	rows := []fleetRow{
+		{"OS", value(func(r *FleetReport) string { return r.Status.OS })},
+		{"allbctl", value(func(r *FleetReport) string { return r.Status.Version })},
+	}
+
+	// One row per language runtime found on any host
+	names := map[string]bool{}
+	for _, s := range statuses {
+		if s.Report == nil {
+			continue
+		}
+		for _, rt := range s.Report.Status.Runtimes {
+			if rt.Category == "language" {
+				names[rt.Name] = true
+			}
+		}
+	}
+	runtimeNames := make([]string, 0, len(names))
+	for name := range names {
+		runtimeNames = append(runtimeNames, name)
+	}
+	sort.Strings(runtimeNames)
+	for _, name := range runtimeNames {
+		rows = append(rows, fleetRow{name, value(func(r *FleetReport) string {
+			for _, rt := range r.Status.Runtimes {
+				if rt.Name == name && rt.Category == "language" {
+					if v := extractVersionNumber(rt.Version); v != "" {
+						return v
+					}
+					return "installed"
+				}
+			}
+			return "-"
+		})})
+	}
+
+	rows = append(rows,
+		fleetRow{"Updates", value(func(r *FleetReport) string { return fleetUpdates(r.Status.Packages) })},
+		fleetRow{"Dirty repos", value(func(r *FleetReport) string {
+			if r.Status.Projects == nil {
+				return "-"
+			}
+			return fmt.Sprintf("%d of %d", r.Status.Projects.Dirty, r.Status.Projects.Total)
+		})},
+		fleetRow{"Bootstrap", value(fleetBootstrap)},
+	)
+	return rows
+}
+
+// fleetUpdates summarizes pending updates, e.g. "4 (apt 3, npm 1)"
+func fleetUpdates(packages []PackageResult) string {
+	total := 0
+	var parts []string
+	for _, p := range packages {
+		if p.UpdateCount > 0 {
+			total += p.UpdateCount
+			parts = append(parts, fmt.Sprintf("%s %d", p.Manager, p.UpdateCount))
+		}
+	}
+	if total == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("%d (%s)", total, strings.Join(parts, ", "))
+}
+
+// fleetBootstrap summarizes bootstrap drift, e.g. "missing (3 ok, 1 missing)"
+func fleetBootstrap(r *FleetReport) string {
+	if r.Bootstrap == nil {
+		if r.BootstrapError != "" {
+			return "error"
+		}
+		return "-"
+	}
+	if r.Bootstrap.Status == model.StatusOK {
+		return "ok"
+	}
+	return fmt.Sprintf("%s (%s)", r.Bootstrap.Status, resultSummary(r.Bootstrap.Results))
+}
+
+// fleetDifference highlights values that are not the same on every host
+var fleetDifference = color.New(color.FgYellow)
+
+func printFleetStatus(statuses []FleetHostStatus) {
+	rows := fleetRows(statuses)
+
+	labelWidth := len("Host")
+	for _, row := range rows {
+		labelWidth = max(labelWidth, len(row.label))
+	}
+	widths := make([]int, len(statuses))
+	for i, s := range statuses {
+		widths[i] = len(s.Host)
+		for _, row := range rows {
+			widths[i] = max(widths[i], len(row.values[i]))
+		}
+	}
+
+	fmt.Printf("%-*s", labelWidth, "Host")
+	for i, s := range statuses {
+		fmt.Printf("  %-*s", widths[i], s.Host)
+	}
+	fmt.Println()
+
+	for _, row := range rows {
+		differs := fleetValuesDiffer(row.values, statuses)
+		fmt.Printf("%-*s", labelWidth, row.label)
+		for i, v := range row.values {
+			cell := v
+			if i < len(row.values)-1 {
+				cell = fmt.Sprintf("%-*s", widths[i], v)
+			}
+			if differs && statuses[i].Report != nil {
+				cell = fleetDifference.Sprint(cell)
+			}
+			fmt.Printf("  %s", cell)
+		}
+		fmt.Println()
+	}
+
+	var failed bool
+	for _, s := range statuses {
+		if s.Error != "" {
+			if !failed {
+				fmt.Println()
+				failed = true
+			}
+			fmt.Printf("%s: %s\n", s.Host, s.Error)
+		} else if s.Report.BootstrapError != "" {
+			fmt.Printf("%s: bootstrap: %s\n", s.Host, s.Report.BootstrapError)
+		}
+	}
+}
+
+// fleetValuesDiffer reports whether the hosts that answered disagree
+func fleetValuesDiffer(values []string, statuses []FleetHostStatus) bool {
+	first := ""
+	seen := false
+	for i, v := range values {
+		if statuses[i].Report == nil {
+			continue
+		}
+		if seen && v != first {
+			return true
+		}
+		first, seen = v, true
+	}
+	return false
+}
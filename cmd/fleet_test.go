@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/aallbrig/allbctl/pkg/model"
+	"github.com/aallbrig/allbctl/pkg/runner"
+)
+
+func testFleetReport(goVersion string, updates int) *FleetReport {
+	snapshot := testSystemSnapshot()
+	snapshot.Runtimes = []RuntimeInfo{{Name: "Go", Version: "go version go" + goVersion + " linux/amd64", Category: "language"}}
+	snapshot.Packages = []PackageResult{{Manager: "apt", Count: 100, UpdateCount: updates}}
+	snapshot.Projects = &ProjectsSummary{Total: 10, Dirty: 2}
+	return &FleetReport{
+		Version: fleetReportVersion,
+		Status:  snapshot,
+		Bootstrap: &BootstrapStatusReport{Status: model.StatusMissing, Results: []*model.Result{
+			model.NewResult("Installable Command: git", model.StatusOK, ""),
+			model.NewResult("Installable Command: gh", model.StatusMissing, ""),
+		}},
+	}
+}
+
+func TestCollectFleetStatus(t *testing.T) {
+	defer func(runner func(context.Context, string, string, []string) ([]byte, error)) { fleetRunner = runner }(fleetRunner)
+	fleetRunner = func(ctx context.Context, host, command string, args []string) ([]byte, error) {
+		if command != "~/bin/allbctl" || strings.Join(args, " ") != "fleet report" {
+			t.Errorf("ran %q %v on %s, want ~/bin/allbctl fleet report", command, args, host)
+		}
+		switch host {
+		case "laptop":
+			return json.Marshal(testFleetReport("1.26.3", 3))
+		case "desktop":
+			return json.Marshal(testFleetReport("1.25.1", 0))
+		case "old":
+			return []byte(`{"version": 0}`), nil
+		case "slow":
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return nil, errors.New("ssh: Could not resolve hostname " + host)
+	}
+
+	statuses := collectFleetStatus(context.Background(), []string{"laptop", "desktop", "gone", "old", "slow"}, "~/bin/allbctl", 50*time.Millisecond)
+	if len(statuses) != 5 || statuses[0].Host != "laptop" || statuses[1].Host != "desktop" {
+		t.Fatalf("collectFleetStatus() = %+v, want one result per host in order", statuses)
+	}
+	if statuses[0].Report == nil || statuses[1].Report == nil {
+		t.Fatalf("collectFleetStatus() reachable hosts failed: %q, %q", statuses[0].Error, statuses[1].Error)
+	}
+	for i, want := range map[int]string{2: "Could not resolve hostname", 3: "unsupported report version", 4: "timed out"} {
+		if statuses[i].Report != nil || !strings.Contains(statuses[i].Error, want) {
+			t.Errorf("%s: error = %q, want it to contain %q", statuses[i].Host, statuses[i].Error, want)
+		}
+	}
+
+	output := captureOutput(func() { printFleetStatus(statuses[:3]) })
+	for _, want := range []string{
+		"Host", "laptop", "desktop",
+		"Go", "1.26.3", "1.25.1",
+		"3 (apt 3)",
+		"2 of 10",
+		"missing (1 ok, 1 missing)",
+		"gone: ssh: Could not resolve hostname gone",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("printFleetStatus() output missing %q\n%s", want, output)
+		}
+	}
+}
+
+func TestFleetRows_Differences(t *testing.T) {
+	statuses := []FleetHostStatus{
+		{Host: "a", Report: testFleetReport("1.26.3", 0)},
+		{Host: "b", Report: testFleetReport("1.25.1", 0)},
+		{Host: "c", Error: "unreachable"},
+	}
+	differs := map[string]bool{}
+	for _, row := range fleetRows(statuses) {
+		differs[row.label] = fleetValuesDiffer(row.values, statuses)
+	}
+	if !differs["Go"] {
+		t.Error("Go versions 1.26.3 and 1.25.1 should be marked as different")
+	}
+	if differs["OS"] || differs["Updates"] {
+		t.Errorf("rows equal on every reachable host should not be marked: %v", differs)
+	}
+}
+
+func TestLoadFleetConfig(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	viper.SetConfigType("yaml")
+	if err := viper.ReadConfig(strings.NewReader("fleet:\n  hosts: [laptop, me@10.0.0.5]\n  command: ~/go/bin/allbctl\n")); err != nil {
+		t.Fatal(err)
+	}
+	config, err := loadFleetConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(config.Hosts, ",") != "laptop,me@10.0.0.5" || config.Command != "~/go/bin/allbctl" {
+		t.Errorf("loadFleetConfig() = %+v", config)
+	}
+}
+
+// argvRunner records the command lines it is asked to run
+type argvRunner struct{ argv [][]string }
+
+func (r *argvRunner) Run(_ context.Context, cmd runner.Cmd) error {
+	r.argv = append(r.argv, cmd.Argv())
+	return nil
+}
+
+func (r *argvRunner) LookPath(name string) (string, error) { return "/usr/bin/" + name, nil }
+
+func TestRunOverSSH_Arguments(t *testing.T) {
+	fake := &argvRunner{}
+	ctx := runner.WithRunner(context.Background(), fake)
+
+	if _, err := runOverSSH(ctx, "me@dev-vm", "~/go/bin/allbctl", []string{"fleet", "report", "--note", "it's; rm -rf ~"}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"ssh", "-o", "BatchMode=yes", "-o", "ConnectTimeout=10", "--", "me@dev-vm", `~/go/bin/allbctl fleet report --note 'it'\''s; rm -rf ~'`}
+	if len(fake.argv) != 1 || strings.Join(fake.argv[0], "\n") != strings.Join(want, "\n") {
+		t.Errorf("ssh argv = %q, want %q", fake.argv, want)
+	}
+
+	if _, err := runOverSSH(ctx, "-oProxyCommand=touch /tmp/pwned", "allbctl", []string{"fleet", "report"}); err == nil || !strings.Contains(err.Error(), "cannot start with '-'") {
+		t.Errorf("runOverSSH(option-like host) error = %v, want it rejected", err)
+	}
+	if len(fake.argv) != 1 {
+		t.Errorf("ssh ran for an option-like host: %q", fake.argv[1:])
+	}
+}
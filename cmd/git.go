@@ -1,30 +1,40 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
 	"strings"
 
 	"github.com/spf13/cobra"
+
+	"github.com/aallbrig/allbctl/pkg/runner"
 )
 
 var GitConfigCmd = &cobra.Command{
 	Use:   "git",
 	Short: "Display git global configuration",
 	Long:  `Display git global configuration including user name, email, and editor.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		PrintGitConfigInfo()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if !isStructuredOutput() {
+			PrintGitConfigInfo(ctx)
+			return nil
+		}
+		if !exists(ctx, "git") {
+			return fmt.Errorf("git is not installed")
+		}
+		return printStructured(gatherGitConfigInfo(ctx))
 	},
 }
 
 type GitConfigInfo struct {
-	UserName   string
-	UserEmail  string
-	CoreEditor string
+	UserName   string `json:"user_name"`
+	UserEmail  string `json:"user_email"`
+	CoreEditor string `json:"core_editor"`
 }
 
-func PrintGitConfigInfo() {
-	if !exists("git") {
+func PrintGitConfigInfo(ctx context.Context) {
+	if !exists(ctx, "git") {
 		fmt.Println("Git is not installed")
 		return
 	}
@@ -32,7 +42,7 @@ func PrintGitConfigInfo() {
 	fmt.Println("Git Global Configuration:")
 	fmt.Println()
 
-	info := gatherGitConfigInfo()
+	info := gatherGitConfigInfo(ctx)
 
 	if info.UserName != "" {
 		fmt.Printf("  User Name:  %s\n", info.UserName)
@@ -53,26 +63,23 @@ func PrintGitConfigInfo() {
 	}
 }
 
-func gatherGitConfigInfo() *GitConfigInfo {
+func gatherGitConfigInfo(ctx context.Context) *GitConfigInfo {
 	info := &GitConfigInfo{}
 
 	// Get user.name
-	cmd := exec.Command("git", "config", "--global", "user.name")
-	out, err := cmd.Output()
+	out, err := runner.Output(ctx, "git", "config", "--global", "user.name")
 	if err == nil {
 		info.UserName = strings.TrimSpace(string(out))
 	}
 
 	// Get user.email
-	cmd = exec.Command("git", "config", "--global", "user.email")
-	out, err = cmd.Output()
+	out, err = runner.Output(ctx, "git", "config", "--global", "user.email")
 	if err == nil {
 		info.UserEmail = strings.TrimSpace(string(out))
 	}
 
 	// Get core.editor
-	cmd = exec.Command("git", "config", "--global", "core.editor")
-	out, err = cmd.Output()
+	out, err = runner.Output(ctx, "git", "config", "--global", "core.editor")
 	if err == nil {
 		info.CoreEditor = strings.TrimSpace(string(out))
 	}
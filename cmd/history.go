@@ -0,0 +1,548 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aallbrig/allbctl/pkg/history"
+	"github.com/aallbrig/allbctl/pkg/telemetry"
+)
+
+// historyLimit is how many status snapshots are kept on disk
+const historyLimit = 100
+
+var (
+	diffSinceFlag string
+	historyLimitN int
+)
+
+// StatusHistoryCmd lists recorded status snapshots
+var StatusHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List recorded status snapshots",
+	Long: `List the snapshots recorded by previous 'allbctl status' runs.
+
+Every 'allbctl status' run stores its results under the user cache directory
+(e.g. ~/.cache/allbctl/history on Linux). The last 100 runs are kept.
+
+Examples:
+  allbctl status history               # List recorded runs, newest first
+  allbctl status history --limit 5     # Only the 5 most recent runs
+  allbctl status history -o json       # Machine-readable list`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openHistoryStore()
+		if err != nil {
+			return err
+		}
+		summaries, total, err := historySummaries(store, historyLimitN)
+		if err != nil {
+			return err
+		}
+		return renderOutput(summaries, func() { printHistorySummaries(summaries, total, store.Dir()) })
+	},
+}
+
+// StatusDiffCmd compares two recorded status snapshots
+var StatusDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show what changed between status runs",
+	Long: `Show what changed on this machine between recorded 'allbctl status' runs:
+new or removed packages, runtime version bumps, repos that became dirty or clean,
+and listening ports that appeared or went away.
+
+By default the latest run is compared with the one before it. Use --since to
+compare against the newest run at least that old (e.g. 36h, 7d, 2w).
+
+Examples:
+  allbctl status diff                  # Latest run vs the previous run
+  allbctl status diff --since 7d       # What changed since last week
+  allbctl status diff --since 7d -o json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		store, err := openHistoryStore()
+		if err != nil {
+			return err
+		}
+		from, to, err := selectDiffRecords(store, diffSinceFlag, time.Now())
+		if err != nil {
+			return err
+		}
+		diff := diffSnapshots(from, to)
+		telemetry.Logger.InfoContext(ctx, "status.diff",
+			"from", diff.From,
+			"to", diff.To,
+			"changes", diff.changeCount(),
+		)
+		return renderOutput(diff, func() { printSnapshotDiff(diff) })
+	},
+}
+
+func init() {
+	StatusHistoryCmd.Flags().IntVar(&historyLimitN, "limit", 0, "Limit the number of runs shown (0 = show all)")
+	StatusDiffCmd.Flags().StringVar(&diffSinceFlag, "since", "", "Compare against the newest run at least this old (e.g. 36h, 7d, 2w)")
+}
+
+// HistoryRecord is what each 'allbctl status' run stores on disk
+type HistoryRecord struct {
+	Snapshot *SystemSnapshot `json:"snapshot"`
+	// PackageNames maps manager → installed package names; too large for --output, needed for diffs
+	PackageNames map[string][]string `json:"package_names,omitempty"`
+}
+
+// newHistoryRecord wraps a snapshot together with the package names it collected
+func newHistoryRecord(snapshot *SystemSnapshot) *HistoryRecord {
+	record := &HistoryRecord{Snapshot: snapshot, PackageNames: map[string][]string{}}
+	for _, p := range snapshot.Packages {
+		if len(p.Names) > 0 {
+			record.PackageNames[p.Manager] = p.Names
+		}
+	}
+	return record
+}
+
+func openHistoryStore() (*history.Store, error) {
+	return history.NewStore("allbctl", "history")
+}
+
+// saveSnapshotHistory records a snapshot so later runs can diff against it.
+// Failures only affect history, never the status output, so they are reported on stderr.
+func saveSnapshotHistory(ctx context.Context, snapshot *SystemSnapshot) {
+	store, err := openHistoryStore()
+	if err == nil {
+		_, err = store.Save(snapshot.CollectedAt, newHistoryRecord(snapshot))
+	}
+	if err == nil {
+		err = store.Prune(historyLimit)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not record status history: %v\n", err)
+		return
+	}
+	telemetry.Logger.DebugContext(ctx, "status.history_saved", "dir", store.Dir())
+}
+
+// HistorySummary is one line of 'allbctl status history'
+type HistorySummary struct {
+	CollectedAt time.Time `json:"collected_at"`
+	Hostname    string    `json:"hostname"`
+	Version     string    `json:"version"`
+	Packages    int       `json:"packages"`
+	Runtimes    int       `json:"runtimes"`
+	Projects    int       `json:"projects"`
+	DirtyRepos  int       `json:"dirty_repos"`
+	Ports       int       `json:"ports"`
+}
+
+// historySummaries loads recorded runs, newest first; limit 0 means all. It
+// also returns how many runs are stored in total.
+func historySummaries(store *history.Store, limit int) ([]HistorySummary, int, error) {
+	entries, err := store.List()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	summaries := []HistorySummary{}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if limit > 0 && len(summaries) >= limit {
+			break
+		}
+		var record HistoryRecord
+		if err := store.Load(entries[i], &record); err != nil || record.Snapshot == nil {
+			continue // Skip unreadable records rather than failing the whole listing
+		}
+		snap := record.Snapshot
+		summary := HistorySummary{
+			CollectedAt: entries[i].Time,
+			Hostname:    snap.Hostname,
+			Version:     snap.Version,
+			Runtimes:    len(snap.Runtimes),
+		}
+		for _, p := range snap.Packages {
+			summary.Packages += p.Count
+		}
+		if snap.Projects != nil {
+			summary.Projects = snap.Projects.Total
+			summary.DirtyRepos = snap.Projects.Dirty
+		}
+		if snap.Ports != nil {
+			summary.Ports = snap.Ports.TCPPorts + snap.Ports.UDPPorts
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, len(entries), nil
+}
+
+func printHistorySummaries(summaries []HistorySummary, total int, dir string) {
+	if len(summaries) == 0 {
+		fmt.Println("No status history recorded yet. Run 'allbctl status' to record a snapshot.")
+		return
+	}
+
+	fmt.Printf("Status history (stored in %s):\n\n", dir)
+	fmt.Printf("  %-20s %-9s %-9s %-9s %-12s %s\n", "WHEN", "PACKAGES", "RUNTIMES", "PROJECTS", "DIRTY REPOS", "PORTS")
+	for _, s := range summaries {
+		fmt.Printf("  %-20s %-9d %-9d %-9d %-12d %d\n",
+			s.CollectedAt.Local().Format("2006-01-02 15:04:05"),
+			s.Packages, s.Runtimes, s.Projects, s.DirtyRepos, s.Ports)
+	}
+	fmt.Printf("\nshowing %d of %d runs\n", len(summaries), total)
+}
+
+// parseSince parses a look-back window. It accepts Go durations (36h, 90m)
+// plus day and week suffixes (7d, 2w).
+func parseSince(since string) (time.Duration, error) {
+	since = strings.TrimSpace(since)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if strings.HasSuffix(since, suffix) {
+			n, err := strconv.ParseFloat(strings.TrimSuffix(since, suffix), 64)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid --since value %q (examples: 36h, 7d, 2w)", since)
+			}
+			return time.Duration(n * float64(unit)), nil
+		}
+	}
+	d, err := time.ParseDuration(since)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid --since value %q (examples: 36h, 7d, 2w)", since)
+	}
+	return d, nil
+}
+
+// selectDiffRecords picks the records to compare: the latest run and either
+// the run before it or, with since, the newest run at least that old.
+func selectDiffRecords(store *history.Store, since string, now time.Time) (*HistoryRecord, *HistoryRecord, error) {
+	entries, err := store.List()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(entries) < 2 {
+		return nil, nil, fmt.Errorf("need at least two recorded status runs to diff (found %d); run 'allbctl status' again later", len(entries))
+	}
+
+	latest := entries[len(entries)-1]
+	base := entries[len(entries)-2]
+	if since != "" {
+		window, err := parseSince(since)
+		if err != nil {
+			return nil, nil, err
+		}
+		var ok bool
+		base, ok = history.Before(entries[:len(entries)-1], now.Add(-window))
+		if !ok {
+			// Nothing that old yet; fall back to the oldest run we have
+			base = entries[0]
+		}
+	}
+
+	var from, to HistoryRecord
+	if err := store.Load(base, &from); err != nil {
+		return nil, nil, err
+	}
+	if err := store.Load(latest, &to); err != nil {
+		return nil, nil, err
+	}
+	if from.Snapshot == nil || to.Snapshot == nil {
+		return nil, nil, fmt.Errorf("history record is missing its snapshot")
+	}
+	return &from, &to, nil
+}
+
+// SnapshotDiff describes what changed between two status snapshots
+type SnapshotDiff struct {
+	From     time.Time       `json:"from"`
+	To       time.Time       `json:"to"`
+	OS       *VersionChange  `json:"os,omitempty"`
+	Packages []PackageChange `json:"packages"`
+	Runtimes []VersionChange `json:"runtimes"`
+	Repos    []RepoChange    `json:"repos"`
+	Ports    PortChanges     `json:"ports"`
+	// NotCompared lists sections left out because one of the runs skipped
+	// them or they timed out or failed
+	NotCompared []string `json:"not_compared,omitempty"`
+}
+
+// PackageChange lists packages added to or removed from one manager
+type PackageChange struct {
+	Manager     string   `json:"manager"`
+	CountBefore int      `json:"count_before"`
+	CountAfter  int      `json:"count_after"`
+	Added       []string `json:"added,omitempty"`
+	Removed     []string `json:"removed,omitempty"`
+}
+
+// VersionChange is a value that changed; an empty From or To means added or removed
+type VersionChange struct {
+	Name string `json:"name"`
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// RepoChange describes a repo whose state changed between snapshots
+type RepoChange struct {
+	Path    string      `json:"path"`
+	Change  string      `json:"change"` // "became dirty", "became clean", "new" or "removed"
+	Reasons DirtyReason `json:"reasons"`
+}
+
+// PortChanges lists listening ports (proto:port) that appeared or disappeared
+type PortChanges struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+func (d *SnapshotDiff) changeCount() int {
+	n := len(d.Packages) + len(d.Runtimes) + len(d.Repos) + len(d.Ports.Added) + len(d.Ports.Removed)
+	if d.OS != nil {
+		n++
+	}
+	return n
+}
+
+// diffSnapshots compares two history records. Only sections both runs
+// collected in full are compared, so a run with --sections, --skip or a
+// timed out section does not report everything it left out as removed.
+func diffSnapshots(from, to *HistoryRecord) *SnapshotDiff {
+	a, b := from.Snapshot, to.Snapshot
+	diff := &SnapshotDiff{
+		From:     a.CollectedAt,
+		To:       b.CollectedAt,
+		Packages: []PackageChange{},
+		Runtimes: []VersionChange{},
+		Repos:    []RepoChange{},
+	}
+	compare := func(section string) bool {
+		if a.sectionCollected(section) && b.sectionCollected(section) {
+			return true
+		}
+		diff.NotCompared = append(diff.NotCompared, section)
+		return false
+	}
+	if compare("system") && a.OS != b.OS {
+		diff.OS = &VersionChange{Name: "OS", From: a.OS, To: b.OS}
+	}
+	if compare("runtimes") {
+		diff.Runtimes = diffRuntimes(a.Runtimes, b.Runtimes)
+	}
+	if compare("ports") {
+		diff.Ports = diffPorts(a.Ports, b.Ports)
+	}
+	if compare("packages") {
+		diff.Packages = diffPackages(from, to)
+	}
+	if compare("projects") {
+		diff.Repos = diffRepos(a.Projects, b.Projects)
+	}
+	return diff
+}
+
+func diffPackages(from, to *HistoryRecord) []PackageChange {
+	before := map[string]PackageResult{}
+	for _, p := range from.Snapshot.Packages {
+		before[p.Manager] = p
+	}
+	after := map[string]PackageResult{}
+	for _, p := range to.Snapshot.Packages {
+		after[p.Manager] = p
+	}
+
+	changes := []PackageChange{}
+	for _, manager := range unionKeys(before, after) {
+		change := PackageChange{
+			Manager:     manager,
+			CountBefore: before[manager].Count,
+			CountAfter:  after[manager].Count,
+		}
+		change.Added, change.Removed = diffStrings(from.PackageNames[manager], to.PackageNames[manager])
+		if len(change.Added) > 0 || len(change.Removed) > 0 || change.CountBefore != change.CountAfter {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+func diffRuntimes(from, to []RuntimeInfo) []VersionChange {
+	version := func(runtimes []RuntimeInfo) map[string]string {
+		m := map[string]string{}
+		for _, rt := range runtimes {
+			v := extractVersionNumber(rt.Version)
+			if v == "" {
+				v = rt.Version
+			}
+			m[rt.Name] = v
+		}
+		return m
+	}
+	before, after := version(from), version(to)
+
+	changes := []VersionChange{}
+	for _, name := range unionKeys(before, after) {
+		if before[name] != after[name] {
+			changes = append(changes, VersionChange{Name: name, From: before[name], To: after[name]})
+		}
+	}
+	return changes
+}
+
+func diffRepos(from, to *ProjectsSummary) []RepoChange {
+	repos := func(summary *ProjectsSummary) map[string]RepoInfo {
+		m := map[string]RepoInfo{}
+		if summary != nil {
+			for _, r := range summary.Repos {
+				m[r.Path] = r
+			}
+		}
+		return m
+	}
+	before, after := repos(from), repos(to)
+
+	changes := []RepoChange{}
+	for _, path := range unionKeys(before, after) {
+		a, hadBefore := before[path]
+		b, hasAfter := after[path]
+		switch {
+		case !hadBefore:
+			changes = append(changes, RepoChange{Path: path, Change: "new", Reasons: b.DirtyReasons})
+		case !hasAfter:
+			changes = append(changes, RepoChange{Path: path, Change: "removed"})
+		case !a.Dirty && b.Dirty:
+			changes = append(changes, RepoChange{Path: path, Change: "became dirty", Reasons: b.DirtyReasons})
+		case a.Dirty && !b.Dirty:
+			changes = append(changes, RepoChange{Path: path, Change: "became clean"})
+		}
+	}
+	return changes
+}
+
+func diffPorts(from, to *PortInfo) PortChanges {
+	// Port details include the owning pid; compare only the "proto:port" part
+	ports := func(info *PortInfo) []string {
+		var out []string
+		if info != nil {
+			for _, p := range info.Ports {
+				if fields := strings.Fields(p); len(fields) > 0 {
+					out = append(out, fields[0])
+				}
+			}
+		}
+		return out
+	}
+	added, removed := diffStrings(ports(from), ports(to))
+	return PortChanges{Added: added, Removed: removed}
+}
+
+// diffStrings returns the sorted, de-duplicated values only in b (added) and only in a (removed)
+func diffStrings(a, b []string) (added, removed []string) {
+	inA, inB := map[string]bool{}, map[string]bool{}
+	for _, s := range a {
+		inA[s] = true
+	}
+	for _, s := range b {
+		inB[s] = true
+	}
+	for s := range inB {
+		if !inA[s] {
+			added = append(added, s)
+		}
+	}
+	for s := range inA {
+		if !inB[s] {
+			removed = append(removed, s)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// unionKeys returns the sorted keys present in either map
+func unionKeys[V any](a, b map[string]V) []string {
+	seen := map[string]bool{}
+	for k := range a {
+		seen[k] = true
+	}
+	for k := range b {
+		seen[k] = true
+	}
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func printSnapshotDiff(diff *SnapshotDiff) {
+	fmt.Printf("Changes from %s to %s:\n",
+		diff.From.Local().Format("2006-01-02 15:04"),
+		diff.To.Local().Format("2006-01-02 15:04"))
+	if len(diff.NotCompared) > 0 {
+		fmt.Printf("  Not compared (not fully collected in both runs): %s\n", strings.Join(diff.NotCompared, ", "))
+	}
+
+	if diff.changeCount() == 0 {
+		fmt.Println("  No changes detected")
+		return
+	}
+
+	if diff.OS != nil {
+		fmt.Printf("\nOS:        %s → %s\n", diff.OS.From, diff.OS.To)
+	}
+
+	if len(diff.Packages) > 0 {
+		fmt.Println("\nPackages:")
+		for _, p := range diff.Packages {
+			fmt.Printf("  %-15s %d → %d (+%d -%d)\n", p.Manager+":", p.CountBefore, p.CountAfter, len(p.Added), len(p.Removed))
+			for _, name := range p.Added {
+				fmt.Printf("    + %s\n", name)
+			}
+			for _, name := range p.Removed {
+				fmt.Printf("    - %s\n", name)
+			}
+		}
+	}
+
+	if len(diff.Runtimes) > 0 {
+		fmt.Println("\nRuntimes:")
+		for _, rt := range diff.Runtimes {
+			switch {
+			case rt.From == "":
+				fmt.Printf("  %-15s added (%s)\n", rt.Name+":", rt.To)
+			case rt.To == "":
+				fmt.Printf("  %-15s removed (was %s)\n", rt.Name+":", rt.From)
+			default:
+				fmt.Printf("  %-15s %s → %s\n", rt.Name+":", rt.From, rt.To)
+			}
+		}
+	}
+
+	if len(diff.Repos) > 0 {
+		fmt.Println("\nProjects:")
+		for _, r := range diff.Repos {
+			line := fmt.Sprintf("  %s %s", formatRepoPath(r.Path, false), r.Change)
+			if reasons := r.Reasons.String(); reasons != "" && r.Change != "removed" && r.Change != "became clean" {
+				line += " " + reasons
+			}
+			fmt.Println(line)
+		}
+	}
+
+	if len(diff.Ports.Added) > 0 || len(diff.Ports.Removed) > 0 {
+		fmt.Println("\nPorts:")
+		for _, p := range diff.Ports.Added {
+			fmt.Printf("  + %s\n", p)
+		}
+		for _, p := range diff.Ports.Removed {
+			fmt.Printf("  - %s\n", p)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aallbrig/allbctl/pkg/history"
+)
+
+func TestParseSince(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"36h", 36 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"7d", 7 * 24 * time.Hour, false},
+		{"1.5d", 36 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"", 0, true},
+		{"soon", 0, true},
+		{"-3d", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseSince(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseSince(%q) expected error, got %v", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSince(%q) unexpected error: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseSince(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func testHistoryRecords() (*HistoryRecord, *HistoryRecord) {
+	before := testSystemSnapshot()
+	before.CollectedAt = time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	before.OS = "ubuntu 22.04"
+	before.Runtimes = []RuntimeInfo{
+		{Name: "Go", Version: "go version go1.22.1 linux/amd64"},
+		{Name: "Ruby", Version: "3.2.0"},
+	}
+	before.Packages = []PackageResult{{Manager: "brew", Count: 2, Names: []string{"git", "wget"}}}
+	before.Ports = &PortInfo{TCPPorts: 1, Ports: []string{"tcp:22 (sshd)"}}
+	before.Projects = &ProjectsSummary{Total: 2, Repos: []RepoInfo{
+		{Path: "/src/a"},
+		{Path: "/src/b", Dirty: true, DirtyReasons: DirtyUncommittedChanges},
+	}}
+
+	after := testSystemSnapshot()
+	after.CollectedAt = before.CollectedAt.Add(7 * 24 * time.Hour)
+	after.OS = "ubuntu 24.04"
+	after.Runtimes = []RuntimeInfo{
+		{Name: "Go", Version: "go version go1.23.0 linux/amd64"},
+		{Name: "Node.js", Version: "v20.1.0"},
+	}
+	after.Packages = []PackageResult{{Manager: "brew", Count: 2, Names: []string{"git", "jq"}}}
+	after.Ports = &PortInfo{TCPPorts: 2, Ports: []string{"tcp:22 (sshd)", "tcp:8080 (node)"}}
+	after.Projects = &ProjectsSummary{Total: 2, Repos: []RepoInfo{
+		{Path: "/src/a", Dirty: true, DirtyReasons: DirtyUncommittedChanges},
+		{Path: "/src/b"},
+	}}
+
+	return newHistoryRecord(before), newHistoryRecord(after)
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	from, to := testHistoryRecords()
+	diff := diffSnapshots(from, to)
+
+	if diff.OS == nil || diff.OS.From != "ubuntu 22.04" || diff.OS.To != "ubuntu 24.04" {
+		t.Errorf("OS change = %+v, want ubuntu 22.04 → ubuntu 24.04", diff.OS)
+	}
+
+	wantPackages := []PackageChange{{Manager: "brew", CountBefore: 2, CountAfter: 2, Added: []string{"jq"}, Removed: []string{"wget"}}}
+	if !reflect.DeepEqual(diff.Packages, wantPackages) {
+		t.Errorf("Packages = %+v, want %+v", diff.Packages, wantPackages)
+	}
+
+	wantRuntimes := []VersionChange{
+		{Name: "Go", From: "1.22.1", To: "1.23.0"},
+		{Name: "Node.js", To: "20.1.0"},
+		{Name: "Ruby", From: "3.2.0"},
+	}
+	if !reflect.DeepEqual(diff.Runtimes, wantRuntimes) {
+		t.Errorf("Runtimes = %+v, want %+v", diff.Runtimes, wantRuntimes)
+	}
+
+	wantRepos := []RepoChange{
+		{Path: "/src/a", Change: "became dirty", Reasons: DirtyUncommittedChanges},
+		{Path: "/src/b", Change: "became clean"},
+	}
+	if !reflect.DeepEqual(diff.Repos, wantRepos) {
+		t.Errorf("Repos = %+v, want %+v", diff.Repos, wantRepos)
+	}
+
+	if !reflect.DeepEqual(diff.Ports.Added, []string{"tcp:8080"}) || len(diff.Ports.Removed) != 0 {
+		t.Errorf("Ports = %+v, want added [tcp:8080]", diff.Ports)
+	}
+}
+
+func TestDiffSnapshots_NoChanges(t *testing.T) {
+	from, _ := testHistoryRecords()
+	diff := diffSnapshots(from, from)
+
+	if n := diff.changeCount(); n != 0 {
+		t.Errorf("changeCount() = %d, want 0 for identical snapshots: %+v", n, diff)
+	}
+	output := captureOutput(func() { printSnapshotDiff(diff) })
+	if !strings.Contains(output, "No changes detected") {
+		t.Errorf("printSnapshotDiff() missing 'No changes detected'\noutput:\n%s", output)
+	}
+}
+
+func TestDiffSnapshots_PartialRuns(t *testing.T) {
+	from, full := testHistoryRecords()
+	full.Snapshot.Sections = []SectionOutcome{
+		{Name: "system", Status: sectionOK}, {Name: "runtimes", Status: sectionOK},
+		{Name: "ports", Status: sectionOK}, {Name: "packages", Status: sectionOK},
+		{Name: "projects", Status: sectionTimedOut},
+	}
+	// `allbctl status --sections cpu` records nothing the diff looks at
+	cpuOnly := newHistoryRecord(&SystemSnapshot{CollectedAt: full.Snapshot.CollectedAt, Sections: []SectionOutcome{{Name: "cpu", Status: sectionOK}}})
+
+	diff := diffSnapshots(from, cpuOnly)
+	if n := diff.changeCount(); n != 0 {
+		t.Errorf("diff against a --sections cpu run has %d changes, want none: %+v", n, diff)
+	}
+	if want := []string{"system", "runtimes", "ports", "packages", "projects"}; !reflect.DeepEqual(diff.NotCompared, want) {
+		t.Errorf("NotCompared = %v, want %v", diff.NotCompared, want)
+	}
+
+	diff = diffSnapshots(from, full)
+	if len(diff.Repos) != 0 || !reflect.DeepEqual(diff.NotCompared, []string{"projects"}) {
+		t.Errorf("diff against a run whose projects timed out = repos %+v, not compared %v; want projects left out", diff.Repos, diff.NotCompared)
+	}
+	if len(diff.Packages) != 1 || diff.OS == nil {
+		t.Errorf("diff should still compare the sections both runs collected: %+v", diff)
+	}
+	output := captureOutput(func() { printSnapshotDiff(diff) })
+	if !strings.Contains(output, "Not compared (not fully collected in both runs): projects") {
+		t.Errorf("printSnapshotDiff() missing the not compared note\noutput:\n%s", output)
+	}
+}
+
+func TestSelectDiffRecords(t *testing.T) {
+	store, err := history.NewStoreInDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	if _, _, err := selectDiffRecords(store, "", now); err == nil {
+		t.Error("selectDiffRecords() with empty history expected error, got nil")
+	}
+
+	// Runs 10, 5, 1 days ago and now
+	for _, daysAgo := range []int{10, 5, 1, 0} {
+		snap := testSystemSnapshot()
+		snap.CollectedAt = now.Add(-time.Duration(daysAgo) * 24 * time.Hour)
+		if _, err := store.Save(snap.CollectedAt, newHistoryRecord(snap)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		since       string
+		wantDaysAgo int
+	}{
+		{"", 1},     // previous run
+		{"3d", 5},   // newest run at least 3 days old
+		{"30d", 10}, // nothing that old, fall back to the oldest
+	}
+	for _, tt := range tests {
+		t.Run(tt.since, func(t *testing.T) {
+			from, to, err := selectDiffRecords(store, tt.since, now)
+			if err != nil {
+				t.Fatalf("selectDiffRecords() error: %v", err)
+			}
+			want := now.Add(-time.Duration(tt.wantDaysAgo) * 24 * time.Hour)
+			if !from.Snapshot.CollectedAt.Equal(want) {
+				t.Errorf("base = %v, want %v", from.Snapshot.CollectedAt, want)
+			}
+			if !to.Snapshot.CollectedAt.Equal(now) {
+				t.Errorf("target = %v, want %v", to.Snapshot.CollectedAt, now)
+			}
+		})
+	}
+}
+
+func TestHistorySummaries_Limit(t *testing.T) {
+	store, err := history.NewStoreInDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	for daysAgo := range 3 {
+		snap := testSystemSnapshot()
+		snap.CollectedAt = now.Add(-time.Duration(daysAgo) * 24 * time.Hour)
+		if _, err := store.Save(snap.CollectedAt, newHistoryRecord(snap)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	summaries, total, err := historySummaries(store, 2)
+	if err != nil {
+		t.Fatalf("historySummaries() error: %v", err)
+	}
+	if len(summaries) != 2 || total != 3 {
+		t.Fatalf("historySummaries() = %d summaries of %d runs, want 2 of 3", len(summaries), total)
+	}
+	output := captureOutput(func() { printHistorySummaries(summaries, total, store.Dir()) })
+	if !strings.Contains(output, "showing 2 of 3 runs") {
+		t.Errorf("history footer should say how many of the stored runs are shown\n%s", output)
+	}
+}
@@ -4,14 +4,16 @@ import (
 	"context"
 	"io"
 	"os"
-	"os/exec"
 	"runtime"
 	"strings"
 	"testing"
+
+	"github.com/aallbrig/allbctl/pkg/runner"
 )
 
 // TestCLICommandsExist verifies that all commands documented in README exist
 func TestCLICommandsExist(t *testing.T) {
+	ctx := context.Background()
 	tests := []struct {
 		name        string
 		args        []string
@@ -63,16 +65,14 @@ func TestCLICommandsExist(t *testing.T) {
 				if _, err := os.Stat(binary); err != nil {
 					// Build the binary
 					t.Log("Building binary for integration tests...")
-					buildCmd := exec.Command("go", "build", "-o", binaryName, "../main.go")
-					if err := buildCmd.Run(); err != nil {
+					if err := runner.Run(ctx, "go", "build", "-o", binaryName, "../main.go"); err != nil {
 						t.Skipf("Failed to build binary, skipping integration test: %v", err)
 					}
 					binary = binaryName
 				}
 			}
 
-			cmd := exec.Command(binary, tt.args...)
-			output, err := cmd.CombinedOutput()
+			output, err := runner.CombinedOutput(ctx, binary, tt.args...)
 
 			if tt.expectError && err == nil {
 				t.Errorf("%s: expected error but got none", tt.name)
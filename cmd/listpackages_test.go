@@ -1,12 +1,14 @@
 package cmd
 
 import (
+	"context"
 	"runtime"
 	"strings"
 	"testing"
 )
 
 func TestExists_KnownCommands(t *testing.T) {
+	ctx := context.Background()
 	// This test checks detection logic for known package managers
 	osType := runtime.GOOS
 	var cmds []string
@@ -18,26 +20,13 @@ func TestExists_KnownCommands(t *testing.T) {
 		cmds = []string{"choco"}
 	}
 	for _, cmd := range cmds {
-		// We can't guarantee all are installed, but exists() should not panic
-		exists(cmd)
-	}
-}
-
-func TestGetPackages_UnknownManager(t *testing.T) {
-	result := getPackages("unknown")
-	if result != "" {
-		t.Errorf("Expected empty string for unknown manager, got '%s'", result)
-	}
-}
-
-func TestRunCmd_InvalidCommand(t *testing.T) {
-	output := runCmd("nonexistentcommand123")
-	if output == "" {
-		t.Error("Expected error output for invalid command")
+		// We can't guarantee all are installed, but exists(ctx, ) should not panic
+		exists(ctx, cmd)
 	}
 }
 
 func TestExists_AllSupportedCommands(t *testing.T) {
+	ctx := context.Background()
 	osType := runtime.GOOS
 	var cmds []string
 	if osType == "linux" {
@@ -51,210 +40,23 @@ func TestExists_AllSupportedCommands(t *testing.T) {
 	cmds = append(cmds, "npm", "pip", "pip3", "gem", "cargo", "go", "pipx")
 
 	for _, cmd := range cmds {
-		exists(cmd) // Should not panic
-	}
-}
-
-func TestGetPackages_AllSupportedManagers(t *testing.T) {
-	managers := []string{"apt", "snap", "flatpak", "dnf", "yum", "pacman", "brew", "choco", "winget", "scoop", "npm", "pip", "gem", "cargo", "go", "pipx", "ollama", "vagrant", "vboxmanage"}
-	for _, m := range managers {
-		_ = getPackages(m) // Should not panic or error
-	}
-}
-
-func TestCountPackages_Apt(t *testing.T) {
-	output := "package1\npackage2\npackage3\n"
-	count := countPackages("apt", output)
-	if count != 3 {
-		t.Errorf("Expected 3 packages for apt, got %d", count)
-	}
-}
-
-func TestCountPackages_Npm(t *testing.T) {
-	output := "/home/user/.nvm/versions/node/v20.0.0/lib\n├── package1@1.0.0\n├── package2@2.0.0\n└── package3@3.0.0\n"
-	count := countPackages("npm", output)
-	if count != 3 {
-		t.Errorf("Expected 3 packages for npm, got %d", count)
-	}
-}
-
-func TestCountPackages_Pip(t *testing.T) {
-	output := "Package    Version\n---------- -------\npkg1       1.0.0\npkg2       2.0.0\n"
-	count := countPackages("pip", output)
-	if count != 2 {
-		t.Errorf("Expected 2 packages for pip, got %d", count)
-	}
-}
-
-func TestCountPackages_Error(t *testing.T) {
-	output := "Error running command: exit status 1"
-	count := countPackages("apt", output)
-	if count != 0 {
-		t.Errorf("Expected 0 packages for error output, got %d", count)
-	}
-}
-
-func TestCountPackages_EmptyOutput(t *testing.T) {
-	output := ""
-	count := countPackages("apt", output)
-	if count != 0 {
-		t.Errorf("Expected 0 packages for empty output, got %d", count)
-	}
-}
-
-func TestCountPackages_Pipx(t *testing.T) {
-	output := "   package    pkg1\n   package    pkg2\n"
-	count := countPackages("pipx", output)
-	if count != 2 {
-		t.Errorf("Expected 2 packages for pipx, got %d", count)
-	}
-}
-
-func TestCountPackages_Ollama(t *testing.T) {
-	output := "NAME                      ID              SIZE      MODIFIED\nllama3.2:latest          a80c4f17acd5    2.0 GB    3 days ago\ncodellama:latest         8fdf8f752f6e    3.8 GB    2 weeks ago\nqwen2.5-coder:latest     6c701bcd39d9    4.7 GB    1 week ago\n"
-	count := countPackages("ollama", output)
-	if count != 3 {
-		t.Errorf("Expected 3 models for ollama, got %d", count)
-	}
-}
-
-func TestCountPackages_Ollama_EmptyOutput(t *testing.T) {
-	output := "NAME    ID    SIZE    MODIFIED\n"
-	count := countPackages("ollama", output)
-	if count != 0 {
-		t.Errorf("Expected 0 models for empty ollama output, got %d", count)
-	}
-}
-
-func TestGetPackages_Ollama(t *testing.T) {
-	_ = getPackages("ollama") // Should not panic
-}
-
-func TestCountPackages_Vagrant(t *testing.T) {
-	output := "gusztavvargadr/windows-10 (virtualbox, 2511.0.0, (amd64))\nubuntu/focal64            (virtualbox, 20240821.0.0)\n"
-	count := countPackages("vagrant", output)
-	if count != 2 {
-		t.Errorf("Expected 2 VMs for vagrant, got %d", count)
-	}
-}
-
-func TestCountPackages_Vagrant_EmptyOutput(t *testing.T) {
-	output := ""
-	count := countPackages("vagrant", output)
-	if count != 0 {
-		t.Errorf("Expected 0 VMs for empty vagrant output, got %d", count)
-	}
-}
-
-func TestGetPackages_Vagrant(t *testing.T) {
-	_ = getPackages("vagrant") // Should not panic
-}
-
-func TestGetQueryCommand_Vagrant(t *testing.T) {
-	cmd := getQueryCommand("vagrant")
-	expected := "vagrant box list"
-	if cmd != expected {
-		t.Errorf("Expected query command '%s', got '%s'", expected, cmd)
-	}
-}
-
-func TestCountPackages_VBoxManage(t *testing.T) {
-	output := `"VM1" {12345678-1234-1234-1234-123456789012}
-"VM2" {87654321-4321-4321-4321-210987654321}`
-	count := countPackages("vboxmanage", output)
-	if count != 2 {
-		t.Errorf("Expected 2 VMs for vboxmanage, got %d", count)
-	}
-}
-
-func TestCountPackages_VBoxManage_EmptyOutput(t *testing.T) {
-	output := ""
-	count := countPackages("vboxmanage", output)
-	if count != 0 {
-		t.Errorf("Expected 0 VMs for empty vboxmanage output, got %d", count)
-	}
-}
-
-func TestGetPackages_VBoxManage(t *testing.T) {
-	_ = getPackages("vboxmanage") // Should not panic
-}
-
-func TestGetQueryCommand_VBoxManage(t *testing.T) {
-	cmd := getQueryCommand("vboxmanage")
-	expected := "VBoxManage list vms"
-	if cmd != expected {
-		t.Errorf("Expected query command '%s', got '%s'", expected, cmd)
-	}
-}
-
-func TestGetCommandForManager_VBoxManage(t *testing.T) {
-	cmd := getCommandForManager("vboxmanage")
-	expected := "VBoxManage"
-	if cmd != expected {
-		t.Errorf("Expected command '%s', got '%s'", expected, cmd)
+		exists(ctx, cmd) // Should not panic
 	}
 }
 
-func TestGetRecentPackages_Apt(t *testing.T) {
-	result := getRecentPackages("apt")
-	_ = result
-}
-
-func TestGetRecentPackages_Dpkg(t *testing.T) {
-	result := getRecentPackages("dpkg")
-	_ = result
-}
-
-func TestGetRecentPackages_Npm(t *testing.T) {
-	result := getRecentPackages("npm")
-	_ = result
-}
-
-func TestGetRecentPackages_Pip(t *testing.T) {
-	result := getRecentPackages("pip")
-	_ = result
-}
-
-func TestGetRecentPackages_UnknownManager(t *testing.T) {
-	result := getRecentPackages("unknown")
-	if result != "" {
-		t.Errorf("Expected empty string for unknown manager, got '%s'", result)
-	}
-}
-
-func TestGetRecentPackagesCommand_Apt(t *testing.T) {
-	cmd := getRecentPackagesCommand("apt")
-	if cmd == "" {
-		t.Error("Expected non-empty command for apt")
-	}
-	if !strings.Contains(cmd, "dpkg.log") {
-		t.Errorf("Expected apt command to contain 'dpkg.log', got '%s'", cmd)
-	}
-}
-
-func TestGetRecentPackagesCommand_Npm(t *testing.T) {
-	cmd := getRecentPackagesCommand("npm")
-	if cmd == "" {
-		t.Error("Expected non-empty command for npm")
-	}
-	if !strings.Contains(cmd, "npm root") {
-		t.Errorf("Expected npm command to contain 'npm root', got '%s'", cmd)
-	}
-}
-
-func TestGetRecentPackagesCommand_Pip(t *testing.T) {
-	cmd := getRecentPackagesCommand("pip")
-	if cmd == "" {
-		t.Error("Expected non-empty command for pip")
-	}
-	if !strings.Contains(cmd, "pkg_resources") {
-		t.Errorf("Expected pip command to contain 'pkg_resources', got '%s'", cmd)
+func TestPackageListing_UnknownManager(t *testing.T) {
+	ctx := context.Background()
+	if _, err := packageListing(ctx, "unknown", false); err == nil || !strings.Contains(err.Error(), "unknown package manager") {
+		t.Errorf("packageListing(ctx, \"unknown\") error = %v, want unknown package manager", err)
 	}
 }
 
-func TestGetRecentPackagesCommand_UnknownManager(t *testing.T) {
-	cmd := getRecentPackagesCommand("unknown")
-	if cmd != "" {
-		t.Errorf("Expected empty string for unknown manager, got '%s'", cmd)
+func TestGetDetectedPackageManagers(t *testing.T) {
+	ctx := context.Background()
+	for _, m := range getDetectedPackageManagers(ctx) {
+		pm, err := detectedPackageManager(ctx, m)
+		if err != nil || pm.Name() != m {
+			t.Errorf("detectedPackageManager(ctx, %q) = %v, %v", m, pm, err)
+		}
 	}
 }
@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"net"
-	"os/exec"
+	"os"
 	"regexp"
 	"runtime"
 	"strings"
@@ -11,6 +13,8 @@ import (
 
 	psnet "github.com/shirou/gopsutil/v4/net"
 	"github.com/spf13/cobra"
+
+	"github.com/aallbrig/allbctl/pkg/runner"
 )
 
 var NetworkCmd = &cobra.Command{
@@ -18,122 +22,131 @@ var NetworkCmd = &cobra.Command{
 	Short: "Display network interface information",
 	Long: `Display network interface information including IP addresses, router, connection type, VPN status, DNS, and connectivity.
 
-This is the same output shown in the 'Network:' section of 'allbctl status'.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		PrintNetworkInfo()
+This is the same output shown in the 'Network:' section of 'allbctl status'.
+Use --watch to keep the view open; changes such as a VPN connecting are highlighted.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if watchMode {
+			return watchCommand(cmd, watchFrame(func(ctx context.Context, w io.Writer) { printNetworkDetails(w, gatherNetworkDetails(ctx)) }))
+		}
+		details := gatherNetworkDetails(ctx)
+		return renderOutput(details, func() { printNetworkDetails(os.Stdout, details) })
 	},
 }
 
 // NetworkDetails holds comprehensive network information
 type NetworkDetails struct {
-	Interfaces     []InterfaceInfo
-	VPNActive      bool
-	VPNInterface   *InterfaceInfo
-	PrimaryIface   *InterfaceInfo
-	DefaultGateway string
-	DNSServers     []string
-	VPNDNSServers  []string
-	PublicIP       string
-	InternetOK     bool
-	WiFiDetails    *WiFiInfo
+	Interfaces     []InterfaceInfo `json:"interfaces"`
+	VPNActive      bool            `json:"vpn_active"`
+	VPNInterface   *InterfaceInfo  `json:"vpn_interface,omitempty"`
+	PrimaryIface   *InterfaceInfo  `json:"primary_iface,omitempty"`
+	DefaultGateway string          `json:"default_gateway,omitempty"`
+	DNSServers     []string        `json:"dns_servers,omitempty"`
+	VPNDNSServers  []string        `json:"vpn_dns_servers,omitempty"`
+	PublicIP       string          `json:"public_ip,omitempty"`
+	InternetOK     bool            `json:"internet_ok"`
+	WiFiDetails    *WiFiInfo       `json:"wifi_details,omitempty"`
 }
 
 // InterfaceInfo holds interface details
 type InterfaceInfo struct {
-	Name    string
-	IP      string
-	Status  string
-	IsVPN   bool
-	Gateway string
+	Name    string `json:"name"`
+	IP      string `json:"ip"`
+	Status  string `json:"status"`
+	IsVPN   bool   `json:"is_vpn"`
+	Gateway string `json:"gateway,omitempty"`
 }
 
 // WiFiInfo holds WiFi-specific details
 type WiFiInfo struct {
-	SSID      string
-	Frequency string
-	Standard  string
-	Signal    string
-	Quality   string
-	Speed     string
+	SSID      string `json:"ssid"`
+	Frequency string `json:"frequency,omitempty"`
+	Standard  string `json:"standard,omitempty"`
+	Signal    string `json:"signal,omitempty"`
+	Quality   string `json:"quality,omitempty"`
+	Speed     string `json:"speed,omitempty"`
 }
 
 // PrintNetworkInfo outputs comprehensive network information
-func PrintNetworkInfo() {
-	details := gatherNetworkDetails()
+func PrintNetworkInfo(ctx context.Context) {
+	printNetworkDetails(os.Stdout, gatherNetworkDetails(ctx))
+}
 
+// printNetworkDetails renders already gathered network details
+func printNetworkDetails(w io.Writer, details *NetworkDetails) {
 	// Primary Interface
 	if details.PrimaryIface != nil {
-		fmt.Printf("Network:\n")
-		fmt.Printf("  Primary Interface: %s (%s)\n", details.PrimaryIface.Name, details.PrimaryIface.IP)
+		fmt.Fprintf(w, "Network:\n")
+		fmt.Fprintf(w, "  Primary Interface: %s (%s)\n", details.PrimaryIface.Name, details.PrimaryIface.IP)
 
 		// WiFi details if available
 		if details.WiFiDetails != nil {
 			if details.WiFiDetails.SSID != "" {
-				fmt.Printf("    WiFi: %s", details.WiFiDetails.SSID)
+				fmt.Fprintf(w, "    WiFi: %s", details.WiFiDetails.SSID)
 				if details.WiFiDetails.Frequency != "" {
-					fmt.Printf(" @ %s", details.WiFiDetails.Frequency)
+					fmt.Fprintf(w, " @ %s", details.WiFiDetails.Frequency)
 				}
 				if details.WiFiDetails.Standard != "" {
-					fmt.Printf(" (%s)", details.WiFiDetails.Standard)
+					fmt.Fprintf(w, " (%s)", details.WiFiDetails.Standard)
 				}
-				fmt.Println()
+				fmt.Fprintln(w)
 			}
 
 			if details.WiFiDetails.Speed != "" || details.WiFiDetails.Signal != "" {
-				fmt.Print("    ")
+				fmt.Fprint(w, "    ")
 				if details.WiFiDetails.Speed != "" {
-					fmt.Printf("Speed: %s", details.WiFiDetails.Speed)
+					fmt.Fprintf(w, "Speed: %s", details.WiFiDetails.Speed)
 				}
 				if details.WiFiDetails.Signal != "" {
 					if details.WiFiDetails.Speed != "" {
-						fmt.Print(" | ")
+						fmt.Fprint(w, " | ")
 					}
-					fmt.Printf("Signal: %s", details.WiFiDetails.Signal)
+					fmt.Fprintf(w, "Signal: %s", details.WiFiDetails.Signal)
 					if details.WiFiDetails.Quality != "" {
-						fmt.Printf(" (%s)", details.WiFiDetails.Quality)
+						fmt.Fprintf(w, " (%s)", details.WiFiDetails.Quality)
 					}
 				}
-				fmt.Println()
+				fmt.Fprintln(w)
 			}
 		}
 
 		if details.PrimaryIface.Gateway != "" {
-			fmt.Printf("    Gateway: %s\n", details.PrimaryIface.Gateway)
+			fmt.Fprintf(w, "    Gateway: %s\n", details.PrimaryIface.Gateway)
 		}
-		fmt.Println()
+		fmt.Fprintln(w)
 	}
 
 	// VPN Status
 	if details.VPNActive && details.VPNInterface != nil {
-		fmt.Printf("  VPN Active: ✓ %s (%s)\n", details.VPNInterface.Name, details.VPNInterface.IP)
+		fmt.Fprintf(w, "  VPN Active: ✓ %s (%s)\n", details.VPNInterface.Name, details.VPNInterface.IP)
 		if details.VPNInterface.Gateway != "" {
-			fmt.Printf("    Gateway: %s\n", details.VPNInterface.Gateway)
+			fmt.Fprintf(w, "    Gateway: %s\n", details.VPNInterface.Gateway)
 		}
-		fmt.Printf("    Status: Traffic routed via VPN\n")
-		fmt.Println()
+		fmt.Fprintf(w, "    Status: Traffic routed via VPN\n")
+		fmt.Fprintln(w)
 	}
 
 	// DNS
 	if len(details.DNSServers) > 0 || len(details.VPNDNSServers) > 0 {
-		fmt.Printf("  DNS:\n")
+		fmt.Fprintf(w, "  DNS:\n")
 		if len(details.DNSServers) > 0 {
-			fmt.Printf("    System: %s\n", strings.Join(details.DNSServers, ", "))
+			fmt.Fprintf(w, "    System: %s\n", strings.Join(details.DNSServers, ", "))
 		}
 		if len(details.VPNDNSServers) > 0 {
-			fmt.Printf("    VPN: %s\n", strings.Join(details.VPNDNSServers, ", "))
+			fmt.Fprintf(w, "    VPN: %s\n", strings.Join(details.VPNDNSServers, ", "))
 		}
-		fmt.Println()
+		fmt.Fprintln(w)
 	}
 
 	// Connectivity
-	fmt.Printf("  Connectivity:\n")
+	fmt.Fprintf(w, "  Connectivity:\n")
 	if details.PublicIP != "" {
-		fmt.Printf("    Public IP: %s\n", details.PublicIP)
+		fmt.Fprintf(w, "    Public IP: %s\n", details.PublicIP)
 	}
 	if details.InternetOK {
-		fmt.Printf("    Internet: ✓ Connected\n")
+		fmt.Fprintf(w, "    Internet: ✓ Connected\n")
 	} else {
-		fmt.Printf("    Internet: ✗ No connection\n")
+		fmt.Fprintf(w, "    Internet: ✗ No connection\n")
 	}
 
 	// Other interfaces
@@ -149,20 +162,20 @@ func PrintNetworkInfo() {
 	}
 
 	if len(otherIfaces) > 0 {
-		fmt.Println()
-		fmt.Printf("  Other Interfaces:\n")
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "  Other Interfaces:\n")
 		for _, iface := range otherIfaces {
 			if iface.Status == "DOWN" {
-				fmt.Printf("    %s: %s\n", iface.Name, iface.Status)
+				fmt.Fprintf(w, "    %s: %s\n", iface.Name, iface.Status)
 			} else {
-				fmt.Printf("    %s: %s\n", iface.Name, iface.IP)
+				fmt.Fprintf(w, "    %s: %s\n", iface.Name, iface.IP)
 			}
 		}
 	}
 }
 
 // gatherNetworkDetails collects comprehensive network information
-func gatherNetworkDetails() *NetworkDetails {
+func gatherNetworkDetails(ctx context.Context) *NetworkDetails {
 	details := &NetworkDetails{
 		Interfaces: []InterfaceInfo{},
 	}
@@ -198,32 +211,32 @@ func gatherNetworkDetails() *NetworkDetails {
 	}
 
 	// Detect primary and VPN interfaces
-	detectPrimaryInterface(details)
+	detectPrimaryInterface(ctx, details)
 	detectVPNInterface(details)
 
 	// Get gateway information
-	details.DefaultGateway = getRouterIP()
+	details.DefaultGateway = getRouterIP(ctx)
 	if details.PrimaryIface != nil {
 		details.PrimaryIface.Gateway = details.DefaultGateway
 	}
 	if details.VPNInterface != nil {
-		details.VPNInterface.Gateway = getVPNGateway(details.VPNInterface.Name)
+		details.VPNInterface.Gateway = getVPNGateway(ctx, details.VPNInterface.Name)
 	}
 
 	// Get WiFi details for primary interface
 	if details.PrimaryIface != nil {
-		details.WiFiDetails = getWiFiDetails(details.PrimaryIface.Name)
+		details.WiFiDetails = getWiFiDetails(ctx, details.PrimaryIface.Name)
 	}
 
 	// Get DNS servers
-	details.DNSServers, details.VPNDNSServers = getDNSServers()
+	details.DNSServers, details.VPNDNSServers = getDNSServers(ctx)
 
 	// Check internet connectivity
-	details.InternetOK = checkInternetConnectivity()
+	details.InternetOK = checkInternetConnectivity(ctx)
 
 	// Get public IP
 	if details.InternetOK {
-		details.PublicIP = getPublicIP()
+		details.PublicIP = getPublicIP(ctx)
 	}
 
 	return details
@@ -241,7 +254,7 @@ func isVPNInterface(name string) bool {
 }
 
 // detectPrimaryInterface finds the primary network interface
-func detectPrimaryInterface(details *NetworkDetails) {
+func detectPrimaryInterface(ctx context.Context, details *NetworkDetails) {
 	if runtime.GOOS != "linux" {
 		// For non-Linux, just pick first non-loopback, non-VPN interface
 		for i := range details.Interfaces {
@@ -255,8 +268,7 @@ func detectPrimaryInterface(details *NetworkDetails) {
 	}
 
 	// On Linux, use ip route to find default interface
-	cmd := exec.Command("sh", "-c", "ip route | grep default | awk '{print $5}' | head -n1")
-	out, err := cmd.Output()
+	out, err := runner.Output(ctx, "sh", "-c", "ip route | grep default | awk '{print $5}' | head -n1")
 	if err == nil && len(out) > 0 {
 		primaryName := strings.TrimSpace(string(out))
 		for i := range details.Interfaces {
@@ -281,13 +293,12 @@ func detectVPNInterface(details *NetworkDetails) {
 }
 
 // getVPNGateway gets the gateway for a VPN interface
-func getVPNGateway(ifaceName string) string {
+func getVPNGateway(ctx context.Context, ifaceName string) string {
 	if runtime.GOOS != "linux" {
 		return ""
 	}
 
-	cmd := exec.Command("sh", "-c", fmt.Sprintf("ip route | grep %s | grep -v default | awk '{print $1}' | head -n1", ifaceName))
-	out, err := cmd.Output()
+	out, err := runner.Output(ctx, "sh", "-c", fmt.Sprintf("ip route | grep %s | grep -v default | awk '{print $1}' | head -n1", ifaceName))
 	if err == nil && len(out) > 0 {
 		return strings.TrimSpace(string(out))
 	}
@@ -296,7 +307,7 @@ func getVPNGateway(ifaceName string) string {
 }
 
 // getWiFiDetails gets WiFi-specific information
-func getWiFiDetails(ifaceName string) *WiFiInfo {
+func getWiFiDetails(ctx context.Context, ifaceName string) *WiFiInfo {
 	if runtime.GOOS != "linux" {
 		return nil
 	}
@@ -304,8 +315,7 @@ func getWiFiDetails(ifaceName string) *WiFiInfo {
 	info := &WiFiInfo{}
 
 	// Get WiFi info using iwconfig
-	cmd := exec.Command("iwconfig", ifaceName)
-	out, err := cmd.Output()
+	out, err := runner.Output(ctx, "iwconfig", ifaceName)
 	if err != nil {
 		return nil
 	}
@@ -351,8 +361,7 @@ func getWiFiDetails(ifaceName string) *WiFiInfo {
 	}
 
 	// Get link speed using iw
-	cmd = exec.Command("iw", "dev", ifaceName, "link")
-	out, err = cmd.Output()
+	out, err = runner.Output(ctx, "iw", "dev", ifaceName, "link")
 	if err == nil {
 		lines := strings.Split(string(out), "\n")
 		for _, line := range lines {
@@ -384,14 +393,13 @@ func getWiFiDetails(ifaceName string) *WiFiInfo {
 }
 
 // getDNSServers gets system and VPN DNS servers
-func getDNSServers() ([]string, []string) {
+func getDNSServers(ctx context.Context) ([]string, []string) {
 	systemDNS := []string{}
 	vpnDNS := []string{}
 
 	if runtime.GOOS == "linux" {
 		// Try resolvectl first (systemd-resolved)
-		cmd := exec.Command("resolvectl", "status")
-		out, err := cmd.Output()
+		out, err := runner.Output(ctx, "resolvectl", "status")
 		if err == nil {
 			lines := strings.Split(string(out), "\n")
 			inVPNSection := false
@@ -424,8 +432,7 @@ func getDNSServers() ([]string, []string) {
 			}
 		} else {
 			// Fallback to /etc/resolv.conf
-			cmd = exec.Command("cat", "/etc/resolv.conf")
-			out, err = cmd.Output()
+			out, err = runner.Output(ctx, "cat", "/etc/resolv.conf")
 			if err == nil {
 				lines := strings.Split(string(out), "\n")
 				for _, line := range lines {
@@ -439,8 +446,7 @@ func getDNSServers() ([]string, []string) {
 			}
 		}
 	} else if runtime.GOOS == "darwin" {
-		cmd := exec.Command("scutil", "--dns")
-		out, err := cmd.Output()
+		out, err := runner.Output(ctx, "scutil", "--dns")
 		if err == nil {
 			lines := strings.Split(string(out), "\n")
 			for _, line := range lines {
@@ -454,8 +460,7 @@ func getDNSServers() ([]string, []string) {
 			}
 		}
 	} else if runtime.GOOS == "windows" {
-		cmd := exec.Command("ipconfig", "/all")
-		out, err := cmd.Output()
+		out, err := runner.Output(ctx, "ipconfig", "/all")
 		if err == nil {
 			lines := strings.Split(string(out), "\n")
 			for _, line := range lines {
@@ -478,9 +483,11 @@ func getDNSServers() ([]string, []string) {
 }
 
 // checkInternetConnectivity checks if internet is accessible by dialing
-// Google's DNS over TCP. Pure Go — no subprocess, no OS-specific flags.
-func checkInternetConnectivity() bool {
-	conn, err := net.DialTimeout("tcp", "8.8.8.8:53", 2*time.Second)
+// Google's DNS over TCP. Pure Go — no subprocess, no OS-specific flags. The
+// dial gives up when ctx is done.
+func checkInternetConnectivity(ctx context.Context) bool {
+	dialer := net.Dialer{Timeout: 2 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", "8.8.8.8:53")
 	if err != nil {
 		return false
 	}
@@ -489,9 +496,8 @@ func checkInternetConnectivity() bool {
 }
 
 // getPublicIP gets the public IP address
-func getPublicIP() string {
-	cmd := exec.Command("curl", "-s", "--max-time", "3", "ifconfig.me")
-	out, err := cmd.Output()
+func getPublicIP(ctx context.Context) string {
+	out, err := runner.Output(ctx, "curl", "-s", "--max-time", "3", "ifconfig.me")
 	if err == nil && len(out) > 0 {
 		ip := strings.TrimSpace(string(out))
 		// Validate it looks like an IP
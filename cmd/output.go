@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats accepted by the --output flag
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputYAML = "yaml"
+)
+
+var outputFormats = []string{outputText, outputJSON, outputYAML}
+
+// outputFormatValue is a pflag.Value that rejects unknown formats at parse time,
+// so a typo fails fast instead of after every collector has already run.
+type outputFormatValue string
+
+func (o *outputFormatValue) String() string {
+	return string(*o)
+}
+
+func (o *outputFormatValue) Set(value string) error {
+	value = strings.ToLower(strings.TrimSpace(value))
+	for _, f := range outputFormats {
+		if value == f {
+			*o = outputFormatValue(value)
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(outputFormats, "|"))
+}
+
+func (o *outputFormatValue) Type() string {
+	return "format"
+}
+
+// outputFormat is shared by status and all of its subcommands
+var outputFormat = outputFormatValue(outputText)
+
+// isStructuredOutput reports whether --output asks for machine-readable output
+func isStructuredOutput() bool {
+	return outputFormat == outputJSON || outputFormat == outputYAML
+}
+
+// renderOutput writes data as JSON or YAML when --output asks for it, otherwise
+// it calls text to print the human-readable view.
+func renderOutput(data interface{}, text func()) error {
+	if !isStructuredOutput() {
+		text()
+		return nil
+	}
+	return printStructured(data)
+}
+
+// printStructured writes data to stdout in the format selected by --output
+func printStructured(data interface{}) error {
+	return writeStructured(os.Stdout, string(outputFormat), data)
+}
+
+// writeStructured encodes v to w as JSON or YAML. YAML is produced from the JSON
+// encoding so both formats share the same field names (the json struct tags).
+func writeStructured(w io.Writer, format string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding %s output: %w", format, err)
+	}
+
+	switch format {
+	case outputJSON:
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case outputYAML:
+		// JSON is valid YAML; decoding into a node keeps the key order, and
+		// clearing the flow style turns it into block-style YAML.
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return fmt.Errorf("encoding yaml output: %w", err)
+		}
+		clearYAMLStyle(&node)
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(&node); err != nil {
+			return fmt.Errorf("encoding yaml output: %w", err)
+		}
+		if err := enc.Close(); err != nil {
+			return fmt.Errorf("encoding yaml output: %w", err)
+		}
+		_, err = w.Write(buf.Bytes())
+		return err
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+}
+
+// clearYAMLStyle resets the flow/quoting style picked up from the JSON source.
+// Strings that would otherwise be read back as another type stay quoted.
+func clearYAMLStyle(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" {
+		node.Style = yaml.DoubleQuotedStyle
+		if isPlainYAMLString(node.Value) {
+			node.Style = 0
+		}
+	} else {
+		node.Style = 0
+	}
+	for _, child := range node.Content {
+		clearYAMLStyle(child)
+	}
+}
+
+// isPlainYAMLString reports whether s round-trips as a string when left unquoted
+func isPlainYAMLString(s string) bool {
+	if s == "" || strings.ContainsAny(s, "\n") {
+		return false
+	}
+	var v interface{}
+	if err := yaml.Unmarshal([]byte(s), &v); err != nil {
+		return false
+	}
+	str, ok := v.(string)
+	return ok && str == s
+}
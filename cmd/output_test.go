@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestOutputFormatValue_Set(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"text", outputText, false},
+		{"json", outputJSON, false},
+		{"YAML", outputYAML, false},
+		{" json ", outputJSON, false},
+		{"xml", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			v := outputFormatValue(outputText)
+			err := v.Set(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Set(%q) expected error, got nil", tt.input)
+				}
+				if v.String() != outputText {
+					t.Errorf("Set(%q) changed value to %q on error", tt.input, v.String())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Set(%q) unexpected error: %v", tt.input, err)
+			}
+			if v.String() != tt.want {
+				t.Errorf("Set(%q) = %q, want %q", tt.input, v.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteStructured_JSON(t *testing.T) {
+	info := &PortInfo{TCPPorts: 2, UDPPorts: 1, Ports: []string{"tcp:22", "udp:53"}}
+
+	var buf bytes.Buffer
+	if err := writeStructured(&buf, outputJSON, info); err != nil {
+		t.Fatalf("writeStructured() error: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("output is not valid JSON: %v\noutput:\n%s", err, buf.String())
+	}
+	if decoded["tcp_ports"] != float64(2) {
+		t.Errorf("tcp_ports = %v, want 2", decoded["tcp_ports"])
+	}
+}
+
+func TestWriteStructured_YAML(t *testing.T) {
+	info := &GitConfigInfo{UserName: "Ada", UserEmail: "", CoreEditor: "yes"}
+
+	var buf bytes.Buffer
+	if err := writeStructured(&buf, outputYAML, info); err != nil {
+		t.Fatalf("writeStructured() error: %v", err)
+	}
+	output := buf.String()
+
+	// Block style, json tag names, original field order
+	if strings.Contains(output, "{") {
+		t.Errorf("expected block-style YAML, got flow style:\n%s", output)
+	}
+	nameIdx := strings.Index(output, "user_name:")
+	editorIdx := strings.Index(output, "core_editor:")
+	if nameIdx == -1 || editorIdx == -1 || nameIdx > editorIdx {
+		t.Errorf("expected user_name before core_editor:\n%s", output)
+	}
+
+	// Strings that look like other YAML types must survive a round trip
+	var decoded map[string]interface{}
+	if err := yaml.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("output is not valid YAML: %v", err)
+	}
+	if decoded["core_editor"] != "yes" {
+		t.Errorf("core_editor = %#v, want string \"yes\"", decoded["core_editor"])
+	}
+	if decoded["user_email"] != "" {
+		t.Errorf("user_email = %#v, want empty string", decoded["user_email"])
+	}
+}
+
+func TestWriteStructured_UnknownFormat(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeStructured(&buf, "xml", struct{}{}); err == nil {
+		t.Error("writeStructured() with unknown format expected error, got nil")
+	}
+}
+
+func TestRenderOutput_TextCallsRenderer(t *testing.T) {
+	old := outputFormat
+	defer func() { outputFormat = old }()
+	outputFormat = outputText
+
+	called := false
+	if err := renderOutput(&PortInfo{}, func() { called = true }); err != nil {
+		t.Fatalf("renderOutput() error: %v", err)
+	}
+	if !called {
+		t.Error("renderOutput() in text mode did not call the text renderer")
+	}
+}
+
+func TestRenderOutput_JSONSkipsRenderer(t *testing.T) {
+	old := outputFormat
+	defer func() { outputFormat = old }()
+	outputFormat = outputJSON
+
+	called := false
+	output := captureOutput(func() {
+		if err := renderOutput(&PortInfo{TCPPorts: 3}, func() { called = true }); err != nil {
+			t.Errorf("renderOutput() error: %v", err)
+		}
+	})
+	if called {
+		t.Error("renderOutput() in json mode called the text renderer")
+	}
+	if !strings.Contains(output, `"tcp_ports": 3`) {
+		t.Errorf("renderOutput() json output missing tcp_ports\noutput:\n%s", output)
+	}
+}
+
+// TestStatusSubcommandsInheritOutputFlag verifies --output is available on every status subcommand.
+func TestStatusSubcommandsInheritOutputFlag(t *testing.T) {
+	for _, c := range StatusCmd.Commands() {
+		if c.Flag("output") == nil {
+			t.Errorf("status %s does not accept --output", c.Name())
+		}
+		for _, sub := range c.Commands() {
+			if sub.Flag("output") == nil {
+				t.Errorf("status %s %s does not accept --output", c.Name(), sub.Name())
+			}
+		}
+	}
+}
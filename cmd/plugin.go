@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/aallbrig/allbctl/pkg/plugin"
+	"github.com/aallbrig/allbctl/pkg/runner"
+	"github.com/aallbrig/allbctl/pkg/telemetry"
+)
+
+// pluginPathAnnotation marks commands that run a plugin and records its path
+const pluginPathAnnotation = "allbctl/plugin-path"
+
+// PluginCmd groups the plugin subcommands
+var PluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "Work with allbctl plugins",
+	Long: `Any executable named allbctl-<name> on PATH runs as 'allbctl <name>'.
+
+Plugins receive their arguments unchanged, plus these environment variables:
+  ALLBCTL_CONFIG   config file allbctl resolved (empty when none was found)
+  ALLBCTL_DEBUG    "true" when --debug was given
+  TRACEPARENT      W3C trace context of the allbctl span running the plugin,
+  TRACESTATE       so the plugin's OpenTelemetry spans join the same trace
+
+Built-in commands always win over plugins with the same name.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help() //nolint:errcheck // Help errors are not critical
+	},
+}
+
+var pluginListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List plugins found on PATH",
+	Long: `List the allbctl-<name> executables found on PATH.
+
+A plugin that cannot be run is listed with the reason: a built-in command of the
+same name, or an earlier PATH entry providing the same plugin.
+
+Examples:
+  allbctl plugin list
+  allbctl plugin list -o json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		plugins := discoverPlugins(rootCmd)
+		return renderOutput(plugins, func() { printPlugins(plugins) })
+	},
+}
+
+func init() {
+	PluginCmd.AddCommand(pluginListCmd)
+	pluginListCmd.Flags().VarP(&outputFormat, "output", "o", "Output format: text, json or yaml")
+}
+
+// pluginExitError carries a plugin's non-zero exit code back to Execute
+type pluginExitError struct {
+	name string
+	code int
+}
+
+func (e *pluginExitError) Error() string {
+	return fmt.Sprintf("plugin %s exited with status %d", e.name, e.code)
+}
+
+// builtinCommandNames returns the names and aliases that plugins cannot take
+func builtinCommandNames(root *cobra.Command) map[string]bool {
+	names := map[string]bool{"help": true, "completion": true}
+	for _, c := range root.Commands() {
+		if _, isPlugin := c.Annotations[pluginPathAnnotation]; isPlugin {
+			continue
+		}
+		names[c.Name()] = true
+		for _, alias := range c.Aliases {
+			names[alias] = true
+		}
+	}
+	return names
+}
+
+// discoverPlugins lists the plugins on PATH, including those that cannot run
+func discoverPlugins(root *cobra.Command) []plugin.Plugin {
+	plugins := plugin.Discover(os.Getenv("PATH"), builtinCommandNames(root))
+	if plugins == nil {
+		plugins = []plugin.Plugin{}
+	}
+	return plugins
+}
+
+// registerPlugins adds a command to root for every usable plugin on PATH
+func registerPlugins(root *cobra.Command) {
+	for _, p := range discoverPlugins(root) {
+		if p.Usable() {
+			root.AddCommand(newPluginCommand(p))
+		}
+	}
+}
+
+func newPluginCommand(p plugin.Plugin) *cobra.Command {
+	return &cobra.Command{
+		Use:         p.Name,
+		Short:       fmt.Sprintf("Plugin (%s)", p.Path),
+		Annotations: map[string]string{pluginPathAnnotation: p.Path},
+		// Every argument, including --help, belongs to the plugin
+		DisableFlagParsing: true,
+		SilenceUsage:       true,
+		SilenceErrors:      true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPlugin(cmd.Context(), p, args)
+		},
+	}
+}
+
+// runPlugin runs the plugin in a child span of the command's root span and
+// hands it the config path, debug flag and trace context
+func runPlugin(ctx context.Context, p plugin.Plugin, args []string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := otel.Tracer("github.com/aallbrig/allbctl").Start(ctx, "plugin "+p.Name,
+		trace.WithAttributes(
+			attribute.String("plugin.name", p.Name),
+			attribute.String("plugin.path", p.Path),
+		),
+	)
+	defer span.End()
+
+	telemetry.Logger.InfoContext(ctx, "plugin.run", "plugin", p.Name, "path", p.Path, "args", args)
+
+	err := runner.FromContext(ctx).Run(ctx, runner.Cmd{
+		Name:   p.Path,
+		Args:   args,
+		Env:    append(os.Environ(), pluginEnv(ctx)...),
+		Stdin:  os.Stdin,
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	})
+	if code, ok := runner.ExitCode(err); ok {
+		span.SetStatus(codes.Error, err.Error())
+		return &pluginExitError{name: p.Name, code: code}
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("running plugin %s: %w", p.Name, err)
+	}
+	return nil
+}
+
+// pluginEnv is the environment a plugin receives on top of allbctl's own
+func pluginEnv(ctx context.Context) []string {
+	env := []string{
+		fmt.Sprintf("%s=%s", plugin.EnvConfig, viper.ConfigFileUsed()),
+		fmt.Sprintf("%s=%s", plugin.EnvDebug, strconv.FormatBool(debugMode)),
+	}
+	return append(env, telemetry.TraceEnv(ctx)...)
+}
+
+// applyPluginGlobalFlags handles 'allbctl --debug foo ...'. Plugin commands
+// do not parse flags, so allbctl's own flags given before a plugin name are
+// applied here and removed from the arguments. Other invocations are returned
+// unchanged for cobra to parse.
+func applyPluginGlobalFlags(root *cobra.Command, args []string) ([]string, error) {
+	flags := root.PersistentFlags()
+	type setting struct{ name, value string }
+	var settings []setting
+
+	i := 0
+	for i < len(args) && strings.HasPrefix(args[i], "--") && args[i] != "--" {
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(args[i], "--"), "=")
+		flag := flags.Lookup(name)
+		if flag == nil {
+			return args, nil
+		}
+		if !hasValue {
+			if flag.NoOptDefVal != "" {
+				value = flag.NoOptDefVal
+			} else if i+1 < len(args) {
+				i++
+				value = args[i]
+			} else {
+				return args, nil
+			}
+		}
+		settings = append(settings, setting{name, value})
+		i++
+	}
+	if len(settings) == 0 || i >= len(args) {
+		return args, nil
+	}
+
+	target, _, err := root.Find(args[i:])
+	if err != nil || target.Annotations[pluginPathAnnotation] == "" {
+		return args, nil
+	}
+	for _, s := range settings {
+		if err := flags.Set(s.name, s.value); err != nil {
+			return nil, fmt.Errorf("invalid argument %q for --%s: %w", s.value, s.name, err)
+		}
+	}
+	return args[i:], nil
+}
+
+func printPlugins(plugins []plugin.Plugin) {
+	if len(plugins) == 0 {
+		fmt.Println("No plugins found. Put an executable named allbctl-<name> on PATH to add 'allbctl <name>'.")
+		return
+	}
+
+	fmt.Printf("  %-20s %s\n", "NAME", "PATH")
+	for _, p := range plugins {
+		if p.Usable() {
+			fmt.Printf("  %-20s %s\n", p.Name, p.Path)
+		} else {
+			fmt.Printf("  %-20s %s (shadowed by %s)\n", p.Name, p.Path, p.ShadowedBy)
+		}
+	}
+}
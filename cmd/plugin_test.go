@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aallbrig/allbctl/pkg/plugin"
+)
+
+// pluginTestRoot is a root command with allbctl's persistent flags, one
+// built-in command and the plugins found in dir
+func pluginTestRoot(t *testing.T, dir string) (*cobra.Command, *bool) {
+	t.Helper()
+	debug := false
+	root := &cobra.Command{Use: "allbctl"}
+	root.PersistentFlags().BoolVar(&debug, "debug", false, "")
+	root.PersistentFlags().String("config", "", "")
+	root.AddCommand(&cobra.Command{Use: "status", Run: func(*cobra.Command, []string) {}})
+	t.Setenv("PATH", dir)
+	registerPlugins(root)
+	return root, &debug
+}
+
+func writePlugin(t *testing.T, dir, name, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins in this test are shell scripts")
+	}
+	path := filepath.Join(dir, plugin.Prefix+name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRegisterPlugins(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "deploy", "")
+	writePlugin(t, dir, "status", "")
+
+	root, _ := pluginTestRoot(t, dir)
+
+	deploy, _, err := root.Find([]string{"deploy"})
+	if err != nil || deploy.Annotations[pluginPathAnnotation] != filepath.Join(dir, "allbctl-deploy") {
+		t.Fatalf("deploy plugin not registered: %v", err)
+	}
+	status, _, _ := root.Find([]string{"status"})
+	if _, isPlugin := status.Annotations[pluginPathAnnotation]; isPlugin {
+		t.Error("a plugin must not replace a built-in command")
+	}
+}
+
+func TestApplyPluginGlobalFlags(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "deploy", "")
+
+	tests := []struct {
+		name      string
+		args      []string
+		want      []string
+		wantDebug bool
+	}{
+		{"no flags", []string{"deploy", "--debug"}, []string{"deploy", "--debug"}, false},
+		{"flags before plugin", []string{"--debug", "--config", "x.yaml", "deploy", "-v"}, []string{"deploy", "-v"}, true},
+		{"flag with value", []string{"--debug=true", "deploy"}, []string{"deploy"}, true},
+		{"built-in command", []string{"--debug", "status"}, []string{"--debug", "status"}, false},
+		{"unknown flag", []string{"--verbose", "deploy"}, []string{"--verbose", "deploy"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, debug := pluginTestRoot(t, dir)
+			got, err := applyPluginGlobalFlags(root, tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") || *debug != tt.wantDebug {
+				t.Errorf("applyPluginGlobalFlags(%v) = %v (debug %v), want %v (debug %v)", tt.args, got, *debug, tt.want, tt.wantDebug)
+			}
+		})
+	}
+}
+
+func TestRunPlugin(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	path := writePlugin(t, dir, "deploy", `echo "$* $ALLBCTL_DEBUG" > "`+out+`"; exit 4`)
+
+	err := runPlugin(context.Background(), plugin.Plugin{Name: "deploy", Path: path}, []string{"--force", "prod"})
+
+	var exitErr *pluginExitError
+	if !errors.As(err, &exitErr) || exitErr.code != 4 {
+		t.Errorf("runPlugin() error = %v, want exit status 4", err)
+	}
+	data, readErr := os.ReadFile(out)
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	if got := strings.TrimSpace(string(data)); got != "--force prod false" {
+		t.Errorf("plugin saw %q, want arguments and ALLBCTL_DEBUG", got)
+	}
+}
@@ -1,49 +1,62 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
+	"io"
+	"os"
 	"runtime"
 	"strings"
 
 	"github.com/spf13/cobra"
+
+	"github.com/aallbrig/allbctl/pkg/runner"
 )
 
 var PortsCmd = &cobra.Command{
 	Use:   "ports",
 	Short: "Display listening ports",
-	Long:  `Display count of listening TCP/UDP ports and details about what's listening.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		PrintPortsInfo()
+	Long: `Display count of listening TCP/UDP ports and details about what's listening.
+
+Use --watch to keep the view open; newly listening ports are highlighted.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if watchMode {
+			return watchCommand(cmd, watchFrame(func(ctx context.Context, w io.Writer) { printPortsInfo(w, gatherPortsInfo(ctx)) }))
+		}
+		info := gatherPortsInfo(ctx)
+		return renderOutput(info, func() { printPortsInfo(os.Stdout, info) })
 	},
 }
 
 type PortInfo struct {
-	TCPPorts int
-	UDPPorts int
-	Ports    []string
+	TCPPorts int      `json:"tcp_ports"`
+	UDPPorts int      `json:"udp_ports"`
+	Ports    []string `json:"ports"`
 }
 
-func PrintPortsInfo() {
-	fmt.Println("Listening Ports:")
-	fmt.Println()
+func PrintPortsInfo(ctx context.Context) {
+	printPortsInfo(os.Stdout, gatherPortsInfo(ctx))
+}
 
-	info := gatherPortsInfo()
+func printPortsInfo(w io.Writer, info *PortInfo) {
+	fmt.Fprintln(w, "Listening Ports:")
+	fmt.Fprintln(w)
 
-	fmt.Printf("  TCP Ports: %d\n", info.TCPPorts)
-	fmt.Printf("  UDP Ports: %d\n", info.UDPPorts)
-	fmt.Printf("  Total:     %d\n", info.TCPPorts+info.UDPPorts)
+	fmt.Fprintf(w, "  TCP Ports: %d\n", info.TCPPorts)
+	fmt.Fprintf(w, "  UDP Ports: %d\n", info.UDPPorts)
+	fmt.Fprintf(w, "  Total:     %d\n", info.TCPPorts+info.UDPPorts)
 
 	if len(info.Ports) > 0 {
-		fmt.Println()
-		fmt.Printf("  Details:\n")
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "  Details:\n")
 		for _, port := range info.Ports {
-			fmt.Printf("    %s\n", port)
+			fmt.Fprintf(w, "    %s\n", port)
 		}
 	}
 }
 
-func gatherPortsInfo() *PortInfo {
+func gatherPortsInfo(ctx context.Context) *PortInfo {
 	info := &PortInfo{
 		Ports: []string{},
 	}
@@ -53,9 +66,8 @@ func gatherPortsInfo() *PortInfo {
 	switch osType {
 	case "linux":
 		// Use ss (preferred) or netstat
-		if exists("ss") {
-			cmd := exec.Command("ss", "-tulpn")
-			out, err := cmd.Output()
+		if exists(ctx, "ss") {
+			out, err := runner.Output(ctx, "ss", "-tulpn")
 			if err == nil {
 				lines := strings.Split(string(out), "\n")
 				for _, line := range lines {
@@ -93,9 +105,8 @@ func gatherPortsInfo() *PortInfo {
 					}
 				}
 			}
-		} else if exists("netstat") {
-			cmd := exec.Command("netstat", "-tulpn")
-			out, err := cmd.Output()
+		} else if exists(ctx, "netstat") {
+			out, err := runner.Output(ctx, "netstat", "-tulpn")
 			if err == nil {
 				lines := strings.Split(string(out), "\n")
 				for _, line := range lines {
@@ -136,9 +147,8 @@ func gatherPortsInfo() *PortInfo {
 		}
 	case "darwin":
 		// Use lsof on macOS
-		if exists("lsof") {
-			cmd := exec.Command("lsof", "-iTCP", "-sTCP:LISTEN", "-P", "-n")
-			out, err := cmd.Output()
+		if exists(ctx, "lsof") {
+			out, err := runner.Output(ctx, "lsof", "-iTCP", "-sTCP:LISTEN", "-P", "-n")
 			if err == nil {
 				lines := strings.Split(string(out), "\n")
 				for _, line := range lines {
@@ -158,8 +168,7 @@ func gatherPortsInfo() *PortInfo {
 			}
 
 			// UDP ports
-			cmd = exec.Command("lsof", "-iUDP", "-P", "-n")
-			out, err = cmd.Output()
+			out, err = runner.Output(ctx, "lsof", "-iUDP", "-P", "-n")
 			if err == nil {
 				lines := strings.Split(string(out), "\n")
 				for _, line := range lines {
@@ -180,9 +189,8 @@ func gatherPortsInfo() *PortInfo {
 		}
 	case "windows":
 		// Use netstat on Windows
-		if exists("netstat") {
-			cmd := exec.Command("netstat", "-ano")
-			out, err := cmd.Output()
+		if exists(ctx, "netstat") {
+			out, err := runner.Output(ctx, "netstat", "-ano")
 			if err == nil {
 				lines := strings.Split(string(out), "\n")
 				for _, line := range lines {
@@ -1,10 +1,11 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/aallbrig/allbctl/pkg/cache"
 	"github.com/aallbrig/allbctl/pkg/languages"
+	"github.com/aallbrig/allbctl/pkg/runner"
 	"github.com/spf13/cobra"
 )
 
@@ -24,6 +26,7 @@ var (
 	verboseFlag   bool
 	languagesFlag bool
 	showLanguages bool // computed in Run; true when language data should be gathered/displayed
+	refreshFlag   bool
 )
 
 // DirtyReason is a bitmask describing why a repo is considered dirty
@@ -65,15 +68,54 @@ func (r DirtyReason) String() string {
 	return "[" + strings.Join(labels, ", ") + "]"
 }
 
+// MarshalJSON encodes the bitmask as its list of labels, e.g. ["unpushed commits"]
+func (r DirtyReason) MarshalJSON() ([]byte, error) {
+	labels := r.Labels()
+	if labels == nil {
+		labels = []string{}
+	}
+	return json.Marshal(labels)
+}
+
+// UnmarshalJSON decodes a list of labels produced by MarshalJSON
+func (r *DirtyReason) UnmarshalJSON(data []byte) error {
+	var labels []string
+	if err := json.Unmarshal(data, &labels); err != nil {
+		return err
+	}
+	*r = 0
+	for _, label := range labels {
+		for bit := DirtyUncommittedChanges; bit <= DirtyCIPending; bit <<= 1 {
+			if bit.Labels()[0] == label {
+				*r |= bit
+			}
+		}
+	}
+	return nil
+}
+
 // ProjectsCmd represents the projects command
 var ProjectsCmd = &cobra.Command{
 	Use:   "projects",
-	Short: "Display git repositories in ~/src",
-	Long: `Display a summary of git repositories found in ~/src directory.
+	Short: "Display git repositories in ~/src and other project roots",
+	Long: `Display a summary of git repositories found in the project roots: ~/src,
+or the directories listed under projects.roots in ~/.allbctl.yaml:
+
+  projects:
+    roots: [~/src, ~/work, ~/go/src, /opt/code]
+    exclude: [node_modules, vendor, archive/*]  # directory names, or paths under a root
+    max_depth: 3                                # repos at most 3 directories below a root
+    include_bare: true                          # also list bare repos (mirror.git)
+    include_worktrees: true                     # also list git worktree checkouts
 
 By default, shows the same summary as the 'Projects:' section in 'allbctl status'.
 Dirty repos are marked with an asterisk (*).
 
+Scan results are cached per repo under the user cache directory and reused
+until the repo's index, HEAD or refs change. Edits to tracked files that have
+not been staged do not touch any of those; use --refresh to re-scan every repo.
+CI status is cached per commit, and looked up again while it is pending.
+
 Examples:
   allbctl status projects                        # Show summary (default, same as status)
   allbctl status projects --all                  # Show all repos
@@ -81,17 +123,26 @@ Examples:
   allbctl status projects --clean                # Show only clean repos
   allbctl status projects --dirty -v             # Show dirty repos with their changed files
   allbctl status projects --all --languages      # Show all repos with language breakdown
-  allbctl status projects -v --languages=false   # Verbose without language breakdown`,
-	Run: func(cmd *cobra.Command, args []string) {
+  allbctl status projects -v --languages=false   # Verbose without language breakdown
+  allbctl status projects --refresh              # Ignore cached scans and re-inspect every repo`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
 		langExplicit := cmd.Flags().Changed("languages")
 		showLanguages = languagesFlag && (verboseFlag || langExplicit)
 
+		if isStructuredOutput() {
+			summary, err := projectsForOutput(ctx)
+			if err != nil {
+				return err
+			}
+			return printStructured(summary)
+		}
+
 		if allFlag || dirtyFlag || cleanFlag || verboseFlag || (langExplicit && languagesFlag) {
-			printProjectsSummary()
-		} else {
-			// Default: show all projects (no limit), unless --limit is specified
-			printProjectsInline(limitFlag)
+			return printProjectsSummary(ctx)
 		}
+		// Default: show all projects (no limit), unless --limit is specified
+		return printProjectsInline(ctx, limitFlag)
 	},
 }
 
@@ -102,22 +153,24 @@ func init() {
 	ProjectsCmd.Flags().IntVar(&limitFlag, "limit", 0, "Limit the number of projects shown (0 = no limit, show all)")
 	ProjectsCmd.Flags().BoolVarP(&verboseFlag, "verbose", "v", false, "Show changed files (tracked and untracked) under each dirty repo")
 	ProjectsCmd.Flags().BoolVar(&languagesFlag, "languages", true, "Show language breakdown for each repo (use --languages=false to hide)")
+	ProjectsCmd.Flags().BoolVar(&refreshFlag, "refresh", false, "Re-scan every repo and look up its CI status instead of reusing cached results")
 }
 
 // RepoInfo contains information about a git repository
 type RepoInfo struct {
-	Path             string
-	ModTime          time.Time
-	Dirty            bool
-	DirtyReasons     DirtyReason
-	RemoteRepo       string                        // e.g., "aallbrig/allbctl" or "godotengine/godot"
-	StatusOutput     string                        // populated when -v/--verbose is set; full `git status --untracked-files=all` output
-	UncommittedFiles int                           // staged + unstaged file count (excludes untracked)
-	UntrackedFiles   int                           // untracked file count
-	UnpushedCommits  int                           // number of commits ahead of upstream
-	CIStatus         string                        // "success", "failure", "pending", or "" (no CI detected)
-	CIChecks         []CICheck                     // populated when -v/--verbose is set
-	Languages        []languages.LanguageBreakdown // populated when -v/--verbose is set
+	Path             string                        `json:"path"`
+	ModTime          time.Time                     `json:"mod_time"`
+	Bare             bool                          `json:"bare,omitempty"` // a bare repository, listed with projects.include_bare
+	Dirty            bool                          `json:"dirty"`
+	DirtyReasons     DirtyReason                   `json:"dirty_reasons"`
+	RemoteRepo       string                        `json:"remote_repo,omitempty"`   // e.g., "aallbrig/allbctl" or "godotengine/godot"
+	StatusOutput     string                        `json:"status_output,omitempty"` // populated when -v/--verbose is set; full `git status --untracked-files=all` output
+	UncommittedFiles int                           `json:"uncommitted_files"`       // staged + unstaged file count (excludes untracked)
+	UntrackedFiles   int                           `json:"untracked_files"`         // untracked file count
+	UnpushedCommits  int                           `json:"unpushed_commits"`        // number of commits ahead of upstream
+	CIStatus         string                        `json:"ci_status,omitempty"`     // "success", "failure", "pending", or "" (no CI detected)
+	CIChecks         []CICheck                     `json:"ci_checks,omitempty"`     // populated when -v/--verbose is set
+	Languages        []languages.LanguageBreakdown `json:"languages,omitempty"`     // populated when -v/--verbose is set
 }
 
 // CICheck represents a single GitHub check run with its name and conclusion.
@@ -127,27 +180,20 @@ type CICheck struct {
 }
 
 // printProjectsSummary prints a summary of git repositories
-func printProjectsSummary() {
-	home, err := os.UserHomeDir()
+func printProjectsSummary(ctx context.Context) error {
+	config, err := loadProjectsConfig()
 	if err != nil {
-		fmt.Printf("Error getting home directory: %v\n", err)
-		return
+		return err
 	}
 
-	srcDir := filepath.Join(home, "src")
-	if _, err := os.Stat(srcDir); os.IsNotExist(err) {
-		fmt.Printf("~/src directory does not exist\n")
-		return
-	}
-
-	repos := findGitRepos(srcDir)
+	repos := config.findRepos()
 	if len(repos) == 0 {
-		fmt.Printf("No git repositories found in ~/src\n")
-		return
+		fmt.Printf("No git repositories found in %s\n", config.displayRoots())
+		return nil
 	}
 
 	// Get repos with their info
-	repoInfos := getReposByModTime(repos)
+	repoInfos := getReposByModTime(ctx, repos)
 
 	// Filter based on flags
 	var displayMode string
@@ -189,13 +235,14 @@ func printProjectsSummary() {
 		}
 		fmt.Printf("\nLast %d recently touched:\n", count)
 		showDetails := verboseFlag || showLanguages
-		printRepoTable(filtered[:count], "  ", showDetails, true)
+		printRepoTable(os.Stdout, filtered[:count], "  ", showDetails, true)
 	} else {
 		fmt.Println(buildSummaryLine(filtered, displayMode))
 		fmt.Println()
 		showDetails := verboseFlag || showLanguages
-		printRepoTable(filtered, "  ", showDetails, dirtyFlag || allFlag)
+		printRepoTable(os.Stdout, filtered, "  ", showDetails, dirtyFlag || allFlag)
 	}
+	return nil
 }
 
 func buildSummaryLine(repos []RepoInfo, displayMode string) string {
@@ -257,25 +304,29 @@ func buildSummaryLine(repos []RepoInfo, displayMode string) string {
 	return strings.Join(parts, "  ")
 }
 
-// printProjectsInline prints a summary for the status command.
-// limit controls how many recently-touched projects to show; 0 means no limit (show all).
-func printProjectsInline(limit int) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return
-	}
+// ProjectsSummary is the structured form of the projects section
+type ProjectsSummary struct {
+	Roots []string   `json:"roots"`
+	Total int        `json:"total"`
+	Dirty int        `json:"dirty"`
+	Repos []RepoInfo `json:"repos"`
+}
 
-	srcDir := filepath.Join(home, "src")
-	if _, err := os.Stat(srcDir); os.IsNotExist(err) {
-		return
+// gatherProjects scans the configured roots (~/src by default) and returns
+// every repo, most recently touched first. Returns nil when they hold no git
+// repositories.
+func gatherProjects(ctx context.Context) (*ProjectsSummary, error) {
+	config, err := loadProjectsConfig()
+	if err != nil {
+		return nil, err
 	}
 
-	repos := findGitRepos(srcDir)
+	repos := config.findRepos()
 	if len(repos) == 0 {
-		return
+		return nil, nil
 	}
 
-	repoInfos := getReposByModTime(repos)
+	repoInfos := getReposByModTime(ctx, repos)
 	dirtyCount := 0
 	for _, repo := range repoInfos {
 		if repo.Dirty {
@@ -283,97 +334,83 @@ func printProjectsInline(limit int) {
 		}
 	}
 
+	return &ProjectsSummary{
+		Roots: config.Roots,
+		Total: len(repos),
+		Dirty: dirtyCount,
+		Repos: repoInfos,
+	}, nil
+}
+
+// printProjectsInline prints a summary for the status command.
+// limit controls how many recently-touched projects to show; 0 means no limit (show all).
+func printProjectsInline(ctx context.Context, limit int) error {
+	summary, err := gatherProjects(ctx)
+	if err != nil {
+		return err
+	}
+	printProjectsSummaryInline(os.Stdout, summary, limit)
+	return nil
+}
+
+// printProjectsSummaryInline renders an already gathered projects summary
+func printProjectsSummaryInline(w io.Writer, summary *ProjectsSummary, limit int) {
+	if summary == nil || summary.Total == 0 {
+		return
+	}
+
 	// Format: "Projects: 4 total (2 dirty)"
-	if dirtyCount > 0 {
-		fmt.Printf("Projects: %d total (%d dirty)\n", len(repos), dirtyCount)
+	if summary.Dirty > 0 {
+		fmt.Fprintf(w, "Projects: %d total (%d dirty)\n", summary.Total, summary.Dirty)
 	} else {
-		fmt.Printf("Projects: %d total\n", len(repos))
+		fmt.Fprintf(w, "Projects: %d total\n", summary.Total)
 	}
 
 	// Show recently touched projects; limit=0 means show all
-	count := len(repoInfos)
+	count := len(summary.Repos)
 	if limit > 0 && limit < count {
 		count = limit
 	}
 	if limit > 0 {
-		fmt.Printf("  Last %d recently touched:\n", count)
+		fmt.Fprintf(w, "  Last %d recently touched:\n", count)
 	} else {
-		fmt.Printf("  Recently touched (%d):\n", count)
+		fmt.Fprintf(w, "  Recently touched (%d):\n", count)
 	}
-	printRepoTable(repoInfos[:count], "    ", false, true)
+	printRepoTable(w, summary.Repos[:count], "    ", false, true)
 }
 
-// findGitRepos recursively finds all git repositories in the given directory
-func findGitRepos(rootDir string) []string {
-	var repos []string
-
-	err := filepath.WalkDir(rootDir, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return nil
-		}
-
-		// Check if this directory is a git repo
-		if d.IsDir() && d.Name() == ".git" {
-			repoPath := filepath.Dir(path)
-			repos = append(repos, repoPath)
-			return filepath.SkipDir
-		}
-
-		return nil
-	})
-
-	if err != nil {
-		return []string{}
+// projectsForOutput gathers projects for --output json|yaml, honouring the
+// --dirty, --clean and --limit flags.
+func projectsForOutput(ctx context.Context) (*ProjectsSummary, error) {
+	summary, err := gatherProjects(ctx)
+	if err != nil || summary == nil {
+		return &ProjectsSummary{Roots: []string{}, Repos: []RepoInfo{}}, err
 	}
 
-	return repos
-}
-
-// getDirtyReasons returns a bitmask describing why a repo is dirty
-func getDirtyReasons(repoPath string) DirtyReason {
-	var reasons DirtyReason
-
-	if output, err := exec.Command("git", "-C", repoPath, "status", "--porcelain").Output(); err == nil {
-		if len(strings.TrimSpace(string(output))) > 0 {
-			reasons |= DirtyUncommittedChanges
-		}
+	if dirtyFlag {
+		summary.Repos = filterRepos(summary.Repos, "dirty")
+	} else if cleanFlag {
+		summary.Repos = filterRepos(summary.Repos, "clean")
 	}
-
-	// If repo has no commits yet, upstream checks don't apply
-	if exec.Command("git", "-C", repoPath, "rev-parse", "HEAD").Run() != nil {
-		return reasons
+	if limitFlag > 0 && limitFlag < len(summary.Repos) {
+		summary.Repos = summary.Repos[:limitFlag]
 	}
-
-	// Check whether the current branch has an upstream tracking branch
-	if exec.Command("git", "-C", repoPath, "rev-parse", "--abbrev-ref", "@{u}").Run() != nil {
-		reasons |= DirtyNoUpstream
-		return reasons
-	}
-
-	// Has upstream — check for unpushed commits
-	if output, err := exec.Command("git", "-C", repoPath, "log", "@{u}..HEAD", "--oneline").Output(); err == nil {
-		if len(strings.TrimSpace(string(output))) > 0 {
-			reasons |= DirtyUnpushedCommits
-		}
+	if summary.Repos == nil {
+		summary.Repos = []RepoInfo{}
 	}
+	return summary, nil
+}
 
-	return reasons
+// getDirtyReasons returns a bitmask describing why a repo is dirty
+func getDirtyReasons(ctx context.Context, repoPath string) DirtyReason {
+	scan, _ := scanRepo(ctx, repoPath)
+	return scan.reasons()
 }
 
 // countUnpushedCommits returns the number of commits ahead of upstream.
-func countUnpushedCommits(repoPath string) int {
-	output, err := exec.Command("git", "-C", repoPath, "log", "@{u}..HEAD", "--oneline").Output()
-	if err != nil {
-		return 0
-	}
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	count := 0
-	for _, l := range lines {
-		if strings.TrimSpace(l) != "" {
-			count++
-		}
-	}
-	return count
+func countUnpushedCommits(ctx context.Context, repoPath string) int {
+	scan, _ := scanRepo(ctx, repoPath)
+	return scan.ahead
 }
 
 // parseCICheckRuns derives a CI status string from a slice of check-run conclusions.
@@ -399,22 +436,17 @@ func parseCICheckRuns(conclusions []string) string {
 	return "success"
 }
 
-// getRemoteCIStatus queries GitHub check-runs for the repo's current branch HEAD.
+// getRemoteCIStatus queries GitHub check-runs for the head of branch ref.
 // Returns aggregate status ("success", "failure", "pending", or "") and individual checks.
-func getRemoteCIStatus(repoPath, remoteRepo string) (string, []CICheck) {
-	if remoteRepo == "" {
-		return "", nil
-	}
-	branch, err := exec.Command("git", "-C", repoPath, "branch", "--show-current").Output()
-	if err != nil || strings.TrimSpace(string(branch)) == "" {
+func getRemoteCIStatus(ctx context.Context, remoteRepo, ref string) (string, []CICheck) {
+	if remoteRepo == "" || ref == "" {
 		return "", nil
 	}
-	ref := strings.TrimSpace(string(branch))
 
-	out, err := exec.Command("gh", "api",
+	out, err := runner.Output(ctx, "gh", "api",
 		fmt.Sprintf("repos/%s/commits/%s/check-runs", remoteRepo, ref),
 		"--jq", "[.check_runs[] | select(.conclusion != \"skipped\") | {name: .name, conclusion: (.conclusion // \"\")}]",
-	).Output()
+	)
 	if err != nil {
 		return "", nil
 	}
@@ -432,8 +464,8 @@ func getRemoteCIStatus(repoPath, remoteRepo string) (string, []CICheck) {
 }
 
 // isGitRepoDirty returns true if the repo has any dirty reasons
-func isGitRepoDirty(repoPath string) bool {
-	return getDirtyReasons(repoPath) != 0
+func isGitRepoDirty(ctx context.Context, repoPath string) bool {
+	return getDirtyReasons(ctx, repoPath) != 0
 }
 
 // filterStatusLines removes noise from git status output for display:
@@ -469,9 +501,8 @@ func filterStatusLines(lines []string) []string {
 	return filtered
 }
 
-func getGitStatusOutput(repoPath string) string {
-	cmd := exec.Command("git", "-C", repoPath, "status", "--untracked-files=all")
-	output, err := cmd.Output()
+func getGitStatusOutput(ctx context.Context, repoPath string) string {
+	output, err := runner.Output(ctx, "git", "-C", repoPath, "status", "--untracked-files=all")
 	if err != nil {
 		return ""
 	}
@@ -499,87 +530,16 @@ func countTotalStatusFiles(repos []RepoInfo) int {
 	return total
 }
 
-// countPorcelainFiles parses `git status --porcelain` output and returns
-// the count of staged/unstaged files and the count of untracked files.
-func countPorcelainFiles(repoPath string) (uncommitted, untracked int) {
-	output, err := exec.Command("git", "-C", repoPath, "status", "--porcelain", "--untracked-files=all").Output()
-	if err != nil {
-		return
-	}
-	for _, line := range strings.Split(strings.TrimRight(string(output), "\n"), "\n") {
-		if len(line) < 2 {
-			continue
-		}
-		if strings.HasPrefix(line, "??") {
-			untracked++
-		} else {
-			uncommitted++
-		}
-	}
-	return
+// countPorcelainFiles returns the number of uncommitted (staged, unstaged or
+// conflicted) and untracked files
+func countPorcelainFiles(ctx context.Context, repoPath string) (uncommitted, untracked int) {
+	scan, _ := scanRepo(ctx, repoPath)
+	return scan.uncommitted, scan.untracked
 }
 
 // getReposByModTime gets repository info sorted by modification time (most recent first)
-func getReposByModTime(repos []string) []RepoInfo {
-	repoInfos := make([]RepoInfo, len(repos))
-	valid := make([]bool, len(repos))
-
-	var wg sync.WaitGroup
-	for i, repo := range repos {
-		wg.Add(1)
-		go func(i int, repo string) {
-			defer wg.Done()
-			info, err := os.Stat(repo)
-			if err != nil {
-				return
-			}
-
-			reasons := getDirtyReasons(repo)
-			repoInfo := RepoInfo{
-				Path:         repo,
-				ModTime:      info.ModTime(),
-				Dirty:        reasons != 0,
-				DirtyReasons: reasons,
-				RemoteRepo:   getRemoteRepo(repo),
-			}
-			if reasons != 0 {
-				repoInfo.UncommittedFiles, repoInfo.UntrackedFiles = countPorcelainFiles(repo)
-				if reasons&DirtyUnpushedCommits != 0 {
-					repoInfo.UnpushedCommits = countUnpushedCommits(repo)
-				}
-				if verboseFlag {
-					repoInfo.StatusOutput = getGitStatusOutput(repo)
-				}
-			}
-			ciStatus, ciChecks := getRemoteCIStatus(repo, repoInfo.RemoteRepo)
-			repoInfo.CIStatus = ciStatus
-			if verboseFlag {
-				repoInfo.CIChecks = ciChecks
-			}
-			if showLanguages {
-				repoInfo.Languages = getRepoLanguages(repo)
-			}
-			switch ciStatus {
-			case "failure":
-				repoInfo.DirtyReasons |= DirtyCIFailed
-				repoInfo.Dirty = true
-			case "pending":
-				repoInfo.DirtyReasons |= DirtyCIPending
-				repoInfo.Dirty = true
-			}
-			repoInfos[i] = repoInfo
-			valid[i] = true
-		}(i, repo)
-	}
-	wg.Wait()
-
-	// Collect valid entries (repos where os.Stat succeeded)
-	var result []RepoInfo
-	for i, ok := range valid {
-		if ok {
-			result = append(result, repoInfos[i])
-		}
-	}
+func getReposByModTime(ctx context.Context, repos []string) []RepoInfo {
+	result := scanRepos(ctx, repos, projectScanWorkers())
 
 	// Sort by modification time (most recent first)
 	sort.Slice(result, func(i, j int) bool {
@@ -630,15 +590,8 @@ func filterRepos(repos []RepoInfo, mode string) []RepoInfo {
 }
 
 // getRemoteRepo gets the remote repository (user/repo) from git remote origin
-func getRemoteRepo(repoPath string) string {
-	cmd := exec.Command("git", "-C", repoPath, "remote", "get-url", "origin")
-	output, err := cmd.Output()
-	if err != nil {
-		return ""
-	}
-
-	url := strings.TrimSpace(string(output))
-	return parseRemoteRepo(url)
+func getRemoteRepo(ctx context.Context, repoPath string) string {
+	return parseRemoteRepo(readOriginURL(ctx, repoPath))
 }
 
 // parseRemoteRepo parses a git remote URL to extract user/repo
@@ -709,7 +662,7 @@ func formatRepoLine(repo RepoInfo) string {
 }
 
 // printRepoTable prints repositories in a table format with aligned columns
-func printRepoTable(repos []RepoInfo, indent string, showFiles bool, showReasons bool) {
+func printRepoTable(w io.Writer, repos []RepoInfo, indent string, showFiles bool, showReasons bool) {
 	if len(repos) == 0 {
 		return
 	}
@@ -744,15 +697,15 @@ func printRepoTable(repos []RepoInfo, indent string, showFiles bool, showReasons
 		if repo.CIStatus == "success" {
 			line += "  ✓"
 		}
-		fmt.Println(line)
+		fmt.Fprintln(w, line)
 
 		if showFiles {
 			details := verboseDetailLines(repo)
 			for _, d := range details {
-				fmt.Printf("%s    %s\n", indent, d)
+				fmt.Fprintf(w, "%s    %s\n", indent, d)
 			}
 			if len(details) > 0 {
-				fmt.Println()
+				fmt.Fprintln(w)
 			}
 		}
 	}
@@ -804,8 +757,8 @@ func getLangCache() *cache.FileCache {
 
 // getRepoLanguages detects languages for a repository, using a file-based
 // cache keyed by the HEAD commit SHA to avoid redundant analysis.
-func getRepoLanguages(repoPath string) []languages.LanguageBreakdown {
-	commit, err := languages.GetHeadCommit(repoPath)
+func getRepoLanguages(ctx context.Context, repoPath string) []languages.LanguageBreakdown {
+	commit, err := languages.GetHeadCommit(ctx, repoPath)
 	if err != nil {
 		return nil
 	}
@@ -821,7 +774,7 @@ func getRepoLanguages(repoPath string) []languages.LanguageBreakdown {
 	}
 
 	// Cache miss — detect languages
-	breakdown, err := languages.DetectLanguages(repoPath)
+	breakdown, err := languages.DetectLanguages(ctx, repoPath)
 	if err != nil {
 		return nil
 	}
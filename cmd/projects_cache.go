@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/aallbrig/allbctl/pkg/cache"
+)
+
+// repoInfoCache is lazily initialized for caching what each repo's scan
+// found; nil when the cache directory cannot be created
+var repoInfoCache = sync.OnceValue(func() *cache.FileCache {
+	c, err := cache.NewFileCache("allbctl", "projects")
+	if err != nil {
+		return nil
+	}
+	return c
+})
+
+// ciStatusCache is lazily initialized for caching each repo's CI lookup;
+// nil when the cache directory cannot be created
+var ciStatusCache = sync.OnceValue(func() *cache.FileCache {
+	c, err := cache.NewFileCache("allbctl", "ci-status")
+	if err != nil {
+		return nil
+	}
+	return c
+})
+
+// cachedRepo is the part of a repo's RepoInfo that comes from the repo
+// itself. CI status lives on the remote and languages have their own cache,
+// so neither is stored here.
+type cachedRepo struct {
+	Info   RepoInfo `json:"info"`
+	Branch string   `json:"branch,omitempty"` // the checked out branch, for the CI lookup
+	Head   string   `json:"head,omitempty"`   // the checked out commit, for the CI lookup
+}
+
+// cachedCIStatus is what the CI status cache stores for a repo: the result
+// of the GitHub check-runs lookup
+type cachedCIStatus struct {
+	Status string    `json:"status,omitempty"`
+	Checks []CICheck `json:"checks,omitempty"`
+}
+
+// inspectRepoCached inspects a repo, serving the result from the projects
+// cache while the repo's git metadata is unchanged. --refresh skips the
+// lookup but still stores the fresh result.
+func inspectRepoCached(ctx context.Context, repo string) cachedRepo {
+	c := repoInfoCache()
+	version, ok := repoCacheVersion(repo)
+	if c == nil || !ok {
+		return inspectRepo(ctx, repo)
+	}
+
+	if !refreshFlag {
+		if raw, ok := c.Get(repo, version); ok {
+			var cached cachedRepo
+			if json.Unmarshal(raw, &cached) == nil {
+				return cached
+			}
+		}
+	}
+
+	inspected := inspectRepo(ctx, repo)
+	//nolint:errcheck // best-effort cache write
+	c.Set(repo, version, inspected)
+	return inspected
+}
+
+// repoCacheVersion stamps a repo with the mtimes and sizes of the files git
+// rewrites whenever its state changes: the index, HEAD, config, packed-refs
+// and everything under refs/. The repo's top directory is included so files
+// created or removed there are noticed too. It is taken before the repo is
+// scanned, so a change made mid-scan shows up on the next run.
+func repoCacheVersion(repo string) (string, bool) {
+	gitDir, commonDir, ok := repoGitDirs(repo)
+	if !ok {
+		return "", false
+	}
+
+	var b strings.Builder
+	stamp := func(path string) {
+		if info, err := os.Stat(path); err == nil {
+			fmt.Fprintf(&b, "%d:%d;", info.ModTime().UnixNano(), info.Size())
+		} else {
+			b.WriteString("-;")
+		}
+	}
+	stamp(repo)
+	for _, name := range []string{"index", "HEAD"} {
+		stamp(filepath.Join(gitDir, name))
+	}
+	for _, name := range []string{"config", "packed-refs"} {
+		stamp(filepath.Join(commonDir, name))
+	}
+	for _, name := range []string{"refs", "reftable"} {
+		//nolint:errcheck // a missing directory simply adds nothing
+		filepath.WalkDir(filepath.Join(commonDir, name), func(path string, d fs.DirEntry, err error) error {
+			if err == nil {
+				stamp(path)
+			}
+			return nil
+		})
+	}
+	// -v adds the full status output, so it is cached separately
+	fmt.Fprintf(&b, "verbose=%t", verboseFlag)
+	return b.String(), true
+}
+
+// repoGitDirs finds a repo's git directory, which holds its index and HEAD,
+// and the common directory that holds refs and config. They differ only
+// for linked worktrees.
+func repoGitDirs(repo string) (gitDir, commonDir string, ok bool) {
+	if isBareRepo(repo) {
+		return repo, repo, true
+	}
+	gitDir = filepath.Join(repo, ".git")
+	info, err := os.Stat(gitDir)
+	if err != nil {
+		return "", "", false
+	}
+	if !info.IsDir() {
+		// A worktree or submodule: .git is a file pointing at the git directory
+		data, err := os.ReadFile(gitDir)
+		if err != nil {
+			return "", "", false
+		}
+		target, found := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+		if !found {
+			return "", "", false
+		}
+		gitDir = strings.TrimSpace(target)
+		if !filepath.IsAbs(gitDir) {
+			gitDir = filepath.Join(repo, gitDir)
+		}
+	}
+
+	commonDir = gitDir
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = strings.TrimSpace(string(data))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+	}
+	return gitDir, commonDir, true
+}
+
+// getRemoteCIStatusCached looks up CI status like getRemoteCIStatus, using a
+// file-based cache keyed by the repo path and versioned by the remote, branch
+// and commit checked. Pending results are not cached since they are about to
+// change; --refresh skips the lookup but still stores the fresh result.
+func getRemoteCIStatusCached(ctx context.Context, repo, remoteRepo, branch, head string) (string, []CICheck) {
+	c := ciStatusCache()
+	if c == nil || head == "" {
+		return getRemoteCIStatus(ctx, remoteRepo, branch)
+	}
+	version := remoteRepo + " " + branch + " " + head
+
+	if !refreshFlag {
+		if raw, ok := c.Get(repo, version); ok {
+			var cached cachedCIStatus
+			if json.Unmarshal(raw, &cached) == nil {
+				return cached.Status, cached.Checks
+			}
+		}
+	}
+
+	status, checks := getRemoteCIStatus(ctx, remoteRepo, branch)
+	if status != "pending" {
+		//nolint:errcheck // best-effort cache write
+		c.Set(repo, version, cachedCIStatus{Status: status, Checks: checks})
+	}
+	return status, checks
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aallbrig/allbctl/pkg/cache"
+	"github.com/aallbrig/allbctl/pkg/runner"
+)
+
+// useTempRepoInfoCache points the projects cache at a temp dir for the
+// rest of the test
+func useTempRepoInfoCache(tb testing.TB) {
+	tb.Helper()
+	c, err := cache.NewFileCacheInDir(tb.TempDir())
+	if err != nil {
+		tb.Fatal(err)
+	}
+	original := repoInfoCache
+	tb.Cleanup(func() { repoInfoCache = original })
+	repoInfoCache = func() *cache.FileCache { return c }
+}
+
+// useTempCIStatusCache points the CI status cache at a temp dir for the
+// rest of the test
+func useTempCIStatusCache(tb testing.TB) {
+	tb.Helper()
+	c, err := cache.NewFileCacheInDir(tb.TempDir())
+	if err != nil {
+		tb.Fatal(err)
+	}
+	original := ciStatusCache
+	tb.Cleanup(func() { ciStatusCache = original })
+	ciStatusCache = func() *cache.FileCache { return c }
+}
+
+// fakeGH answers gh check-runs lookups with conclusion and runs everything
+// else for real
+type fakeGH struct {
+	conclusion string
+	calls      int
+}
+
+func (f *fakeGH) Run(ctx context.Context, cmd runner.Cmd) error {
+	if cmd.Name != "gh" {
+		return runner.Exec{}.Run(ctx, cmd)
+	}
+	f.calls++
+	_, err := fmt.Fprintf(cmd.Stdout, `[{"name":"build","conclusion":%q}]`, f.conclusion)
+	return err
+}
+
+func (f *fakeGH) LookPath(name string) (string, error) { return runner.Exec{}.LookPath(name) }
+
+func TestScanRepoInfoCached(t *testing.T) {
+	useTempRepoInfoCache(t)
+	useTempCIStatusCache(t)
+	repo := cloneProjects(t, "allbctl")[0]
+	// git rewrites the index on every status while files are as new as it
+	// is, so age the checkout and let it settle before scanning
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(repo, "README"), past, past); err != nil {
+		t.Fatal(err)
+	}
+	gitIn(t, repo, "status")
+
+	rec := runner.Record(runner.Exec{})
+	ctx := runner.WithRunner(context.Background(), rec)
+	scans := func() int {
+		n := 0
+		for _, c := range rec.Fixture().Commands {
+			if slices.Contains(c.Argv, "status") {
+				n++
+			}
+		}
+		return n
+	}
+
+	first, ok := scanRepoInfo(ctx, repo)
+	if !ok || first.Dirty || scans() != 1 {
+		t.Fatalf("first scanRepoInfo() = %+v, %d scans, want a clean repo from one scan", first, scans())
+	}
+	second, _ := scanRepoInfo(ctx, repo)
+	if scans() != 1 || second.Path != repo || second.Dirty || !second.ModTime.Equal(first.ModTime) {
+		t.Errorf("second scanRepoInfo() = %+v after %d scans, want it served from cache", second, scans())
+	}
+
+	commitFile(t, repo, "a.txt", "a")
+	third, _ := scanRepoInfo(ctx, repo)
+	if scans() != 2 || third.DirtyReasons != DirtyUnpushedCommits || third.UnpushedCommits != 1 {
+		t.Errorf("scanRepoInfo() after a commit = %+v after %d scans, want a re-scan finding 1 unpushed commit", third, scans())
+	}
+
+	refreshFlag = true
+	defer func() { refreshFlag = false }()
+	scanRepoInfo(ctx, repo)
+	if scans() != 3 {
+		t.Errorf("scanRepoInfo() with --refresh ran %d scans, want 3", scans())
+	}
+}
+
+func TestRepoCacheVersion(t *testing.T) {
+	repo := cloneProjects(t, "allbctl")[0]
+	worktree := filepath.Join(t.TempDir(), "topic")
+	gitIn(t, repo, "worktree", "add", "-b", "topic", worktree)
+
+	gitDir, commonDir, ok := repoGitDirs(worktree)
+	if !ok || filepath.Dir(gitDir) != filepath.Join(repo, ".git", "worktrees") || commonDir != filepath.Join(repo, ".git") {
+		t.Fatalf("repoGitDirs(worktree) = %s, %s, %v", gitDir, commonDir, ok)
+	}
+
+	before, _ := repoCacheVersion(repo)
+	if err := os.WriteFile(filepath.Join(worktree, "b.txt"), []byte("b"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := repoCacheVersion(repo); after != before {
+		t.Errorf("repoCacheVersion(repo) changed after an edit in another worktree")
+	}
+	gitIn(t, worktree, "add", "b.txt")
+	gitIn(t, worktree, "commit", "-m", "b")
+	// The worktree's branch moved, which every checkout of the repo sees
+	if after, _ := repoCacheVersion(repo); after == before {
+		t.Errorf("repoCacheVersion(repo) = %s after a commit in a worktree, want it changed", after)
+	}
+
+	if _, ok := repoCacheVersion(t.TempDir()); ok {
+		t.Error("repoCacheVersion() of a plain directory should not be ok")
+	}
+}
+
+func TestGetRemoteCIStatusCached(t *testing.T) {
+	useTempCIStatusCache(t)
+	gh := &fakeGH{conclusion: "success"}
+	ctx := runner.WithRunner(context.Background(), gh)
+	repo, remote := "/src/allbctl", "aallbrig/allbctl"
+
+	for i := range 2 {
+		if status, _ := getRemoteCIStatusCached(ctx, repo, remote, "main", "abc"); status != "success" || gh.calls != 1 {
+			t.Errorf("lookup %d = %s after %d gh calls, want success from one call", i+1, status, gh.calls)
+		}
+	}
+
+	// A new commit is looked up again; pending results are not kept
+	gh.conclusion = ""
+	for i := range 2 {
+		if status, _ := getRemoteCIStatusCached(ctx, repo, remote, "main", "def"); status != "pending" || gh.calls != 2+i {
+			t.Errorf("lookup %d of a new commit = %s after %d gh calls, want pending looked up every time", i+1, status, gh.calls)
+		}
+	}
+
+	refreshFlag = true
+	defer func() { refreshFlag = false }()
+	getRemoteCIStatusCached(ctx, repo, remote, "main", "abc")
+	if gh.calls != 4 {
+		t.Errorf("lookup with --refresh made %d gh calls in total, want 4", gh.calls)
+	}
+}
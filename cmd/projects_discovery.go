@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// projectsConfigKey is the config file section that says where git
+// repositories live
+const projectsConfigKey = "projects"
+
+// ProjectsConfig is the `projects:` section of ~/.allbctl.yaml
+type ProjectsConfig struct {
+	// Roots are the directories searched for repos; ~ is the home directory
+	Roots []string `mapstructure:"roots"`
+	// Exclude lists globs of directories not to search. A pattern without a
+	// slash matches a directory name anywhere (node_modules); one with a
+	// slash matches a path relative to its root (work/archive/*), or an
+	// absolute path when it starts with / or ~.
+	Exclude []string `mapstructure:"exclude"`
+	// MaxDepth is how many directories below a root a repo can be; 0 means
+	// any depth
+	MaxDepth int `mapstructure:"max_depth"`
+	// IncludeBare also lists bare repositories (e.g. mirror.git)
+	IncludeBare bool `mapstructure:"include_bare"`
+	// IncludeWorktrees also lists linked worktrees made by git worktree add
+	IncludeWorktrees bool `mapstructure:"include_worktrees"`
+	// Manifest is the workspace manifest 'allbctl projects sync' reads
+	Manifest string `mapstructure:"manifest"`
+	// ManifestAllowHome lets manifest paths point anywhere under the home
+	// directory, not just inside the roots
+	ManifestAllowHome bool `mapstructure:"manifest_allow_home"`
+}
+
+// defaultProjectsConfig is used without a `projects:` config section
+func defaultProjectsConfig() ProjectsConfig {
+	return ProjectsConfig{
+		Roots:    []string{"~/src"},
+		Exclude:  []string{"node_modules", "vendor"},
+		Manifest: "~/.allbctl-workspace.yaml",
+	}
+}
+
+// loadProjectsConfig reads the `projects:` section of the config file, if
+// any, with ~ in roots and exclude patterns expanded
+func loadProjectsConfig() (ProjectsConfig, error) {
+	config := defaultProjectsConfig()
+	if viper.IsSet(projectsConfigKey) {
+		if err := viper.UnmarshalKey(projectsConfigKey, &config); err != nil {
+			return config, fmt.Errorf("cannot read %s from %s: %w", projectsConfigKey, viper.ConfigFileUsed(), err)
+		}
+	}
+	if len(config.Roots) == 0 {
+		return config, fmt.Errorf("%s: %s.roots must list at least one directory", viper.ConfigFileUsed(), projectsConfigKey)
+	}
+	if config.MaxDepth < 0 {
+		return config, fmt.Errorf("%s: %s.max_depth must not be negative", viper.ConfigFileUsed(), projectsConfigKey)
+	}
+	for i, root := range config.Roots {
+		config.Roots[i] = expandHome(root)
+	}
+	config.Manifest = expandHome(config.Manifest)
+	for i, pattern := range config.Exclude {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return config, fmt.Errorf("%s: %s.exclude: bad pattern %q", viper.ConfigFileUsed(), projectsConfigKey, pattern)
+		}
+		if strings.HasPrefix(pattern, "~") {
+			config.Exclude[i] = filepath.ToSlash(expandHome(pattern))
+		}
+	}
+	return config, nil
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(p string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return p
+	}
+	if p == "~" {
+		return home
+	}
+	if strings.HasPrefix(p, "~/") || strings.HasPrefix(p, `~\`) {
+		return filepath.Join(home, p[2:])
+	}
+	return p
+}
+
+// displayRoots is how the roots are shown to the user, e.g. "~/src, ~/work"
+func (c ProjectsConfig) displayRoots() string {
+	roots := make([]string, len(c.Roots))
+	for i, root := range c.Roots {
+		roots[i] = formatRepoPath(root, false)
+	}
+	return strings.Join(roots, ", ")
+}
+
+// findRepos finds the repos under every root, each listed once even when
+// roots overlap. Roots that do not exist are skipped.
+func (c ProjectsConfig) findRepos() []string {
+	var repos []string
+	seen := map[string]bool{}
+	for _, root := range c.Roots {
+		for _, repo := range findGitRepos(root, c) {
+			if !seen[repo] {
+				seen[repo] = true
+				repos = append(repos, repo)
+			}
+		}
+	}
+	return repos
+}
+
+// excluded reports whether dir, found under root, matches an exclude pattern
+func (c ProjectsConfig) excluded(root, dir string) bool {
+	name := filepath.Base(dir)
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	abs := filepath.ToSlash(dir)
+	for _, pattern := range c.Exclude {
+		target := name
+		switch {
+		case strings.HasPrefix(pattern, "/"):
+			target = abs
+		case strings.Contains(pattern, "/"):
+			target = rel
+		}
+		if ok, _ := path.Match(pattern, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// findGitRepos recursively finds all git repositories in the given directory,
+// following the exclude, depth, bare and worktree rules of config
+func findGitRepos(rootDir string, config ProjectsConfig) []string {
+	var repos []string
+
+	err := filepath.WalkDir(rootDir, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if d.Name() == ".git" {
+			switch {
+			// Check if this directory is a git repo
+			case d.IsDir():
+				repos = append(repos, filepath.Dir(p))
+				return filepath.SkipDir
+			case config.IncludeWorktrees && isLinkedWorktree(p):
+				repos = append(repos, filepath.Dir(p))
+			}
+			return nil
+		}
+		if !d.IsDir() || p == rootDir {
+			return nil
+		}
+
+		if config.excluded(rootDir, p) {
+			return filepath.SkipDir
+		}
+		if config.MaxDepth > 0 && pathDepth(rootDir, p) > config.MaxDepth {
+			return filepath.SkipDir
+		}
+		if config.IncludeBare && isBareRepo(p) {
+			repos = append(repos, p)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+
+	if err != nil {
+		return []string{}
+	}
+
+	return repos
+}
+
+// pathDepth is how many directories dir is below root
+func pathDepth(root, dir string) int {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return len(strings.Split(rel, string(filepath.Separator)))
+}
+
+// isLinkedWorktree reports whether the .git file at gitFile points into
+// another repo's worktrees, as opposed to a submodule's .git file
+func isLinkedWorktree(gitFile string) bool {
+	data, err := os.ReadFile(gitFile)
+	if err != nil {
+		return false
+	}
+	gitdir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return false
+	}
+	parent := filepath.Base(filepath.Dir(filepath.Clean(strings.TrimSpace(gitdir))))
+	return parent == "worktrees"
+}
+
+// isBareRepo reports whether dir is a bare git repository: it holds HEAD,
+// objects and refs itself rather than in a .git directory
+func isBareRepo(dir string) bool {
+	if info, err := os.Stat(filepath.Join(dir, "HEAD")); err != nil || info.IsDir() {
+		return false
+	}
+	for _, sub := range []string{"objects", "refs"} {
+		if info, err := os.Stat(filepath.Join(dir, sub)); err != nil || !info.IsDir() {
+			return false
+		}
+	}
+	return true
+}
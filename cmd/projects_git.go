@@ -0,0 +1,363 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/aallbrig/allbctl/pkg/runner"
+)
+
+// Outcomes of a git operation on one project
+const (
+	projectGitDone     = "done"
+	projectGitUpToDate = "up-to-date"
+	projectGitSkipped  = "skipped"
+	projectGitFailed   = "failed"
+)
+
+var projectGitLabels = map[string]*color.Color{
+	projectGitDone:     color.New(color.FgGreen),
+	projectGitUpToDate: color.New(color.Faint),
+	projectGitSkipped:  color.New(color.FgYellow),
+	projectGitFailed:   color.New(color.FgRed),
+}
+
+// ProjectGitResult is what a git operation did to one project
+type ProjectGitResult struct {
+	Path   string `json:"path"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// ProjectsGitReport is the result of 'allbctl projects fetch|pull|push'
+type ProjectsGitReport struct {
+	Operation string             `json:"operation"`
+	Results   []ProjectGitResult `json:"results"`
+}
+
+var (
+	projectsGitDirty    bool
+	projectsGitClean    bool
+	projectsGitParallel int
+	projectsGitFFOnly   bool
+)
+
+// ProjectsGitCmd runs git operations across every discovered project
+var ProjectsGitCmd = &cobra.Command{
+	Use:   "projects",
+	Short: "Fetch, pull, push or sync the git repositories in the project roots",
+	Long: `Run a git operation across every repository 'allbctl status projects' finds
+(~/src, or projects.roots in ~/.allbctl.yaml), several at a time.
+
+pull and push leave alone repos with uncommitted changes, without an upstream,
+or whose branch has diverged from it; those are reported as skipped. Each repo
+gets a line in the result table. Exit status is 1 when an operation failed.
+
+Arguments to fetch, pull and push are globs matched against the repo's
+directory name or its path under a project root.
+
+sync clones the repos listed in the workspace manifest that are missing, and
+export-manifest writes that manifest from the repos already here.
+
+Examples:
+  allbctl projects fetch                 # Fetch every repo
+  allbctl projects pull --ff-only        # Fast-forward every clean repo
+  allbctl projects push --dirty          # Push repos with unpushed commits
+  allbctl projects pull 'allbctl*' 'go-*'
+  allbctl projects export-manifest > ~/.allbctl-workspace.yaml
+  allbctl projects sync                  # Clone what the manifest lists and is missing`,
+}
+
+var projectsFetchCmd = &cobra.Command{
+	Use:           "fetch [glob...]",
+	Short:         "Fetch every repo's remotes",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runProjectsGitCommand(cmd, args, "fetch", fetchProject)
+	},
+}
+
+var projectsPullCmd = &cobra.Command{
+	Use:           "pull [glob...]",
+	Short:         "Fast-forward every clean repo to its upstream",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !projectsGitFFOnly {
+			return errors.New("projects pull only fast-forwards; merge diverged branches in the repo itself")
+		}
+		return runProjectsGitCommand(cmd, args, "pull", pullProject)
+	},
+}
+
+var projectsPushCmd = &cobra.Command{
+	Use:           "push [glob...]",
+	Short:         "Push every repo with commits its upstream lacks",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runProjectsGitCommand(cmd, args, "push", pushProject)
+	},
+}
+
+func init() {
+	ProjectsGitCmd.AddCommand(projectsFetchCmd)
+	ProjectsGitCmd.AddCommand(projectsPullCmd)
+	ProjectsGitCmd.AddCommand(projectsPushCmd)
+	ProjectsGitCmd.PersistentFlags().VarP(&outputFormat, "output", "o", "Output format: text, json or yaml")
+	for _, c := range []*cobra.Command{projectsFetchCmd, projectsPullCmd, projectsPushCmd} {
+		c.Flags().BoolVar(&projectsGitDirty, "dirty", false, "Only repos that are dirty (uncommitted changes, unpushed commits or no upstream)")
+		c.Flags().BoolVar(&projectsGitClean, "clean", false, "Only clean repos")
+	}
+	ProjectsGitCmd.PersistentFlags().IntVar(&projectsGitParallel, "parallel", 4, "Maximum number of repos to work on at once")
+	projectsPullCmd.Flags().BoolVar(&projectsGitFFOnly, "ff-only", true, "Only fast-forward (the only mode supported)")
+}
+
+// runProjectsGitCommand runs op over the repos selected by the flags and args
+func runProjectsGitCommand(cmd *cobra.Command, args []string, name string, op func(context.Context, string) ProjectGitResult) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if projectsGitDirty && projectsGitClean {
+		return errors.New("--dirty and --clean cannot be used together")
+	}
+	for _, pattern := range args {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("bad glob %q", pattern)
+		}
+	}
+	config, err := loadProjectsConfig()
+	if err != nil {
+		return err
+	}
+
+	repos := selectProjects(ctx, config, args, projectsGitDirty, projectsGitClean)
+	report := &ProjectsGitReport{Operation: name, Results: runProjectsGit(ctx, repos, projectsGitParallel, op)}
+	if err := renderOutput(report, func() { printProjectsGitReport(report, config) }); err != nil {
+		return err
+	}
+	for _, r := range report.Results {
+		if r.Status == projectGitFailed {
+			return &exitCodeError{code: 1}
+		}
+	}
+	return nil
+}
+
+// selectProjects finds the repos under the project roots that match one of
+// the globs (all of them without any) and the --dirty or --clean filter
+func selectProjects(ctx context.Context, config ProjectsConfig, globs []string, dirty, clean bool) []string {
+	var selected []string
+	for _, root := range config.Roots {
+		for _, repo := range findGitRepos(root, config) {
+			if !matchesProjectGlob(root, repo, globs) {
+				continue
+			}
+			if dirty || clean {
+				isDirty := !isBareRepo(repo) && getDirtyReasons(ctx, repo) != 0
+				if isDirty != dirty {
+					continue
+				}
+			}
+			if !slices.Contains(selected, repo) {
+				selected = append(selected, repo)
+			}
+		}
+	}
+	return selected
+}
+
+// matchesProjectGlob reports whether repo, found under root, matches one of
+// globs by directory name or by path under the root
+func matchesProjectGlob(root, repo string, globs []string) bool {
+	if len(globs) == 0 {
+		return true
+	}
+	rel, err := filepath.Rel(root, repo)
+	if err != nil {
+		rel = repo
+	}
+	for _, glob := range globs {
+		if ok, _ := path.Match(glob, filepath.Base(repo)); ok {
+			return true
+		}
+		if ok, _ := path.Match(glob, filepath.ToSlash(rel)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// runProjectsGit runs op on every repo, at most parallel at a time, and
+// returns the results in the order of repos
+func runProjectsGit(ctx context.Context, repos []string, parallel int, op func(context.Context, string) ProjectGitResult) []ProjectGitResult {
+	if parallel < 1 {
+		parallel = 1
+	}
+	results := make([]ProjectGitResult, len(repos))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, repo := range repos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = op(ctx, repo)
+			results[i].Path = repo
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// fetchProject fetches every remote of repo, pruning deleted branches
+func fetchProject(ctx context.Context, repo string) ProjectGitResult {
+	if out, err := projectGit(ctx, repo, "fetch", "--all", "--prune"); err != nil {
+		return ProjectGitResult{Status: projectGitFailed, Detail: gitFailure(out, err)}
+	}
+	ahead, behind, err := upstreamDivergence(ctx, repo)
+	if err != nil || behind == 0 {
+		return ProjectGitResult{Status: projectGitDone, Detail: "fetched"}
+	}
+	return ProjectGitResult{Status: projectGitDone, Detail: divergenceDetail(ahead, behind)}
+}
+
+// pullProject fast-forwards repo's branch to its upstream
+func pullProject(ctx context.Context, repo string) ProjectGitResult {
+	if skip := checkProjectSyncable(ctx, repo); skip != "" {
+		return ProjectGitResult{Status: projectGitSkipped, Detail: skip}
+	}
+	if out, err := projectGit(ctx, repo, "fetch", "--prune"); err != nil {
+		return ProjectGitResult{Status: projectGitFailed, Detail: gitFailure(out, err)}
+	}
+	ahead, behind, err := upstreamDivergence(ctx, repo)
+	switch {
+	case err != nil:
+		return ProjectGitResult{Status: projectGitFailed, Detail: err.Error()}
+	case ahead > 0 && behind > 0:
+		return ProjectGitResult{Status: projectGitSkipped, Detail: "diverged: " + divergenceDetail(ahead, behind)}
+	case behind == 0:
+		return ProjectGitResult{Status: projectGitUpToDate}
+	}
+	if out, err := projectGit(ctx, repo, "merge", "--ff-only", "@{u}"); err != nil {
+		return ProjectGitResult{Status: projectGitFailed, Detail: gitFailure(out, err)}
+	}
+	return ProjectGitResult{Status: projectGitDone, Detail: fmt.Sprintf("pulled %d commit(s)", behind)}
+}
+
+// pushProject pushes repo's branch to its upstream
+func pushProject(ctx context.Context, repo string) ProjectGitResult {
+	if skip := checkProjectSyncable(ctx, repo); skip != "" {
+		return ProjectGitResult{Status: projectGitSkipped, Detail: skip}
+	}
+	if out, err := projectGit(ctx, repo, "fetch", "--prune"); err != nil {
+		return ProjectGitResult{Status: projectGitFailed, Detail: gitFailure(out, err)}
+	}
+	ahead, behind, err := upstreamDivergence(ctx, repo)
+	switch {
+	case err != nil:
+		return ProjectGitResult{Status: projectGitFailed, Detail: err.Error()}
+	case ahead > 0 && behind > 0:
+		return ProjectGitResult{Status: projectGitSkipped, Detail: "diverged: " + divergenceDetail(ahead, behind)}
+	case ahead == 0:
+		return ProjectGitResult{Status: projectGitUpToDate}
+	}
+	if out, err := projectGit(ctx, repo, "push"); err != nil {
+		return ProjectGitResult{Status: projectGitFailed, Detail: gitFailure(out, err)}
+	}
+	return ProjectGitResult{Status: projectGitDone, Detail: fmt.Sprintf("pushed %d commit(s)", ahead)}
+}
+
+// checkProjectSyncable says why repo should not be pulled or pushed, or ""
+// when it can be
+func checkProjectSyncable(ctx context.Context, repo string) string {
+	if isBareRepo(repo) {
+		return "bare repository"
+	}
+	status, err := projectGit(ctx, repo, "status", "--porcelain")
+	if err != nil {
+		return "not a work tree"
+	}
+	if status != "" {
+		return "uncommitted changes"
+	}
+	if _, err := projectGit(ctx, repo, "rev-parse", "--abbrev-ref", "@{u}"); err != nil {
+		return "no upstream"
+	}
+	return ""
+}
+
+// upstreamDivergence counts the commits repo's branch and its upstream each
+// have that the other lacks
+func upstreamDivergence(ctx context.Context, repo string) (ahead, behind int, err error) {
+	out, err := projectGit(ctx, repo, "rev-list", "--left-right", "--count", "HEAD...@{u}")
+	if err != nil {
+		return 0, 0, fmt.Errorf("cannot compare with upstream: %s", gitFailure(out, err))
+	}
+	fields := strings.Fields(out)
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("cannot compare with upstream: unexpected output %q", out)
+	}
+	ahead, _ = strconv.Atoi(fields[0])
+	behind, _ = strconv.Atoi(fields[1])
+	return ahead, behind, nil
+}
+
+func divergenceDetail(ahead, behind int) string {
+	var parts []string
+	if ahead > 0 {
+		parts = append(parts, fmt.Sprintf("%d ahead", ahead))
+	}
+	if behind > 0 {
+		parts = append(parts, fmt.Sprintf("%d behind", behind))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// projectGit runs git in repo, returning its trimmed output
+func projectGit(ctx context.Context, repo string, args ...string) (string, error) {
+	out, err := runner.CombinedOutput(ctx, "git", append([]string{"-C", repo}, args...)...)
+	return strings.TrimSpace(string(out)), err
+}
+
+// gitFailure is the last line git printed, which names the problem, or err
+func gitFailure(out string, err error) string {
+	if out == "" {
+		return err.Error()
+	}
+	lines := strings.Split(out, "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+func printProjectsGitReport(report *ProjectsGitReport, config ProjectsConfig) {
+	if len(report.Results) == 0 {
+		fmt.Printf("No git repositories found in %s\n", config.displayRoots())
+		return
+	}
+
+	width := 0
+	for _, r := range report.Results {
+		width = max(width, len(formatRepoPath(r.Path, false)))
+	}
+	counts := map[string]int{}
+	for _, r := range report.Results {
+		counts[r.Status]++
+		label := projectGitLabels[r.Status].Sprintf("%-10s", r.Status)
+		fmt.Println(strings.TrimRight(fmt.Sprintf("%-*s  %s %s", width, formatRepoPath(r.Path, false), label, r.Detail), " "))
+	}
+	fmt.Printf("\n%s: %d done, %d up to date, %d skipped, %d failed\n", report.Operation,
+		counts[projectGitDone], counts[projectGitUpToDate], counts[projectGitSkipped], counts[projectGitFailed])
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aallbrig/allbctl/pkg/runner"
+	"github.com/spf13/cobra"
+)
+
+// gitIn runs git in dir, failing the test when it fails
+func gitIn(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	args = append([]string{"-C", dir, "-c", "user.email=test@test.com", "-c", "user.name=Test"}, args...)
+	out, err := runner.CombinedOutput(context.Background(), "git", args...)
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return string(out)
+}
+
+// commitFile writes a file in repo and commits it
+func commitFile(t *testing.T, repo, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(repo, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitIn(t, repo, "add", name)
+	gitIn(t, repo, "commit", "-m", "change "+name)
+}
+
+// cloneProjects makes a bare remote with one commit and clones it once per
+// name, returning the clones' paths
+func cloneProjects(t *testing.T, names ...string) []string {
+	t.Helper()
+	if !runner.Available(context.Background(), "git") {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	remote := filepath.Join(dir, "remote.git")
+	seed := filepath.Join(dir, "seed")
+	gitIn(t, dir, "init", "--bare", remote)
+	gitIn(t, dir, "clone", remote, seed)
+	commitFile(t, seed, "README", "hello")
+	gitIn(t, seed, "push", "-u", "origin", "HEAD")
+
+	var clones []string
+	for _, name := range names {
+		clone := filepath.Join(dir, "src", name)
+		gitIn(t, dir, "clone", remote, clone)
+		clones = append(clones, clone)
+	}
+	return clones
+}
+
+func TestPullProject(t *testing.T) {
+	ctx := context.Background()
+	clones := cloneProjects(t, "pusher", "behind", "dirty", "diverged")
+	pusher, behind, dirty, diverged := clones[0], clones[1], clones[2], clones[3]
+
+	commitFile(t, pusher, "a.txt", "a")
+	gitIn(t, pusher, "push")
+	if err := os.WriteFile(filepath.Join(dirty, "README"), []byte("edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, diverged, "b.txt", "b")
+
+	cases := []struct {
+		repo       string
+		wantStatus string
+		wantDetail string
+	}{
+		{behind, projectGitDone, "pulled 1 commit(s)"},
+		{dirty, projectGitSkipped, "uncommitted changes"},
+		{diverged, projectGitSkipped, "diverged: 1 ahead, 1 behind"},
+		{pusher, projectGitUpToDate, ""},
+	}
+	for _, tc := range cases {
+		got := pullProject(ctx, tc.repo)
+		if got.Status != tc.wantStatus || got.Detail != tc.wantDetail {
+			t.Errorf("pullProject(%s) = %+v, want %s %q", filepath.Base(tc.repo), got, tc.wantStatus, tc.wantDetail)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(behind, "a.txt")); err != nil {
+		t.Errorf("pull did not bring in the pushed commit: %v", err)
+	}
+	if got := pullProject(ctx, behind); got.Status != projectGitUpToDate {
+		t.Errorf("second pullProject() = %+v, want up-to-date", got)
+	}
+}
+
+func TestPushProject(t *testing.T) {
+	ctx := context.Background()
+	clones := cloneProjects(t, "ahead", "other", "local")
+	ahead, other, local := clones[0], clones[1], clones[2]
+
+	commitFile(t, ahead, "a.txt", "a")
+	gitIn(t, local, "checkout", "-b", "topic")
+	commitFile(t, local, "c.txt", "c")
+
+	if got := pushProject(ctx, ahead); got.Status != projectGitDone || got.Detail != "pushed 1 commit(s)" {
+		t.Errorf("pushProject(ahead) = %+v, want pushed 1 commit(s)", got)
+	}
+	if got := pushProject(ctx, ahead); got.Status != projectGitUpToDate {
+		t.Errorf("second pushProject(ahead) = %+v, want up-to-date", got)
+	}
+	// other is now behind and has nothing of its own to push
+	if got := pushProject(ctx, other); got.Status != projectGitUpToDate {
+		t.Errorf("pushProject(other) = %+v, want up-to-date", got)
+	}
+	commitFile(t, other, "b.txt", "b")
+	if got := pushProject(ctx, other); got.Status != projectGitSkipped || !strings.HasPrefix(got.Detail, "diverged") {
+		t.Errorf("pushProject(diverged) = %+v, want skipped as diverged", got)
+	}
+	if got := pushProject(ctx, local); got.Status != projectGitSkipped || got.Detail != "no upstream" {
+		t.Errorf("pushProject(no upstream) = %+v, want skipped, no upstream", got)
+	}
+
+	if got := fetchProject(ctx, other); got.Status != projectGitDone || got.Detail != "1 ahead, 1 behind" {
+		t.Errorf("fetchProject(other) = %+v, want done, 1 ahead, 1 behind", got)
+	}
+}
+
+func TestSelectProjects(t *testing.T) {
+	ctx := context.Background()
+	clones := cloneProjects(t, "allbctl", "dotfiles", "go-tools")
+	if err := os.WriteFile(filepath.Join(clones[1], "new.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := ProjectsConfig{Roots: []string{filepath.Dir(clones[0])}}
+
+	names := func(repos []string) string {
+		var base []string
+		for _, repo := range repos {
+			base = append(base, filepath.Base(repo))
+		}
+		return strings.Join(base, ",")
+	}
+	cases := []struct {
+		globs        []string
+		dirty, clean bool
+		want         string
+	}{
+		{nil, false, false, "allbctl,dotfiles,go-tools"},
+		{[]string{"go-*", "allbctl"}, false, false, "allbctl,go-tools"},
+		{nil, true, false, "dotfiles"},
+		{[]string{"*o*"}, false, true, "go-tools"},
+	}
+	for _, tc := range cases {
+		if got := names(selectProjects(ctx, config, tc.globs, tc.dirty, tc.clean)); got != tc.want {
+			t.Errorf("selectProjects(%v, dirty=%v, clean=%v) = %s, want %s", tc.globs, tc.dirty, tc.clean, got, tc.want)
+		}
+	}
+}
+
+func TestRunProjectsGit(t *testing.T) {
+	repos := []string{"/src/a", "/src/b", "/src/c"}
+	results := runProjectsGit(context.Background(), repos, 2, func(_ context.Context, repo string) ProjectGitResult {
+		if repo == "/src/b" {
+			return ProjectGitResult{Status: projectGitFailed, Detail: "boom"}
+		}
+		return ProjectGitResult{Status: projectGitDone}
+	})
+	if len(results) != 3 || results[0].Path != "/src/a" || results[1].Status != projectGitFailed || results[2].Path != "/src/c" {
+		t.Errorf("runProjectsGit() = %+v, want results in repo order", results)
+	}
+}
+
+// The commands report failures per repo and exit 1; cobra must not print the
+// exit status on top of that
+func TestProjectsGitCommands_SilenceErrors(t *testing.T) {
+	for _, cmd := range []*cobra.Command{projectsFetchCmd, projectsPullCmd, projectsPushCmd} {
+		if !cmd.SilenceErrors || !cmd.SilenceUsage {
+			t.Errorf("projects %s: SilenceErrors = %v, SilenceUsage = %v, want both set", cmd.Name(), cmd.SilenceErrors, cmd.SilenceUsage)
+		}
+	}
+}
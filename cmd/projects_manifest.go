@@ -0,0 +1,378 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/aallbrig/allbctl/pkg/externalcmd"
+)
+
+// WorkspaceManifest lists the repos a machine should have
+type WorkspaceManifest struct {
+	Repos []ManifestRepo `json:"repos" yaml:"repos"`
+}
+
+// ManifestRepo is one repo of the workspace manifest
+type ManifestRepo struct {
+	URL string `json:"url" yaml:"url"`
+	// Path is where the repo lives: relative to the first project root, or
+	// absolute or under ~ for repos in the other roots. It must resolve inside
+	// a project root, or anywhere under ~ with projects.manifest_allow_home.
+	Path string `json:"path" yaml:"path"`
+	// Branch is checked out after cloning; empty means the remote's default
+	Branch string `json:"branch,omitempty" yaml:"branch,omitempty"`
+}
+
+// ProjectsSyncReport is the result of 'allbctl projects sync'
+type ProjectsSyncReport struct {
+	Manifest string             `json:"manifest"`
+	Results  []ProjectGitResult `json:"results"`
+	// Unlisted are the local repos the manifest does not list
+	Unlisted []string `json:"unlisted"`
+}
+
+var (
+	projectsManifest     string
+	projectsSyncDryRun   bool
+	projectsSyncHome     bool
+	projectsManifestFile string
+)
+
+// cloneProject clones url into dir. Tests replace it.
+var cloneProject = func(ctx context.Context, dir, url, branch string) error {
+	client := externalcmd.GitClient{}
+	_, err := client.PlainCloneContext(ctx, dir, url, branch)
+	return err
+}
+
+var projectsSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Clone the manifest's missing repos and list local repos it lacks",
+	Long: `Read the workspace manifest (projects.manifest in ~/.allbctl.yaml, by default
+~/.allbctl-workspace.yaml) and clone every repo it lists that is not here yet.
+Repos already present are left alone. Local repos the manifest does not list
+are reported, so nothing goes missing when the manifest is used elsewhere.
+
+Every path must resolve inside a project root; a manifest listing one that
+does not is rejected before anything is cloned. --allow-home (or
+projects.manifest_allow_home) also accepts paths elsewhere under ~.
+
+Examples:
+  allbctl projects sync --dry-run        # Show what would be cloned
+  allbctl projects sync --manifest ~/dotfiles/workspace.yaml`,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		config, err := loadProjectsConfig()
+		if err != nil {
+			return err
+		}
+		if projectsManifest != "" {
+			config.Manifest = expandHome(projectsManifest)
+		}
+		if projectsSyncHome {
+			config.ManifestAllowHome = true
+		}
+		manifest, err := loadWorkspaceManifest(config.Manifest)
+		if err != nil {
+			return err
+		}
+
+		report, err := syncWorkspace(ctx, config, manifest, projectsGitParallel, projectsSyncDryRun)
+		if err != nil {
+			return err
+		}
+		if err := renderOutput(report, func() { printProjectsSyncReport(report) }); err != nil {
+			return err
+		}
+		for _, r := range report.Results {
+			if r.Status == projectGitFailed {
+				return &exitCodeError{code: 1}
+			}
+		}
+		return nil
+	},
+}
+
+var projectsExportManifestCmd = &cobra.Command{
+	Use:   "export-manifest",
+	Short: "Write a workspace manifest listing the repos in the project roots",
+	Long: `Write a workspace manifest with the origin URL, path and branch of every repo in
+the project roots, so 'allbctl projects sync' can reproduce this layout on
+another machine. Repos without an origin remote, bare repos and worktrees are
+left out, with a note on stderr. The manifest is YAML (JSON with -o json),
+written to stdout unless --file is given.
+
+Examples:
+  allbctl projects export-manifest > ~/.allbctl-workspace.yaml
+  allbctl projects export-manifest --file ~/dotfiles/workspace.yaml`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		config, err := loadProjectsConfig()
+		if err != nil {
+			return err
+		}
+
+		manifest, notes := buildWorkspaceManifest(ctx, config)
+		for _, note := range notes {
+			fmt.Fprintln(os.Stderr, note)
+		}
+		format := outputYAML
+		if outputFormat == outputJSON {
+			format = outputJSON
+		}
+		if projectsManifestFile == "" {
+			return writeStructured(os.Stdout, format, manifest)
+		}
+		return writeManifestFile(expandHome(projectsManifestFile), format, manifest)
+	},
+}
+
+func init() {
+	ProjectsGitCmd.AddCommand(projectsSyncCmd)
+	ProjectsGitCmd.AddCommand(projectsExportManifestCmd)
+	projectsSyncCmd.Flags().StringVar(&projectsManifest, "manifest", "", "Workspace manifest to read (default: projects.manifest from config, or ~/.allbctl-workspace.yaml)")
+	projectsSyncCmd.Flags().BoolVar(&projectsSyncDryRun, "dry-run", false, "Show what would be cloned without cloning")
+	projectsSyncCmd.Flags().BoolVar(&projectsSyncHome, "allow-home", false, "Accept manifest paths anywhere under ~, not just inside the project roots")
+	projectsExportManifestCmd.Flags().StringVarP(&projectsManifestFile, "file", "f", "", "Write the manifest to this file instead of stdout")
+}
+
+// loadWorkspaceManifest reads a workspace manifest (YAML, or JSON, which is
+// valid YAML)
+func loadWorkspaceManifest(path string) (*WorkspaceManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("no workspace manifest at %s (create one with 'allbctl projects export-manifest')", path)
+		}
+		return nil, fmt.Errorf("cannot read workspace manifest: %w", err)
+	}
+	var manifest WorkspaceManifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("cannot parse workspace manifest %s: %w", path, err)
+	}
+	paths := map[string]bool{}
+	for i, repo := range manifest.Repos {
+		if repo.URL == "" || repo.Path == "" {
+			return nil, fmt.Errorf("%s: repos[%d] needs both url and path", path, i)
+		}
+		if paths[repo.Path] {
+			return nil, fmt.Errorf("%s: path %s is listed more than once", path, repo.Path)
+		}
+		paths[repo.Path] = true
+	}
+	return &manifest, nil
+}
+
+// writeManifestFile writes manifest to path in format
+func writeManifestFile(path, format string, manifest *WorkspaceManifest) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("cannot write workspace manifest: %w", err)
+	}
+	if err := writeStructured(f, format, manifest); err != nil {
+		_ = f.Close() //nolint:errcheck // The write error is the one to report
+		return err
+	}
+	return f.Close()
+}
+
+// manifestRepoDir is where a manifest path lives on this machine. Paths that
+// resolve outside the project roots (absolute ones, or ones climbing out with
+// ..) are refused, since the manifest may come from another machine; with
+// ManifestAllowHome, anywhere under the home directory is accepted too.
+func manifestRepoDir(config ProjectsConfig, p string) (string, error) {
+	dir := expandHome(p)
+	if filepath.IsAbs(dir) {
+		dir = filepath.Clean(dir)
+	} else {
+		dir = filepath.Join(config.Roots[0], filepath.FromSlash(dir))
+	}
+	for _, root := range config.Roots {
+		if isWithinDir(root, dir) {
+			return dir, nil
+		}
+	}
+	if config.ManifestAllowHome {
+		if home, err := os.UserHomeDir(); err == nil && isWithinDir(home, dir) {
+			return dir, nil
+		}
+		return "", fmt.Errorf("path %s is outside the home directory", p)
+	}
+	return "", fmt.Errorf("path %s is outside the project roots (%s); pass --allow-home to accept paths elsewhere under ~", p, config.displayRoots())
+}
+
+// isWithinDir reports whether p is below dir (and not dir itself)
+func isWithinDir(dir, p string) bool {
+	rel, err := filepath.Rel(dir, p)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// manifestPath is how repo is written in a manifest: relative to the first
+// project root when it is under it, otherwise under ~ or absolute
+func manifestPath(config ProjectsConfig, repo string) string {
+	if rel, err := filepath.Rel(config.Roots[0], repo); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return formatRepoPath(repo, false)
+}
+
+// syncWorkspace clones the repos in manifest that are missing, at most
+// parallel at a time, and lists the local repos manifest does not have. It
+// clones nothing when any manifest path is refused by manifestRepoDir.
+func syncWorkspace(ctx context.Context, config ProjectsConfig, manifest *WorkspaceManifest, parallel int, dryRun bool) (*ProjectsSyncReport, error) {
+	report := &ProjectsSyncReport{Manifest: config.Manifest, Unlisted: []string{}}
+
+	entries := map[string]ManifestRepo{}
+	dirs := make([]string, 0, len(manifest.Repos))
+	for _, repo := range manifest.Repos {
+		dir, err := manifestRepoDir(config, repo.Path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", config.Manifest, err)
+		}
+		if _, listed := entries[dir]; listed {
+			return nil, fmt.Errorf("%s: path %s is listed more than once", config.Manifest, repo.Path)
+		}
+		entries[dir] = repo
+		dirs = append(dirs, dir)
+	}
+	report.Results = runProjectsGit(ctx, dirs, parallel, func(ctx context.Context, dir string) ProjectGitResult {
+		return syncManifestRepo(ctx, dir, entries[dir], dryRun)
+	})
+
+	for _, repo := range config.findRepos() {
+		if _, listed := entries[repo]; !listed {
+			report.Unlisted = append(report.Unlisted, repo)
+		}
+	}
+	return report, nil
+}
+
+// syncManifestRepo clones one manifest repo into dir unless it is there
+func syncManifestRepo(ctx context.Context, dir string, repo ManifestRepo, dryRun bool) ProjectGitResult {
+	if info, err := os.Stat(dir); err == nil {
+		switch {
+		case !info.IsDir():
+			return ProjectGitResult{Status: projectGitFailed, Detail: "path exists and is not a directory"}
+		case isGitRepo(dir):
+			if origin := getRemoteRepo(ctx, dir); origin != "" && origin != parseRemoteRepo(repo.URL) {
+				return ProjectGitResult{Status: projectGitUpToDate, Detail: "present, but origin is " + origin}
+			}
+			return ProjectGitResult{Status: projectGitUpToDate, Detail: "present"}
+		case !isEmptyDir(dir):
+			return ProjectGitResult{Status: projectGitFailed, Detail: "path exists and is not a git repository"}
+		}
+	}
+
+	if dryRun {
+		return ProjectGitResult{Status: projectGitSkipped, Detail: "would clone " + repo.URL}
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return ProjectGitResult{Status: projectGitFailed, Detail: err.Error()}
+	}
+	if err := cloneProject(ctx, dir, repo.URL, repo.Branch); err != nil {
+		return ProjectGitResult{Status: projectGitFailed, Detail: fmt.Sprintf("clone %s: %v", repo.URL, err)}
+	}
+	detail := "cloned " + repo.URL
+	if repo.Branch != "" {
+		detail += " (" + repo.Branch + ")"
+	}
+	return ProjectGitResult{Status: projectGitDone, Detail: detail}
+}
+
+// buildWorkspaceManifest lists the repos in the project roots as a manifest,
+// along with notes on those it had to leave out
+func buildWorkspaceManifest(ctx context.Context, config ProjectsConfig) (*WorkspaceManifest, []string) {
+	manifest := &WorkspaceManifest{Repos: []ManifestRepo{}}
+	var notes []string
+	for _, repo := range config.findRepos() {
+		display := formatRepoPath(repo, false)
+		if info, err := os.Stat(filepath.Join(repo, ".git")); err != nil || !info.IsDir() {
+			notes = append(notes, fmt.Sprintf("%s: left out, not a plain clone (bare repo or worktree)", display))
+			continue
+		}
+		url, err := projectGit(ctx, repo, "remote", "get-url", "origin")
+		if err != nil || url == "" {
+			notes = append(notes, fmt.Sprintf("%s: left out, no origin remote", display))
+			continue
+		}
+		manifest.Repos = append(manifest.Repos, ManifestRepo{
+			URL:    url,
+			Path:   manifestPath(config, repo),
+			Branch: nonDefaultBranch(ctx, repo),
+		})
+	}
+	return manifest, notes
+}
+
+// nonDefaultBranch is the branch checked out in repo when it is not the one
+// a clone of origin gets anyway, otherwise ""
+func nonDefaultBranch(ctx context.Context, repo string) string {
+	branch, err := projectGit(ctx, repo, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil || branch == "HEAD" {
+		return ""
+	}
+	if head, err := projectGit(ctx, repo, "symbolic-ref", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		if strings.TrimPrefix(head, "origin/") == branch {
+			return ""
+		}
+		return branch
+	}
+	if branch == "main" || branch == "master" {
+		return ""
+	}
+	return branch
+}
+
+// isGitRepo reports whether dir is a git repository, bare or not
+func isGitRepo(dir string) bool {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		return true
+	}
+	return isBareRepo(dir)
+}
+
+// isEmptyDir reports whether dir has nothing in it
+func isEmptyDir(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	return err == nil && len(entries) == 0
+}
+
+func printProjectsSyncReport(report *ProjectsSyncReport) {
+	if len(report.Results) == 0 {
+		fmt.Printf("%s lists no repos\n", formatRepoPath(report.Manifest, false))
+	}
+	width := 0
+	for _, r := range report.Results {
+		width = max(width, len(formatRepoPath(r.Path, false)))
+	}
+	counts := map[string]int{}
+	for _, r := range report.Results {
+		counts[r.Status]++
+		label := projectGitLabels[r.Status].Sprintf("%-10s", r.Status)
+		fmt.Println(strings.TrimRight(fmt.Sprintf("%-*s  %s %s", width, formatRepoPath(r.Path, false), label, r.Detail), " "))
+	}
+	if len(report.Unlisted) > 0 {
+		fmt.Printf("\nNot in %s:\n", formatRepoPath(report.Manifest, false))
+		for _, repo := range report.Unlisted {
+			fmt.Printf("  %s\n", formatRepoPath(repo, false))
+		}
+	}
+	fmt.Printf("\nsync: %d cloned, %d present, %d skipped, %d failed; %d local repo(s) not in the manifest\n",
+		counts[projectGitDone], counts[projectGitUpToDate], counts[projectGitSkipped], counts[projectGitFailed], len(report.Unlisted))
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWorkspaceManifestSync(t *testing.T) {
+	ctx := context.Background()
+	clones := cloneProjects(t, "allbctl", "dotfiles")
+	gitIn(t, clones[1], "checkout", "-b", "laptop")
+	gitIn(t, clones[1], "push", "-u", "origin", "laptop")
+
+	// Export the layout of this "laptop"
+	laptop := ProjectsConfig{Roots: []string{filepath.Dir(clones[0])}}
+	manifest, notes := buildWorkspaceManifest(ctx, laptop)
+	if len(notes) != 0 || len(manifest.Repos) != 2 {
+		t.Fatalf("buildWorkspaceManifest() = %+v, notes %v", manifest, notes)
+	}
+	for _, repo := range manifest.Repos {
+		switch repo.Path {
+		case "allbctl":
+			if repo.Branch != "" {
+				t.Errorf("allbctl Branch = %q, want the default branch left out", repo.Branch)
+			}
+		case "dotfiles":
+			if repo.Branch != "laptop" {
+				t.Errorf("dotfiles Branch = %q, want laptop", repo.Branch)
+			}
+		default:
+			t.Errorf("unexpected manifest path %q", repo.Path)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "workspace.yaml")
+	if err := writeManifestFile(path, outputYAML, manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reproduce it on a "desktop" that already has one repo of its own
+	desktop := ProjectsConfig{Roots: []string{filepath.Join(t.TempDir(), "src")}, Manifest: path}
+	gitIn(t, t.TempDir(), "init", filepath.Join(desktop.Roots[0], "scratch"))
+	loaded, err := loadWorkspaceManifest(path)
+	if err != nil {
+		t.Fatalf("loadWorkspaceManifest() error = %v", err)
+	}
+
+	dryRun, err := syncWorkspace(ctx, desktop, loaded, 2, true)
+	if err != nil {
+		t.Fatalf("syncWorkspace() error = %v", err)
+	}
+	for _, r := range dryRun.Results {
+		if r.Status != projectGitSkipped || !strings.HasPrefix(r.Detail, "would clone") {
+			t.Errorf("dry run result = %+v, want would clone", r)
+		}
+	}
+
+	report, err := syncWorkspace(ctx, desktop, loaded, 2, false)
+	if err != nil {
+		t.Fatalf("syncWorkspace() error = %v", err)
+	}
+	for _, r := range report.Results {
+		if r.Status != projectGitDone {
+			t.Errorf("sync result = %+v, want cloned", r)
+		}
+	}
+	if len(report.Unlisted) != 1 || filepath.Base(report.Unlisted[0]) != "scratch" {
+		t.Errorf("Unlisted = %v, want [scratch]", report.Unlisted)
+	}
+	if branch := strings.TrimSpace(gitIn(t, filepath.Join(desktop.Roots[0], "dotfiles"), "rev-parse", "--abbrev-ref", "HEAD")); branch != "laptop" {
+		t.Errorf("dotfiles cloned on %q, want laptop", branch)
+	}
+
+	again, err := syncWorkspace(ctx, desktop, loaded, 2, false)
+	if err != nil {
+		t.Fatalf("syncWorkspace() error = %v", err)
+	}
+	for _, r := range again.Results {
+		if r.Status != projectGitUpToDate || r.Detail != "present" {
+			t.Errorf("second sync result = %+v, want present", r)
+		}
+	}
+}
+
+func TestSyncManifestRepoConflicts(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	notRepo := filepath.Join(dir, "not-a-repo")
+	empty := filepath.Join(dir, "empty")
+	for _, d := range []string{notRepo, empty} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	//nolint:errcheck // Test setup
+	_ = os.WriteFile(file, nil, 0644)
+	_ = os.WriteFile(filepath.Join(notRepo, "notes.txt"), nil, 0644) //nolint:errcheck // Test setup
+
+	var cloned []string
+	original := cloneProject
+	defer func() { cloneProject = original }()
+	cloneProject = func(_ context.Context, dir, url, branch string) error {
+		cloned = append(cloned, dir)
+		return nil
+	}
+
+	repo := ManifestRepo{URL: "git@github.com:aallbrig/allbctl.git", Path: "allbctl"}
+	if got := syncManifestRepo(ctx, file, repo, false); got.Status != projectGitFailed {
+		t.Errorf("syncManifestRepo(file) = %+v, want failed", got)
+	}
+	if got := syncManifestRepo(ctx, notRepo, repo, false); got.Status != projectGitFailed {
+		t.Errorf("syncManifestRepo(not a repo) = %+v, want failed", got)
+	}
+	if got := syncManifestRepo(ctx, empty, repo, false); got.Status != projectGitDone {
+		t.Errorf("syncManifestRepo(empty dir) = %+v, want cloned", got)
+	}
+	if len(cloned) != 1 || cloned[0] != empty {
+		t.Errorf("cloned = %v, want only the empty directory", cloned)
+	}
+}
+
+func TestLoadWorkspaceManifest(t *testing.T) {
+	dir := t.TempDir()
+	cases := map[string]string{
+		"ok":        "repos:\n  - url: https://github.com/aallbrig/allbctl.git\n    path: allbctl\n  - url: git@github.com:aallbrig/dotfiles.git\n    path: ~/dotfiles\n    branch: main\n",
+		"no path":   "repos:\n  - url: https://github.com/aallbrig/allbctl.git\n",
+		"duplicate": "repos:\n  - {url: a, path: x}\n  - {url: b, path: x}\n",
+	}
+	for name, content := range cases {
+		path := filepath.Join(dir, name+".yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		manifest, err := loadWorkspaceManifest(path)
+		if name == "ok" {
+			if err != nil || len(manifest.Repos) != 2 || manifest.Repos[1].Branch != "main" {
+				t.Errorf("loadWorkspaceManifest(ok) = %+v, %v", manifest, err)
+			}
+		} else if err == nil {
+			t.Errorf("loadWorkspaceManifest(%s) should fail", name)
+		}
+	}
+	if _, err := loadWorkspaceManifest(filepath.Join(dir, "missing.yaml")); err == nil || !strings.Contains(err.Error(), "export-manifest") {
+		t.Errorf("loadWorkspaceManifest(missing) = %v, want a hint to export one", err)
+	}
+}
+
+func TestManifestPaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	config := ProjectsConfig{Roots: []string{filepath.Join(home, "src"), filepath.Join(home, "work")}}
+
+	cases := map[string]string{
+		filepath.Join(home, "src", "org", "repo"): "org/repo",
+		filepath.Join(home, "work", "api"):        "~/work/api",
+	}
+	for repo, want := range cases {
+		got := manifestPath(config, repo)
+		if got != want {
+			t.Errorf("manifestPath(%s) = %s, want %s", repo, got, want)
+		}
+		if back, err := manifestRepoDir(config, got); err != nil || back != repo {
+			t.Errorf("manifestRepoDir(%s) = %s, %v, want %s", got, back, err, repo)
+		}
+	}
+}
+
+func TestManifestRepoDir_Confined(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	config := ProjectsConfig{Roots: []string{filepath.Join(home, "src")}}
+
+	for _, p := range []string{"../.ssh", "org/../../etc", "~/dotfiles", "~", "~/src", filepath.Join(t.TempDir(), "repo")} {
+		if dir, err := manifestRepoDir(config, p); err == nil {
+			t.Errorf("manifestRepoDir(%s) = %s, want it refused as outside the roots", p, dir)
+		}
+	}
+	if dir, err := manifestRepoDir(config, "org/../repo"); err != nil || dir != filepath.Join(home, "src", "repo") {
+		t.Errorf("manifestRepoDir(org/../repo) = %s, %v, want it cleaned", dir, err)
+	}
+
+	config.ManifestAllowHome = true
+	if dir, err := manifestRepoDir(config, "~/dotfiles"); err != nil || dir != filepath.Join(home, "dotfiles") {
+		t.Errorf("manifestRepoDir(~/dotfiles) with allow home = %s, %v", dir, err)
+	}
+	if dir, err := manifestRepoDir(config, "../../outside"); err == nil {
+		t.Errorf("manifestRepoDir(../../outside) with allow home = %s, want it refused as outside ~", dir)
+	}
+
+	// Nothing is cloned when any entry is refused
+	original := cloneProject
+	defer func() { cloneProject = original }()
+	cloneProject = func(context.Context, string, string, string) error {
+		t.Error("syncWorkspace() cloned despite a refused path")
+		return nil
+	}
+	config.ManifestAllowHome = false
+	manifest := &WorkspaceManifest{Repos: []ManifestRepo{{URL: "a", Path: "ok"}, {URL: "b", Path: "../escape"}}}
+	if _, err := syncWorkspace(context.Background(), config, manifest, 2, false); err == nil || !strings.Contains(err.Error(), "../escape") {
+		t.Errorf("syncWorkspace() error = %v, want ../escape refused", err)
+	}
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aallbrig/allbctl/pkg/runner"
+)
+
+// perfRepoCount is how many repos the projects benchmarks scan
+const perfRepoCount = 40
+
+// makePerfRepos clones a seeded remote n times, leaving every third clone
+// with an unpushed commit and an untracked file, and every fifth without an
+// upstream
+func makePerfRepos(tb testing.TB, n int) []string {
+	tb.Helper()
+	ctx := context.Background()
+	if !runner.Available(ctx, "git") {
+		tb.Skip("git not available")
+	}
+	git := func(dir string, args ...string) {
+		args = append([]string{"-C", dir, "-c", "user.email=test@test.com", "-c", "user.name=Test"}, args...)
+		if out, err := runner.CombinedOutput(ctx, "git", args...); err != nil {
+			tb.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	dir := tb.TempDir()
+	remote := filepath.Join(dir, "remote.git")
+	seed := filepath.Join(dir, "seed")
+	git(dir, "init", "--bare", remote)
+	git(dir, "clone", remote, seed)
+	if err := os.WriteFile(filepath.Join(seed, "README"), []byte("hello"), 0644); err != nil {
+		tb.Fatal(err)
+	}
+	git(seed, "add", "README")
+	git(seed, "commit", "-m", "initial")
+	git(seed, "push", "-u", "origin", "HEAD")
+
+	repos := make([]string, n)
+	for i := range repos {
+		repos[i] = filepath.Join(dir, "src", fmt.Sprintf("repo%02d", i))
+		git(dir, "clone", remote, repos[i])
+		if i%3 == 0 {
+			if err := os.WriteFile(filepath.Join(repos[i], "README"), []byte(fmt.Sprint(i)), 0644); err != nil {
+				tb.Fatal(err)
+			}
+			git(repos[i], "commit", "-am", "local change")
+			_ = os.WriteFile(filepath.Join(repos[i], "notes.txt"), nil, 0644) //nolint:errcheck // Test setup
+		}
+		if i%5 == 0 {
+			git(repos[i], "branch", "--unset-upstream")
+		}
+	}
+	return repos
+}
+
+// legacyRepoInfo gathers a repo's info the way the projects section did
+// before the scanner: one git process per question, one after another
+func legacyRepoInfo(ctx context.Context, repo string) RepoInfo {
+	info, _ := os.Stat(repo) //nolint:errcheck // The repos were just created
+	repoInfo := RepoInfo{Path: repo, ModTime: info.ModTime()}
+
+	if output, err := runner.Output(ctx, "git", "-C", repo, "status", "--porcelain"); err == nil && len(strings.TrimSpace(string(output))) > 0 {
+		repoInfo.DirtyReasons |= DirtyUncommittedChanges
+	}
+	if runner.Run(ctx, "git", "-C", repo, "rev-parse", "HEAD") == nil {
+		if runner.Run(ctx, "git", "-C", repo, "rev-parse", "--abbrev-ref", "@{u}") != nil {
+			repoInfo.DirtyReasons |= DirtyNoUpstream
+		} else if output, err := runner.Output(ctx, "git", "-C", repo, "log", "@{u}..HEAD", "--oneline"); err == nil && len(strings.TrimSpace(string(output))) > 0 {
+			repoInfo.DirtyReasons |= DirtyUnpushedCommits
+		}
+	}
+	repoInfo.Dirty = repoInfo.DirtyReasons != 0
+	if output, err := runner.Output(ctx, "git", "-C", repo, "remote", "get-url", "origin"); err == nil {
+		repoInfo.RemoteRepo = parseRemoteRepo(strings.TrimSpace(string(output)))
+	}
+	if repoInfo.Dirty {
+		if output, err := runner.Output(ctx, "git", "-C", repo, "status", "--porcelain", "--untracked-files=all"); err == nil {
+			for _, line := range strings.Split(strings.TrimRight(string(output), "\n"), "\n") {
+				if strings.HasPrefix(line, "??") {
+					repoInfo.UntrackedFiles++
+				} else if len(line) >= 2 {
+					repoInfo.UncommittedFiles++
+				}
+			}
+		}
+		if repoInfo.DirtyReasons&DirtyUnpushedCommits != 0 {
+			if output, err := runner.Output(ctx, "git", "-C", repo, "log", "@{u}..HEAD", "--oneline"); err == nil {
+				repoInfo.UnpushedCommits = len(strings.Split(strings.TrimSpace(string(output)), "\n"))
+			}
+		}
+	}
+	_, _ = runner.Output(ctx, "git", "-C", repo, "branch", "--show-current") // for the CI lookup
+	return repoInfo
+}
+
+// legacyScanRepos is the old scan: a goroutine per repo, each running
+// legacyRepoInfo
+func legacyScanRepos(ctx context.Context, repos []string) []RepoInfo {
+	infos := make([]RepoInfo, len(repos))
+	var wg sync.WaitGroup
+	for i, repo := range repos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			infos[i] = legacyRepoInfo(ctx, repo)
+		}()
+	}
+	wg.Wait()
+	return infos
+}
+
+// Test_ProjectsScanPerformance compares the scanner with the subprocess per
+// question approach it replaced, and checks both see the same repos
+// Note: This test logs a warning if the scanner is not faster but doesn't fail
+func Test_ProjectsScanPerformance(t *testing.T) {
+	// Skip in short mode as this is a longer-running test
+	if testing.Short() {
+		t.Skip("Skipping performance test in short mode")
+	}
+	ctx := context.Background()
+	repos := makePerfRepos(t, perfRepoCount)
+
+	start := time.Now()
+	legacy := legacyScanRepos(ctx, repos)
+	legacyDuration := time.Since(start)
+
+	start = time.Now()
+	scanned := scanRepos(ctx, repos, projectScanWorkers())
+	scanDuration := time.Since(start)
+
+	if len(scanned) != len(legacy) {
+		t.Fatalf("scanRepos() found %d repos, legacy scan %d", len(scanned), len(legacy))
+	}
+	for i := range legacy {
+		want, got := legacy[i], scanned[i]
+		if got.DirtyReasons != want.DirtyReasons || got.RemoteRepo != want.RemoteRepo ||
+			got.UncommittedFiles != want.UncommittedFiles || got.UntrackedFiles != want.UntrackedFiles ||
+			got.UnpushedCommits != want.UnpushedCommits {
+			t.Errorf("%s: scanRepos() = %+v, legacy scan = %+v", filepath.Base(want.Path), got, want)
+		}
+	}
+
+	t.Logf("Scanned %d repos in %v (legacy: %v, %.1fx)", len(repos), scanDuration, legacyDuration,
+		float64(legacyDuration)/float64(scanDuration))
+	if scanDuration > legacyDuration {
+		t.Logf("⚠️  PERFORMANCE WARNING: scanRepos took %v, longer than the legacy scan's %v", scanDuration, legacyDuration)
+	}
+}
+
+// Benchmark_ProjectsScan benchmarks the scanner behind the projects section,
+// re-scanning every repo as --refresh does
+func Benchmark_ProjectsScan(b *testing.B) {
+	ctx := context.Background()
+	repos := makePerfRepos(b, perfRepoCount)
+	refreshFlag = true
+	defer func() { refreshFlag = false }()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		scanRepos(ctx, repos, projectScanWorkers())
+	}
+}
+
+// Benchmark_ProjectsScanCached benchmarks the scanner when no repo has
+// changed since the last run, so every repo is served from the cache
+func Benchmark_ProjectsScanCached(b *testing.B) {
+	ctx := context.Background()
+	repos := makePerfRepos(b, perfRepoCount)
+	useTempRepoInfoCache(b)
+	// Let git settle each repo's index, then warm the cache
+	scanRepos(ctx, repos, projectScanWorkers())
+	time.Sleep(time.Second)
+	scanRepos(ctx, repos, projectScanWorkers())
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		scanRepos(ctx, repos, projectScanWorkers())
+	}
+}
+
+// Benchmark_ProjectsScanLegacy benchmarks the approach the scanner replaced,
+// for comparison with Benchmark_ProjectsScan
+func Benchmark_ProjectsScanLegacy(b *testing.B) {
+	ctx := context.Background()
+	repos := makePerfRepos(b, perfRepoCount)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		legacyScanRepos(ctx, repos)
+	}
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+
+	"github.com/aallbrig/allbctl/pkg/runner"
+)
+
+// repoScan is what one `git status --porcelain=v2 --branch` call says about
+// a repo: everything the projects section needs besides the remote, which is
+// read from the repo's config in-process
+type repoScan struct {
+	// branch is the checked out branch; empty when HEAD is detached
+	branch string
+	// head is the checked out commit; empty before the first commit
+	head string
+	// hasUpstream is set when the branch tracks an upstream that exists
+	hasUpstream bool
+	ahead       int
+	behind      int
+	uncommitted int // staged, unstaged and conflicted files
+	untracked   int
+}
+
+// scanRepo reads a repo's branch, upstream and file status in a single git
+// call. ok is false when git could not read the repo.
+func scanRepo(ctx context.Context, repoPath string) (scan repoScan, ok bool) {
+	output, err := runner.Output(ctx, "git", "-C", repoPath, "status", "--porcelain=v2", "--branch", "--untracked-files=all")
+	if err != nil {
+		return repoScan{}, false
+	}
+	return parsePorcelainV2(string(output)), true
+}
+
+// parsePorcelainV2 parses `git status --porcelain=v2 --branch` output
+func parsePorcelainV2(output string) repoScan {
+	var scan repoScan
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "# branch.oid "):
+			if oid := strings.TrimPrefix(line, "# branch.oid "); oid != "(initial)" {
+				scan.head = oid
+			}
+		case strings.HasPrefix(line, "# branch.head "):
+			if head := strings.TrimPrefix(line, "# branch.head "); head != "(detached)" {
+				scan.branch = head
+			}
+		case strings.HasPrefix(line, "# branch.ab "):
+			// Only printed when the upstream exists, e.g. "# branch.ab +2 -0"
+			fields := strings.Fields(strings.TrimPrefix(line, "# branch.ab "))
+			if len(fields) == 2 {
+				scan.hasUpstream = true
+				scan.ahead, _ = strconv.Atoi(strings.TrimPrefix(fields[0], "+"))
+				scan.behind, _ = strconv.Atoi(strings.TrimPrefix(fields[1], "-"))
+			}
+		case strings.HasPrefix(line, "? "):
+			scan.untracked++
+		case strings.HasPrefix(line, "1 "), strings.HasPrefix(line, "2 "), strings.HasPrefix(line, "u "):
+			scan.uncommitted++
+		}
+	}
+	return scan
+}
+
+// reasons is why the scanned repo counts as dirty
+func (s repoScan) reasons() DirtyReason {
+	var reasons DirtyReason
+	if s.uncommitted > 0 || s.untracked > 0 {
+		reasons |= DirtyUncommittedChanges
+	}
+	// If repo has no commits yet, upstream checks don't apply
+	if s.head == "" {
+		return reasons
+	}
+	if !s.hasUpstream {
+		return reasons | DirtyNoUpstream
+	}
+	if s.ahead > 0 {
+		reasons |= DirtyUnpushedCommits
+	}
+	return reasons
+}
+
+// readOriginURL reads the origin remote's URL from the repo's config without
+// running git, falling back to git for repos go-git cannot open
+func readOriginURL(ctx context.Context, repoPath string) string {
+	repo, err := git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err == nil {
+		if remote, err := repo.Remote("origin"); err == nil {
+			if urls := remote.Config().URLs; len(urls) > 0 {
+				return urls[0]
+			}
+		}
+		return ""
+	}
+	output, err := runner.Output(ctx, "git", "-C", repoPath, "remote", "get-url", "origin")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// projectScanWorkers is how many repos are scanned at once. Scanning mostly
+// waits on git and the disk, so it runs more workers than there are CPUs.
+func projectScanWorkers() int {
+	return max(4, 2*runtime.NumCPU())
+}
+
+// scanRepos builds the RepoInfo of every repo with a pool of workers,
+// keeping the order of repos. Repos that cannot be stat'ed are left out.
+func scanRepos(ctx context.Context, repos []string, workers int) []RepoInfo {
+	infos := make([]RepoInfo, len(repos))
+	valid := make([]bool, len(repos))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(repos)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				infos[i], valid[i] = scanRepoInfo(ctx, repos[i])
+			}
+		}()
+	}
+	for i := range repos {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var result []RepoInfo
+	for i, ok := range valid {
+		if ok {
+			result = append(result, infos[i])
+		}
+	}
+	return result
+}
+
+// scanRepoInfo gathers everything the projects section shows about one repo
+func scanRepoInfo(ctx context.Context, repo string) (RepoInfo, bool) {
+	info, err := os.Stat(repo)
+	if err != nil {
+		return RepoInfo{}, false
+	}
+	inspected := inspectRepoCached(ctx, repo)
+	repoInfo := inspected.Info
+	repoInfo.ModTime = info.ModTime()
+	if repoInfo.Bare {
+		return repoInfo, true
+	}
+
+	ciStatus, ciChecks := getRemoteCIStatusCached(ctx, repo, repoInfo.RemoteRepo, inspected.Branch, inspected.Head)
+	repoInfo.CIStatus = ciStatus
+	if verboseFlag {
+		repoInfo.CIChecks = ciChecks
+	}
+	if showLanguages {
+		repoInfo.Languages = getRepoLanguages(ctx, repo)
+	}
+	switch ciStatus {
+	case "failure":
+		repoInfo.DirtyReasons |= DirtyCIFailed
+		repoInfo.Dirty = true
+	case "pending":
+		repoInfo.DirtyReasons |= DirtyCIPending
+		repoInfo.Dirty = true
+	}
+	return repoInfo, true
+}
+
+// inspectRepo reads what the repo itself says: its remote, dirty reasons
+// and file counts, and the branch and commit CI is looked up for
+func inspectRepo(ctx context.Context, repo string) cachedRepo {
+	repoInfo := RepoInfo{Path: repo}
+	if isBareRepo(repo) {
+		// No working tree to be dirty, and nothing to push from
+		repoInfo.Bare = true
+		repoInfo.RemoteRepo = parseRemoteRepo(readOriginURL(ctx, repo))
+		return cachedRepo{Info: repoInfo}
+	}
+
+	scan, _ := scanRepo(ctx, repo)
+	reasons := scan.reasons()
+	repoInfo.Dirty = reasons != 0
+	repoInfo.DirtyReasons = reasons
+	repoInfo.RemoteRepo = parseRemoteRepo(readOriginURL(ctx, repo))
+	if reasons != 0 {
+		repoInfo.UncommittedFiles, repoInfo.UntrackedFiles = scan.uncommitted, scan.untracked
+		repoInfo.UnpushedCommits = scan.ahead
+		if verboseFlag {
+			repoInfo.StatusOutput = getGitStatusOutput(ctx, repo)
+		}
+	}
+	return cachedRepo{Info: repoInfo, Branch: scan.branch, Head: scan.head}
+}
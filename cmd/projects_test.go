@@ -1,15 +1,19 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/spf13/viper"
+
 	"github.com/aallbrig/allbctl/pkg/languages"
+	"github.com/aallbrig/allbctl/pkg/runner"
 )
 
 func TestFindGitRepos(t *testing.T) {
@@ -36,20 +40,120 @@ func TestFindGitRepos(t *testing.T) {
 	// Create non-repo directory
 	_ = os.MkdirAll(filepath.Join(tmpDir, "not-a-repo"), 0755) //nolint:errcheck // Test setup
 
-	repos := findGitRepos(tmpDir)
+	repos := findGitRepos(tmpDir, ProjectsConfig{})
 	if len(repos) != 3 {
 		t.Errorf("Expected 3 repos, got %d", len(repos))
 	}
 }
 
+func TestFindGitReposRules(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{
+		"a/.git",
+		"org/b/.git",
+		"org/deep/c/.git",
+		"a/node_modules/pkg/.git",
+		"archive/old/.git",
+		"mirror.git/objects",
+		"mirror.git/refs",
+	} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	//nolint:errcheck // Test setup
+	_ = os.WriteFile(filepath.Join(root, "mirror.git", "HEAD"), []byte("ref: refs/heads/main\n"), 0644)
+	_ = os.MkdirAll(filepath.Join(root, "a-feature"), 0755) //nolint:errcheck // Test setup
+	//nolint:errcheck // Test setup
+	_ = os.WriteFile(filepath.Join(root, "a-feature", ".git"), []byte("gitdir: "+filepath.Join(root, "a", ".git", "worktrees", "a-feature")+"\n"), 0644)
+
+	names := func(repos []string) string {
+		var rel []string
+		for _, repo := range repos {
+			r, _ := filepath.Rel(root, repo)
+			rel = append(rel, filepath.ToSlash(r))
+		}
+		sort.Strings(rel)
+		return strings.Join(rel, ",")
+	}
+
+	cases := []struct {
+		name   string
+		config ProjectsConfig
+		want   string
+	}{
+		{"defaults", ProjectsConfig{Exclude: []string{"node_modules", "vendor"}}, "a,archive/old,org/b,org/deep/c"},
+		{"relative exclude", ProjectsConfig{Exclude: []string{"node_modules", "archive/*"}}, "a,org/b,org/deep/c"},
+		{"absolute exclude", ProjectsConfig{Exclude: []string{"node_modules", filepath.ToSlash(filepath.Join(root, "org"))}}, "a,archive/old"},
+		{"max depth", ProjectsConfig{Exclude: []string{"node_modules"}, MaxDepth: 2}, "a,archive/old,org/b"},
+		{"bare and worktrees", ProjectsConfig{Exclude: []string{"node_modules"}, MaxDepth: 1, IncludeBare: true, IncludeWorktrees: true}, "a,a-feature,mirror.git"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := names(findGitRepos(root, tc.config)); got != tc.want {
+				t.Errorf("findGitRepos() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+
+	config := ProjectsConfig{Roots: []string{root, filepath.Join(root, "org"), filepath.Join(root, "missing")}, MaxDepth: 1}
+	if got := names(config.findRepos()); got != "a,org/b" {
+		t.Errorf("findRepos() over overlapping roots = %s, want a,org/b", got)
+	}
+}
+
+func TestLoadProjectsConfig(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	config, err := loadProjectsConfig()
+	if err != nil {
+		t.Fatalf("loadProjectsConfig() without config error = %v", err)
+	}
+	if len(config.Roots) != 1 || config.Roots[0] != filepath.Join(home, "src") {
+		t.Errorf("default Roots = %v, want [~/src]", config.Roots)
+	}
+
+	viper.SetConfigType("yaml")
+	yaml := "projects:\n  roots: [~/work, /opt/code]\n  exclude: [\"~/work/scratch\"]\n  max_depth: 3\n  include_bare: true\n"
+	if err := viper.ReadConfig(strings.NewReader(yaml)); err != nil {
+		t.Fatal(err)
+	}
+	config, err = loadProjectsConfig()
+	if err != nil {
+		t.Fatalf("loadProjectsConfig() error = %v", err)
+	}
+	if config.Roots[0] != filepath.Join(home, "work") || config.Roots[1] != "/opt/code" {
+		t.Errorf("Roots = %v, want ~ expanded", config.Roots)
+	}
+	if config.Exclude[0] != filepath.ToSlash(filepath.Join(home, "work", "scratch")) || config.MaxDepth != 3 || !config.IncludeBare {
+		t.Errorf("config = %+v", config)
+	}
+
+	for _, bad := range []string{"projects:\n  max_depth: -1\n", "projects:\n  exclude: [\"[\"]\n", "projects:\n  roots: []\n"} {
+		viper.Reset()
+		viper.SetConfigType("yaml")
+		if err := viper.ReadConfig(strings.NewReader(bad)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadProjectsConfig(); err == nil {
+			t.Errorf("loadProjectsConfig() with %q should fail", bad)
+		}
+	}
+}
+
 func TestIsGitRepoDirty(t *testing.T) {
+	ctx := context.Background()
 	tmpDir, err := os.MkdirTemp("", "allbctl-test-")
 	if err != nil {
 		t.Fatalf("Failed to create temp dir: %v", err)
 	}
 	defer os.RemoveAll(tmpDir)
 
-	dirty := isGitRepoDirty(tmpDir)
+	dirty := isGitRepoDirty(ctx, tmpDir)
 	if dirty {
 		t.Error("Non-git directory should not be dirty")
 	}
@@ -78,6 +182,7 @@ func TestDirtyReasonString(t *testing.T) {
 }
 
 func TestGetDirtyReasons(t *testing.T) {
+	ctx := context.Background()
 	t.Run("non-git directory has no reasons", func(t *testing.T) {
 		tmpDir, err := os.MkdirTemp("", "allbctl-test-")
 		if err != nil {
@@ -85,7 +190,7 @@ func TestGetDirtyReasons(t *testing.T) {
 		}
 		defer os.RemoveAll(tmpDir)
 
-		reasons := getDirtyReasons(tmpDir)
+		reasons := getDirtyReasons(ctx, tmpDir)
 		if reasons != 0 {
 			t.Errorf("Expected no dirty reasons for non-git dir, got %s", reasons)
 		}
@@ -98,14 +203,14 @@ func TestGetDirtyReasons(t *testing.T) {
 		}
 		defer os.RemoveAll(tmpDir)
 
-		if err := exec.Command("git", "-C", tmpDir, "init").Run(); err != nil {
+		if err := runner.Run(ctx, "git", "-C", tmpDir, "init"); err != nil {
 			t.Skip("git not available")
 		}
 		if err := os.WriteFile(filepath.Join(tmpDir, "test.txt"), []byte("test"), 0644); err != nil {
 			t.Fatalf("Failed to create test file: %v", err)
 		}
 
-		reasons := getDirtyReasons(tmpDir)
+		reasons := getDirtyReasons(ctx, tmpDir)
 		if reasons&DirtyUncommittedChanges == 0 {
 			t.Errorf("Expected DirtyUncommittedChanges, got %s", reasons)
 		}
@@ -118,16 +223,16 @@ func TestGetDirtyReasons(t *testing.T) {
 		}
 		defer os.RemoveAll(tmpDir)
 
-		if err := exec.Command("git", "-C", tmpDir, "init").Run(); err != nil {
+		if err := runner.Run(ctx, "git", "-C", tmpDir, "init"); err != nil {
 			t.Skip("git not available")
 		}
 		if err := os.WriteFile(filepath.Join(tmpDir, "readme.txt"), []byte("hi"), 0644); err != nil {
 			t.Fatalf("Failed to create file: %v", err)
 		}
-		_ = exec.Command("git", "-C", tmpDir, "add", ".").Run()                                                                       //nolint:errcheck
-		_ = exec.Command("git", "-C", tmpDir, "-c", "user.email=test@test.com", "-c", "user.name=Test", "commit", "-m", "init").Run() //nolint:errcheck
+		_ = runner.Run(ctx, "git", "-C", tmpDir, "add", ".")                                                                       //nolint:errcheck
+		_ = runner.Run(ctx, "git", "-C", tmpDir, "-c", "user.email=test@test.com", "-c", "user.name=Test", "commit", "-m", "init") //nolint:errcheck
 
-		reasons := getDirtyReasons(tmpDir)
+		reasons := getDirtyReasons(ctx, tmpDir)
 		if reasons&DirtyNoUpstream == 0 {
 			t.Errorf("Expected DirtyNoUpstream for local-only repo, got %s", reasons)
 		}
@@ -147,10 +252,10 @@ func TestGetDirtyReasons(t *testing.T) {
 		}
 		defer os.RemoveAll(localDir)
 
-		if err := exec.Command("git", "init", "--bare", remoteDir).Run(); err != nil {
+		if err := runner.Run(ctx, "git", "init", "--bare", remoteDir); err != nil {
 			t.Skip("git not available")
 		}
-		if err := exec.Command("git", "clone", remoteDir, localDir).Run(); err != nil {
+		if err := runner.Run(ctx, "git", "clone", remoteDir, localDir); err != nil {
 			t.Skipf("git clone failed: %v", err)
 		}
 
@@ -158,18 +263,18 @@ func TestGetDirtyReasons(t *testing.T) {
 		if err := os.WriteFile(filepath.Join(localDir, "file.txt"), []byte("hello"), 0644); err != nil {
 			t.Fatalf("Failed to create file: %v", err)
 		}
-		_ = exec.Command("git", "-C", localDir, "add", ".").Run()                                                                          //nolint:errcheck
-		_ = exec.Command("git", "-C", localDir, "-c", "user.email=test@test.com", "-c", "user.name=Test", "commit", "-m", "initial").Run() //nolint:errcheck
-		_ = exec.Command("git", "-C", localDir, "push", "-u", "origin", "HEAD").Run()                                                      //nolint:errcheck
+		_ = runner.Run(ctx, "git", "-C", localDir, "add", ".")                                                                          //nolint:errcheck
+		_ = runner.Run(ctx, "git", "-C", localDir, "-c", "user.email=test@test.com", "-c", "user.name=Test", "commit", "-m", "initial") //nolint:errcheck
+		_ = runner.Run(ctx, "git", "-C", localDir, "push", "-u", "origin", "HEAD")                                                      //nolint:errcheck
 
 		// Now make a local commit that isn't pushed
 		if err := os.WriteFile(filepath.Join(localDir, "file.txt"), []byte("world"), 0644); err != nil {
 			t.Fatalf("Failed to update file: %v", err)
 		}
-		_ = exec.Command("git", "-C", localDir, "add", ".").Run()                                                                           //nolint:errcheck
-		_ = exec.Command("git", "-C", localDir, "-c", "user.email=test@test.com", "-c", "user.name=Test", "commit", "-m", "unpushed").Run() //nolint:errcheck
+		_ = runner.Run(ctx, "git", "-C", localDir, "add", ".")                                                                           //nolint:errcheck
+		_ = runner.Run(ctx, "git", "-C", localDir, "-c", "user.email=test@test.com", "-c", "user.name=Test", "commit", "-m", "unpushed") //nolint:errcheck
 
-		reasons := getDirtyReasons(localDir)
+		reasons := getDirtyReasons(ctx, localDir)
 		if reasons&DirtyUnpushedCommits == 0 {
 			t.Errorf("Expected DirtyUnpushedCommits, got %s", reasons)
 		}
@@ -180,6 +285,7 @@ func TestGetDirtyReasons(t *testing.T) {
 }
 
 func TestGetReposByModTime(t *testing.T) {
+	ctx := context.Background()
 	tmpDir, err := os.MkdirTemp("", "allbctl-test-")
 	if err != nil {
 		t.Fatalf("Failed to create temp dir: %v", err)
@@ -198,7 +304,7 @@ func TestGetReposByModTime(t *testing.T) {
 	_ = os.MkdirAll(repo3, 0755) //nolint:errcheck // Test setup
 
 	repos := []string{repo1, repo2, repo3}
-	sorted := getReposByModTime(repos)
+	sorted := getReposByModTime(ctx, repos)
 
 	if len(sorted) != 3 {
 		t.Errorf("Expected 3 repos, got %d", len(sorted))
@@ -400,6 +506,7 @@ func TestShowFilesFlag(t *testing.T) {
 }
 
 func TestGetGitStatusOutput(t *testing.T) {
+	ctx := context.Background()
 	t.Run("non-git directory returns empty string", func(t *testing.T) {
 		tmpDir, err := os.MkdirTemp("", "allbctl-test-")
 		if err != nil {
@@ -407,7 +514,7 @@ func TestGetGitStatusOutput(t *testing.T) {
 		}
 		defer os.RemoveAll(tmpDir)
 
-		out := getGitStatusOutput(tmpDir)
+		out := getGitStatusOutput(ctx, tmpDir)
 		if out != "" {
 			t.Errorf("Expected empty string for non-git directory, got %q", out)
 		}
@@ -420,7 +527,7 @@ func TestGetGitStatusOutput(t *testing.T) {
 		}
 		defer os.RemoveAll(tmpDir)
 
-		if err := exec.Command("git", "-C", tmpDir, "init").Run(); err != nil {
+		if err := runner.Run(ctx, "git", "-C", tmpDir, "init"); err != nil {
 			t.Skip("git not available")
 		}
 
@@ -428,7 +535,7 @@ func TestGetGitStatusOutput(t *testing.T) {
 			t.Fatalf("Failed to create test file: %v", err)
 		}
 
-		out := getGitStatusOutput(tmpDir)
+		out := getGitStatusOutput(ctx, tmpDir)
 		if out == "" {
 			t.Error("Expected non-empty status output for dirty repo")
 		}
@@ -444,12 +551,12 @@ func TestGetGitStatusOutput(t *testing.T) {
 		}
 		defer os.RemoveAll(tmpDir)
 
-		if err := exec.Command("git", "-C", tmpDir, "init").Run(); err != nil {
+		if err := runner.Run(ctx, "git", "-C", tmpDir, "init"); err != nil {
 			t.Skip("git not available")
 		}
 
 		// Clean repos still produce "nothing to commit" output
-		out := getGitStatusOutput(tmpDir)
+		out := getGitStatusOutput(ctx, tmpDir)
 		if out == "" {
 			t.Error("Expected non-empty status output even for clean repo")
 		}
@@ -493,6 +600,7 @@ func TestCountTotalStatusFiles(t *testing.T) {
 }
 
 func TestCountPorcelainFiles(t *testing.T) {
+	ctx := context.Background()
 	t.Run("non-git directory returns zeros", func(t *testing.T) {
 		tmpDir, err := os.MkdirTemp("", "allbctl-test-")
 		if err != nil {
@@ -500,7 +608,7 @@ func TestCountPorcelainFiles(t *testing.T) {
 		}
 		defer os.RemoveAll(tmpDir)
 
-		uncommitted, untracked := countPorcelainFiles(tmpDir)
+		uncommitted, untracked := countPorcelainFiles(ctx, tmpDir)
 		if uncommitted != 0 || untracked != 0 {
 			t.Errorf("Expected (0, 0), got (%d, %d)", uncommitted, untracked)
 		}
@@ -513,20 +621,20 @@ func TestCountPorcelainFiles(t *testing.T) {
 		}
 		defer os.RemoveAll(tmpDir)
 
-		if err := exec.Command("git", "-C", tmpDir, "init").Run(); err != nil {
+		if err := runner.Run(ctx, "git", "-C", tmpDir, "init"); err != nil {
 			t.Skip("git not available")
 		}
 
 		if err := os.WriteFile(filepath.Join(tmpDir, "staged.txt"), []byte("staged"), 0644); err != nil {
 			t.Fatalf("Failed to create staged file: %v", err)
 		}
-		_ = exec.Command("git", "-C", tmpDir, "add", "staged.txt").Run() //nolint:errcheck
+		_ = runner.Run(ctx, "git", "-C", tmpDir, "add", "staged.txt") //nolint:errcheck
 
 		if err := os.WriteFile(filepath.Join(tmpDir, "untracked.txt"), []byte("untracked"), 0644); err != nil {
 			t.Fatalf("Failed to create untracked file: %v", err)
 		}
 
-		uncommitted, untracked := countPorcelainFiles(tmpDir)
+		uncommitted, untracked := countPorcelainFiles(ctx, tmpDir)
 		if uncommitted != 1 {
 			t.Errorf("Expected 1 uncommitted file, got %d", uncommitted)
 		}
@@ -537,6 +645,7 @@ func TestCountPorcelainFiles(t *testing.T) {
 }
 
 func TestCountUnpushedCommits(t *testing.T) {
+	ctx := context.Background()
 	t.Run("non-git directory returns zero", func(t *testing.T) {
 		tmpDir, err := os.MkdirTemp("", "allbctl-test-")
 		if err != nil {
@@ -544,7 +653,7 @@ func TestCountUnpushedCommits(t *testing.T) {
 		}
 		defer os.RemoveAll(tmpDir)
 
-		if countUnpushedCommits(tmpDir) != 0 {
+		if countUnpushedCommits(ctx, tmpDir) != 0 {
 			t.Error("Expected 0 for non-git dir")
 		}
 	})
@@ -556,11 +665,11 @@ func TestCountUnpushedCommits(t *testing.T) {
 		}
 		defer os.RemoveAll(tmpDir)
 
-		if err := exec.Command("git", "-C", tmpDir, "init").Run(); err != nil {
+		if err := runner.Run(ctx, "git", "-C", tmpDir, "init"); err != nil {
 			t.Skip("git not available")
 		}
 
-		if countUnpushedCommits(tmpDir) != 0 {
+		if countUnpushedCommits(ctx, tmpDir) != 0 {
 			t.Error("Expected 0 for repo with no upstream")
 		}
 	})
@@ -683,6 +792,7 @@ func TestParseCICheckRuns(t *testing.T) {
 }
 
 func TestGetRepoLanguages(t *testing.T) {
+	ctx := context.Background()
 	t.Run("returns languages for a valid git repo", func(t *testing.T) {
 		tmpDir, err := os.MkdirTemp("", "allbctl-lang-test-")
 		if err != nil {
@@ -690,7 +800,7 @@ func TestGetRepoLanguages(t *testing.T) {
 		}
 		defer os.RemoveAll(tmpDir)
 
-		if err := exec.Command("git", "-C", tmpDir, "init").Run(); err != nil {
+		if err := runner.Run(ctx, "git", "-C", tmpDir, "init"); err != nil {
 			t.Skip("git not available")
 		}
 
@@ -704,10 +814,10 @@ func TestGetRepoLanguages(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		_ = exec.Command("git", "-C", tmpDir, "add", ".").Run()                                                                       //nolint:errcheck
-		_ = exec.Command("git", "-C", tmpDir, "-c", "user.email=test@test.com", "-c", "user.name=Test", "commit", "-m", "init").Run() //nolint:errcheck
+		_ = runner.Run(ctx, "git", "-C", tmpDir, "add", ".")                                                                       //nolint:errcheck
+		_ = runner.Run(ctx, "git", "-C", tmpDir, "-c", "user.email=test@test.com", "-c", "user.name=Test", "commit", "-m", "init") //nolint:errcheck
 
-		langs := getRepoLanguages(tmpDir)
+		langs := getRepoLanguages(ctx, tmpDir)
 		if len(langs) == 0 {
 			t.Fatal("Expected at least one language, got none")
 		}
@@ -731,7 +841,7 @@ func TestGetRepoLanguages(t *testing.T) {
 		}
 		defer os.RemoveAll(tmpDir)
 
-		langs := getRepoLanguages(tmpDir)
+		langs := getRepoLanguages(ctx, tmpDir)
 		if langs != nil {
 			t.Errorf("Expected nil for non-git dir, got %v", langs)
 		}
@@ -744,17 +854,17 @@ func TestGetRepoLanguages(t *testing.T) {
 		}
 		defer os.RemoveAll(tmpDir)
 
-		if err := exec.Command("git", "-C", tmpDir, "init").Run(); err != nil {
+		if err := runner.Run(ctx, "git", "-C", tmpDir, "init"); err != nil {
 			t.Skip("git not available")
 		}
 		if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main\n"), 0644); err != nil {
 			t.Fatal(err)
 		}
-		_ = exec.Command("git", "-C", tmpDir, "add", ".").Run()                                                                       //nolint:errcheck
-		_ = exec.Command("git", "-C", tmpDir, "-c", "user.email=test@test.com", "-c", "user.name=Test", "commit", "-m", "init").Run() //nolint:errcheck
+		_ = runner.Run(ctx, "git", "-C", tmpDir, "add", ".")                                                                       //nolint:errcheck
+		_ = runner.Run(ctx, "git", "-C", tmpDir, "-c", "user.email=test@test.com", "-c", "user.name=Test", "commit", "-m", "init") //nolint:errcheck
 
-		langs1 := getRepoLanguages(tmpDir)
-		langs2 := getRepoLanguages(tmpDir)
+		langs1 := getRepoLanguages(ctx, tmpDir)
+		langs2 := getRepoLanguages(ctx, tmpDir)
 
 		if len(langs1) != len(langs2) {
 			t.Errorf("Expected same result from cache, got %d vs %d", len(langs1), len(langs2))
@@ -939,3 +1049,44 @@ func TestLanguagesFlag(t *testing.T) {
 		}
 	})
 }
+
+func TestParsePorcelainV2(t *testing.T) {
+	output := `# branch.oid 5b1d3c0f7e2a9d4b8c6e1f0a3b2c4d5e6f7a8b9c
+# branch.head main
+# branch.upstream origin/main
+# branch.ab +2 -1
+1 .M N... 100644 100644 100644 3f2a1b 3f2a1b README.md
+1 A. N... 000000 100644 100644 000000 9c8d7e new.go
+2 R. N... 100644 100644 100644 1a2b3c 1a2b3c R100 cmd/new.go	cmd/old.go
+u UU N... 100644 100644 100644 100644 aa bb cc conflict.txt
+? notes.txt
+? tmp/scratch.txt
+`
+	scan := parsePorcelainV2(output)
+	if scan.branch != "main" || scan.head != "5b1d3c0f7e2a9d4b8c6e1f0a3b2c4d5e6f7a8b9c" || !scan.hasUpstream || scan.ahead != 2 || scan.behind != 1 {
+		t.Errorf("branch info = %+v", scan)
+	}
+	if scan.uncommitted != 4 || scan.untracked != 2 {
+		t.Errorf("uncommitted, untracked = %d, %d, want 4, 2", scan.uncommitted, scan.untracked)
+	}
+	if got := scan.reasons(); got != DirtyUncommittedChanges|DirtyUnpushedCommits {
+		t.Errorf("reasons() = %s", got)
+	}
+
+	cases := []struct {
+		name   string
+		output string
+		want   DirtyReason
+	}{
+		{"no commits yet", "# branch.oid (initial)\n# branch.head main\n? a.txt\n", DirtyUncommittedChanges},
+		{"no upstream", "# branch.oid abc\n# branch.head topic\n", DirtyNoUpstream},
+		{"upstream gone", "# branch.oid abc\n# branch.head topic\n# branch.upstream origin/topic\n", DirtyNoUpstream},
+		{"detached", "# branch.oid abc\n# branch.head (detached)\n", DirtyNoUpstream},
+		{"clean", "# branch.oid abc\n# branch.head main\n# branch.upstream origin/main\n# branch.ab +0 -3\n", 0},
+	}
+	for _, tc := range cases {
+		if got := parsePorcelainV2(tc.output).reasons(); got != tc.want {
+			t.Errorf("%s: reasons() = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
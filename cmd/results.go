@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/aallbrig/allbctl/pkg/model"
+)
+
+// resultLabels are the colored status labels used when rendering results as text
+var resultLabels = map[model.Status]*color.Color{
+	model.StatusOK:       color.New(color.FgGreen),
+	model.StatusMissing:  color.New(color.FgRed),
+	model.StatusDrifted:  color.New(color.FgYellow),
+	model.StatusOutdated: color.New(color.FgYellow),
+	model.StatusError:    color.New(color.FgRed),
+	model.StatusSkipped:  color.New(color.Faint),
+}
+
+// writeResultSection writes a top-level result under a "Name / -----" header.
+// A group's own line is left out since the header already names it.
+func writeResultSection(out *bytes.Buffer, r *model.Result) {
+	out.WriteString(fmt.Sprintf("%s\n-----\n", r.Name))
+	if len(r.Children) > 0 && r.Message == "" {
+		for _, child := range r.Children {
+			writeResult(out, child, r.Name, "")
+		}
+	} else {
+		writeResult(out, r, r.Name, "")
+	}
+	out.WriteString("\n")
+}
+
+// writeResult writes one result line followed by its hints, command output
+// and children. The name is left out when it repeats the parent's, as with
+// the drift findings of a single configuration.
+func writeResult(out *bytes.Buffer, r *model.Result, parentName, indent string) {
+	label, ok := resultLabels[r.Status]
+	if !ok {
+		label = color.New(color.Reset)
+	}
+	out.WriteString(indent)
+	_, _ = label.Fprintf(out, "%-7s", strings.ToUpper(string(r.Status)))
+
+	var text []string
+	if r.Name != parentName {
+		text = append(text, r.Name)
+	}
+	if r.Message != "" {
+		text = append(text, r.Message)
+	}
+	out.WriteString(fmt.Sprintf(" %s\n", strings.Join(text, ": ")))
+
+	for _, hint := range r.Hints {
+		_, _ = color.New(color.Faint).Fprintf(out, "%s        → %s\n", indent, hint)
+	}
+	if output := strings.TrimRight(r.Output, "\n"); output != "" {
+		for _, line := range strings.Split(output, "\n") {
+			out.WriteString(fmt.Sprintf("%s        | %s\n", indent, line))
+		}
+	}
+	for _, child := range r.Children {
+		writeResult(out, child, r.Name, indent+"    ")
+	}
+}
+
+// countResults tallies leaf results by status
+func countResults(results []*model.Result) map[model.Status]int {
+	counts := map[model.Status]int{}
+	var walk func(r *model.Result)
+	walk = func(r *model.Result) {
+		if len(r.Children) == 0 || r.Status == model.StatusDrifted {
+			counts[r.Status]++
+			return
+		}
+		for _, child := range r.Children {
+			walk(child)
+		}
+	}
+	for _, r := range results {
+		walk(r)
+	}
+	return counts
+}
+
+// resultSummary is a one-line tally such as "3 ok, 1 missing"
+func resultSummary(results []*model.Result) string {
+	counts := countResults(results)
+	var parts []string
+	for _, status := range []model.Status{model.StatusOK, model.StatusMissing, model.StatusOutdated, model.StatusDrifted, model.StatusError, model.StatusSkipped} {
+		if counts[status] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[status], status))
+		}
+	}
+	if len(parts) == 0 {
+		return "nothing to check"
+	}
+	return strings.Join(parts, ", ")
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"time"
@@ -14,11 +15,17 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/aallbrig/allbctl/pkg/runner"
 	"github.com/aallbrig/allbctl/pkg/telemetry"
 )
 
 var cfgFile string
 var debugMode bool
+var traceCommands bool
+var recordCommands string
+
+// commandRecorder records external commands for --record-commands
+var commandRecorder *runner.Recorder
 
 // telemetryShutdown is set by initTelemetry and called in postRunTelemetry.
 var telemetryShutdown func(context.Context) error
@@ -37,7 +44,7 @@ $ allbctl bootstrap status
 $ allbctl bootstrap install
 $ allbctl status
 $ allbctl status runtimes              # Show detected programming runtimes
-$ allbctl status projects              # Show git repositories in ~/src
+$ allbctl status projects              # Show git repositories in ~/src (projects.roots)
 $ allbctl status list-packages         # Show package counts from all package managers
 $ allbctl status db                    # Show detected databases and their status
 $ allbctl status network               # Show network interface information
@@ -51,9 +58,15 @@ $ allbctl status cloud-native aws      # Show detailed AWS resources by region
 $ allbctl update                       # Update all detected package managers
 $ allbctl update --dry-run             # Preview updates without executing
 $ allbctl update --managers apt,npm    # Only update apt and npm
+$ allbctl plugin list                  # Show allbctl-<name> plugins found on PATH
+$ allbctl serve --listen :9742         # Serve status data as Prometheus metrics and JSON
+$ allbctl fleet status host1 host2     # Compare status across machines over SSH
+$ allbctl doctor                       # Check for problems; exits non-zero on errors
+$ allbctl fix                          # Apply the fixes doctor finds, confirming each
 `,
 	Version: Version,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		initCommandRunner()
 		return initTelemetry(cmd, args)
 	},
 	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
@@ -64,9 +77,39 @@ $ allbctl update --managers apt,npm    # Only update apt and npm
 	},
 }
 
+// exitCodeError makes Execute exit with code. The command has already
+// reported why, so nothing more is printed.
+type exitCodeError struct {
+	code int
+}
+
+func (e *exitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
 // Execute comment for execute
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	registerPlugins(rootCmd)
+	args, err := applyPluginGlobalFlags(rootCmd, os.Args[1:])
+	var cmd *cobra.Command
+	if err == nil {
+		rootCmd.SetArgs(args)
+		cmd, err = rootCmd.ExecuteC()
+		saveCommandRecording()
+	}
+	if err != nil {
+		var exitErr *pluginExitError
+		if errors.As(err, &exitErr) {
+			// The plugin has already reported its own error. Its spans belong
+			// to this command's trace, so flush telemetry before exiting.
+			_ = finishTelemetry(cmd, false) //nolint:errcheck // exiting anyway
+			os.Exit(exitErr.code)
+		}
+		var codeErr *exitCodeError
+		if errors.As(err, &codeErr) {
+			_ = finishTelemetry(cmd, false) //nolint:errcheck // exiting anyway
+			os.Exit(codeErr.code)
+		}
 		fmt.Println(err)
 		os.Exit(1)
 	}
@@ -77,6 +120,12 @@ func init() {
 	rootCmd.AddCommand(BootstrapCmd)
 	rootCmd.AddCommand(StatusCmd)
 	rootCmd.AddCommand(UpdateCmd)
+	rootCmd.AddCommand(PluginCmd)
+	rootCmd.AddCommand(ServeCmd)
+	rootCmd.AddCommand(FleetCmd)
+	rootCmd.AddCommand(DoctorCmd)
+	rootCmd.AddCommand(FixCmd)
+	rootCmd.AddCommand(ProjectsGitCmd)
 
 	// Add subcommands to status
 	StatusCmd.AddCommand(RuntimesCmd)
@@ -90,13 +139,40 @@ func init() {
 	StatusCmd.AddCommand(GitConfigCmd)
 	StatusCmd.AddCommand(PortsCmd)
 	StatusCmd.AddCommand(CloudNativeCmd)
+	StatusCmd.AddCommand(StatusHistoryCmd)
+	StatusCmd.AddCommand(StatusDiffCmd)
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.allbctl.yaml)")
 	rootCmd.PersistentFlags().BoolVar(&debugMode, "debug", false, "Enable debug telemetry (structured logs, traces, and metrics to stderr)")
+	rootCmd.PersistentFlags().BoolVar(&traceCommands, "trace-commands", false, "Log every external command run, with its duration and exit status, to stderr")
+	rootCmd.PersistentFlags().StringVar(&recordCommands, "record-commands", "", "Record every external command run and its output to a JSON fixture file")
+	_ = rootCmd.PersistentFlags().MarkHidden("record-commands") //nolint:errcheck // the flag exists
 
 	rootCmd.SetVersionTemplate(fmt.Sprintf("allbctl %s (commit %s)\n", Version, Commit))
 }
 
+// initCommandRunner wraps the runner that external commands go through for
+// --record-commands and --trace-commands
+func initCommandRunner() {
+	if recordCommands != "" && commandRecorder == nil {
+		commandRecorder = runner.Record(runner.Default)
+		runner.Default = commandRecorder
+	}
+	if traceCommands {
+		runner.Default = runner.Trace(runner.Default, os.Stderr)
+	}
+}
+
+// saveCommandRecording writes what --record-commands recorded
+func saveCommandRecording() {
+	if commandRecorder == nil {
+		return
+	}
+	if err := commandRecorder.Save(recordCommands); err != nil {
+		fmt.Fprintf(os.Stderr, "record-commands: %v\n", err)
+	}
+}
+
 // initTelemetry is called by PersistentPreRunE on every command. It sets up
 // the OTel providers and starts a root span for the command invocation.
 func initTelemetry(cmd *cobra.Command, args []string) error {
@@ -136,6 +212,12 @@ func initTelemetry(cmd *cobra.Command, args []string) error {
 
 // postRunTelemetry ends the command span, records metrics, and flushes providers.
 func postRunTelemetry(cmd *cobra.Command, _ []string) error {
+	return finishTelemetry(cmd, true)
+}
+
+// finishTelemetry does the work of postRunTelemetry, which cobra skips when a
+// command fails
+func finishTelemetry(cmd *cobra.Command, success bool) error {
 	ctx := cmd.Context()
 	if ctx == nil {
 		ctx = context.Background()
@@ -153,7 +235,7 @@ func postRunTelemetry(cmd *cobra.Command, _ []string) error {
 		"duration_ms", duration.Milliseconds(),
 	)
 
-	telemetry.RecordCommandMetrics(ctx, cmd.CommandPath(), duration, true)
+	telemetry.RecordCommandMetrics(ctx, cmd.CommandPath(), duration, success)
 
 	if telemetryShutdown != nil {
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -180,6 +262,7 @@ func initConfig() {
 	viper.AutomaticEnv()
 
 	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+		// stderr keeps --output json/yaml on stdout parseable
+		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
 }
@@ -1,18 +1,23 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
+
+	"github.com/aallbrig/allbctl/pkg/runner"
+	"github.com/aallbrig/allbctl/pkg/version"
 )
 
 type RuntimeInfo struct {
-	Name     string
-	Version  string
-	Category string
+	Name     string      `json:"name"`
+	Version  string      `json:"version"`
+	Category string      `json:"category"`
+	Update   *UpdateInfo `json:"update,omitempty"` // newer release of a language runtime, set by withRuntimeUpdates
 }
 
 type RuntimeCheck struct {
@@ -20,7 +25,7 @@ type RuntimeCheck struct {
 	Category string
 }
 
-func getAllRuntimeChecks() map[string]RuntimeCheck {
+func getAllRuntimeChecks(ctx context.Context) map[string]RuntimeCheck {
 	checks := map[string]RuntimeCheck{
 		// Programming Languages
 		"Python":  {[]string{"python3", "--version"}, "language"},
@@ -54,7 +59,7 @@ func getAllRuntimeChecks() map[string]RuntimeCheck {
 	}
 
 	// Add gaming platforms
-	gamingChecks := detectGamingPlatforms()
+	gamingChecks := detectGamingPlatforms(ctx)
 	for name, check := range gamingChecks {
 		checks[name] = check
 	}
@@ -62,12 +67,12 @@ func getAllRuntimeChecks() map[string]RuntimeCheck {
 	return checks
 }
 
-func detectRuntimes() []RuntimeInfo {
-	var runtimes []RuntimeInfo
-	checks := getAllRuntimeChecks()
+func detectRuntimes(ctx context.Context) []RuntimeInfo {
+	runtimes := []RuntimeInfo{}
+	checks := getAllRuntimeChecks(ctx)
 
 	for name, check := range checks {
-		version := checkRuntime(check.Command)
+		version := checkRuntime(ctx, check.Command)
 		if version != "" {
 			runtimes = append(runtimes, RuntimeInfo{
 				Name:     name,
@@ -77,16 +82,18 @@ func detectRuntimes() []RuntimeInfo {
 		}
 	}
 
+	// Map iteration order is random; sort so output is stable between runs
+	sort.Slice(runtimes, func(i, j int) bool { return runtimes[i].Name < runtimes[j].Name })
+
 	return runtimes
 }
 
-func checkRuntime(cmdArgs []string) string {
+func checkRuntime(ctx context.Context, cmdArgs []string) string {
 	if len(cmdArgs) == 0 {
 		return ""
 	}
 
-	cmd := exec.Command(cmdArgs[0], cmdArgs[1:]...)
-	output, err := cmd.CombinedOutput()
+	output, err := runner.CombinedOutput(ctx, cmdArgs[0], cmdArgs[1:]...)
 	if err != nil {
 		return ""
 	}
@@ -160,8 +167,25 @@ func formatRuntimesOutput(runtimes []RuntimeInfo) string {
 	return output.String()
 }
 
-func detectRuntimesInline() string {
-	runtimes := detectRuntimes()
+func detectRuntimesInline(ctx context.Context) string {
+	return formatRuntimesInline(withRuntimeUpdates(detectRuntimes(ctx)))
+}
+
+// withRuntimeUpdates looks up newer releases of the language runtimes
+func withRuntimeUpdates(runtimes []RuntimeInfo) []RuntimeInfo {
+	for i, rt := range runtimes {
+		if rt.Category != "language" {
+			continue
+		}
+		if version := extractVersionNumber(rt.Version); version != "" {
+			runtimes[i].Update = checkVersionUpdate(rt.Name, version)
+		}
+	}
+	return runtimes
+}
+
+// formatRuntimesInline renders the language runtimes as "Go (1.22.0), Python (3.12.1)"
+func formatRuntimesInline(runtimes []RuntimeInfo) string {
 	if len(runtimes) == 0 {
 		return ""
 	}
@@ -173,7 +197,7 @@ func detectRuntimesInline() string {
 			// Extract just the version number for cleaner display
 			version := extractVersionNumber(rt.Version)
 			if version != "" {
-				versionStr := formatVersionWithUpdate(rt.Name, version)
+				versionStr := formatVersion(version, rt.Update)
 				parts = append(parts, fmt.Sprintf("%s (%s)", rt.Name, versionStr))
 			} else {
 				parts = append(parts, rt.Name)
@@ -190,129 +214,7 @@ func detectRuntimesInline() string {
 
 // extractVersionNumber extracts just the version number from version output
 func extractVersionNumber(versionOutput string) string {
-	// Handle common version output patterns
-	output := strings.TrimSpace(versionOutput)
-
-	// For "Python 3.9.0" or "python 3.9.0"
-	if strings.HasPrefix(strings.ToLower(output), "python ") {
-		parts := strings.Fields(output)
-		if len(parts) >= 2 {
-			return parts[1]
-		}
-	}
-
-	// For "go version go1.20.0 linux/amd64"
-	if strings.HasPrefix(output, "go version go") {
-		parts := strings.Fields(output)
-		if len(parts) >= 3 {
-			return strings.TrimPrefix(parts[2], "go")
-		}
-	}
-
-	// For "rustc 1.70.0 (90c541806 2023-05-31)"
-	if strings.HasPrefix(output, "rustc ") {
-		parts := strings.Fields(output)
-		if len(parts) >= 2 {
-			return parts[1]
-		}
-	}
-
-	// For "node v18.0.0" or "Node.js v18.0.0"
-	if strings.Contains(strings.ToLower(output), "node") || strings.HasPrefix(output, "v") {
-		parts := strings.Fields(output)
-		for _, part := range parts {
-			if strings.HasPrefix(part, "v") && len(part) > 1 {
-				return strings.TrimPrefix(part, "v")
-			}
-		}
-	}
-
-	// For "ruby 3.0.0p0 (2020-12-25 revision 95aff21468)"
-	if strings.HasPrefix(output, "ruby ") {
-		parts := strings.Fields(output)
-		if len(parts) >= 2 {
-			// Remove patch level suffix like "p0"
-			version := parts[1]
-			if idx := strings.Index(version, "p"); idx > 0 {
-				return version[:idx]
-			}
-			return version
-		}
-	}
-
-	// For "This is perl 5, version 38, subversion 2 (v5.38.2)"
-	if strings.Contains(output, "perl") {
-		// Look for version in parentheses like (v5.38.2)
-		if idx := strings.Index(output, "(v"); idx >= 0 {
-			rest := output[idx+2:]
-			if endIdx := strings.Index(rest, ")"); endIdx >= 0 {
-				return rest[:endIdx]
-			}
-		}
-		// Try "version X, subversion Y" pattern
-		if strings.Contains(output, "version") {
-			parts := strings.Split(output, ",")
-			var versionParts []string
-			for _, part := range parts {
-				part = strings.TrimSpace(part)
-				if strings.HasPrefix(part, "version ") {
-					versionParts = append(versionParts, strings.TrimPrefix(part, "version "))
-				} else if strings.HasPrefix(part, "subversion ") {
-					versionParts = append(versionParts, strings.TrimPrefix(part, "subversion "))
-				}
-			}
-			if len(versionParts) >= 2 {
-				return versionParts[0] + "." + versionParts[1]
-			}
-		}
-	}
-
-	// For "PHP 8.1.0 (cli) (built: Nov 23 2021)"
-	if strings.HasPrefix(strings.ToUpper(output), "PHP ") {
-		parts := strings.Fields(output)
-		if len(parts) >= 2 {
-			return parts[1]
-		}
-	}
-
-	// For "javac 17.0.1" or "java version \"17.0.1\""
-	if strings.Contains(strings.ToLower(output), "java") {
-		parts := strings.Fields(output)
-		for i, part := range parts {
-			if (part == "version" || strings.HasPrefix(part, "version")) && i+1 < len(parts) {
-				version := parts[i+1]
-				// Remove quotes
-				version = strings.Trim(version, "\"'")
-				return version
-			}
-		}
-		// Try second field
-		if len(parts) >= 2 {
-			return parts[1]
-		}
-	}
-
-	// For C# / dotnet: "6.0.100"
-	if !strings.Contains(output, " ") {
-		// Just a version number
-		return output
-	}
-
-	// Generic: try to find version-like pattern (e.g., "1.2.3")
-	fields := strings.Fields(output)
-	for _, field := range fields {
-		// Check if field looks like a version (contains digits and dots)
-		if strings.Contains(field, ".") {
-			// Clean up any surrounding characters
-			field = strings.Trim(field, "()[]{}\"',")
-			if len(field) > 0 && (field[0] >= '0' && field[0] <= '9') {
-				return field
-			}
-		}
-	}
-
-	// If no pattern matches, return the first line as-is
-	return output
+	return version.Extract(versionOutput)
 }
 
 func checkNvmInstalled() bool {
@@ -328,11 +230,11 @@ func checkNvmInstalled() bool {
 }
 
 // detectGamingPlatforms returns gaming platform checks based on the OS
-func detectGamingPlatforms() map[string]RuntimeCheck {
+func detectGamingPlatforms(ctx context.Context) map[string]RuntimeCheck {
 	checks := make(map[string]RuntimeCheck)
 
 	// Steam detection - cross-platform
-	steamCmd := detectSteamCommand()
+	steamCmd := detectSteamCommand(ctx)
 	if len(steamCmd) > 0 {
 		checks["Steam"] = RuntimeCheck{steamCmd, "gaming"}
 	}
@@ -341,7 +243,7 @@ func detectGamingPlatforms() map[string]RuntimeCheck {
 }
 
 // detectSteamCommand returns the appropriate command to check Steam installation
-func detectSteamCommand() []string {
+func detectSteamCommand(ctx context.Context) []string {
 	osType := runtime.GOOS
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -351,7 +253,7 @@ func detectSteamCommand() []string {
 	switch osType {
 	case "linux":
 		// Try command-line first
-		if exists("steam") {
+		if exists(ctx, "steam") {
 			return []string{"bash", "-c", "steam --version 2>/dev/null | head -1 || echo 'Steam (installed)'"}
 		}
 
@@ -383,7 +285,7 @@ func detectSteamCommand() []string {
 
 	case "windows":
 		// Check Windows registry for Steam installation
-		if exists("reg") {
+		if exists(ctx, "reg") {
 			return []string{"cmd", "/c", "reg query \"HKCU\\Software\\Valve\\Steam\" /v SteamPath >nul 2>&1 && echo Steam (installed) || echo"}
 		}
 
@@ -1,12 +1,14 @@
 package cmd
 
 import (
+	"context"
 	"strings"
 	"testing"
 )
 
 func TestDetectGamingPlatforms(t *testing.T) {
-	checks := detectGamingPlatforms()
+	ctx := context.Background()
+	checks := detectGamingPlatforms(ctx)
 
 	// Test should always return a map (empty if no gaming platforms found)
 	if checks == nil {
@@ -15,7 +17,8 @@ func TestDetectGamingPlatforms(t *testing.T) {
 }
 
 func TestDetectSteamCommand(t *testing.T) {
-	cmd := detectSteamCommand()
+	ctx := context.Background()
+	cmd := detectSteamCommand(ctx)
 
 	// This test is environment-dependent, so we just check the return type
 	// If Steam is installed, cmd should be non-nil and have elements
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -12,14 +13,19 @@ var RuntimesCmd = &cobra.Command{
 	Long: `Display detected programming language runtimes and their versions.
 
 This is the same output shown in the 'Runtimes:' section of 'allbctl status'.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		PrintRuntimes()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if isStructuredOutput() {
+			return printStructured(detectRuntimes(ctx))
+		}
+		PrintRuntimes(ctx)
+		return nil
 	},
 }
 
 // PrintRuntimes outputs the runtimes in inline format (same as status command)
-func PrintRuntimes() {
-	runtimesInline := detectRuntimesInline()
+func PrintRuntimes(ctx context.Context) {
+	runtimesInline := detectRuntimesInline(ctx)
 	if runtimesInline != "" {
 		fmt.Println(runtimesInline)
 	} else {
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"strings"
 	"testing"
 )
@@ -18,8 +19,9 @@ func Test_RuntimesCommand_DetectsRuntimes(t *testing.T) {
 }
 
 func Test_DetectRuntimesInline_IncludesVersion(t *testing.T) {
+	ctx := context.Background()
 	// This test verifies that runtime detection includes versions in inline format
-	runtimesInline := detectRuntimesInline()
+	runtimesInline := detectRuntimesInline(ctx)
 	// If any runtimes are detected, they should include version info in parentheses
 	if runtimesInline != "" {
 		// Check that at least one runtime has version info (contains parentheses)
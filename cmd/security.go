@@ -1,35 +1,41 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
 	"runtime"
 	"strings"
 
 	"github.com/spf13/cobra"
+
+	"github.com/aallbrig/allbctl/pkg/runner"
 )
 
 var SecurityCmd = &cobra.Command{
 	Use:   "security",
 	Short: "Display security and authentication status",
 	Long:  `Display information about SSH keys, GPG keys, and kernel keyring.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		PrintSecurityInfo()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		info := gatherSecurityInfo(ctx)
+		return renderOutput(info, func() { printSecurityInfo(info) })
 	},
 }
 
 type SecurityInfo struct {
-	SSHKeys     []string
-	GPGKeys     []string
-	KeyringInfo string
+	SSHKeys     []string `json:"ssh_keys"`
+	GPGKeys     []string `json:"gpg_keys"`
+	KeyringInfo string   `json:"keyring_info,omitempty"`
+}
+
+func PrintSecurityInfo(ctx context.Context) {
+	printSecurityInfo(gatherSecurityInfo(ctx))
 }
 
-func PrintSecurityInfo() {
+func printSecurityInfo(info *SecurityInfo) {
 	fmt.Println("Security/Authentication Status:")
 	fmt.Println()
 
-	info := gatherSecurityInfo()
-
 	// SSH Keys
 	fmt.Printf("  SSH Keys (loaded in agent):\n")
 	if len(info.SSHKeys) > 0 {
@@ -68,16 +74,15 @@ func PrintSecurityInfo() {
 	}
 }
 
-func gatherSecurityInfo() *SecurityInfo {
+func gatherSecurityInfo(ctx context.Context) *SecurityInfo {
 	info := &SecurityInfo{
 		SSHKeys: []string{},
 		GPGKeys: []string{},
 	}
 
 	// Get SSH keys
-	if exists("ssh-add") {
-		cmd := exec.Command("ssh-add", "-l")
-		out, err := cmd.Output()
+	if exists(ctx, "ssh-add") {
+		out, err := runner.Output(ctx, "ssh-add", "-l")
 		if err == nil {
 			lines := strings.Split(strings.TrimSpace(string(out)), "\n")
 			for _, line := range lines {
@@ -102,9 +107,8 @@ func gatherSecurityInfo() *SecurityInfo {
 	}
 
 	// Get GPG keys
-	if exists("gpg") {
-		cmd := exec.Command("gpg", "--list-keys", "--keyid-format", "SHORT")
-		out, err := cmd.Output()
+	if exists(ctx, "gpg") {
+		out, err := runner.Output(ctx, "gpg", "--list-keys", "--keyid-format", "SHORT")
 		if err == nil {
 			lines := strings.Split(string(out), "\n")
 			var currentKey string
@@ -132,9 +136,8 @@ func gatherSecurityInfo() *SecurityInfo {
 	}
 
 	// Get kernel keyring (Linux only)
-	if runtime.GOOS == "linux" && exists("keyctl") {
-		cmd := exec.Command("keyctl", "show", "@u")
-		out, err := cmd.Output()
+	if runtime.GOOS == "linux" && exists(ctx, "keyctl") {
+		out, err := runner.Output(ctx, "keyctl", "show", "@u")
 		if err == nil {
 			output := strings.TrimSpace(string(out))
 			// Count keys (lines that contain "keyring" or have key IDs)
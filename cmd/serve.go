@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spf13/cobra"
+
+	"github.com/aallbrig/allbctl/pkg/exporter"
+	"github.com/aallbrig/allbctl/pkg/telemetry"
+	"github.com/aallbrig/allbctl/pkg/version"
+)
+
+var (
+	serveListen      string
+	serveAllowRemote bool
+)
+
+// ServeCmd serves status data over HTTP
+var ServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve status data as Prometheus metrics and a JSON API",
+	Long: `Serve what 'allbctl status' knows about this machine as Prometheus metrics
+on /metrics, and as a JSON API under /v1.
+
+Each group of metrics is refreshed in the background on its own schedule, so
+scrapes are fast and expensive checks run rarely:
+
+  ports        every 15s   allbctl_listening_ports{protocol}
+  systemd      every 30s   allbctl_systemd_units_running{scope}, allbctl_systemd_units_failed{scope}
+  containers   every 30s   allbctl_containers_running{runtime}, allbctl_container_images{runtime}
+  projects     every 5m    allbctl_projects, allbctl_projects_dirty, allbctl_projects_unpushed_commits
+  packages     every 1h    allbctl_packages_installed{manager}, allbctl_package_updates_pending{manager}
+  runtimes     every 1h    allbctl_runtime_info{runtime,version,category}
+
+allbctl_collector_up, allbctl_collector_duration_seconds and
+allbctl_collector_last_success_timestamp_seconds report how each group's
+latest refresh went.
+
+serve listens on 127.0.0.1 only. Binding another address, such as :9742 for
+every interface, needs --allow-remote: the API token and the data travel over
+plain HTTP, so only do so on a network you trust or behind a TLS proxy.
+
+The JSON API is served when the config file sets a token, which clients send
+as "Authorization: Bearer <token>":
+
+  serve:
+    token: a-long-random-string
+
+  GET /v1/status               status snapshot (?sections=cpu,memory&skip=packages)
+  GET /v1/projects             repos under ~/src (?filter=dirty|clean)
+  GET /v1/packages             package and update counts per manager
+  GET /v1/packages/{manager}   one manager's packages (?recent=true)
+  GET /v1/bootstrap            bootstrap status checks
+  GET /v1/ports                listening ports
+  GET /v1/network              network interfaces and connectivity
+  GET /v1/containers           container runtimes
+
+Responses are the same JSON documents the matching commands print with
+--output json. /v1/status is served from sections collected in the
+background (each as often as 'status --watch' refreshes it, at least every
+30s), so polling it never starts a collection; sections not collected yet
+are reported as pending.
+
+Examples:
+  allbctl serve                    # Listen on 127.0.0.1:9742
+  allbctl serve --listen 127.0.0.1:9100
+  allbctl serve --listen :9742 --allow-remote
+  curl -s localhost:9742/metrics | grep ^allbctl_
+  curl -s -H "Authorization: Bearer $TOKEN" localhost:9742/v1/projects?filter=dirty`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+		defer stop()
+
+		if err := checkListenAddress(serveListen, serveAllowRemote); err != nil {
+			return err
+		}
+		config, err := loadServeConfig()
+		if err != nil {
+			return err
+		}
+
+		sources := statusMetricSources(ctx)
+		var api *statusAPI
+		if config.Token != "" {
+			statusConfig, err := loadStatusConfig()
+			if err != nil {
+				return err
+			}
+			entries, err := selectStatusSections(statusSections, nil, nil, statusConfig.Timeouts)
+			if err != nil {
+				return err
+			}
+			cache := newStatusCache(entries)
+			sources = append(sources, cache.source())
+			api = newStatusAPI(config.Token, cache)
+		}
+		metrics := exporter.New(sources...)
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(metrics, newBuildInfoCollector())
+
+		mux := newServeMux(registry)
+		if api != nil {
+			api.register(mux)
+		} else {
+			fmt.Fprintln(os.Stderr, "JSON API disabled: set serve.token in the config file to enable /v1")
+		}
+
+		server := &http.Server{
+			Addr:              serveListen,
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go metrics.Run(ctx)
+
+		serveErr := make(chan error, 1)
+		go func() {
+			serveErr <- server.ListenAndServe()
+		}()
+		telemetry.Logger.InfoContext(ctx, "serve.listen", "addr", serveListen)
+		fmt.Fprintf(os.Stderr, "Serving on %s\n", serveListen)
+
+		select {
+		case err := <-serveErr:
+			return fmt.Errorf("serving on %s: %w", serveListen, err)
+		case <-ctx.Done():
+		}
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	ServeCmd.Flags().StringVar(&serveListen, "listen", "127.0.0.1:9742", "Address to listen on")
+	ServeCmd.Flags().BoolVar(&serveAllowRemote, "allow-remote", false, "Allow --listen on an address other hosts can reach")
+}
+
+// checkListenAddress refuses addresses other hosts can reach, such as :9742
+// or 0.0.0.0:9742, unless allowRemote is set
+func checkListenAddress(addr string, allowRemote bool) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid --listen address %q: %w", addr, err)
+	}
+	if allowRemote || host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("--listen %s is reachable from other hosts, which would send the API token over plain HTTP; pass --allow-remote to listen there anyway", addr)
+}
+
+// newServeMux routes the serve endpoints
+func newServeMux(registry *prometheus.Registry) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	return mux
+}
+
+// newBuildInfoCollector reports the running allbctl version
+func newBuildInfoCollector() prometheus.Collector {
+	buildInfo := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: exporter.Namespace,
+		Name:      "build_info",
+		Help:      "The allbctl version serving these metrics.",
+	}, []string{"version", "commit"})
+	buildInfo.WithLabelValues(Version, Commit).Set(1)
+	return buildInfo
+}
+
+func metricDesc(name, help string, labels ...string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(exporter.Namespace, "", name), help, labels, nil)
+}
+
+var (
+	listeningPortsDesc  = metricDesc("listening_ports", "Listening ports.", "protocol")
+	systemdRunningDesc  = metricDesc("systemd_units_running", "Running systemd services.", "scope")
+	systemdFailedDesc   = metricDesc("systemd_units_failed", "Failed systemd services.", "scope")
+	containersDesc      = metricDesc("containers_running", "Running containers.", "runtime")
+	containerImagesDesc = metricDesc("container_images", "Container images.", "runtime")
+	projectsDesc        = metricDesc("projects", "Git repositories under ~/src.")
+	projectsDirtyDesc   = metricDesc("projects_dirty", "Git repositories under ~/src with uncommitted or untracked changes.")
+	unpushedDesc        = metricDesc("projects_unpushed_commits", "Commits ahead of upstream, summed over the git repositories under ~/src.")
+	packagesDesc        = metricDesc("packages_installed", "Installed packages.", "manager")
+	packageUpdatesDesc  = metricDesc("package_updates_pending", "Packages with an update available.", "manager")
+	runtimeInfoDesc     = metricDesc("runtime_info", "A detected development runtime; always 1.", "runtime", "version", "category")
+)
+
+// statusMetricSources lists the metric groups serve refreshes, each on its
+// own schedule. Collectors use the context Refresh hands them so a source's
+// Timeout stops its commands; ctx is only used to probe for systemctl.
+func statusMetricSources(ctx context.Context) []exporter.Source {
+	sources := []exporter.Source{
+		{
+			Name: "ports", Interval: 15 * time.Second, Timeout: 10 * time.Second,
+			Descs:   []*prometheus.Desc{listeningPortsDesc},
+			Collect: func(ctx context.Context) ([]prometheus.Metric, error) { return portMetrics(gatherPortsInfo(ctx)), nil },
+		},
+		{
+			Name: "containers", Interval: 30 * time.Second, Timeout: 20 * time.Second,
+			Descs: []*prometheus.Desc{containersDesc, containerImagesDesc},
+			Collect: func(ctx context.Context) ([]prometheus.Metric, error) {
+				return containerMetrics(gatherContainersReport(ctx)), nil
+			},
+		},
+		{
+			Name: "projects", Interval: 5 * time.Minute, Timeout: 2 * time.Minute,
+			Descs: []*prometheus.Desc{projectsDesc, projectsDirtyDesc, unpushedDesc},
+			Collect: func(ctx context.Context) ([]prometheus.Metric, error) {
+				summary, err := gatherProjects(ctx)
+				if err != nil {
+					return nil, err
+				}
+				return projectMetrics(summary), nil
+			},
+		},
+		{
+			Name: "packages", Interval: time.Hour, Timeout: 10 * time.Minute,
+			Descs: []*prometheus.Desc{packagesDesc, packageUpdatesDesc},
+			Collect: func(ctx context.Context) ([]prometheus.Metric, error) {
+				return packageMetrics(StartPackageSummary(ctx).Results()), nil
+			},
+		},
+		{
+			Name: "runtimes", Interval: time.Hour, Timeout: time.Minute,
+			Descs: []*prometheus.Desc{runtimeInfoDesc},
+			Collect: func(ctx context.Context) ([]prometheus.Metric, error) {
+				return runtimeMetrics(detectRuntimes(ctx)), nil
+			},
+		},
+	}
+	if runtime.GOOS == "linux" && exists(ctx, "systemctl") {
+		sources = append(sources, exporter.Source{
+			Name: "systemd", Interval: 30 * time.Second, Timeout: 20 * time.Second,
+			Descs: []*prometheus.Desc{systemdRunningDesc, systemdFailedDesc},
+			Collect: func(ctx context.Context) ([]prometheus.Metric, error) {
+				return systemdMetrics(gatherSystemctlInfo(ctx)), nil
+			},
+		})
+	}
+	return sources
+}
+
+func gauge(desc *prometheus.Desc, value int, labels ...string) prometheus.Metric {
+	return prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(value), labels...)
+}
+
+func portMetrics(info *PortInfo) []prometheus.Metric {
+	if info == nil {
+		return nil
+	}
+	return []prometheus.Metric{
+		gauge(listeningPortsDesc, info.TCPPorts, "tcp"),
+		gauge(listeningPortsDesc, info.UDPPorts, "udp"),
+	}
+}
+
+func systemdMetrics(info *SystemctlInfo) []prometheus.Metric {
+	return []prometheus.Metric{
+		gauge(systemdRunningDesc, info.SystemRunning, "system"),
+		gauge(systemdRunningDesc, info.UserRunning, "user"),
+		gauge(systemdFailedDesc, info.SystemFailed, "system"),
+		gauge(systemdFailedDesc, info.UserFailed, "user"),
+	}
+}
+
+func containerMetrics(report *ContainersReport) []prometheus.Metric {
+	var metrics []prometheus.Metric
+	for name, info := range map[string]*ContainerInfo{"docker": report.Docker, "podman": report.Podman} {
+		if info == nil {
+			continue
+		}
+		metrics = append(metrics,
+			gauge(containersDesc, info.Running, name),
+			gauge(containerImagesDesc, info.Images, name),
+		)
+	}
+	return metrics
+}
+
+func projectMetrics(summary *ProjectsSummary) []prometheus.Metric {
+	if summary == nil {
+		summary = &ProjectsSummary{}
+	}
+	unpushed := 0
+	for _, repo := range summary.Repos {
+		unpushed += repo.UnpushedCommits
+	}
+	return []prometheus.Metric{
+		gauge(projectsDesc, summary.Total),
+		gauge(projectsDirtyDesc, summary.Dirty),
+		gauge(unpushedDesc, unpushed),
+	}
+}
+
+func packageMetrics(results []PackageResult) []prometheus.Metric {
+	var metrics []prometheus.Metric
+	for _, result := range results {
+		metrics = append(metrics,
+			gauge(packagesDesc, result.Count, result.Manager),
+			gauge(packageUpdatesDesc, result.UpdateCount, result.Manager),
+		)
+	}
+	return metrics
+}
+
+func runtimeMetrics(runtimes []RuntimeInfo) []prometheus.Metric {
+	var metrics []prometheus.Metric
+	seen := map[string]bool{}
+	for _, rt := range runtimes {
+		ver := version.Extract(rt.Version)
+		if ver == "" {
+			ver = rt.Version
+		}
+		// A registry rejects duplicate series, e.g. one runtime found twice
+		key := rt.Name + "\x00" + ver + "\x00" + rt.Category
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		metrics = append(metrics, gauge(runtimeInfoDesc, 1, rt.Name, ver, rt.Category))
+	}
+	return metrics
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// serveConfigKey is the config file section that configures 'allbctl serve'
+const serveConfigKey = "serve"
+
+// ServeConfig is the `serve:` section of ~/.allbctl.yaml
+type ServeConfig struct {
+	// Token must be sent as "Authorization: Bearer <token>" to use the /v1
+	// API; without one the API is not served
+	Token string `mapstructure:"token"`
+}
+
+// loadServeConfig reads the `serve:` section of the config file, if any
+func loadServeConfig() (ServeConfig, error) {
+	var config ServeConfig
+	if !viper.IsSet(serveConfigKey) {
+		return config, nil
+	}
+	if err := viper.UnmarshalKey(serveConfigKey, &config); err != nil {
+		return config, fmt.Errorf("cannot read %s from %s: %w", serveConfigKey, viper.ConfigFileUsed(), err)
+	}
+	config.Token = strings.TrimSpace(config.Token)
+	return config, nil
+}
+
+// statusAPI serves machine state as JSON, using the same structs the CLI
+// prints with --output json. The collectors are fields so tests can replace
+// them.
+type statusAPI struct {
+	token string
+
+	status     func(ctx context.Context, sections []statusSectionEntry) *SystemSnapshot
+	projects   func(ctx context.Context) (*ProjectsSummary, error)
+	packages   func(ctx context.Context) []PackageResult
+	listing    func(ctx context.Context, manager string, recent bool) (*PackageListing, error)
+	bootstrap  func(ctx context.Context) (*BootstrapStatusReport, error)
+	ports      func(ctx context.Context) *PortInfo
+	network    func(ctx context.Context) *NetworkDetails
+	containers func(ctx context.Context) *ContainersReport
+}
+
+func newStatusAPI(token string, status *statusCache) *statusAPI {
+	return &statusAPI{
+		token:      token,
+		status:     status.snapshot,
+		projects:   gatherProjects,
+		packages:   func(ctx context.Context) []PackageResult { return StartPackageSummary(ctx).Results() },
+		listing:    packageListing,
+		bootstrap:  collectBootstrapStatus,
+		ports:      gatherPortsInfo,
+		network:    gatherNetworkDetails,
+		containers: gatherContainersReport,
+	}
+}
+
+// register adds the /v1 endpoints to mux
+func (api *statusAPI) register(mux *http.ServeMux) {
+	mux.Handle("GET /v1/status", api.handle(api.getStatus))
+	mux.Handle("GET /v1/projects", api.handle(api.getProjects))
+	mux.Handle("GET /v1/packages", api.handle(func(r *http.Request) (any, error) { return api.packages(r.Context()), nil }))
+	mux.Handle("GET /v1/packages/{manager}", api.handle(api.getPackageListing))
+	mux.Handle("GET /v1/bootstrap", api.handle(func(r *http.Request) (any, error) { return api.bootstrap(r.Context()) }))
+	mux.Handle("GET /v1/ports", api.handle(func(r *http.Request) (any, error) { return api.ports(r.Context()), nil }))
+	mux.Handle("GET /v1/network", api.handle(func(r *http.Request) (any, error) { return api.network(r.Context()), nil }))
+	mux.Handle("GET /v1/containers", api.handle(func(r *http.Request) (any, error) { return api.containers(r.Context()), nil }))
+}
+
+// handle checks the token and writes the result of get as JSON
+func (api *statusAPI) handle(get func(r *http.Request) (any, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !api.authorized(r) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="allbctl"`)
+			writeJSONError(w, http.StatusUnauthorized, "missing or invalid token")
+			return
+		}
+
+		data, err := get(r)
+		var apiErr *apiError
+		switch {
+		case errors.As(err, &apiErr):
+			writeJSONError(w, apiErr.status, err.Error())
+		case err != nil:
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+		default:
+			writeJSON(w, http.StatusOK, data)
+		}
+	})
+}
+
+// authorized reports whether r carries the configured bearer token
+func (api *statusAPI) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && api.token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(api.token)) == 1
+}
+
+// getStatus returns the status snapshot from the sections serve collects in
+// the background. ?sections= and ?skip= work like the status flags; without
+// them the layout comes from the config file.
+func (api *statusAPI) getStatus(r *http.Request) (any, error) {
+	config, err := loadStatusConfig()
+	if err != nil {
+		return nil, err
+	}
+	query := r.URL.Query()
+	if sections := query.Get("sections"); sections != "" {
+		config.Sections = strings.Split(sections, ",")
+	}
+	if skip := query.Get("skip"); skip != "" {
+		config.Skip = append(config.Skip, strings.Split(skip, ",")...)
+	}
+	sections, err := selectStatusSections(statusSections, config.Sections, config.Skip, config.Timeouts)
+	if err != nil {
+		return nil, &apiError{http.StatusBadRequest, err}
+	}
+	return api.status(r.Context(), sections), nil
+}
+
+// getProjects returns the repos under the project roots; ?filter=dirty|clean
+// narrows them
+func (api *statusAPI) getProjects(r *http.Request) (any, error) {
+	summary, err := api.projects(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	if summary == nil {
+		summary = &ProjectsSummary{}
+	}
+	switch filter := r.URL.Query().Get("filter"); filter {
+	case "":
+	case "dirty", "clean":
+		summary.Repos = filterRepos(summary.Repos, filter)
+	default:
+		return nil, &apiError{http.StatusBadRequest, fmt.Errorf("unknown filter %q (want dirty or clean)", filter)}
+	}
+	if summary.Repos == nil {
+		summary.Repos = []RepoInfo{}
+	}
+	return summary, nil
+}
+
+// getPackageListing lists one manager's packages; ?recent=true adds the
+// recently installed ones
+func (api *statusAPI) getPackageListing(r *http.Request) (any, error) {
+	listing, err := api.listing(r.Context(), r.PathValue("manager"), r.URL.Query().Get("recent") == "true")
+	if err != nil {
+		return nil, &apiError{http.StatusNotFound, err}
+	}
+	return listing, nil
+}
+
+// apiError is an error the API reports with a specific HTTP status
+type apiError struct {
+	status int
+	err    error
+}
+
+func (e *apiError) Error() string { return e.err.Error() }
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = writeStructured(w, outputJSON, data) //nolint:errcheck // the client may have gone away
+}
+
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/viper"
+)
+
+func newTestStatusAPI() *statusAPI {
+	api := newStatusAPI("s3cret", newStatusCache(nil))
+	api.status = func(ctx context.Context, sections []statusSectionEntry) *SystemSnapshot {
+		snapshot := testSystemSnapshot()
+		for _, entry := range sections {
+			snapshot.Sections = append(snapshot.Sections, SectionOutcome{Name: entry.section.Name(), Status: sectionOK})
+		}
+		return snapshot
+	}
+	api.projects = func(context.Context) (*ProjectsSummary, error) {
+		return &ProjectsSummary{Total: 2, Dirty: 1, Repos: []RepoInfo{
+			{Path: "/home/me/src/a", Dirty: true},
+			{Path: "/home/me/src/b"},
+		}}, nil
+	}
+	api.packages = func(context.Context) []PackageResult {
+		return []PackageResult{{Manager: "apt", Count: 42, UpdateCount: 3}}
+	}
+	api.listing = func(_ context.Context, manager string, recent bool) (*PackageListing, error) {
+		if manager != "apt" {
+			return nil, fmt.Errorf("package manager '%s' not found on this system", manager)
+		}
+		listing := &PackageListing{Manager: "apt", Count: 1, Packages: []string{"jq"}}
+		if recent {
+			listing.Recent = []string{"jq"}
+		}
+		return listing, nil
+	}
+	api.ports = func(context.Context) *PortInfo { return &PortInfo{TCPPorts: 2, Ports: []string{"tcp:22", "tcp:80"}} }
+	return api
+}
+
+func apiGet(t *testing.T, server *httptest.Server, path, token string) (*http.Response, map[string]any) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var body map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("GET %s: decoding response: %v", path, err)
+	}
+	return resp, body
+}
+
+func TestStatusAPI(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	mux := newServeMux(prometheus.NewRegistry())
+	newTestStatusAPI().register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		path       string
+		token      string
+		wantStatus int
+		wantKey    string
+		wantValue  string
+	}{
+		{path: "/v1/ports", wantStatus: http.StatusUnauthorized, wantKey: "error", wantValue: "missing or invalid token"},
+		{path: "/v1/ports", token: "wrong", wantStatus: http.StatusUnauthorized, wantKey: "error", wantValue: "missing or invalid token"},
+		{path: "/v1/ports", token: "s3cret", wantStatus: http.StatusOK, wantKey: "tcp_ports", wantValue: "2"},
+		{path: "/v1/status?sections=memory,cpu", token: "s3cret", wantStatus: http.StatusOK, wantKey: "hostname", wantValue: "testhost"},
+		{path: "/v1/status?sections=nope", token: "s3cret", wantStatus: http.StatusBadRequest, wantKey: "error", wantValue: `unknown status section "nope"`},
+		{path: "/v1/projects?filter=dirty", token: "s3cret", wantStatus: http.StatusOK, wantKey: "repos", wantValue: "/home/me/src/a"},
+		{path: "/v1/projects?filter=stale", token: "s3cret", wantStatus: http.StatusBadRequest, wantKey: "error", wantValue: "unknown filter"},
+		{path: "/v1/packages/apt?recent=true", token: "s3cret", wantStatus: http.StatusOK, wantKey: "recent", wantValue: "jq"},
+		{path: "/v1/packages/zypper", token: "s3cret", wantStatus: http.StatusNotFound, wantKey: "error", wantValue: "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, body := apiGet(t, server, tt.path, tt.token)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("GET %s status = %d, want %d (body %v)", tt.path, resp.StatusCode, tt.wantStatus, body)
+			}
+			if got := fmt.Sprint(body[tt.wantKey]); !strings.Contains(got, tt.wantValue) {
+				t.Errorf("GET %s %s = %q, want it to contain %q", tt.path, tt.wantKey, got, tt.wantValue)
+			}
+		})
+	}
+
+	// Filtering dirty repos leaves the clean one out
+	_, body := apiGet(t, server, "/v1/projects?filter=dirty", "s3cret")
+	if repos := body["repos"].([]any); len(repos) != 1 {
+		t.Errorf("GET /v1/projects?filter=dirty returned %d repos, want 1", len(repos))
+	}
+	// The requested sections are collected, in order
+	_, body = apiGet(t, server, "/v1/status?sections=memory,cpu", "s3cret")
+	if sections := body["sections"].([]any); len(sections) != 2 || sections[0].(map[string]any)["name"] != "memory" {
+		t.Errorf("GET /v1/status?sections=memory,cpu sections = %v", sections)
+	}
+}
+
+func TestStatusAPI_PackagesIsAList(t *testing.T) {
+	mux := http.NewServeMux()
+	newTestStatusAPI().register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/v1/packages", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer s3cret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var results []PackageResult
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Manager != "apt" || results[0].UpdateCount != 3 {
+		t.Errorf("GET /v1/packages = %+v", results)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+}
+
+func TestLoadServeConfig(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	if config, err := loadServeConfig(); err != nil || config.Token != "" {
+		t.Fatalf("loadServeConfig() without config = %+v, %v", config, err)
+	}
+
+	viper.SetConfigType("yaml")
+	if err := viper.ReadConfig(strings.NewReader("serve:\n  token: \" abc123 \"\n")); err != nil {
+		t.Fatal(err)
+	}
+	config, err := loadServeConfig()
+	if err != nil || config.Token != "abc123" {
+		t.Errorf("loadServeConfig() = %+v, %v; want token abc123", config, err)
+	}
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/aallbrig/allbctl/pkg/exporter"
+)
+
+// statusCacheInterval is how often serve looks for status sections that are
+// due; each is collected again once its --watch refresh cadence has passed
+const statusCacheInterval = 30 * time.Second
+
+// statusCache keeps the latest result of every status section for GET
+// /v1/status. serve refreshes it in the background like its metric groups,
+// so a request only reads what was already collected.
+type statusCache struct {
+	entries []statusSectionEntry
+
+	mu      sync.Mutex
+	results map[string]statusSectionResult
+	lastRun map[string]time.Time
+	running map[string]bool
+}
+
+func newStatusCache(entries []statusSectionEntry) *statusCache {
+	return &statusCache{
+		entries: entries,
+		results: map[string]statusSectionResult{},
+		lastRun: map[string]time.Time{},
+		running: map[string]bool{},
+	}
+}
+
+// source refreshes the cache on the exporter's schedule. It reports no
+// metrics of its own beyond the allbctl_collector_* ones for "status".
+func (c *statusCache) source() exporter.Source {
+	timeout := statusCacheInterval
+	for _, entry := range c.entries {
+		timeout = max(timeout, entry.timeout)
+	}
+	return exporter.Source{
+		Name: "status", Interval: statusCacheInterval, Timeout: timeout,
+		Collect: func(ctx context.Context) ([]prometheus.Metric, error) {
+			c.refresh(ctx)
+			return nil, nil
+		},
+	}
+}
+
+// refresh collects the sections that are due and not still running from an
+// earlier refresh, and waits for them
+func (c *statusCache) refresh(ctx context.Context) {
+	now := time.Now()
+	var wg sync.WaitGroup
+	for _, entry := range c.entries {
+		name := entry.section.Name()
+		c.mu.Lock()
+		last, ran := c.lastRun[name]
+		due := !c.running[name] && (!ran || now.Sub(last) >= entry.refresh)
+		if due {
+			c.running[name] = true
+			c.lastRun[name] = now
+		}
+		c.mu.Unlock()
+		if !due {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			outcome, store := runStatusSection(ctx, entry)
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.running[name] = false
+			c.results[name] = statusSectionResult{outcome: outcome, store: store}
+		}()
+	}
+	wg.Wait()
+}
+
+// snapshot builds a status snapshot of sections from the cached results.
+// Sections not collected yet are reported as pending.
+func (c *statusCache) snapshot(_ context.Context, sections []statusSectionEntry) *SystemSnapshot {
+	snapshot := newSystemSnapshot()
+	outcomes := make([]SectionOutcome, 0, len(sections))
+
+	c.mu.Lock()
+	for _, entry := range sections {
+		result, ok := c.results[entry.section.Name()]
+		if !ok {
+			outcomes = append(outcomes, SectionOutcome{Name: entry.section.Name(), Status: sectionPending})
+			continue
+		}
+		if result.store != nil {
+			result.store(snapshot)
+		}
+		outcomes = append(outcomes, result.outcome)
+	}
+	c.mu.Unlock()
+
+	snapshot.Sections = outcomes
+	fillEmptySnapshotLists(snapshot)
+	return snapshot
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/aallbrig/allbctl/pkg/exporter"
+	"github.com/aallbrig/allbctl/pkg/runner"
+)
+
+func TestServeMux_Metrics(t *testing.T) {
+	source := exporter.Source{
+		Name: "status", Interval: time.Minute, Timeout: time.Second,
+		Collect: func(context.Context) ([]prometheus.Metric, error) {
+			var metrics []prometheus.Metric
+			metrics = append(metrics, portMetrics(&PortInfo{TCPPorts: 2, UDPPorts: 1})...)
+			metrics = append(metrics, systemdMetrics(&SystemctlInfo{SystemRunning: 30, SystemFailed: 1})...)
+			metrics = append(metrics, containerMetrics(&ContainersReport{Docker: &ContainerInfo{Running: 4, Images: 9}})...)
+			metrics = append(metrics, projectMetrics(&ProjectsSummary{Total: 3, Dirty: 1, Repos: []RepoInfo{
+				{UnpushedCommits: 2}, {UnpushedCommits: 5}, {},
+			}})...)
+			metrics = append(metrics, packageMetrics([]PackageResult{{Manager: "apt", Count: 42, UpdateCount: 3}})...)
+			metrics = append(metrics, runtimeMetrics([]RuntimeInfo{
+				{Name: "Go", Version: "go version go1.22.1 linux/amd64", Category: "language"},
+				{Name: "Go", Version: "go version go1.22.1 linux/amd64", Category: "language"},
+			})...)
+			return metrics, nil
+		},
+	}
+	metrics := exporter.New(source)
+	metrics.Refresh(context.Background(), source)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(metrics, newBuildInfoCollector())
+
+	server := httptest.NewServer(newServeMux(registry))
+	defer server.Close()
+	resp, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := string(body)
+
+	expected := []string{
+		`allbctl_build_info{commit="` + Commit + `",version="` + Version + `"} 1`,
+		`allbctl_collector_up{collector="status"} 1`,
+		`allbctl_listening_ports{protocol="tcp"} 2`,
+		`allbctl_listening_ports{protocol="udp"} 1`,
+		`allbctl_systemd_units_failed{scope="system"} 1`,
+		`allbctl_containers_running{runtime="docker"} 4`,
+		`allbctl_container_images{runtime="docker"} 9`,
+		`allbctl_projects 3`,
+		`allbctl_projects_dirty 1`,
+		`allbctl_projects_unpushed_commits 7`,
+		`allbctl_packages_installed{manager="apt"} 42`,
+		`allbctl_package_updates_pending{manager="apt"} 3`,
+		`allbctl_runtime_info{category="language",runtime="Go",version="1.22.1"} 1`,
+	}
+	for _, want := range expected {
+		if !strings.Contains(output, want) {
+			t.Errorf("/metrics missing %q\noutput:\n%s", want, output)
+		}
+	}
+}
+
+func TestStatusMetricSources(t *testing.T) {
+	ctx := context.Background()
+	seen := map[string]bool{}
+	for _, source := range statusMetricSources(ctx) {
+		if seen[source.Name] {
+			t.Errorf("source %q listed twice", source.Name)
+		}
+		seen[source.Name] = true
+		if source.Interval <= 0 || source.Timeout <= 0 || source.Collect == nil {
+			t.Errorf("source %q needs an interval, a timeout and a collector", source.Name)
+		}
+	}
+}
+
+// deadlineRunner fails every command, noting whether it was run with a deadline
+type deadlineRunner struct {
+	mu        sync.Mutex
+	runs      int
+	deadlines int
+}
+
+func (r *deadlineRunner) Run(ctx context.Context, _ runner.Cmd) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runs++
+	if _, ok := ctx.Deadline(); ok {
+		r.deadlines++
+	}
+	return errors.New("not run")
+}
+
+func (r *deadlineRunner) LookPath(name string) (string, error) { return "/usr/bin/" + name, nil }
+
+func TestStatusMetricSources_UseRefreshContext(t *testing.T) {
+	total := 0
+	for _, source := range statusMetricSources(context.Background()) {
+		fake := &deadlineRunner{}
+		ctx, cancel := context.WithTimeout(runner.WithRunner(context.Background(), fake), source.Timeout)
+		_, _ = source.Collect(ctx) //nolint:errcheck // Only the commands it ran matter
+		cancel()
+		total += fake.runs
+		if fake.deadlines != fake.runs {
+			t.Errorf("source %q ran %d commands, %d under the refresh deadline; want them all", source.Name, fake.runs, fake.deadlines)
+		}
+	}
+	if total == 0 {
+		t.Error("no source ran its commands through the context it was given")
+	}
+}
+
+func TestCheckListenAddress(t *testing.T) {
+	for _, addr := range []string{"127.0.0.1:9742", "localhost:9742", "[::1]:9742"} {
+		if err := checkListenAddress(addr, false); err != nil {
+			t.Errorf("checkListenAddress(%s) = %v, want loopback allowed", addr, err)
+		}
+	}
+	for _, addr := range []string{":9742", "0.0.0.0:9742", "[::]:9742", "192.168.1.10:9742", "myhost:9742"} {
+		if err := checkListenAddress(addr, false); err == nil || !strings.Contains(err.Error(), "--allow-remote") {
+			t.Errorf("checkListenAddress(%s) = %v, want it refused without --allow-remote", addr, err)
+		}
+		if err := checkListenAddress(addr, true); err != nil {
+			t.Errorf("checkListenAddress(%s, allow remote) = %v", addr, err)
+		}
+	}
+	if err := checkListenAddress("9742", false); err == nil {
+		t.Error("checkListenAddress(9742) should reject an address without a port separator")
+	}
+}
+
+func TestStatusCache(t *testing.T) {
+	var fastRuns, slowRuns int
+	entries := []statusSectionEntry{
+		{section: statusSection[int]{
+			name: "terminal", title: "Terminal",
+			collect: func(context.Context) int { fastRuns++; return fastRuns },
+			store:   func(s *SystemSnapshot, v int) { s.Terminal = fmt.Sprintf("tick %d", v) },
+		}, timeout: time.Second},
+		{section: statusSection[int]{
+			name: "packages", title: "Packages",
+			collect: func(context.Context) int { slowRuns++; return slowRuns },
+			store:   func(s *SystemSnapshot, v int) { s.Hardware = fmt.Sprintf("%d counts", v) },
+		}, timeout: time.Second, refresh: time.Hour},
+	}
+	cache := newStatusCache(entries)
+	if source := cache.source(); source.Name != "status" || source.Timeout < time.Second {
+		t.Errorf("source() = %+v", source)
+	}
+
+	// Before the first refresh every section is pending
+	snapshot := cache.snapshot(context.Background(), entries)
+	if len(snapshot.Sections) != 2 || snapshot.Sections[0].Status != sectionPending || snapshot.Terminal != "" {
+		t.Errorf("snapshot() before a refresh = %+v", snapshot.Sections)
+	}
+
+	cache.refresh(context.Background())
+	cache.refresh(context.Background())
+	if fastRuns != 2 || slowRuns != 1 {
+		t.Errorf("two refreshes collected terminal %d and packages %d times, want 2 and 1", fastRuns, slowRuns)
+	}
+
+	// Reading the cache collects nothing, and only has the sections asked for
+	snapshot = cache.snapshot(context.Background(), entries[1:])
+	if fastRuns != 2 || slowRuns != 1 {
+		t.Errorf("snapshot() collected sections")
+	}
+	if snapshot.Hardware != "1 counts" || snapshot.Terminal != "" || len(snapshot.Sections) != 1 || snapshot.Sections[0].Status != sectionOK {
+		t.Errorf("snapshot(packages) = %+v, sections %+v", snapshot, snapshot.Sections)
+	}
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/aallbrig/allbctl/pkg/telemetry"
+)
+
+// SystemSnapshot is everything `allbctl status` knows about the machine at one point in time.
+// The text view, --output json|yaml and other consumers all render from this struct.
+type SystemSnapshot struct {
+	CollectedAt     time.Time            `json:"collected_at"`
+	Version         string               `json:"version"`
+	Commit          string               `json:"commit"`
+	User            string               `json:"user"`
+	Hostname        string               `json:"hostname"`
+	OS              string               `json:"os"`
+	Shell           string               `json:"shell"`
+	Terminal        string               `json:"terminal"`
+	CPU             CPUDetails           `json:"cpu"`
+	GPUs            []GPUInfo            `json:"gpus"`
+	MemoryBytes     uint64               `json:"memory_bytes"`
+	Disks           []DiskInfo           `json:"disks"`
+	DiskSummary     string               `json:"disk_summary,omitempty"` // fallback when detailed disk info is unavailable
+	Hardware        string               `json:"hardware"`
+	Runtimes        []RuntimeInfo        `json:"runtimes"`
+	Databases       []DatabaseInfo       `json:"databases"`
+	Network         *NetworkDetails      `json:"network,omitempty"`
+	Ports           *PortInfo            `json:"ports,omitempty"`
+	Browsers        []BrowserInfo        `json:"browsers"`
+	AIAgents        []AIAgent            `json:"ai_agents"`
+	PackageManagers []PackageManagerInfo `json:"package_managers"`
+	Packages        []PackageResult      `json:"packages"`
+	CloudNative     []CloudCLIInfo       `json:"cloud_native"`
+	Projects        *ProjectsSummary     `json:"projects,omitempty"`
+	Sections        []SectionOutcome     `json:"sections,omitempty"` // how collecting each status section went
+}
+
+// memoryString formats total memory the way the text view shows it
+func (s *SystemSnapshot) memoryString() string {
+	if s.MemoryBytes == 0 {
+		return "Unknown"
+	}
+	return fmt.Sprintf("%.1f GiB", float64(s.MemoryBytes)/1e9)
+}
+
+// sectionCollected reports whether the named section was collected in full.
+// Snapshots without a record of their sections (e.g. older history) count
+// every section as collected.
+func (s *SystemSnapshot) sectionCollected(name string) bool {
+	if len(s.Sections) == 0 {
+		return true
+	}
+	for _, outcome := range s.Sections {
+		if outcome.Name == name {
+			return outcome.Status == sectionOK
+		}
+	}
+	return false
+}
+
+// collectSystemSnapshot runs the given status sections and returns the combined result
+func collectSystemSnapshot(ctx context.Context, sections []statusSectionEntry) *SystemSnapshot {
+	snapshot := newSystemSnapshot()
+	collectStatusSections(ctx, sections, snapshot)
+	fillEmptySnapshotLists(snapshot)
+	logSystemSnapshot(ctx, snapshot)
+	return snapshot
+}
+
+// fillEmptySnapshotLists reports "nothing detected" as an empty list rather
+// than null
+func fillEmptySnapshotLists(snapshot *SystemSnapshot) {
+	if snapshot.GPUs == nil {
+		snapshot.GPUs = []GPUInfo{}
+	}
+	if snapshot.Disks == nil {
+		snapshot.Disks = []DiskInfo{}
+	}
+	if snapshot.Browsers == nil {
+		snapshot.Browsers = []BrowserInfo{}
+	}
+	if snapshot.AIAgents == nil {
+		snapshot.AIAgents = []AIAgent{}
+	}
+}
+
+// newSystemSnapshot returns a snapshot with only the header filled in
+func newSystemSnapshot() *SystemSnapshot {
+	snapshot := &SystemSnapshot{
+		CollectedAt: time.Now(),
+		Version:     Version,
+		Commit:      Commit,
+	}
+
+	// Get current user for header
+	snapshot.User = os.Getenv("USER")
+	if snapshot.User == "" {
+		snapshot.User = os.Getenv("USERNAME")
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "Unknown"
+	}
+	snapshot.Hostname = hostname
+	return snapshot
+}
+
+// logSystemSnapshot emits a wide structured log and span attributes for a snapshot
+func logSystemSnapshot(ctx context.Context, snapshot *SystemSnapshot) {
+	runtimeNames := make([]string, 0, len(snapshot.Runtimes))
+	for _, rt := range snapshot.Runtimes {
+		if rt.Category == "language" {
+			runtimeNames = append(runtimeNames, rt.Name)
+		}
+	}
+	browserNames := make([]string, 0, len(snapshot.Browsers))
+	for _, b := range snapshot.Browsers {
+		browserNames = append(browserNames, b.Name)
+	}
+	telemetry.Logger.InfoContext(ctx, "status.system_info",
+		"os", snapshot.OS,
+		"hostname", snapshot.Hostname,
+		"shell", snapshot.Shell,
+		"terminal", snapshot.Terminal,
+		"memory", snapshot.memoryString(),
+		"disk_count", len(snapshot.Disks),
+		"runtimes_count", len(runtimeNames),
+		"runtimes", strings.Join(runtimeNames, ", "),
+		"browsers", browserNames,
+	)
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		attribute.String("os", snapshot.OS),
+		attribute.String("hostname", snapshot.Hostname),
+		attribute.Int("disk_count", len(snapshot.Disks)),
+		attribute.Int("runtime_count", len(runtimeNames)),
+		attribute.Int("browser_count", len(snapshot.Browsers)),
+	)
+}
@@ -3,8 +3,8 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"runtime"
@@ -16,11 +16,10 @@ import (
 
 	"github.com/shirou/gopsutil/v4/cpu"
 	"github.com/shirou/gopsutil/v4/disk"
-	"github.com/shirou/gopsutil/v4/host"
-	"github.com/shirou/gopsutil/v4/mem"
 	"github.com/spf13/cobra"
 
-	"github.com/aallbrig/allbctl/pkg/telemetry"
+	"github.com/aallbrig/allbctl/pkg/pkgmgr"
+	"github.com/aallbrig/allbctl/pkg/runner"
 )
 
 // browserVersionRegex is used to extract version numbers from browser output
@@ -30,7 +29,43 @@ var browserVersionRegex = regexp.MustCompile(`\d+\.\d+[\d.]*`)
 var StatusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Display system information (like neofetch)",
-	Run: func(cmd *cobra.Command, args []string) {
+	Long: `Display system information (like neofetch).
+
+Use --output json or --output yaml (also available on every status subcommand)
+to emit the same data as a machine-readable document for scripts and dashboards.
+
+Each run is recorded to the status history; see 'allbctl status history' and
+'allbctl status diff'.
+
+--sections picks the sections to show, in order, and --skip leaves sections out.
+Without --sections, the 'status:' section of ~/.allbctl.yaml sets the layout:
+
+  status:
+    sections: [system, cpu, memory, disks, runtimes, packages, projects]
+    skip: [browsers, ai-agents]
+    timeouts:
+      packages: 2m
+    projects:
+      limit: 10
+
+--watch redraws the view every --interval and highlights lines that changed
+since the previous refresh. Slow sections (packages, runtimes, projects, ...)
+refresh in the background on a longer cadence; watch runs are not recorded
+to the history.
+
+Sections: system, terminal, cpu, gpu, memory, disks, hardware, runtimes,
+databases, network, ports, browsers, ai-agents, package-managers, packages,
+cloud-native, projects
+
+Examples:
+  allbctl status                         # Human-readable summary
+  allbctl status -o json | jq .runtimes  # Machine-readable snapshot
+  allbctl status ports --output yaml     # Any subcommand supports --output
+  allbctl status diff --since 7d         # What changed since last week
+  allbctl status --sections cpu,memory   # Only these sections, in this order
+  allbctl status --skip cloud-native     # Everything but the slow cloud CLIs
+  allbctl status --watch                 # Redraw every 2s, highlighting changes`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
 		if ctx == nil {
 			ctx = context.Background()
@@ -39,84 +74,116 @@ var StatusCmd = &cobra.Command{
 			trace.WithAttributes(attribute.String("command", "status")),
 		)
 		defer span.End()
-		printSystemInfo(ctx)
+
+		config, err := loadStatusConfig()
+		if err != nil {
+			return err
+		}
+		if cmd.Flags().Changed("sections") {
+			config.Sections = statusSectionsFlag
+		}
+		config.Skip = append(config.Skip, statusSkipFlag...)
+		sections, err := selectStatusSections(statusSections, config.Sections, config.Skip, config.Timeouts)
+		if err != nil {
+			return err
+		}
+		statusConfig = config
+
+		if watchMode {
+			return watchCommand(cmd, newStatusWatch(sections).next)
+		}
+		snapshot := collectSystemSnapshot(ctx, sections)
+		saveSnapshotHistory(ctx, snapshot)
+		return renderOutput(snapshot, func() { printSystemSnapshot(snapshot) })
 	},
 }
 
+var (
+	statusSectionsFlag []string
+	statusSkipFlag     []string
+)
+
+func init() {
+	StatusCmd.PersistentFlags().VarP(&outputFormat, "output", "o", "Output format: text, json or yaml")
+	StatusCmd.Flags().StringSliceVar(&statusSectionsFlag, "sections", nil, "Sections to show, in order (default: status.sections from config, or all)")
+	StatusCmd.Flags().StringSliceVar(&statusSkipFlag, "skip", nil, "Sections to leave out")
+	StatusCmd.Flags().BoolVar(&refreshFlag, "refresh", false, "Re-scan every project and look up its CI status instead of reusing cached results")
+}
+
 // BrowserInfo holds browser information
 type BrowserInfo struct {
-	Name    string
-	Version string
+	Name    string `json:"name"`
+	Version string `json:"version"`
 }
 
 // detectBrowsers detects installed web browsers and their versions
-func detectBrowsers() []BrowserInfo {
+func detectBrowsers(ctx context.Context) []BrowserInfo {
 	var browsers []BrowserInfo
 	osType := runtime.GOOS
 
 	switch osType {
 	case "linux":
-		browsers = detectLinuxBrowsers()
+		browsers = detectLinuxBrowsers(ctx)
 	case "darwin":
-		browsers = detectMacBrowsers()
+		browsers = detectMacBrowsers(ctx)
 	case "windows":
-		browsers = detectWindowsBrowsers()
+		browsers = detectWindowsBrowsers(ctx)
 	}
 
 	return browsers
 }
 
 // detectLinuxBrowsers detects browsers on Linux
-func detectLinuxBrowsers() []BrowserInfo {
+func detectLinuxBrowsers(ctx context.Context) []BrowserInfo {
 	var browsers []BrowserInfo
 
 	// Chrome/Chromium
-	if version := getBrowserVersion("google-chrome", "--version"); version != "" {
+	if version := getBrowserVersion(ctx, "google-chrome", "--version"); version != "" {
 		browsers = append(browsers, BrowserInfo{Name: "Chrome", Version: version})
-	} else if version := getBrowserVersion("chromium", "--version"); version != "" {
+	} else if version := getBrowserVersion(ctx, "chromium", "--version"); version != "" {
 		browsers = append(browsers, BrowserInfo{Name: "Chromium", Version: version})
-	} else if version := getBrowserVersion("chromium-browser", "--version"); version != "" {
+	} else if version := getBrowserVersion(ctx, "chromium-browser", "--version"); version != "" {
 		browsers = append(browsers, BrowserInfo{Name: "Chromium", Version: version})
-	} else if version := getFlatpakBrowserVersion("org.chromium.Chromium"); version != "" {
+	} else if version := getFlatpakBrowserVersion(ctx, "org.chromium.Chromium"); version != "" {
 		browsers = append(browsers, BrowserInfo{Name: "Chromium", Version: version})
 	}
 
 	// Firefox
-	if version := getBrowserVersion("firefox", "--version"); version != "" {
+	if version := getBrowserVersion(ctx, "firefox", "--version"); version != "" {
 		browsers = append(browsers, BrowserInfo{Name: "Firefox", Version: version})
-	} else if version := getFlatpakBrowserVersion("org.mozilla.firefox"); version != "" {
+	} else if version := getFlatpakBrowserVersion(ctx, "org.mozilla.firefox"); version != "" {
 		browsers = append(browsers, BrowserInfo{Name: "Firefox", Version: version})
 	}
 
 	// Brave
-	if version := getBrowserVersion("brave-browser", "--version"); version != "" {
+	if version := getBrowserVersion(ctx, "brave-browser", "--version"); version != "" {
 		browsers = append(browsers, BrowserInfo{Name: "Brave", Version: version})
-	} else if version := getBrowserVersion("brave", "--version"); version != "" {
+	} else if version := getBrowserVersion(ctx, "brave", "--version"); version != "" {
 		browsers = append(browsers, BrowserInfo{Name: "Brave", Version: version})
-	} else if version := getFlatpakBrowserVersion("com.brave.Browser"); version != "" {
+	} else if version := getFlatpakBrowserVersion(ctx, "com.brave.Browser"); version != "" {
 		browsers = append(browsers, BrowserInfo{Name: "Brave", Version: version})
 	}
 
 	// Edge
-	if version := getBrowserVersion("microsoft-edge", "--version"); version != "" {
+	if version := getBrowserVersion(ctx, "microsoft-edge", "--version"); version != "" {
 		browsers = append(browsers, BrowserInfo{Name: "Edge", Version: version})
-	} else if version := getBrowserVersion("microsoft-edge-stable", "--version"); version != "" {
+	} else if version := getBrowserVersion(ctx, "microsoft-edge-stable", "--version"); version != "" {
 		browsers = append(browsers, BrowserInfo{Name: "Edge", Version: version})
-	} else if version := getFlatpakBrowserVersion("com.microsoft.Edge"); version != "" {
+	} else if version := getFlatpakBrowserVersion(ctx, "com.microsoft.Edge"); version != "" {
 		browsers = append(browsers, BrowserInfo{Name: "Edge", Version: version})
 	}
 
 	// Opera
-	if version := getBrowserVersion("opera", "--version"); version != "" {
+	if version := getBrowserVersion(ctx, "opera", "--version"); version != "" {
 		browsers = append(browsers, BrowserInfo{Name: "Opera", Version: version})
-	} else if version := getFlatpakBrowserVersion("com.opera.Opera"); version != "" {
+	} else if version := getFlatpakBrowserVersion(ctx, "com.opera.Opera"); version != "" {
 		browsers = append(browsers, BrowserInfo{Name: "Opera", Version: version})
 	}
 
 	// Vivaldi
-	if version := getBrowserVersion("vivaldi", "--version"); version != "" {
+	if version := getBrowserVersion(ctx, "vivaldi", "--version"); version != "" {
 		browsers = append(browsers, BrowserInfo{Name: "Vivaldi", Version: version})
-	} else if version := getFlatpakBrowserVersion("com.vivaldi.Vivaldi"); version != "" {
+	} else if version := getFlatpakBrowserVersion(ctx, "com.vivaldi.Vivaldi"); version != "" {
 		browsers = append(browsers, BrowserInfo{Name: "Vivaldi", Version: version})
 	}
 
@@ -124,7 +191,7 @@ func detectLinuxBrowsers() []BrowserInfo {
 }
 
 // detectMacBrowsers detects browsers on macOS
-func detectMacBrowsers() []BrowserInfo {
+func detectMacBrowsers(ctx context.Context) []BrowserInfo {
 	var browsers []BrowserInfo
 
 	// Check for browsers in /Applications
@@ -140,7 +207,7 @@ func detectMacBrowsers() []BrowserInfo {
 
 	for name, appPath := range appPaths {
 		if _, err := os.Stat(appPath); err == nil {
-			version := getMacAppVersion(appPath)
+			version := getMacAppVersion(ctx, appPath)
 			if version != "" {
 				browsers = append(browsers, BrowserInfo{Name: name, Version: version})
 			} else {
@@ -153,7 +220,7 @@ func detectMacBrowsers() []BrowserInfo {
 }
 
 // detectWindowsBrowsers detects browsers on Windows
-func detectWindowsBrowsers() []BrowserInfo {
+func detectWindowsBrowsers(ctx context.Context) []BrowserInfo {
 	var browsers []BrowserInfo
 
 	// Check common browser paths using filepath.Join for cross-platform compatibility
@@ -205,9 +272,8 @@ func detectWindowsBrowsers() []BrowserInfo {
 }
 
 // getBrowserVersion gets browser version using command line
-func getBrowserVersion(command string, args ...string) string {
-	cmd := exec.Command(command, args...)
-	output, err := cmd.CombinedOutput()
+func getBrowserVersion(ctx context.Context, command string, args ...string) string {
+	output, err := runner.CombinedOutput(ctx, command, args...)
 	if err != nil {
 		return ""
 	}
@@ -217,9 +283,8 @@ func getBrowserVersion(command string, args ...string) string {
 }
 
 // getFlatpakBrowserVersion gets browser version from Flatpak
-func getFlatpakBrowserVersion(appID string) string {
-	cmd := exec.Command("flatpak", "run", appID, "--version")
-	output, err := cmd.CombinedOutput()
+func getFlatpakBrowserVersion(ctx context.Context, appID string) string {
+	output, err := runner.CombinedOutput(ctx, "flatpak", "run", appID, "--version")
 	if err != nil {
 		return ""
 	}
@@ -289,14 +354,12 @@ func parseBrowserVersion(output string) string {
 }
 
 // getMacAppVersion gets version from macOS app bundle
-func getMacAppVersion(appPath string) string {
+func getMacAppVersion(ctx context.Context, appPath string) string {
 	plistPath := filepath.Join(appPath, "Contents", "Info.plist")
-	cmd := exec.Command("defaults", "read", plistPath, "CFBundleShortVersionString")
-	output, err := cmd.Output()
+	output, err := runner.Output(ctx, "defaults", "read", plistPath, "CFBundleShortVersionString")
 	if err != nil {
 		// Try alternative version key
-		cmd = exec.Command("defaults", "read", plistPath, "CFBundleVersion")
-		output, err = cmd.Output()
+		output, err = runner.Output(ctx, "defaults", "read", plistPath, "CFBundleVersion")
 		if err != nil {
 			return ""
 		}
@@ -342,7 +405,7 @@ func getWindowsBrowserVersion(browserPath string) string {
 }
 
 // printBrowsers displays detected browsers
-func printBrowsers(browsers []BrowserInfo) {
+func printBrowsers(w io.Writer, browsers []BrowserInfo) {
 	if len(browsers) == 0 {
 		return
 	}
@@ -357,209 +420,48 @@ func printBrowsers(browsers []BrowserInfo) {
 	}
 
 	if len(browserStrings) > 0 {
-		fmt.Printf("  %s\n", strings.Join(browserStrings, ", "))
+		fmt.Fprintf(w, "  %s\n", strings.Join(browserStrings, ", "))
 	}
 }
 
 // printSystemInfo collects and prints system information in a structured format
 func printSystemInfo(ctx context.Context) {
-	// Start package detection early (runs in background)
-	packagesFuture := StartPackageSummary()
-
-	// Get current user for header
-	user := os.Getenv("USER")
-	if user == "" {
-		user = os.Getenv("USERNAME")
-	}
-
-	// Hostname
-	hostname, err := os.Hostname()
-	if err != nil {
-		hostname = "Unknown"
-	}
-
-	// Print header with version
-	header := fmt.Sprintf("%s@%s", user, hostname)
-	fmt.Println(header)
-	fmt.Printf("allbctl %s (commit %s)\n", Version, Commit)
-	fmt.Println()
-
-	// Host Info using gopsutil
-	hostInfo, err := host.Info()
-	osStr := "Unknown"
-	if err == nil {
-		osStr = fmt.Sprintf("%s %s", hostInfo.Platform, hostInfo.PlatformVersion)
-	}
-
-	// Shell
-	shell := os.Getenv("SHELL")
-	if shell == "" {
-		shell = os.Getenv("COMSPEC")
-	}
-	if shell == "" {
-		shell = "Unknown"
-	}
-
-	// Terminal
-	terminal := detectTerminal()
-
-	// CPU Info - get detailed information
-	cpuDetails := getDetailedCPUInfo()
-
-	// GPU Info - get detailed information
-	gpuDetails := getDetailedGPUInfo()
-
-	// Memory using gopsutil - show only total installed
-	memInfo, err := mem.VirtualMemory()
-	memStr := "Unknown"
-	if err == nil {
-		memStr = fmt.Sprintf("%.1f GiB", float64(memInfo.Total)/1e9)
-	}
-
-	// Hardware Info
-	hwStr := "Unknown"
-	if hostInfo != nil {
-		if hostInfo.Platform != "" {
-			hwStr = hostInfo.Platform
-		}
-		if hostInfo.Hostname != "" && !strings.Contains(hwStr, hostInfo.Hostname) {
-			hwStr = hostInfo.Hostname + " " + hwStr
-		}
-	}
-
-	// Print system information (no "Host:" header)
-	fmt.Printf("OS:        %s\n", osStr)
-	fmt.Printf("Hostname:  %s\n", hostname)
-	fmt.Printf("Shell:     %s\n", shell)
-	fmt.Printf("Terminal:  %s\n", terminal)
-	fmt.Printf("CPU:\n")
-	printCPUInfo(cpuDetails)
-	fmt.Printf("GPU(s):\n")
-	printGPUInfo(gpuDetails)
-	fmt.Printf("Memory:    %s\n", memStr)
-
-	// Disks - detailed view
-	disks := getDetailedDiskInfo()
-	totalDiskSpace := uint64(0)
-	for _, d := range disks {
-		totalDiskSpace += d.Total
-	}
-	if len(disks) > 0 {
-		fmt.Printf("Disks:     %d total (%.1f GB)\n", len(disks), float64(totalDiskSpace)/1e9)
-		printDiskInfo(disks)
-	} else {
-		// Fallback to old summary if detailed view fails
-		diskInfo := getDiskInfo()
-		if diskInfo != "" {
-			fmt.Printf("Disks:     %s\n", diskInfo)
-		} else {
-			fmt.Printf("Disks:     No disks detected\n")
-		}
-	}
-
-	fmt.Printf("Hardware:  %s\n", hwStr)
-
-	// Runtimes (using shared function)
-	runtimesInline := detectRuntimesInline()
-	if runtimesInline != "" {
-		fmt.Printf("Runtimes:  %s\n", runtimesInline)
-	}
-
-	// Databases summary
-	PrintDatabaseSummaryForStatus()
-	fmt.Println()
-
-	// Network section
-	printNetworkInfo()
-	fmt.Println()
-
-	// Ports section
-	printPortsSummary()
-	fmt.Println()
-
-	// Browsers section
-	browsers := detectBrowsers()
-	if len(browsers) > 0 {
-		fmt.Println("Browsers:")
-		printBrowsers(browsers)
-		fmt.Println()
-	}
-
-	// AI Agents section
-	fmt.Println("AI Agents:")
-	printAIAgents()
-	fmt.Println()
+	printSystemSnapshot(collectSystemSnapshot(ctx, statusSections))
+}
 
-	// Package Managers section
-	fmt.Println("Package Managers:")
-	printPackageManagers()
-	fmt.Println()
+// printSystemSnapshot renders a snapshot in the neofetch-style text layout
+func printSystemSnapshot(snapshot *SystemSnapshot) {
+	printSnapshotHeader(os.Stdout, snapshot)
+	renderStatusSections(os.Stdout, statusSections, snapshot)
+}
 
-	// Packages section - wait for background detection to complete
-	fmt.Println("Packages:")
-	if packagesFuture != nil {
-		packagesFuture.PrintResults()
-	} else {
-		fmt.Println("  No package managers detected")
-	}
-	fmt.Println()
-
-	// Cloud Native section
-	printCloudNativeForStatus()
-
-	// Projects section
-	printProjectsInline(5)
-
-	// Wide structured log with all detected system info
-	runtimeCount := len(strings.Split(runtimesInline, ","))
-	if runtimesInline == "" {
-		runtimeCount = 0
-	}
-	browserNames := make([]string, 0, len(browsers))
-	for _, b := range browsers {
-		browserNames = append(browserNames, b.Name)
-	}
-	telemetry.Logger.InfoContext(ctx, "status.system_info",
-		"os", osStr,
-		"hostname", hostname,
-		"shell", shell,
-		"terminal", terminal,
-		"memory", memStr,
-		"disk_count", len(disks),
-		"runtimes_count", runtimeCount,
-		"runtimes", runtimesInline,
-		"browsers", browserNames,
-	)
-	span := trace.SpanFromContext(ctx)
-	span.SetAttributes(
-		attribute.String("os", osStr),
-		attribute.String("hostname", hostname),
-		attribute.Int("disk_count", len(disks)),
-		attribute.Int("runtime_count", runtimeCount),
-		attribute.Int("browser_count", len(browsers)),
-	)
+// printSnapshotHeader prints the user@hostname and version lines
+func printSnapshotHeader(w io.Writer, snapshot *SystemSnapshot) {
+	fmt.Fprintf(w, "%s@%s\n", snapshot.User, snapshot.Hostname)
+	fmt.Fprintf(w, "allbctl %s (commit %s)\n", snapshot.Version, snapshot.Commit)
+	fmt.Fprintln(w)
 }
 
 // GPUInfo holds detailed GPU information
 type GPUInfo struct {
-	Name          string
-	Vendor        string
-	Memory        string
-	Driver        string
-	ComputeCap    string
-	ClockGraphics string
-	ClockMemory   string
+	Name          string `json:"name"`
+	Vendor        string `json:"vendor"`
+	Memory        string `json:"memory,omitempty"`
+	Driver        string `json:"driver,omitempty"`
+	ComputeCap    string `json:"compute_cap,omitempty"`
+	ClockGraphics string `json:"clock_graphics,omitempty"`
+	ClockMemory   string `json:"clock_memory,omitempty"`
 }
 
 // getDetailedGPUInfo gathers detailed GPU information from multiple sources
-func getDetailedGPUInfo() []GPUInfo {
+func getDetailedGPUInfo(ctx context.Context) []GPUInfo {
 	var gpus []GPUInfo
 
 	osType := runtime.GOOS
 
 	// Try nvidia-smi first for NVIDIA GPUs
-	if exists("nvidia-smi") {
-		nvidiaGPUs := getNvidiaGPUInfo()
+	if exists(ctx, "nvidia-smi") {
+		nvidiaGPUs := getNvidiaGPUInfo(ctx)
 		gpus = append(gpus, nvidiaGPUs...)
 	}
 
@@ -567,11 +469,11 @@ func getDetailedGPUInfo() []GPUInfo {
 	var platformGPUs []GPUInfo
 	switch osType {
 	case "linux":
-		platformGPUs = getLinuxGPUInfo()
+		platformGPUs = getLinuxGPUInfo(ctx)
 	case "darwin":
-		platformGPUs = getMacGPUInfo()
+		platformGPUs = getMacGPUInfo(ctx)
 	case "windows":
-		platformGPUs = getWindowsGPUInfo()
+		platformGPUs = getWindowsGPUInfo(ctx)
 	}
 
 	// Merge platform-specific GPUs with NVIDIA GPUs, avoiding duplicates
@@ -607,11 +509,10 @@ func getDetailedGPUInfo() []GPUInfo {
 }
 
 // getNvidiaGPUInfo gets GPU information from nvidia-smi
-func getNvidiaGPUInfo() []GPUInfo {
+func getNvidiaGPUInfo(ctx context.Context) []GPUInfo {
 	var gpus []GPUInfo
 
-	cmd := exec.Command("nvidia-smi", "--query-gpu=name,memory.total,driver_version,compute_cap,clocks.current.graphics,clocks.current.memory", "--format=csv,noheader,nounits")
-	out, err := cmd.Output()
+	out, err := runner.Output(ctx, "nvidia-smi", "--query-gpu=name,memory.total,driver_version,compute_cap,clocks.current.graphics,clocks.current.memory", "--format=csv,noheader,nounits")
 	if err != nil {
 		return gpus
 	}
@@ -637,11 +538,10 @@ func getNvidiaGPUInfo() []GPUInfo {
 }
 
 // getLinuxGPUInfo gets GPU information on Linux using lspci
-func getLinuxGPUInfo() []GPUInfo {
+func getLinuxGPUInfo(ctx context.Context) []GPUInfo {
 	var gpus []GPUInfo
 
-	cmd := exec.Command("sh", "-c", "lspci | grep -Ei 'vga|3d controller'")
-	out, err := cmd.Output()
+	out, err := runner.Output(ctx, "sh", "-c", "lspci | grep -Ei 'vga|3d controller'")
 	if err != nil {
 		return gpus
 	}
@@ -673,11 +573,10 @@ func getLinuxGPUInfo() []GPUInfo {
 }
 
 // getMacGPUInfo gets GPU information on macOS
-func getMacGPUInfo() []GPUInfo {
+func getMacGPUInfo(ctx context.Context) []GPUInfo {
 	var gpus []GPUInfo
 
-	cmd := exec.Command("system_profiler", "SPDisplaysDataType")
-	out, err := cmd.Output()
+	out, err := runner.Output(ctx, "system_profiler", "SPDisplaysDataType")
 	if err != nil {
 		return gpus
 	}
@@ -712,11 +611,10 @@ func getMacGPUInfo() []GPUInfo {
 }
 
 // getWindowsGPUInfo gets GPU information on Windows
-func getWindowsGPUInfo() []GPUInfo {
+func getWindowsGPUInfo(ctx context.Context) []GPUInfo {
 	var gpus []GPUInfo
 
-	cmd := exec.Command("wmic", "path", "win32_VideoController", "get", "Name,AdapterRAM,DriverVersion", "/format:csv")
-	out, err := cmd.Output()
+	out, err := runner.Output(ctx, "wmic", "path", "win32_VideoController", "get", "Name,AdapterRAM,DriverVersion", "/format:csv")
 	if err != nil {
 		return gpus
 	}
@@ -787,56 +685,56 @@ func detectVendor(name string) string {
 }
 
 // printGPUInfo prints detailed GPU information
-func printGPUInfo(gpus []GPUInfo) {
+func printGPUInfo(w io.Writer, gpus []GPUInfo) {
 	if len(gpus) == 0 {
-		fmt.Printf("  Unavailable\n")
+		fmt.Fprintf(w, "  Unavailable\n")
 		return
 	}
 
 	for i, gpu := range gpus {
 		if i > 0 {
-			fmt.Println()
+			fmt.Fprintln(w)
 		}
-		fmt.Printf("  Name:      %s\n", gpu.Name)
+		fmt.Fprintf(w, "  Name:      %s\n", gpu.Name)
 		if gpu.Vendor != "" && gpu.Vendor != "Unknown" {
-			fmt.Printf("  Vendor:    %s\n", gpu.Vendor)
+			fmt.Fprintf(w, "  Vendor:    %s\n", gpu.Vendor)
 		}
 		if gpu.Memory != "" {
-			fmt.Printf("  Memory:    %s\n", gpu.Memory)
+			fmt.Fprintf(w, "  Memory:    %s\n", gpu.Memory)
 		}
 		if gpu.Driver != "" {
-			fmt.Printf("  Driver:    %s\n", gpu.Driver)
+			fmt.Fprintf(w, "  Driver:    %s\n", gpu.Driver)
 		}
 		if gpu.ComputeCap != "" {
-			fmt.Printf("  Compute:   %s\n", gpu.ComputeCap)
+			fmt.Fprintf(w, "  Compute:   %s\n", gpu.ComputeCap)
 		}
 		if gpu.ClockGraphics != "" {
-			fmt.Printf("  Clock:     %s (graphics)\n", gpu.ClockGraphics)
+			fmt.Fprintf(w, "  Clock:     %s (graphics)\n", gpu.ClockGraphics)
 		}
 		if gpu.ClockMemory != "" {
-			fmt.Printf("  Clock Mem: %s (memory)\n", gpu.ClockMemory)
+			fmt.Fprintf(w, "  Clock Mem: %s (memory)\n", gpu.ClockMemory)
 		}
 	}
 }
 
 // CPUDetails holds detailed CPU information
 type CPUDetails struct {
-	ModelName      string
-	Architecture   string
-	Cores          int
-	ThreadsPerCore int
-	CoresPerSocket int
-	Sockets        int
-	PhysicalCores  int
-	LogicalCores   int
-	BaseClock      string
-	PCores         int
-	ECores         int
-	HasPECores     bool
+	ModelName      string `json:"model_name"`
+	Architecture   string `json:"architecture"`
+	Cores          int    `json:"cores"`
+	ThreadsPerCore int    `json:"threads_per_core"`
+	CoresPerSocket int    `json:"cores_per_socket"`
+	Sockets        int    `json:"sockets"`
+	PhysicalCores  int    `json:"physical_cores"`
+	LogicalCores   int    `json:"logical_cores"`
+	BaseClock      string `json:"base_clock,omitempty"`
+	PCores         int    `json:"p_cores"`
+	ECores         int    `json:"e_cores"`
+	HasPECores     bool   `json:"has_pe_cores"`
 }
 
 // getDetailedCPUInfo gathers detailed CPU information from multiple sources
-func getDetailedCPUInfo() CPUDetails {
+func getDetailedCPUInfo(ctx context.Context) CPUDetails {
 	details := CPUDetails{
 		ModelName:      "Unknown",
 		Architecture:   runtime.GOARCH,
@@ -857,8 +755,7 @@ func getDetailedCPUInfo() CPUDetails {
 
 	// On Linux, use lscpu for more detailed information
 	if runtime.GOOS == "linux" {
-		cmd := exec.Command("lscpu")
-		out, err := cmd.Output()
+		out, err := runner.Output(ctx, "lscpu")
 		if err == nil {
 			lines := strings.Split(string(out), "\n")
 			for _, line := range lines {
@@ -888,42 +785,36 @@ func getDetailedCPUInfo() CPUDetails {
 		}
 	} else if runtime.GOOS == "darwin" {
 		// On macOS, use sysctl for detailed information
-		cmd := exec.Command("sysctl", "-n", "machdep.cpu.brand_string")
-		if out, err := cmd.Output(); err == nil {
+		if out, err := runner.Output(ctx, "sysctl", "-n", "machdep.cpu.brand_string"); err == nil {
 			details.ModelName = strings.TrimSpace(string(out))
 		}
 
 		// Get core counts
-		cmd = exec.Command("sysctl", "-n", "hw.physicalcpu")
-		if out, err := cmd.Output(); err == nil {
+		if out, err := runner.Output(ctx, "sysctl", "-n", "hw.physicalcpu"); err == nil {
 			//nolint:errcheck // Sscanf errors are non-critical for best-effort parsing
 			_, _ = fmt.Sscanf(strings.TrimSpace(string(out)), "%d", &details.PhysicalCores)
 		}
 
-		cmd = exec.Command("sysctl", "-n", "hw.logicalcpu")
-		if out, err := cmd.Output(); err == nil {
+		if out, err := runner.Output(ctx, "sysctl", "-n", "hw.logicalcpu"); err == nil {
 			//nolint:errcheck // Sscanf errors are non-critical for best-effort parsing
 			_, _ = fmt.Sscanf(strings.TrimSpace(string(out)), "%d", &details.LogicalCores)
 		}
 
 		// Try to get P and E core counts (Apple Silicon)
-		cmd = exec.Command("sysctl", "-n", "hw.perflevel0.physicalcpu")
-		if out, err := cmd.Output(); err == nil {
+		if out, err := runner.Output(ctx, "sysctl", "-n", "hw.perflevel0.physicalcpu"); err == nil {
 			//nolint:errcheck // Sscanf errors are non-critical for best-effort parsing
 			_, _ = fmt.Sscanf(strings.TrimSpace(string(out)), "%d", &details.PCores)
 			details.HasPECores = true
 		}
 
-		cmd = exec.Command("sysctl", "-n", "hw.perflevel1.physicalcpu")
-		if out, err := cmd.Output(); err == nil {
+		if out, err := runner.Output(ctx, "sysctl", "-n", "hw.perflevel1.physicalcpu"); err == nil {
 			//nolint:errcheck // Sscanf errors are non-critical for best-effort parsing
 			_, _ = fmt.Sscanf(strings.TrimSpace(string(out)), "%d", &details.ECores)
 			details.HasPECores = true
 		}
 
 		// Get base clock
-		cmd = exec.Command("sysctl", "-n", "hw.cpufrequency")
-		if out, err := cmd.Output(); err == nil {
+		if out, err := runner.Output(ctx, "sysctl", "-n", "hw.cpufrequency"); err == nil {
 			var hz int64
 			//nolint:errcheck // Sscanf errors are non-critical for best-effort parsing
 			_, _ = fmt.Sscanf(strings.TrimSpace(string(out)), "%d", &hz)
@@ -933,16 +824,14 @@ func getDetailedCPUInfo() CPUDetails {
 		}
 	} else if runtime.GOOS == "windows" {
 		// On Windows, use wmic
-		cmd := exec.Command("wmic", "cpu", "get", "Name")
-		if out, err := cmd.Output(); err == nil {
+		if out, err := runner.Output(ctx, "wmic", "cpu", "get", "Name"); err == nil {
 			lines := strings.Split(string(out), "\n")
 			if len(lines) > 1 {
 				details.ModelName = strings.TrimSpace(lines[1])
 			}
 		}
 
-		cmd = exec.Command("wmic", "cpu", "get", "NumberOfCores")
-		if out, err := cmd.Output(); err == nil {
+		if out, err := runner.Output(ctx, "wmic", "cpu", "get", "NumberOfCores"); err == nil {
 			lines := strings.Split(string(out), "\n")
 			if len(lines) > 1 {
 				//nolint:errcheck // Sscanf errors are non-critical for best-effort parsing
@@ -950,8 +839,7 @@ func getDetailedCPUInfo() CPUDetails {
 			}
 		}
 
-		cmd = exec.Command("wmic", "cpu", "get", "NumberOfLogicalProcessors")
-		if out, err := cmd.Output(); err == nil {
+		if out, err := runner.Output(ctx, "wmic", "cpu", "get", "NumberOfLogicalProcessors"); err == nil {
 			lines := strings.Split(string(out), "\n")
 			if len(lines) > 1 {
 				//nolint:errcheck // Sscanf errors are non-critical for best-effort parsing
@@ -974,34 +862,34 @@ func getDetailedCPUInfo() CPUDetails {
 }
 
 // printCPUInfo prints detailed CPU information
-func printCPUInfo(details CPUDetails) {
-	fmt.Printf("  Model:     %s\n", details.ModelName)
-	fmt.Printf("  Arch:      %s\n", details.Architecture)
+func printCPUInfo(w io.Writer, details CPUDetails) {
+	fmt.Fprintf(w, "  Model:     %s\n", details.ModelName)
+	fmt.Fprintf(w, "  Arch:      %s\n", details.Architecture)
 
 	if details.BaseClock != "" {
-		fmt.Printf("  Clock:     %s\n", details.BaseClock)
+		fmt.Fprintf(w, "  Clock:     %s\n", details.BaseClock)
 	}
 
 	// Show physical vs logical cores
 	if details.PhysicalCores > 0 && details.LogicalCores > 0 {
-		fmt.Printf("  Cores:     %d physical, %d logical", details.PhysicalCores, details.LogicalCores)
+		fmt.Fprintf(w, "  Cores:     %d physical, %d logical", details.PhysicalCores, details.LogicalCores)
 		if details.ThreadsPerCore > 1 {
-			fmt.Printf(" (%d threads/core)", details.ThreadsPerCore)
+			fmt.Fprintf(w, " (%d threads/core)", details.ThreadsPerCore)
 		}
-		fmt.Println()
+		fmt.Fprintln(w)
 	} else {
-		fmt.Printf("  Cores:     %d\n", details.LogicalCores)
+		fmt.Fprintf(w, "  Cores:     %d\n", details.LogicalCores)
 	}
 
 	// Show P/E core breakdown if available (Apple Silicon)
 	if details.HasPECores && (details.PCores > 0 || details.ECores > 0) {
-		fmt.Printf("  P-cores:   %d (performance)\n", details.PCores)
-		fmt.Printf("  E-cores:   %d (efficiency)\n", details.ECores)
+		fmt.Fprintf(w, "  P-cores:   %d (performance)\n", details.PCores)
+		fmt.Fprintf(w, "  E-cores:   %d (efficiency)\n", details.ECores)
 	}
 
 	// Show socket/core organization if multiple sockets or meaningful
 	if details.Sockets > 1 || (details.CoresPerSocket > 0 && details.CoresPerSocket != details.PhysicalCores) {
-		fmt.Printf("  Layout:    %d socket(s), %d core(s) per socket\n", details.Sockets, details.CoresPerSocket)
+		fmt.Fprintf(w, "  Layout:    %d socket(s), %d core(s) per socket\n", details.Sockets, details.CoresPerSocket)
 	}
 }
 
@@ -1073,26 +961,18 @@ func detectTerminal() string {
 	return "Unknown"
 }
 
-// printNetworkInfo prints network information for status command
-func printNetworkInfo() {
-	// Use the enhanced network details function from network.go
-	PrintNetworkInfo()
-}
-
 // getRouterIP gets the default gateway IP (exported for network subcommand)
-func getRouterIP() string {
+func getRouterIP(ctx context.Context) string {
 	osType := runtime.GOOS
 
 	switch osType {
 	case "linux":
-		cmd := exec.Command("sh", "-c", "ip route | grep default | awk '{print $3}' | head -n1")
-		out, err := cmd.Output()
+		out, err := runner.Output(ctx, "sh", "-c", "ip route | grep default | awk '{print $3}' | head -n1")
 		if err == nil && len(out) > 0 {
 			return strings.TrimSpace(string(out))
 		}
 	case "darwin":
-		cmd := exec.Command("route", "-n", "get", "default")
-		out, err := cmd.Output()
+		out, err := runner.Output(ctx, "route", "-n", "get", "default")
 		if err == nil {
 			for _, line := range strings.Split(string(out), "\n") {
 				if strings.Contains(line, "gateway:") {
@@ -1104,8 +984,7 @@ func getRouterIP() string {
 			}
 		}
 	case "windows":
-		cmd := exec.Command("ipconfig")
-		out, err := cmd.Output()
+		out, err := runner.Output(ctx, "ipconfig")
 		if err == nil {
 			for _, line := range strings.Split(string(out), "\n") {
 				if strings.Contains(line, "Default Gateway") {
@@ -1126,65 +1005,76 @@ func getRouterIP() string {
 
 // AIAgent represents an AI coding assistant
 type AIAgent struct {
-	Name    string
-	Version string
+	Name    string      `json:"name"`
+	Version string      `json:"version"`
+	Update  *UpdateInfo `json:"update,omitempty"` // newer release, set by withAIAgentUpdates
+}
+
+// withAIAgentUpdates looks up newer releases of the detected AI agents
+func withAIAgentUpdates(agents []AIAgent) []AIAgent {
+	for i, agent := range agents {
+		if agent.Version != "" {
+			agents[i].Update = checkVersionUpdate(agent.Name, agent.Version)
+		}
+	}
+	return agents
 }
 
 // detectAIAgents detects available AI coding assistants
-func detectAIAgents() []AIAgent {
+func detectAIAgents(ctx context.Context) []AIAgent {
 	var agents []AIAgent
 
 	// GitHub Copilot CLI
-	if exists("copilot") {
-		version := getAIAgentVersion("copilot")
+	if exists(ctx, "copilot") {
+		version := getAIAgentVersion(ctx, "copilot")
 		agents = append(agents, AIAgent{Name: "copilot", Version: version})
 	}
 
 	// Claude Code (if it exists as a CLI)
-	if exists("claude") {
-		version := getAIAgentVersion("claude")
+	if exists(ctx, "claude") {
+		version := getAIAgentVersion(ctx, "claude")
 		agents = append(agents, AIAgent{Name: "claude", Version: version})
 	}
 
 	// Cursor AI
-	if exists("cursor") {
-		version := getAIAgentVersion("cursor")
+	if exists(ctx, "cursor") {
+		version := getAIAgentVersion(ctx, "cursor")
 		agents = append(agents, AIAgent{Name: "cursor", Version: version})
 	}
 
 	// Aider
-	if exists("aider") {
-		version := getAIAgentVersion("aider")
+	if exists(ctx, "aider") {
+		version := getAIAgentVersion(ctx, "aider")
 		agents = append(agents, AIAgent{Name: "aider", Version: version})
 	}
 
 	// Continue.dev (if it has a CLI)
-	if exists("continue") {
-		version := getAIAgentVersion("continue")
+	if exists(ctx, "continue") {
+		version := getAIAgentVersion(ctx, "continue")
 		agents = append(agents, AIAgent{Name: "continue", Version: version})
 	}
 
 	// Cody (Sourcegraph)
-	if exists("cody") {
-		version := getAIAgentVersion("cody")
+	if exists(ctx, "cody") {
+		version := getAIAgentVersion(ctx, "cody")
 		agents = append(agents, AIAgent{Name: "cody", Version: version})
 	}
 
 	// Tabby (local AI)
-	if exists("tabby") {
-		version := getAIAgentVersion("tabby")
+	if exists(ctx, "tabby") {
+		version := getAIAgentVersion(ctx, "tabby")
 		agents = append(agents, AIAgent{Name: "tabby", Version: version})
 	}
 
 	// Amazon CodeWhisperer
-	if exists("codewhisperer") {
-		version := getAIAgentVersion("codewhisperer")
+	if exists(ctx, "codewhisperer") {
+		version := getAIAgentVersion(ctx, "codewhisperer")
 		agents = append(agents, AIAgent{Name: "codewhisperer", Version: version})
 	}
 
 	// Ollama (local LLM runner)
-	if exists("ollama") {
-		version := getAIAgentVersion("ollama")
+	if exists(ctx, "ollama") {
+		version := getAIAgentVersion(ctx, "ollama")
 		agents = append(agents, AIAgent{Name: "ollama", Version: version})
 	}
 
@@ -1192,37 +1082,33 @@ func detectAIAgents() []AIAgent {
 }
 
 // getAIAgentVersion returns the version of an AI agent
-func getAIAgentVersion(agent string) string {
-	var cmd *exec.Cmd
+func getAIAgentVersion(ctx context.Context, agent string) string {
+	var argv []string
 
 	switch agent {
 	case "copilot":
-		cmd = exec.Command("copilot", "--version")
+		argv = []string{"copilot", "--version"}
 	case "claude":
-		cmd = exec.Command("claude", "--version")
+		argv = []string{"claude", "--version"}
 	case "cursor":
-		cmd = exec.Command("cursor", "--version")
+		argv = []string{"cursor", "--version"}
 	case "aider":
-		cmd = exec.Command("aider", "--version")
+		argv = []string{"aider", "--version"}
 	case "continue":
-		cmd = exec.Command("continue", "--version")
+		argv = []string{"continue", "--version"}
 	case "cody":
-		cmd = exec.Command("cody", "--version")
+		argv = []string{"cody", "--version"}
 	case "tabby":
-		cmd = exec.Command("tabby", "--version")
+		argv = []string{"tabby", "--version"}
 	case "codewhisperer":
-		cmd = exec.Command("codewhisperer", "--version")
+		argv = []string{"codewhisperer", "--version"}
 	case "ollama":
-		cmd = exec.Command("ollama", "--version")
+		argv = []string{"ollama", "--version"}
 	default:
 		return ""
 	}
 
-	if cmd == nil {
-		return ""
-	}
-
-	output, err := cmd.CombinedOutput()
+	output, err := runner.CombinedOutput(ctx, argv[0], argv[1:]...)
 	if err != nil {
 		return ""
 	}
@@ -1289,176 +1175,83 @@ func extractAIAgentVersion(agent, output string) string {
 }
 
 // printAIAgents displays available AI coding assistants
-func printAIAgents() {
-	agents := detectAIAgents()
-
+func printAIAgents(w io.Writer, agents []AIAgent) {
 	if len(agents) == 0 {
-		fmt.Printf("  No AI agents detected\n")
+		fmt.Fprintf(w, "  No AI agents detected\n")
 		return
 	}
 
 	var agentStrings []string
 	for _, agent := range agents {
 		if agent.Version != "" {
-			versionStr := formatVersionWithUpdate(agent.Name, agent.Version)
+			versionStr := formatVersion(agent.Version, agent.Update)
 			agentStrings = append(agentStrings, fmt.Sprintf("%s (%s)", agent.Name, versionStr))
 		} else {
 			agentStrings = append(agentStrings, agent.Name)
 		}
 	}
 
-	fmt.Printf("  %s\n", strings.Join(agentStrings, ", "))
+	fmt.Fprintf(w, "  %s\n", strings.Join(agentStrings, ", "))
 }
 
-// printPackageManagers displays available package managers
-func printPackageManagers() {
-	osType := runtime.GOOS
-	systemAvailable := []string{}
-	languageAvailable := []string{}
-	runtimeAvailable := []string{}
-	infrastructureAvailable := []string{}
+// Package manager categories used by the "Package Managers:" section
+const (
+	pmCategorySystem         = string(pkgmgr.System)
+	pmCategoryLanguage       = "language"
+	pmCategoryRuntime        = string(pkgmgr.Runtime)
+	pmCategoryInfrastructure = string(pkgmgr.Infrastructure)
+)
 
-	// System package managers
-	switch osType {
-	case "linux":
-		if exists("apt-get") {
-			version := getPackageManagerVersion("apt")
-			if version != "" {
-				versionStr := formatVersionWithUpdate("apt", version)
-				systemAvailable = append(systemAvailable, fmt.Sprintf("apt (%s)", versionStr))
-			} else {
-				systemAvailable = append(systemAvailable, "apt")
-			}
-		}
-		if exists("flatpak") {
-			version := getPackageManagerVersion("flatpak")
-			if version != "" {
-				versionStr := formatVersionWithUpdate("flatpak", version)
-				systemAvailable = append(systemAvailable, fmt.Sprintf("flatpak (%s)", versionStr))
-			} else {
-				systemAvailable = append(systemAvailable, "flatpak")
-			}
-		}
-		if exists("snap") {
-			version := getPackageManagerVersion("snap")
-			if version != "" {
-				versionStr := formatVersionWithUpdate("snap", version)
-				systemAvailable = append(systemAvailable, fmt.Sprintf("snap (%s)", versionStr))
-			} else {
-				systemAvailable = append(systemAvailable, "snap")
-			}
-		}
-		if exists("dnf") {
-			version := getPackageManagerVersion("dnf")
-			if version != "" {
-				versionStr := formatVersionWithUpdate("dnf", version)
-				systemAvailable = append(systemAvailable, fmt.Sprintf("dnf (%s)", versionStr))
-			} else {
-				systemAvailable = append(systemAvailable, "dnf")
-			}
-		}
-		if exists("yum") {
-			version := getPackageManagerVersion("yum")
-			if version != "" {
-				versionStr := formatVersionWithUpdate("yum", version)
-				systemAvailable = append(systemAvailable, fmt.Sprintf("yum (%s)", versionStr))
-			} else {
-				systemAvailable = append(systemAvailable, "yum")
-			}
-		}
-		if exists("pacman") {
-			version := getPackageManagerVersion("pacman")
-			if version != "" {
-				versionStr := formatVersionWithUpdate("pacman", version)
-				systemAvailable = append(systemAvailable, fmt.Sprintf("pacman (%s)", versionStr))
-			} else {
-				systemAvailable = append(systemAvailable, "pacman")
-			}
-		}
-	case "darwin":
-		if exists("brew") {
-			version := getPackageManagerVersion("brew")
-			if version != "" {
-				versionStr := formatVersionWithUpdate("brew", version)
-				systemAvailable = append(systemAvailable, fmt.Sprintf("homebrew (%s)", versionStr))
-			} else {
-				systemAvailable = append(systemAvailable, "homebrew")
-			}
-		}
-	case "windows":
-		if exists("choco") {
-			version := getPackageManagerVersion("choco")
-			if version != "" {
-				versionStr := formatVersionWithUpdate("choco", version)
-				systemAvailable = append(systemAvailable, fmt.Sprintf("chocolatey (%s)", versionStr))
-			} else {
-				systemAvailable = append(systemAvailable, "chocolatey")
-			}
-		}
-		if exists("winget") {
-			version := getPackageManagerVersion("winget")
-			if version != "" {
-				versionStr := formatVersionWithUpdate("winget", version)
-				systemAvailable = append(systemAvailable, fmt.Sprintf("winget (%s)", versionStr))
-			} else {
-				systemAvailable = append(systemAvailable, "winget")
-			}
-		}
-	}
+// PackageManagerInfo describes an available package or version manager
+type PackageManagerInfo struct {
+	Name     string `json:"name"`              // display name, e.g. "homebrew"
+	ID       string `json:"id"`                // lookup key for versions and updates, e.g. "brew"
+	Version  string `json:"version,omitempty"` // empty when the version could not be determined
+	Category string `json:"category"`          // system, language, runtime or infrastructure
+	// Update is a newer release of the manager itself, set by withPackageManagerUpdates
+	Update *UpdateInfo `json:"update,omitempty"`
+}
 
-	// Language version managers
-	if checkNvmInstalled() {
-		version := getVersionManagerVersion("nvm")
-		if version != "" {
-			versionStr := formatVersionWithUpdate("nvm", version)
-			languageAvailable = append(languageAvailable, fmt.Sprintf("nvm (%s)", versionStr))
-		} else {
-			languageAvailable = append(languageAvailable, "nvm")
+// withPackageManagerUpdates looks up newer releases of the detected package managers
+func withPackageManagerUpdates(managers []PackageManagerInfo) []PackageManagerInfo {
+	for i, pm := range managers {
+		if pm.Version != "" {
+			managers[i].Update = checkVersionUpdate(pm.ID, pm.Version)
 		}
 	}
-	if exists("pyenv") {
-		version := getVersionManagerVersion("pyenv")
-		if version != "" {
-			versionStr := formatVersionWithUpdate("pyenv", version)
-			languageAvailable = append(languageAvailable, fmt.Sprintf("pyenv (%s)", versionStr))
-		} else {
-			languageAvailable = append(languageAvailable, "pyenv")
-		}
-	}
-	if exists("rbenv") {
-		version := getVersionManagerVersion("rbenv")
-		if version != "" {
-			versionStr := formatVersionWithUpdate("rbenv", version)
-			languageAvailable = append(languageAvailable, fmt.Sprintf("rbenv (%s)", versionStr))
-		} else {
-			languageAvailable = append(languageAvailable, "rbenv")
-		}
+	return managers
+}
+
+// packageManagerDisplayNames are shown in place of some managers' names
+var packageManagerDisplayNames = map[string]string{
+	"brew":       "homebrew",
+	"choco":      "chocolatey",
+	"vboxmanage": "VBoxManage",
+}
+
+// detectPackageManagers finds available package managers and their versions
+func detectPackageManagers(ctx context.Context) []PackageManagerInfo {
+	managers := []PackageManagerInfo{}
+	add := func(category, name, id, version string) {
+		managers = append(managers, PackageManagerInfo{Name: name, ID: id, Version: version, Category: category})
 	}
-	if exists("jenv") {
-		version := getVersionManagerVersion("jenv")
-		if version != "" {
-			versionStr := formatVersionWithUpdate("jenv", version)
-			languageAvailable = append(languageAvailable, fmt.Sprintf("jenv (%s)", versionStr))
-		} else {
-			languageAvailable = append(languageAvailable, "jenv")
+
+	// System, runtime and infrastructure package managers
+	for _, pm := range pkgmgr.Detected(ctx) {
+		name := pm.Name()
+		if display, ok := packageManagerDisplayNames[name]; ok {
+			name = display
 		}
+		add(string(pm.Category()), name, pm.Name(), getPackageManagerVersion(ctx, pm.Name()))
 	}
-	if exists("rustup") {
-		version := getVersionManagerVersion("rustup")
-		if version != "" {
-			versionStr := formatVersionWithUpdate("rustup", version)
-			languageAvailable = append(languageAvailable, fmt.Sprintf("rustup (%s)", versionStr))
-		} else {
-			languageAvailable = append(languageAvailable, "rustup")
-		}
+
+	// Language version managers
+	if checkNvmInstalled() {
+		add(pmCategoryLanguage, "nvm", "nvm", getVersionManagerVersion(ctx, "nvm"))
 	}
-	if exists("asdf") {
-		version := getVersionManagerVersion("asdf")
-		if version != "" {
-			versionStr := formatVersionWithUpdate("asdf", version)
-			languageAvailable = append(languageAvailable, fmt.Sprintf("asdf (%s)", versionStr))
-		} else {
-			languageAvailable = append(languageAvailable, "asdf")
+	for _, vm := range []string{"pyenv", "rbenv", "jenv", "rustup", "asdf"} {
+		if exists(ctx, vm) {
+			add(pmCategoryLanguage, vm, vm, getVersionManagerVersion(ctx, vm))
 		}
 	}
 	// Check for sdkman
@@ -1466,286 +1259,71 @@ func printPackageManagers() {
 	if err == nil {
 		sdkmanInit := filepath.Join(home, ".sdkman", "bin", "sdkman-init.sh")
 		if _, err := os.Stat(sdkmanInit); err == nil {
-			version := getVersionManagerVersion("sdkman")
-			if version != "" {
-				versionStr := formatVersionWithUpdate("sdkman", version)
-				languageAvailable = append(languageAvailable, fmt.Sprintf("sdkman (%s)", versionStr))
-			} else {
-				languageAvailable = append(languageAvailable, "sdkman")
-			}
+			add(pmCategoryLanguage, "sdkman", "sdkman", getVersionManagerVersion(ctx, "sdkman"))
 		}
 	}
 
-	// Programming runtime package managers
-	if exists("npm") {
-		version := getPackageManagerVersion("npm")
-		if version != "" {
-			versionStr := formatVersionWithUpdate("npm", version)
-			runtimeAvailable = append(runtimeAvailable, fmt.Sprintf("npm (%s)", versionStr))
-		} else {
-			runtimeAvailable = append(runtimeAvailable, "npm")
-		}
-	}
-	if exists("pip") || exists("pip3") {
-		version := getPackageManagerVersion("pip")
-		if version != "" {
-			versionStr := formatVersionWithUpdate("pip", version)
-			runtimeAvailable = append(runtimeAvailable, fmt.Sprintf("pip (%s)", versionStr))
-		} else {
-			runtimeAvailable = append(runtimeAvailable, "pip")
-		}
-	}
-	if exists("pipx") {
-		version := getPackageManagerVersion("pipx")
-		if version != "" {
-			versionStr := formatVersionWithUpdate("pipx", version)
-			runtimeAvailable = append(runtimeAvailable, fmt.Sprintf("pipx (%s)", versionStr))
-		} else {
-			runtimeAvailable = append(runtimeAvailable, "pipx")
-		}
-	}
-	if exists("gem") {
-		version := getPackageManagerVersion("gem")
-		if version != "" {
-			versionStr := formatVersionWithUpdate("gem", version)
-			runtimeAvailable = append(runtimeAvailable, fmt.Sprintf("gem (%s)", versionStr))
-		} else {
-			runtimeAvailable = append(runtimeAvailable, "gem")
-		}
-	}
-	if exists("cargo") {
-		version := getPackageManagerVersion("cargo")
-		if version != "" {
-			versionStr := formatVersionWithUpdate("cargo", version)
-			runtimeAvailable = append(runtimeAvailable, fmt.Sprintf("cargo (%s)", versionStr))
-		} else {
-			runtimeAvailable = append(runtimeAvailable, "cargo")
-		}
-	}
-	if exists("go") {
-		version := getPackageManagerVersion("go")
-		if version != "" {
-			versionStr := formatVersionWithUpdate("go", version)
-			runtimeAvailable = append(runtimeAvailable, fmt.Sprintf("go (%s)", versionStr))
-		} else {
-			runtimeAvailable = append(runtimeAvailable, "go")
-		}
-	}
-
-	// Infrastructure package managers
-	if exists("VBoxManage") {
-		version := getPackageManagerVersion("vboxmanage")
-		if version != "" {
-			versionStr := formatVersionWithUpdate("vboxmanage", version)
-			infrastructureAvailable = append(infrastructureAvailable, fmt.Sprintf("VBoxManage (%s)", versionStr))
-		} else {
-			infrastructureAvailable = append(infrastructureAvailable, "VBoxManage")
-		}
-	}
-
-	// Print
-	if len(systemAvailable) > 0 {
-		fmt.Printf("  System:         %s\n", strings.Join(systemAvailable, ", "))
-	}
-	if len(languageAvailable) > 0 {
-		fmt.Printf("  Language:       %s\n", strings.Join(languageAvailable, ", "))
-	}
-	if len(runtimeAvailable) > 0 {
-		fmt.Printf("  Runtime:        %s\n", strings.Join(runtimeAvailable, ", "))
-	}
-	if len(infrastructureAvailable) > 0 {
-		fmt.Printf("  Infrastructure: %s\n", strings.Join(infrastructureAvailable, ", "))
-	}
+	return managers
 }
 
-// getPackageManagerVersion returns the version of a package manager
-func getPackageManagerVersion(manager string) string {
-	var cmd *exec.Cmd
-
-	switch manager {
-	case "apt":
-		cmd = exec.Command("apt-get", "--version")
-	case "flatpak":
-		cmd = exec.Command("flatpak", "--version")
-	case "snap":
-		cmd = exec.Command("snap", "--version")
-	case "dnf":
-		cmd = exec.Command("dnf", "--version")
-	case "yum":
-		cmd = exec.Command("yum", "--version")
-	case "pacman":
-		cmd = exec.Command("pacman", "--version")
-	case "brew":
-		cmd = exec.Command("brew", "--version")
-	case "choco":
-		cmd = exec.Command("choco", "--version")
-	case "winget":
-		cmd = exec.Command("winget", "--version")
-	case "npm":
-		cmd = exec.Command("npm", "--version")
-	case "pip":
-		if exists("pip3") {
-			cmd = exec.Command("pip3", "--version")
-		} else {
-			cmd = exec.Command("pip", "--version")
-		}
-	case "pipx":
-		cmd = exec.Command("pipx", "--version")
-	case "gem":
-		cmd = exec.Command("gem", "--version")
-	case "cargo":
-		cmd = exec.Command("cargo", "--version")
-	case "go":
-		cmd = exec.Command("go", "version")
-	case "vboxmanage":
-		cmd = exec.Command("VBoxManage", "--version")
-	default:
-		return ""
-	}
-
-	if cmd == nil {
-		return ""
+// printPackageManagers displays available package managers grouped by category
+func printPackageManagers(w io.Writer, managers []PackageManagerInfo) {
+	grouped := map[string][]string{}
+	for _, pm := range managers {
+		entry := pm.Name
+		if pm.Version != "" {
+			entry = fmt.Sprintf("%s (%s)", pm.Name, formatVersion(pm.Version, pm.Update))
+		}
+		grouped[pm.Category] = append(grouped[pm.Category], entry)
 	}
 
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return ""
+	for _, category := range []struct{ key, label string }{
+		{pmCategorySystem, "System:"},
+		{pmCategoryLanguage, "Language:"},
+		{pmCategoryRuntime, "Runtime:"},
+		{pmCategoryInfrastructure, "Infrastructure:"},
+	} {
+		if entries := grouped[category.key]; len(entries) > 0 {
+			fmt.Fprintf(w, "  %-15s %s\n", category.label, strings.Join(entries, ", "))
+		}
 	}
-
-	version := strings.TrimSpace(string(output))
-	return extractPackageManagerVersion(manager, version)
 }
 
-// extractPackageManagerVersion extracts clean version from package manager output
-func extractPackageManagerVersion(manager, output string) string {
-	output = strings.TrimSpace(output)
-	if output == "" {
+// getPackageManagerVersion returns the version of a package manager, or
+// nothing when it is unknown or its version cannot be read
+func getPackageManagerVersion(ctx context.Context, manager string) string {
+	pm, ok := pkgmgr.Get(manager)
+	if !ok {
 		return ""
 	}
-
-	// Take first line only
-	if idx := strings.Index(output, "\n"); idx >= 0 {
-		output = output[:idx]
-	}
-
-	switch manager {
-	case "apt":
-		// "apt 2.8.3 (amd64)" -> "2.8.3"
-		if strings.HasPrefix(output, "apt ") {
-			parts := strings.Fields(output)
-			if len(parts) >= 2 {
-				return parts[1]
-			}
-		}
-	case "flatpak":
-		// "Flatpak 1.14.6" -> "1.14.6"
-		if strings.HasPrefix(output, "Flatpak ") {
-			return strings.TrimPrefix(output, "Flatpak ")
-		}
-	case "snap":
-		// "snap    2.63" -> "2.63"
-		if strings.HasPrefix(output, "snap") {
-			parts := strings.Fields(output)
-			if len(parts) >= 2 {
-				return parts[1]
-			}
-		}
-	case "pip":
-		// "pip 24.0 from /usr/lib..." -> "24.0"
-		if strings.HasPrefix(output, "pip ") {
-			parts := strings.Fields(output)
-			if len(parts) >= 2 {
-				return parts[1]
-			}
-		}
-	case "npm", "pipx", "gem":
-		// These usually return just version number
-		return output
-	case "cargo":
-		// "cargo 1.70.0 (7c2f85da6 2023-05-31)" -> "1.70.0"
-		if strings.HasPrefix(output, "cargo ") {
-			parts := strings.Fields(output)
-			if len(parts) >= 2 {
-				return parts[1]
-			}
-		}
-	case "go":
-		// "go version go1.25.5 linux/amd64" -> "1.25.5"
-		if strings.HasPrefix(output, "go version go") {
-			parts := strings.Fields(output)
-			if len(parts) >= 3 {
-				return strings.TrimPrefix(parts[2], "go")
-			}
-		}
-	case "brew":
-		// "Homebrew 4.0.0" or just "4.0.0"
-		if strings.HasPrefix(output, "Homebrew ") {
-			return strings.TrimPrefix(output, "Homebrew ")
-		}
-		return output
-	case "dnf", "yum":
-		// Usually just version number
-		return output
-	case "pacman":
-		// "Pacman v6.0.1 - libalpm v13.0.1" -> "6.0.1"
-		if strings.Contains(output, "Pacman v") {
-			parts := strings.Fields(output)
-			for _, part := range parts {
-				if strings.HasPrefix(part, "v") && len(part) > 1 {
-					return strings.TrimPrefix(part, "v")
-				}
-			}
-		}
-	case "choco", "winget":
-		// Usually just version number
-		return output
-	case "vboxmanage":
-		// "7.0.14r161095" - just the version number
-		return output
-	}
-
-	// Generic: try to extract version-like pattern
-	fields := strings.Fields(output)
-	for _, field := range fields {
-		if strings.Contains(field, ".") {
-			field = strings.Trim(field, "()[]{}\"',")
-			if len(field) > 0 && (field[0] >= '0' && field[0] <= '9') {
-				return field
-			}
-		}
-	}
-
-	return output
+	version, _ := pm.Version(ctx) //nolint:errcheck
+	return version
 }
 
 // getVersionManagerVersion returns the version of a language version manager
-func getVersionManagerVersion(manager string) string {
-	var cmd *exec.Cmd
+func getVersionManagerVersion(ctx context.Context, manager string) string {
+	var argv []string
 
 	switch manager {
 	case "nvm":
-		cmd = exec.Command("bash", "-c", ". ~/.nvm/nvm.sh 2>/dev/null && nvm --version || echo ''")
+		argv = []string{"bash", "-c", ". ~/.nvm/nvm.sh 2>/dev/null && nvm --version || echo ''"}
 	case "pyenv":
-		cmd = exec.Command("pyenv", "--version")
+		argv = []string{"pyenv", "--version"}
 	case "rbenv":
-		cmd = exec.Command("rbenv", "--version")
+		argv = []string{"rbenv", "--version"}
 	case "jenv":
-		cmd = exec.Command("jenv", "--version")
+		argv = []string{"jenv", "--version"}
 	case "rustup":
-		cmd = exec.Command("rustup", "--version")
+		argv = []string{"rustup", "--version"}
 	case "asdf":
-		cmd = exec.Command("asdf", "--version")
+		argv = []string{"asdf", "--version"}
 	case "sdkman":
-		cmd = exec.Command("bash", "-c", "source ~/.sdkman/bin/sdkman-init.sh 2>/dev/null && sdk version || echo ''")
+		argv = []string{"bash", "-c", "source ~/.sdkman/bin/sdkman-init.sh 2>/dev/null && sdk version || echo ''"}
 	default:
 		return ""
 	}
 
-	if cmd == nil {
-		return ""
-	}
-
-	output, err := cmd.CombinedOutput()
+	output, err := runner.CombinedOutput(ctx, argv[0], argv[1:]...)
 	if err != nil {
 		return ""
 	}
@@ -1832,13 +1410,13 @@ func extractVersionManagerVersion(manager, output string) string {
 
 // DiskInfo holds detailed disk information
 type DiskInfo struct {
-	Device      string
-	Mountpoint  string
-	Filesystem  string
-	Total       uint64
-	Used        uint64
-	Free        uint64
-	UsedPercent float64
+	Device      string  `json:"device"`
+	Mountpoint  string  `json:"mountpoint"`
+	Filesystem  string  `json:"filesystem"`
+	Total       uint64  `json:"total"`
+	Used        uint64  `json:"used"`
+	Free        uint64  `json:"free"`
+	UsedPercent float64 `json:"used_percent"`
 }
 
 // getDetailedDiskInfo returns detailed information about all disks/partitions
@@ -1903,15 +1481,15 @@ func getDetailedDiskInfo() []DiskInfo {
 }
 
 // printDiskInfo prints detailed disk information
-func printDiskInfo(disks []DiskInfo) {
+func printDiskInfo(w io.Writer, disks []DiskInfo) {
 	if len(disks) == 0 {
-		fmt.Printf("  No disks detected\n")
+		fmt.Fprintf(w, "  No disks detected\n")
 		return
 	}
 
 	for i, disk := range disks {
 		if i > 0 {
-			fmt.Println()
+			fmt.Fprintln(w)
 		}
 
 		// Format sizes
@@ -1920,20 +1498,20 @@ func printDiskInfo(disks []DiskInfo) {
 		freeGB := float64(disk.Free) / 1e9
 
 		// Mountpoint (or drive letter on Windows)
-		fmt.Printf("  Mount:     %s\n", disk.Mountpoint)
+		fmt.Fprintf(w, "  Mount:     %s\n", disk.Mountpoint)
 
 		// Device name
-		fmt.Printf("  Device:    %s\n", disk.Device)
+		fmt.Fprintf(w, "  Device:    %s\n", disk.Device)
 
 		// Filesystem type
 		if disk.Filesystem != "" {
-			fmt.Printf("  Type:      %s\n", disk.Filesystem)
+			fmt.Fprintf(w, "  Type:      %s\n", disk.Filesystem)
 		}
 
 		// Size information
-		fmt.Printf("  Size:      %.1f GB total\n", totalGB)
-		fmt.Printf("  Used:      %.1f GB (%.1f%%)\n", usedGB, disk.UsedPercent)
-		fmt.Printf("  Free:      %.1f GB\n", freeGB)
+		fmt.Fprintf(w, "  Size:      %.1f GB total\n", totalGB)
+		fmt.Fprintf(w, "  Used:      %.1f GB (%.1f%%)\n", usedGB, disk.UsedPercent)
+		fmt.Fprintf(w, "  Free:      %.1f GB\n", freeGB)
 	}
 }
 
@@ -1984,11 +1562,13 @@ func getDiskInfo() string {
 }
 
 // printPortsSummary prints a summary of listening ports
-func printPortsSummary() {
-	info := gatherPortsInfo()
+func printPortsSummary(w io.Writer, info *PortInfo) {
+	if info == nil {
+		return
+	}
 	total := info.TCPPorts + info.UDPPorts
 
 	if total > 0 {
-		fmt.Printf("Ports:     %d listening (TCP: %d, UDP: %d)\n", total, info.TCPPorts, info.UDPPorts)
+		fmt.Fprintf(w, "Ports:     %d listening (TCP: %d, UDP: %d)\n", total, info.TCPPorts, info.UDPPorts)
 	}
 }
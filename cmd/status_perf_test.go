@@ -84,8 +84,9 @@ func Benchmark_StatusCommand(b *testing.B) {
 
 // Test_PackageDetectionPerformance tests the async package detection specifically
 func Test_PackageDetectionPerformance(t *testing.T) {
+	ctx := context.Background()
 	start := time.Now()
-	future := StartPackageSummary()
+	future := StartPackageSummary(ctx)
 	if future != nil {
 		// Redirect stdout to suppress output
 		oldStdout := os.Stdout
@@ -0,0 +1,588 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/host"
+	"github.com/shirou/gopsutil/v4/mem"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/aallbrig/allbctl/pkg/telemetry"
+)
+
+// StatusSection is one part of 'allbctl status', such as the GPU list or the
+// package counts. Sections are collected concurrently, each under its own
+// deadline, and rendered in registration order.
+type StatusSection interface {
+	// Name identifies the section, e.g. "gpu"
+	Name() string
+	// Collect gathers the section's data. The returned function stores it in
+	// the snapshot; it is only called when Collect finishes before the
+	// section's deadline, so a late collector never touches the snapshot.
+	Collect(ctx context.Context) (func(*SystemSnapshot), error)
+	// Render writes the section's part of the text view to w. outcome reports how
+	// collection went, and is nil for snapshots without that record (e.g. ones
+	// loaded from older history).
+	Render(w io.Writer, snapshot *SystemSnapshot, outcome *SectionOutcome)
+}
+
+// Section outcome statuses
+const (
+	sectionOK       = "ok"
+	sectionTimedOut = "timed_out"
+	sectionError    = "error"
+	// sectionPending marks a section --watch has not collected yet
+	sectionPending = "pending"
+)
+
+// SectionOutcome records how collecting one status section went
+type SectionOutcome struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	DurationMS int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// statusSectionEntry is a registered section and its deadline
+type statusSectionEntry struct {
+	section StatusSection
+	timeout time.Duration
+	// refresh is how often --watch re-collects the section; zero means every
+	// tick. Sections with a refresh are collected in the background.
+	refresh time.Duration
+}
+
+// statusSectionRefresh lists the sections that are slow or rarely change,
+// and how often --watch re-collects them
+var statusSectionRefresh = map[string]time.Duration{
+	"gpu":              30 * time.Second,
+	"runtimes":         5 * time.Minute,
+	"databases":        time.Minute,
+	"browsers":         10 * time.Minute,
+	"ai-agents":        10 * time.Minute,
+	"package-managers": 10 * time.Minute,
+	"packages":         5 * time.Minute,
+	"cloud-native":     5 * time.Minute,
+	"projects":         time.Minute,
+}
+
+// statusSections is the registry, in display order
+var statusSections []statusSectionEntry
+
+// registerStatusSection adds a section to 'allbctl status'. Its collector is
+// abandoned, and the section reported as timed out, after timeout.
+func registerStatusSection(section StatusSection, timeout time.Duration) {
+	statusSections = append(statusSections, statusSectionEntry{
+		section: section,
+		timeout: timeout,
+		refresh: statusSectionRefresh[section.Name()],
+	})
+}
+
+// collectStatusSections runs every section concurrently and stores the
+// results of those that finished in snapshot
+func collectStatusSections(ctx context.Context, entries []statusSectionEntry, snapshot *SystemSnapshot) {
+	outcomes := make([]SectionOutcome, len(entries))
+	stores := make([]func(*SystemSnapshot), len(entries))
+
+	var wg sync.WaitGroup
+	for i, entry := range entries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			outcomes[i], stores[i] = runStatusSection(ctx, entry)
+		}()
+	}
+	wg.Wait()
+
+	for _, store := range stores {
+		if store != nil {
+			store(snapshot)
+		}
+	}
+	snapshot.Sections = outcomes
+}
+
+// runStatusSection collects one section under its deadline
+func runStatusSection(ctx context.Context, entry statusSectionEntry) (SectionOutcome, func(*SystemSnapshot)) {
+	name := entry.section.Name()
+	ctx, cancel := context.WithTimeout(ctx, entry.timeout)
+	defer cancel()
+	ctx, span := otel.Tracer("github.com/aallbrig/allbctl").Start(ctx, "status.section."+name,
+		trace.WithAttributes(attribute.String("section", name)),
+	)
+	defer span.End()
+
+	type collected struct {
+		store func(*SystemSnapshot)
+		err   error
+	}
+	// Buffered so a collector that finishes after the deadline does not block forever
+	done := make(chan collected, 1)
+	start := time.Now()
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- collected{err: fmt.Errorf("panic: %v", r)}
+			}
+		}()
+		store, err := entry.section.Collect(ctx)
+		done <- collected{store: store, err: err}
+	}()
+
+	outcome := SectionOutcome{Name: name, Status: sectionOK}
+	var store func(*SystemSnapshot)
+	select {
+	case result := <-done:
+		store = result.store
+		if result.err != nil {
+			outcome.Status = sectionError
+			outcome.Error = result.err.Error()
+		}
+	case <-ctx.Done():
+		outcome.Status = sectionTimedOut
+		outcome.Error = fmt.Sprintf("timed out after %s", entry.timeout)
+	}
+	outcome.DurationMS = time.Since(start).Milliseconds()
+
+	if outcome.Status != sectionOK {
+		span.SetStatus(codes.Error, outcome.Error)
+	}
+	telemetry.Logger.DebugContext(ctx, "status.section",
+		"section", name,
+		"status", outcome.Status,
+		"duration_ms", outcome.DurationMS,
+	)
+	return outcome, store
+}
+
+// renderStatusSections prints the sections recorded in snapshot, in the order
+// they were collected. Snapshots without that record (e.g. older history)
+// print every section in entries.
+func renderStatusSections(w io.Writer, entries []statusSectionEntry, snapshot *SystemSnapshot) {
+	if len(snapshot.Sections) == 0 {
+		for _, entry := range entries {
+			entry.section.Render(w, snapshot, nil)
+		}
+		return
+	}
+
+	byName := make(map[string]StatusSection, len(entries))
+	for _, entry := range entries {
+		byName[entry.section.Name()] = entry.section
+	}
+	for i := range snapshot.Sections {
+		if section, ok := byName[snapshot.Sections[i].Name]; ok {
+			section.Render(w, snapshot, &snapshot.Sections[i])
+		}
+	}
+}
+
+// statusConfigKey is the config file section that lays out 'allbctl status'
+const statusConfigKey = "status"
+
+// StatusConfig is the `status:` section of ~/.allbctl.yaml
+type StatusConfig struct {
+	// Sections lists the sections to show, in order; empty means all of them
+	Sections []string `mapstructure:"sections"`
+	// Skip lists sections to leave out
+	Skip []string `mapstructure:"skip"`
+	// Timeouts overrides the deadline of individual sections, e.g. packages: 2m
+	Timeouts map[string]time.Duration `mapstructure:"timeouts"`
+	Projects ProjectsSectionConfig    `mapstructure:"projects"`
+}
+
+// ProjectsSectionConfig holds the options of the projects section
+type ProjectsSectionConfig struct {
+	// Limit is how many recently touched repos to list; 0 lists all of them
+	Limit int `mapstructure:"limit"`
+}
+
+// defaultStatusConfig is the layout used without a `status:` config section
+func defaultStatusConfig() StatusConfig {
+	return StatusConfig{Projects: ProjectsSectionConfig{Limit: 5}}
+}
+
+// statusConfig is the layout of the current 'allbctl status' run
+var statusConfig = defaultStatusConfig()
+
+// loadStatusConfig reads the `status:` section of the config file, if any
+func loadStatusConfig() (StatusConfig, error) {
+	config := defaultStatusConfig()
+	if !viper.IsSet(statusConfigKey) {
+		return config, nil
+	}
+	if err := viper.UnmarshalKey(statusConfigKey, &config); err != nil {
+		return config, fmt.Errorf("cannot read %s from %s: %w", statusConfigKey, viper.ConfigFileUsed(), err)
+	}
+	if config.Projects.Limit < 0 {
+		return config, fmt.Errorf("%s: %s.projects.limit must not be negative", viper.ConfigFileUsed(), statusConfigKey)
+	}
+	for name, timeout := range config.Timeouts {
+		if timeout <= 0 {
+			return config, fmt.Errorf("%s: %s.timeouts.%s must be positive", viper.ConfigFileUsed(), statusConfigKey, name)
+		}
+	}
+	return config, nil
+}
+
+// selectStatusSections returns the entries named by sections (all of them
+// when empty), in that order, minus those named by skip, with the timeouts
+// overridden
+func selectStatusSections(entries []statusSectionEntry, sections, skip []string, timeouts map[string]time.Duration) ([]statusSectionEntry, error) {
+	byName := make(map[string]statusSectionEntry, len(entries))
+	for _, entry := range entries {
+		byName[entry.section.Name()] = entry
+	}
+	for name := range timeouts {
+		if _, ok := byName[name]; !ok {
+			return nil, unknownStatusSectionError(entries, name)
+		}
+	}
+
+	skipped := map[string]bool{}
+	for _, name := range skip {
+		if _, ok := byName[name]; !ok {
+			return nil, unknownStatusSectionError(entries, name)
+		}
+		skipped[name] = true
+	}
+
+	if len(sections) == 0 {
+		for _, entry := range entries {
+			sections = append(sections, entry.section.Name())
+		}
+	}
+
+	var selected []statusSectionEntry
+	seen := map[string]bool{}
+	for _, name := range sections {
+		entry, ok := byName[name]
+		if !ok {
+			return nil, unknownStatusSectionError(entries, name)
+		}
+		if skipped[name] || seen[name] {
+			continue
+		}
+		seen[name] = true
+		if timeout, ok := timeouts[name]; ok {
+			entry.timeout = timeout
+		}
+		selected = append(selected, entry)
+	}
+	return selected, nil
+}
+
+func unknownStatusSectionError(entries []statusSectionEntry, name string) error {
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.section.Name())
+	}
+	return fmt.Errorf("unknown status section %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// statusSection adapts a collector function and a renderer to StatusSection
+type statusSection[T any] struct {
+	name  string
+	title string // label used when the section could not be collected
+	block bool   // the section ends with a blank line
+	// collect gathers the data, store puts it in the snapshot and render prints it
+	collect func(ctx context.Context) T
+	store   func(snapshot *SystemSnapshot, value T)
+	render  func(w io.Writer, snapshot *SystemSnapshot)
+	// tryCollect replaces collect for sections that can fail outright, e.g.
+	// on a broken config section
+	tryCollect func(ctx context.Context) (T, error)
+}
+
+func (s statusSection[T]) Name() string {
+	return s.name
+}
+
+func (s statusSection[T]) Collect(ctx context.Context) (func(*SystemSnapshot), error) {
+	if s.tryCollect != nil {
+		value, err := s.tryCollect(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return func(snapshot *SystemSnapshot) { s.store(snapshot, value) }, nil
+	}
+	value := s.collect(ctx)
+	return func(snapshot *SystemSnapshot) { s.store(snapshot, value) }, nil
+}
+
+func (s statusSection[T]) Render(w io.Writer, snapshot *SystemSnapshot, outcome *SectionOutcome) {
+	if outcome == nil || outcome.Status == sectionOK {
+		s.render(w, snapshot)
+		return
+	}
+	message := outcome.Error
+	if outcome.Status == sectionPending {
+		message = "collecting..."
+	}
+	fmt.Fprintf(w, "%-10s %s\n", s.title+":", message)
+	if s.block {
+		fmt.Fprintln(w)
+	}
+}
+
+func init() {
+	registerStatusSection(statusSection[hostDetails]{
+		name: "system", title: "OS",
+		collect: func(context.Context) hostDetails { return detectHostDetails() },
+		store: func(s *SystemSnapshot, v hostDetails) {
+			s.OS = v.os
+			s.Shell = v.shell
+		},
+		render: func(w io.Writer, s *SystemSnapshot) {
+			fmt.Fprintf(w, "OS:        %s\n", s.OS)
+			fmt.Fprintf(w, "Hostname:  %s\n", s.Hostname)
+			fmt.Fprintf(w, "Shell:     %s\n", s.Shell)
+		},
+	}, 5*time.Second)
+
+	registerStatusSection(statusSection[string]{
+		name: "terminal", title: "Terminal",
+		collect: func(context.Context) string { return detectTerminal() },
+		store:   func(s *SystemSnapshot, v string) { s.Terminal = v },
+		render:  func(w io.Writer, s *SystemSnapshot) { fmt.Fprintf(w, "Terminal:  %s\n", s.Terminal) },
+	}, 5*time.Second)
+
+	registerStatusSection(statusSection[CPUDetails]{
+		name: "cpu", title: "CPU",
+		collect: func(ctx context.Context) CPUDetails { return getDetailedCPUInfo(ctx) },
+		store:   func(s *SystemSnapshot, v CPUDetails) { s.CPU = v },
+		render: func(w io.Writer, s *SystemSnapshot) {
+			fmt.Fprintf(w, "CPU:\n")
+			printCPUInfo(w, s.CPU)
+		},
+	}, 10*time.Second)
+
+	registerStatusSection(statusSection[[]GPUInfo]{
+		name: "gpu", title: "GPU(s)",
+		collect: func(ctx context.Context) []GPUInfo { return getDetailedGPUInfo(ctx) },
+		store:   func(s *SystemSnapshot, v []GPUInfo) { s.GPUs = v },
+		render: func(w io.Writer, s *SystemSnapshot) {
+			fmt.Fprintf(w, "GPU(s):\n")
+			printGPUInfo(w, s.GPUs)
+		},
+	}, 10*time.Second)
+
+	registerStatusSection(statusSection[uint64]{
+		name: "memory", title: "Memory",
+		collect: func(context.Context) uint64 {
+			// Show only total installed
+			if memInfo, err := mem.VirtualMemory(); err == nil {
+				return memInfo.Total
+			}
+			return 0
+		},
+		store:  func(s *SystemSnapshot, v uint64) { s.MemoryBytes = v },
+		render: func(w io.Writer, s *SystemSnapshot) { fmt.Fprintf(w, "Memory:    %s\n", s.memoryString()) },
+	}, 5*time.Second)
+
+	registerStatusSection(statusSection[diskDetails]{
+		name: "disks", title: "Disks",
+		collect: func(context.Context) diskDetails {
+			details := diskDetails{disks: getDetailedDiskInfo()}
+			if len(details.disks) == 0 {
+				details.summary = getDiskInfo()
+			}
+			return details
+		},
+		store: func(s *SystemSnapshot, v diskDetails) {
+			s.Disks = v.disks
+			s.DiskSummary = v.summary
+		},
+		render: printDisksSection,
+	}, 10*time.Second)
+
+	registerStatusSection(statusSection[string]{
+		name: "hardware", title: "Hardware",
+		collect: func(context.Context) string { return detectHostDetails().hardware },
+		store:   func(s *SystemSnapshot, v string) { s.Hardware = v },
+		render:  func(w io.Writer, s *SystemSnapshot) { fmt.Fprintf(w, "Hardware:  %s\n", s.Hardware) },
+	}, 5*time.Second)
+
+	registerStatusSection(statusSection[[]RuntimeInfo]{
+		name: "runtimes", title: "Runtimes",
+		collect: func(ctx context.Context) []RuntimeInfo { return withRuntimeUpdates(detectRuntimes(ctx)) },
+		store:   func(s *SystemSnapshot, v []RuntimeInfo) { s.Runtimes = v },
+		render: func(w io.Writer, s *SystemSnapshot) {
+			if runtimesInline := formatRuntimesInline(s.Runtimes); runtimesInline != "" {
+				fmt.Fprintf(w, "Runtimes:  %s\n", runtimesInline)
+			}
+		},
+	}, 15*time.Second)
+
+	registerStatusSection(statusSection[[]DatabaseInfo]{
+		name: "databases", title: "Databases", block: true,
+		collect: func(ctx context.Context) []DatabaseInfo { return withDatabaseUpdates(detectAllDatabases(ctx)) },
+		store:   func(s *SystemSnapshot, v []DatabaseInfo) { s.Databases = v },
+		render: func(w io.Writer, s *SystemSnapshot) {
+			printDatabaseSummaryLine(w, s.Databases)
+			fmt.Fprintln(w)
+		},
+	}, 10*time.Second)
+
+	registerStatusSection(statusSection[*NetworkDetails]{
+		name: "network", title: "Network", block: true,
+		collect: func(ctx context.Context) *NetworkDetails { return gatherNetworkDetails(ctx) },
+		store:   func(s *SystemSnapshot, v *NetworkDetails) { s.Network = v },
+		render: func(w io.Writer, s *SystemSnapshot) {
+			if s.Network != nil {
+				printNetworkDetails(w, s.Network)
+			}
+			fmt.Fprintln(w)
+		},
+	}, 15*time.Second)
+
+	registerStatusSection(statusSection[*PortInfo]{
+		name: "ports", title: "Ports", block: true,
+		collect: func(ctx context.Context) *PortInfo { return gatherPortsInfo(ctx) },
+		store:   func(s *SystemSnapshot, v *PortInfo) { s.Ports = v },
+		render: func(w io.Writer, s *SystemSnapshot) {
+			printPortsSummary(w, s.Ports)
+			fmt.Fprintln(w)
+		},
+	}, 10*time.Second)
+
+	registerStatusSection(statusSection[[]BrowserInfo]{
+		name: "browsers", title: "Browsers", block: true,
+		collect: func(ctx context.Context) []BrowserInfo { return detectBrowsers(ctx) },
+		store:   func(s *SystemSnapshot, v []BrowserInfo) { s.Browsers = v },
+		render: func(w io.Writer, s *SystemSnapshot) {
+			if len(s.Browsers) > 0 {
+				fmt.Fprintln(w, "Browsers:")
+				printBrowsers(w, s.Browsers)
+				fmt.Fprintln(w)
+			}
+		},
+	}, 10*time.Second)
+
+	registerStatusSection(statusSection[[]AIAgent]{
+		name: "ai-agents", title: "AI Agents", block: true,
+		collect: func(ctx context.Context) []AIAgent { return withAIAgentUpdates(detectAIAgents(ctx)) },
+		store:   func(s *SystemSnapshot, v []AIAgent) { s.AIAgents = v },
+		render: func(w io.Writer, s *SystemSnapshot) {
+			fmt.Fprintln(w, "AI Agents:")
+			printAIAgents(w, s.AIAgents)
+			fmt.Fprintln(w)
+		},
+	}, 10*time.Second)
+
+	registerStatusSection(statusSection[[]PackageManagerInfo]{
+		name: "package-managers", title: "Package Managers", block: true,
+		collect: func(ctx context.Context) []PackageManagerInfo {
+			return withPackageManagerUpdates(detectPackageManagers(ctx))
+		},
+		store: func(s *SystemSnapshot, v []PackageManagerInfo) { s.PackageManagers = v },
+		render: func(w io.Writer, s *SystemSnapshot) {
+			fmt.Fprintln(w, "Package Managers:")
+			printPackageManagers(w, s.PackageManagers)
+			fmt.Fprintln(w)
+		},
+	}, 15*time.Second)
+
+	registerStatusSection(statusSection[[]PackageResult]{
+		name: "packages", title: "Packages", block: true,
+		collect: func(ctx context.Context) []PackageResult { return StartPackageSummary(ctx).Results() },
+		store:   func(s *SystemSnapshot, v []PackageResult) { s.Packages = v },
+		render: func(w io.Writer, s *SystemSnapshot) {
+			fmt.Fprintln(w, "Packages:")
+			if len(s.Packages) > 0 {
+				printPackageResults(w, s.Packages)
+			} else {
+				fmt.Fprintln(w, "  No package managers detected")
+			}
+			fmt.Fprintln(w)
+		},
+	}, 60*time.Second)
+
+	registerStatusSection(statusSection[[]CloudCLIInfo]{
+		name: "cloud-native", title: "Cloud Native", block: true,
+		collect: func(ctx context.Context) []CloudCLIInfo { return detectCloudCLIs(ctx) },
+		store:   func(s *SystemSnapshot, v []CloudCLIInfo) { s.CloudNative = v },
+		render:  func(w io.Writer, s *SystemSnapshot) { printCloudNativeForStatus(w, s.CloudNative) },
+	}, 20*time.Second)
+
+	registerStatusSection(statusSection[*ProjectsSummary]{
+		name: "projects", title: "Projects",
+		tryCollect: gatherProjects,
+		store:      func(s *SystemSnapshot, v *ProjectsSummary) { s.Projects = v },
+		render: func(w io.Writer, s *SystemSnapshot) {
+			printProjectsSummaryInline(w, s.Projects, statusConfig.Projects.Limit)
+		},
+	}, 30*time.Second)
+}
+
+// diskDetails is what the disks section collects: each disk, or a one-line
+// summary when they cannot be listed
+type diskDetails struct {
+	disks   []DiskInfo
+	summary string
+}
+
+// hostDetails is what the system and hardware sections read from the host;
+// each section keeps only its own fields
+type hostDetails struct {
+	os       string
+	hardware string
+	shell    string
+}
+
+func detectHostDetails() hostDetails {
+	details := hostDetails{os: "Unknown", hardware: "Unknown"}
+
+	// Host Info using gopsutil
+	hostInfo, err := host.Info()
+	if err == nil {
+		details.os = fmt.Sprintf("%s %s", hostInfo.Platform, hostInfo.PlatformVersion)
+	}
+
+	// Hardware Info
+	if hostInfo != nil {
+		if hostInfo.Platform != "" {
+			details.hardware = hostInfo.Platform
+		}
+		if hostInfo.Hostname != "" && !strings.Contains(details.hardware, hostInfo.Hostname) {
+			details.hardware = hostInfo.Hostname + " " + details.hardware
+		}
+	}
+
+	details.shell = os.Getenv("SHELL")
+	if details.shell == "" {
+		details.shell = os.Getenv("COMSPEC")
+	}
+	if details.shell == "" {
+		details.shell = "Unknown"
+	}
+	return details
+}
+
+// printDisksSection prints the detailed disk view, falling back to the summary
+func printDisksSection(w io.Writer, s *SystemSnapshot) {
+	if len(s.Disks) > 0 {
+		totalDiskSpace := uint64(0)
+		for _, d := range s.Disks {
+			totalDiskSpace += d.Total
+		}
+		fmt.Fprintf(w, "Disks:     %d total (%.1f GB)\n", len(s.Disks), float64(totalDiskSpace)/1e9)
+		printDiskInfo(w, s.Disks)
+	} else if s.DiskSummary != "" {
+		// Fallback to old summary if detailed view fails
+		fmt.Fprintf(w, "Disks:     %s\n", s.DiskSummary)
+	} else {
+		fmt.Fprintf(w, "Disks:     No disks detected\n")
+	}
+}
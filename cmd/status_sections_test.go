@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/aallbrig/allbctl/pkg/runner"
+)
+
+func Test_CollectStatusSections_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	entries := []statusSectionEntry{
+		{section: statusSection[string]{
+			name: "terminal", title: "Terminal",
+			collect: func(context.Context) string { return "xterm" },
+			store:   func(s *SystemSnapshot, v string) { s.Terminal = v },
+		}, timeout: time.Second},
+		{section: statusSection[string]{
+			name: "hung", title: "Hung",
+			collect: func(context.Context) string {
+				<-release
+				return "too late"
+			},
+			store: func(s *SystemSnapshot, v string) { s.Hardware = v },
+		}, timeout: 50 * time.Millisecond},
+	}
+
+	snapshot := &SystemSnapshot{}
+	start := time.Now()
+	collectStatusSections(context.Background(), entries, snapshot)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("collectStatusSections() waited %s for a hung section", elapsed)
+	}
+
+	if snapshot.Terminal != "xterm" {
+		t.Errorf("Terminal = %q, want %q", snapshot.Terminal, "xterm")
+	}
+	if snapshot.Hardware != "" {
+		t.Errorf("Hardware = %q, a timed out section must not be stored", snapshot.Hardware)
+	}
+	if len(snapshot.Sections) != 2 {
+		t.Fatalf("Sections = %+v, want 2 outcomes", snapshot.Sections)
+	}
+	if got := snapshot.Sections[0].Status; got != sectionOK {
+		t.Errorf("terminal status = %q, want %q", got, sectionOK)
+	}
+	hung := snapshot.Sections[1]
+	if hung.Status != sectionTimedOut || hung.Error != "timed out after 50ms" {
+		t.Errorf("hung outcome = %+v, want timed out after 50ms", hung)
+	}
+}
+
+func Test_CollectStatusSections_Panic(t *testing.T) {
+	entries := []statusSectionEntry{
+		{section: statusSection[[]GPUInfo]{
+			name: "gpu", title: "GPU(s)",
+			collect: func(context.Context) []GPUInfo { panic("nvidia-smi exploded") },
+			store:   func(s *SystemSnapshot, v []GPUInfo) { s.GPUs = v },
+		}, timeout: time.Second},
+	}
+
+	snapshot := &SystemSnapshot{}
+	collectStatusSections(context.Background(), entries, snapshot)
+
+	outcome := snapshot.Sections[0]
+	if outcome.Status != sectionError || !strings.Contains(outcome.Error, "nvidia-smi exploded") {
+		t.Errorf("outcome = %+v, want error mentioning the panic", outcome)
+	}
+}
+
+func Test_RenderStatusSections(t *testing.T) {
+	snapshot := testSystemSnapshot()
+	for _, entry := range statusSections {
+		outcome := SectionOutcome{Name: entry.section.Name(), Status: sectionOK}
+		switch outcome.Name {
+		case "gpu":
+			outcome = SectionOutcome{Name: "gpu", Status: sectionTimedOut, Error: "timed out after 10s"}
+		case "cloud-native":
+			outcome = SectionOutcome{Name: "cloud-native", Status: sectionError, Error: "panic: boom"}
+		}
+		snapshot.Sections = append(snapshot.Sections, outcome)
+	}
+
+	output := captureOutput(func() { printSystemSnapshot(snapshot) })
+
+	expected := []string{
+		"OS:        debian 12",
+		"GPU(s):    timed out after 10s",
+		"Cloud Native: panic: boom",
+		"Memory:    16.0 GiB",
+		"Projects: 1 total",
+	}
+	for _, want := range expected {
+		if !strings.Contains(output, want) {
+			t.Errorf("printSystemSnapshot() output missing %q\noutput:\n%s", want, output)
+		}
+	}
+}
+
+func Test_StatusSections_Names(t *testing.T) {
+	seen := map[string]bool{}
+	for _, entry := range statusSections {
+		name := entry.section.Name()
+		if seen[name] {
+			t.Errorf("section %q registered twice", name)
+		}
+		seen[name] = true
+		if entry.timeout <= 0 {
+			t.Errorf("section %q has no timeout", name)
+		}
+	}
+}
+
+func Test_SelectStatusSections(t *testing.T) {
+	names := func(entries []statusSectionEntry) string {
+		var out []string
+		for _, entry := range entries {
+			out = append(out, entry.section.Name())
+		}
+		return strings.Join(out, ",")
+	}
+
+	all, err := selectStatusSections(statusSections, nil, nil, nil)
+	if err != nil || len(all) != len(statusSections) {
+		t.Fatalf("selectStatusSections(nil) = %d sections, %v; want all %d", len(all), err, len(statusSections))
+	}
+
+	selected, err := selectStatusSections(statusSections, []string{"projects", "cpu", "gpu", "cpu"}, []string{"gpu"}, map[string]time.Duration{"projects": time.Minute})
+	if err != nil {
+		t.Fatalf("selectStatusSections() error = %v", err)
+	}
+	if got := names(selected); got != "projects,cpu" {
+		t.Errorf("selectStatusSections() = %s, want projects,cpu", got)
+	}
+	if selected[0].timeout != time.Minute {
+		t.Errorf("projects timeout = %s, want 1m0s", selected[0].timeout)
+	}
+
+	skipped, err := selectStatusSections(statusSections, nil, []string{"browsers", "ai-agents"}, nil)
+	if err != nil {
+		t.Fatalf("selectStatusSections() error = %v", err)
+	}
+	if got := names(skipped); strings.Contains(got, "browsers") || strings.Contains(got, "ai-agents") {
+		t.Errorf("selectStatusSections() = %s, want browsers and ai-agents skipped", got)
+	}
+
+	for _, tc := range []struct {
+		sections, skip []string
+		timeouts       map[string]time.Duration
+	}{
+		{sections: []string{"gpus"}},
+		{skip: []string{"cloud"}},
+		{timeouts: map[string]time.Duration{"aws": time.Second}},
+	} {
+		if _, err := selectStatusSections(statusSections, tc.sections, tc.skip, tc.timeouts); err == nil || !strings.Contains(err.Error(), "unknown status section") {
+			t.Errorf("selectStatusSections(%v, %v, %v) error = %v, want unknown status section", tc.sections, tc.skip, tc.timeouts, err)
+		}
+	}
+}
+
+func Test_LoadStatusConfig(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	config, err := loadStatusConfig()
+	if err != nil || config.Projects.Limit != 5 || len(config.Sections) != 0 {
+		t.Fatalf("loadStatusConfig() without config = %+v, %v; want defaults", config, err)
+	}
+
+	viper.SetConfigType("yaml")
+	yaml := "status:\n  sections: [cpu, projects]\n  skip: [gpu]\n  timeouts:\n    packages: 2m\n  projects:\n    limit: 10\n"
+	if err := viper.ReadConfig(strings.NewReader(yaml)); err != nil {
+		t.Fatal(err)
+	}
+	config, err = loadStatusConfig()
+	if err != nil {
+		t.Fatalf("loadStatusConfig() error = %v", err)
+	}
+	if strings.Join(config.Sections, ",") != "cpu,projects" || strings.Join(config.Skip, ",") != "gpu" {
+		t.Errorf("loadStatusConfig() sections = %v, skip = %v", config.Sections, config.Skip)
+	}
+	if config.Timeouts["packages"] != 2*time.Minute {
+		t.Errorf("loadStatusConfig() packages timeout = %s, want 2m0s", config.Timeouts["packages"])
+	}
+	if config.Projects.Limit != 10 {
+		t.Errorf("loadStatusConfig() projects limit = %d, want 10", config.Projects.Limit)
+	}
+
+	viper.Reset()
+	viper.SetConfigType("yaml")
+	if err := viper.ReadConfig(strings.NewReader("status:\n  projects:\n    limit: -1\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadStatusConfig(); err == nil {
+		t.Error("loadStatusConfig() with a negative limit should fail")
+	}
+}
+
+func Test_RenderStatusSections_Layout(t *testing.T) {
+	snapshot := testSystemSnapshot()
+	snapshot.Sections = []SectionOutcome{
+		{Name: "memory", Status: sectionOK},
+		{Name: "system", Status: sectionOK},
+	}
+
+	output := captureOutput(func() { printSystemSnapshot(snapshot) })
+
+	memory := strings.Index(output, "Memory:")
+	system := strings.Index(output, "OS:")
+	if memory < 0 || system < 0 || memory > system {
+		t.Errorf("printSystemSnapshot() should print memory, then system\noutput:\n%s", output)
+	}
+	for _, absent := range []string{"Runtimes:", "AI Agents:", "Projects:"} {
+		if strings.Contains(output, absent) {
+			t.Errorf("printSystemSnapshot() printed unselected %q\noutput:\n%s", absent, output)
+		}
+	}
+}
+
+func Test_StatusSections_Replay(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the fixture was recorded on Linux")
+	}
+	replay, err := runner.LoadReplay(filepath.Join("..", "test", "sample", "linux-workstation-commands.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Keep version managers and Steam in the home directory out of it
+	t.Setenv("HOME", t.TempDir())
+
+	sections, err := selectStatusSections(statusSections,
+		[]string{"cpu", "gpu", "runtimes", "ports", "browsers", "ai-agents", "package-managers", "packages", "cloud-native"}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := collectSystemSnapshot(runner.WithRunner(context.Background(), replay), sections)
+
+	for _, s := range snapshot.Sections {
+		if s.Status != "ok" {
+			t.Errorf("section %s status = %s", s.Name, s.Status)
+		}
+	}
+	if snapshot.CPU.Architecture != "x86_64" || snapshot.CPU.LogicalCores != 16 || snapshot.CPU.BaseClock != "5.10 GHz" {
+		t.Errorf("CPU = %+v", snapshot.CPU)
+	}
+	if len(snapshot.GPUs) != 1 || snapshot.GPUs[0].Vendor != "NVIDIA" {
+		t.Errorf("GPUs = %+v", snapshot.GPUs)
+	}
+	var runtimes []string
+	for _, r := range snapshot.Runtimes {
+		runtimes = append(runtimes, r.Name)
+	}
+	if strings.Join(runtimes, ",") != "Go,Node.js,Python,pyenv" {
+		t.Errorf("Runtimes = %v", runtimes)
+	}
+	if snapshot.Ports == nil || snapshot.Ports.TCPPorts != 1 || snapshot.Ports.UDPPorts != 1 {
+		t.Errorf("Ports = %+v", snapshot.Ports)
+	}
+	if len(snapshot.Browsers) != 1 || snapshot.Browsers[0].Version != "125.0.2" {
+		t.Errorf("Browsers = %+v", snapshot.Browsers)
+	}
+	if len(snapshot.AIAgents) != 2 || snapshot.AIAgents[0].Version != "1.0.24" {
+		t.Errorf("AIAgents = %+v", snapshot.AIAgents)
+	}
+	packages := map[string][2]int{}
+	for _, p := range snapshot.Packages {
+		packages[p.Manager] = [2]int{p.Count, p.UpdateCount}
+	}
+	if packages["apt"] != [2]int{3, 1} || packages["npm"] != [2]int{3, 1} {
+		t.Errorf("Packages = %+v", snapshot.Packages)
+	}
+	if len(snapshot.CloudNative) != 1 || snapshot.CloudNative[0].ProfileCount != 2 || snapshot.CloudNative[0].Connected {
+		t.Errorf("CloudNative = %+v", snapshot.CloudNative)
+	}
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"os"
 	"runtime"
@@ -51,7 +52,85 @@ func TestPrintSystemInfo_Output(t *testing.T) {
 	}
 }
 
+// testSystemSnapshot returns a fixed snapshot; versions are left empty so rendering
+// does not trigger online update checks.
+func testSystemSnapshot() *SystemSnapshot {
+	return &SystemSnapshot{
+		Version:     "v1.2.3",
+		Commit:      "abc123",
+		User:        "tester",
+		Hostname:    "testhost",
+		OS:          "debian 12",
+		Shell:       "/bin/bash",
+		Terminal:    "xterm",
+		CPU:         CPUDetails{ModelName: "Test CPU", Architecture: "x86_64", LogicalCores: 4},
+		MemoryBytes: 16e9,
+		Hardware:    "testhost debian",
+		Runtimes:    []RuntimeInfo{{Name: "Go", Category: "language"}},
+		Ports:       &PortInfo{TCPPorts: 2, UDPPorts: 1},
+		Browsers:    []BrowserInfo{{Name: "firefox", Version: "installed"}},
+		AIAgents:    []AIAgent{{Name: "copilot"}},
+		PackageManagers: []PackageManagerInfo{
+			{Name: "apt", ID: "apt", Category: pmCategorySystem},
+			{Name: "npm", ID: "npm", Category: pmCategoryRuntime},
+		},
+		Packages: []PackageResult{{Manager: "apt", Count: 42, UpdateCount: 3}},
+		Projects: &ProjectsSummary{Total: 1, Repos: []RepoInfo{{Path: "/home/tester/src/allbctl"}}},
+	}
+}
+
+func Test_PrintSystemSnapshot(t *testing.T) {
+	output := captureOutput(func() { printSystemSnapshot(testSystemSnapshot()) })
+
+	expected := []string{
+		"tester@testhost",
+		"allbctl v1.2.3 (commit abc123)",
+		"OS:        debian 12",
+		"Memory:    16.0 GiB",
+		"Disks:     No disks detected",
+		"Runtimes:  Go",
+		"Ports:     3 listening (TCP: 2, UDP: 1)",
+		"  firefox",
+		"  copilot",
+		"  System:         apt",
+		"  Runtime:        npm",
+		"  apt:            42 packages (3 want updates)",
+		"Projects: 1 total",
+	}
+	for _, want := range expected {
+		if !strings.Contains(output, want) {
+			t.Errorf("printSystemSnapshot() output missing %q\noutput:\n%s", want, output)
+		}
+	}
+}
+
+func Test_SystemSnapshot_JSONRoundTrip(t *testing.T) {
+	snapshot := testSystemSnapshot()
+	snapshot.Projects.Repos[0].Dirty = true
+	snapshot.Projects.Repos[0].DirtyReasons = DirtyUncommittedChanges | DirtyNoUpstream
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatalf("json.Marshal() error: %v", err)
+	}
+	if !strings.Contains(string(data), `"dirty_reasons":["uncommitted changes","no upstream"]`) {
+		t.Errorf("dirty_reasons not encoded as labels: %s", data)
+	}
+
+	var decoded SystemSnapshot
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error: %v", err)
+	}
+	if decoded.Hostname != snapshot.Hostname || decoded.CPU.ModelName != snapshot.CPU.ModelName {
+		t.Errorf("decoded snapshot mismatch: %+v", decoded)
+	}
+	if got := decoded.Projects.Repos[0].DirtyReasons; got != snapshot.Projects.Repos[0].DirtyReasons {
+		t.Errorf("DirtyReasons round trip = %v, want %v", got, snapshot.Projects.Repos[0].DirtyReasons)
+	}
+}
+
 func Test_GetPackageManagerVersion(t *testing.T) {
+	ctx := context.Background()
 	tests := []struct {
 		name    string
 		manager string
@@ -64,7 +143,7 @@ func Test_GetPackageManagerVersion(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			version := getPackageManagerVersion(tt.manager)
+			version := getPackageManagerVersion(ctx, tt.manager)
 			// Version might be empty if manager not installed, that's ok
 			t.Logf("Manager %s version: %s", tt.manager, version)
 		})
@@ -72,7 +151,8 @@ func Test_GetPackageManagerVersion(t *testing.T) {
 }
 
 func Test_DetectAIAgents(t *testing.T) {
-	agents := detectAIAgents()
+	ctx := context.Background()
+	agents := detectAIAgents(ctx)
 	// May be empty if no AI agents installed, that's ok
 	t.Logf("Detected AI agents: %v", agents)
 
@@ -87,6 +167,7 @@ func Test_DetectAIAgents(t *testing.T) {
 }
 
 func Test_GetAIAgentVersion(t *testing.T) {
+	ctx := context.Background()
 	tests := []struct {
 		name  string
 		agent string
@@ -99,7 +180,7 @@ func Test_GetAIAgentVersion(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			version := getAIAgentVersion(tt.agent)
+			version := getAIAgentVersion(ctx, tt.agent)
 			// Version might be empty if agent not installed
 			t.Logf("Agent %s version: %s", tt.agent, version)
 		})
@@ -107,6 +188,7 @@ func Test_GetAIAgentVersion(t *testing.T) {
 }
 
 func Test_GetVersionManagerVersion(t *testing.T) {
+	ctx := context.Background()
 	tests := []struct {
 		name    string
 		manager string
@@ -120,7 +202,7 @@ func Test_GetVersionManagerVersion(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			version := getVersionManagerVersion(tt.manager)
+			version := getVersionManagerVersion(ctx, tt.manager)
 			// Version might be empty if manager not installed
 			t.Logf("Version manager %s version: %s", tt.manager, version)
 		})
@@ -128,7 +210,8 @@ func Test_GetVersionManagerVersion(t *testing.T) {
 }
 
 func Test_GetDetailedCPUInfo(t *testing.T) {
-	cpuDetails := getDetailedCPUInfo()
+	ctx := context.Background()
+	cpuDetails := getDetailedCPUInfo(ctx)
 
 	// Should always have some basic info
 	if cpuDetails.ModelName == "" || cpuDetails.ModelName == "Unknown" {
@@ -146,7 +229,8 @@ func Test_GetDetailedCPUInfo(t *testing.T) {
 }
 
 func Test_GetDetailedGPUInfo(t *testing.T) {
-	gpus := getDetailedGPUInfo()
+	ctx := context.Background()
+	gpus := getDetailedGPUInfo(ctx)
 
 	// May be empty on systems without GPU, that's ok
 	t.Logf("Detected %d GPU(s)", len(gpus))
@@ -160,9 +244,10 @@ func Test_GetDetailedGPUInfo(t *testing.T) {
 }
 
 func Test_GetDetailedGPUInfo_MultipleGPUs(t *testing.T) {
+	ctx := context.Background()
 	// This test verifies that the function can detect multiple GPUs
 	// on systems with both integrated and discrete GPUs (e.g., Intel + NVIDIA)
-	gpus := getDetailedGPUInfo()
+	gpus := getDetailedGPUInfo(ctx)
 
 	t.Logf("Detected %d GPU(s)", len(gpus))
 
@@ -260,7 +345,7 @@ func Test_PrintCPUInfo(t *testing.T) {
 	}
 	os.Stdout = w
 
-	printCPUInfo(details)
+	printCPUInfo(w, details)
 
 	w.Close()
 	os.Stdout = oldStdout
@@ -306,7 +391,7 @@ func Test_PrintGPUInfo(t *testing.T) {
 	}
 	os.Stdout = w
 
-	printGPUInfo(gpus)
+	printGPUInfo(w, gpus)
 
 	w.Close()
 	os.Stdout = oldStdout
@@ -331,7 +416,8 @@ func Test_PrintGPUInfo(t *testing.T) {
 }
 
 func Test_DetectBrowsers(t *testing.T) {
-	browsers := detectBrowsers()
+	ctx := context.Background()
+	browsers := detectBrowsers(ctx)
 
 	// May be empty on systems without browsers, that's ok
 	t.Logf("Detected %d browser(s)", len(browsers))
@@ -353,7 +439,7 @@ func Test_PrintBrowsers(t *testing.T) {
 	}
 	os.Stdout = w
 
-	printBrowsers([]BrowserInfo{})
+	printBrowsers(w, []BrowserInfo{})
 
 	w.Close()
 	os.Stdout = oldStdout
@@ -382,7 +468,7 @@ func Test_PrintBrowsers(t *testing.T) {
 	}
 	os.Stdout = w2
 
-	printBrowsers(browsers)
+	printBrowsers(w2, browsers)
 
 	w2.Close()
 	os.Stdout = oldStdout
@@ -458,39 +544,6 @@ func Test_ParseBrowserVersion(t *testing.T) {
 	}
 }
 
-func Test_ExtractPackageManagerVersion_VBoxManage(t *testing.T) {
-	tests := []struct {
-		name   string
-		output string
-		want   string
-	}{
-		{
-			name:   "VBoxManage standard version output",
-			output: "7.0.14r161095",
-			want:   "7.0.14r161095",
-		},
-		{
-			name:   "VBoxManage version with whitespace",
-			output: "  7.1.0r164728  \n",
-			want:   "7.1.0r164728",
-		},
-		{
-			name:   "VBoxManage empty output",
-			output: "",
-			want:   "",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := extractPackageManagerVersion("vboxmanage", tt.output)
-			if got != tt.want {
-				t.Errorf("extractPackageManagerVersion('vboxmanage', %q) = %q, want %q", tt.output, got, tt.want)
-			}
-		})
-	}
-}
-
 func Test_DetectTerminal(t *testing.T) {
 	// Save original environment
 	originalEnv := make(map[string]string)
@@ -1,70 +1,105 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
+	"io"
+	"os"
 	"runtime"
 	"strings"
 
 	"github.com/spf13/cobra"
+
+	"github.com/aallbrig/allbctl/pkg/runner"
 )
 
 var SystemctlCmd = &cobra.Command{
 	Use:   "systemctl",
 	Short: "Display systemd service status",
-	Long:  `Display count of running system and user services, and any failed services.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		PrintSystemctlInfo()
+	Long: `Display count of running system and user services, and any failed services.
+
+Use --watch to keep the view open; units that fail are highlighted.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if watchMode {
+			return watchCommand(cmd, watchFrame(PrintSystemctlInfo))
+		}
+		if !isStructuredOutput() {
+			PrintSystemctlInfo(ctx, os.Stdout)
+			return nil
+		}
+		if runtime.GOOS != "linux" || !exists(ctx, "systemctl") {
+			return fmt.Errorf("systemctl is not available on this system")
+		}
+		return printStructured(gatherSystemctlInfo(ctx))
 	},
 }
 
 type SystemctlInfo struct {
-	SystemRunning int
-	SystemFailed  int
-	UserRunning   int
-	UserFailed    int
+	SystemRunning int `json:"system_running"`
+	SystemFailed  int `json:"system_failed"`
+	UserRunning   int `json:"user_running"`
+	UserFailed    int `json:"user_failed"`
 }
 
-func PrintSystemctlInfo() {
+func PrintSystemctlInfo(ctx context.Context, w io.Writer) {
 	if runtime.GOOS != "linux" {
-		fmt.Println("Systemctl is only available on Linux systems")
+		fmt.Fprintln(w, "Systemctl is only available on Linux systems")
 		return
 	}
 
-	if !exists("systemctl") {
-		fmt.Println("Systemctl not found on this system")
+	if !exists(ctx, "systemctl") {
+		fmt.Fprintln(w, "Systemctl not found on this system")
 		return
 	}
 
-	fmt.Println("Systemd Services:")
-	fmt.Println()
+	fmt.Fprintln(w, "Systemd Services:")
+	fmt.Fprintln(w)
 
-	info := gatherSystemctlInfo()
+	info := gatherSystemctlInfo(ctx)
 
 	// System services
-	fmt.Printf("  System Services:\n")
+	fmt.Fprintf(w, "  System Services:\n")
 	if info.SystemFailed > 0 {
-		fmt.Printf("    Running: %d (%d failed)\n", info.SystemRunning, info.SystemFailed)
+		fmt.Fprintf(w, "    Running: %d (%d failed)\n", info.SystemRunning, info.SystemFailed)
 	} else {
-		fmt.Printf("    Running: %d\n", info.SystemRunning)
+		fmt.Fprintf(w, "    Running: %d\n", info.SystemRunning)
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 
 	// User services
-	fmt.Printf("  User Services:\n")
+	fmt.Fprintf(w, "  User Services:\n")
 	if info.UserFailed > 0 {
-		fmt.Printf("    Running: %d (%d failed)\n", info.UserRunning, info.UserFailed)
+		fmt.Fprintf(w, "    Running: %d (%d failed)\n", info.UserRunning, info.UserFailed)
 	} else {
-		fmt.Printf("    Running: %d\n", info.UserRunning)
+		fmt.Fprintf(w, "    Running: %d\n", info.UserRunning)
+	}
+}
+
+// failedSystemdUnits lists the failed services, system-wide or for the user
+func failedSystemdUnits(ctx context.Context, user bool) []string {
+	args := []string{"list-units", "--type=service", "--state=failed", "--no-pager", "--no-legend", "--plain"}
+	if user {
+		args = append([]string{"--user"}, args...)
+	}
+	out, err := runner.Output(ctx, "systemctl", args...)
+	if err != nil {
+		return nil
+	}
+	var units []string
+	for _, line := range nonEmptyLines(string(out)) {
+		if fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(line), "●")); len(fields) > 0 {
+			units = append(units, fields[0])
+		}
 	}
+	return units
 }
 
-func gatherSystemctlInfo() *SystemctlInfo {
+func gatherSystemctlInfo(ctx context.Context) *SystemctlInfo {
 	info := &SystemctlInfo{}
 
 	// Count system running services
-	cmd := exec.Command("systemctl", "list-units", "--type=service", "--state=running", "--no-pager", "--no-legend")
-	out, err := cmd.Output()
+	out, err := runner.Output(ctx, "systemctl", "list-units", "--type=service", "--state=running", "--no-pager", "--no-legend")
 	if err == nil {
 		lines := strings.Split(strings.TrimSpace(string(out)), "\n")
 		if len(lines) == 1 && lines[0] == "" {
@@ -75,8 +110,7 @@ func gatherSystemctlInfo() *SystemctlInfo {
 	}
 
 	// Count system failed services
-	cmd = exec.Command("systemctl", "list-units", "--type=service", "--state=failed", "--no-pager", "--no-legend")
-	out, err = cmd.Output()
+	out, err = runner.Output(ctx, "systemctl", "list-units", "--type=service", "--state=failed", "--no-pager", "--no-legend")
 	if err == nil {
 		lines := strings.Split(strings.TrimSpace(string(out)), "\n")
 		if len(lines) == 1 && lines[0] == "" {
@@ -87,8 +121,7 @@ func gatherSystemctlInfo() *SystemctlInfo {
 	}
 
 	// Count user running services
-	cmd = exec.Command("systemctl", "--user", "list-units", "--type=service", "--state=running", "--no-pager", "--no-legend")
-	out, err = cmd.Output()
+	out, err = runner.Output(ctx, "systemctl", "--user", "list-units", "--type=service", "--state=running", "--no-pager", "--no-legend")
 	if err == nil {
 		lines := strings.Split(strings.TrimSpace(string(out)), "\n")
 		if len(lines) == 1 && lines[0] == "" {
@@ -99,8 +132,7 @@ func gatherSystemctlInfo() *SystemctlInfo {
 	}
 
 	// Count user failed services
-	cmd = exec.Command("systemctl", "--user", "list-units", "--type=service", "--state=failed", "--no-pager", "--no-legend")
-	out, err = cmd.Output()
+	out, err = runner.Output(ctx, "systemctl", "--user", "list-units", "--type=service", "--state=failed", "--no-pager", "--no-legend")
 	if err == nil {
 		lines := strings.Split(strings.TrimSpace(string(out)), "\n")
 		if len(lines) == 1 && lines[0] == "" {
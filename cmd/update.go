@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"strings"
 
 	"go.opentelemetry.io/otel"
@@ -14,6 +13,8 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/aallbrig/allbctl/pkg/pkgmgr"
+	"github.com/aallbrig/allbctl/pkg/runner"
 	"github.com/aallbrig/allbctl/pkg/telemetry"
 )
 
@@ -22,12 +23,10 @@ var (
 	updateManagers []string
 )
 
-// packageManagerUpdate defines how to update a specific package manager
+// packageManagerUpdate is a detected package manager's update plan
 type packageManagerUpdate struct {
-	Name        string     // e.g., "apt", "brew"
-	NeedsSudo   bool       // whether commands need sudo prefix
-	Commands    [][]string // each inner slice is one command to run
-	Description string     // human-readable description
+	Name string // e.g., "apt", "brew"
+	pkgmgr.UpdatePlan
 }
 
 // UpdateCmd represents the update command
@@ -37,9 +36,11 @@ var UpdateCmd = &cobra.Command{
 	Long: `Update and upgrade packages from all detected package managers on the system.
 
 Runs update/upgrade commands for each detected package manager sequentially.
-Some managers require sudo and will prompt for your password.
+Some managers require root; unless allbctl runs as root, their commands run
+through sudo, which will prompt for your password.
 
-Supported managers: apt, flatpak, snap, dnf, yum, pacman, brew, choco, winget, npm, pipx, gem
+Supported managers: apt, flatpak, snap, dnf, yum, pacman, zypper, apk, brew,
+choco, winget, npm, pipx, gem
 
 Intentionally skipped:
   pip   - Risky to auto-upgrade all pip packages (can break system Python)
@@ -68,65 +69,21 @@ func init() {
 	UpdateCmd.Flags().StringSliceVar(&updateManagers, "managers", nil, "Comma-separated list of package managers to update (default: all detected)")
 }
 
-// getUpdatableManagers returns the registry of all supported package manager update definitions
+// getUpdatableManagers returns the update plans of every package manager that has one
 func getUpdatableManagers() []packageManagerUpdate {
-	return []packageManagerUpdate{
-		{Name: "apt", NeedsSudo: true, Commands: [][]string{
-			{"apt-get", "update"},
-			{"apt-get", "upgrade", "-y"},
-		}, Description: "Update apt package lists and upgrade all packages"},
-
-		{Name: "flatpak", NeedsSudo: false, Commands: [][]string{
-			{"flatpak", "update", "-y"},
-		}, Description: "Update all Flatpak applications"},
-
-		{Name: "snap", NeedsSudo: true, Commands: [][]string{
-			{"snap", "refresh"},
-		}, Description: "Refresh all snap packages"},
-
-		{Name: "dnf", NeedsSudo: true, Commands: [][]string{
-			{"dnf", "upgrade", "-y"},
-		}, Description: "Upgrade all dnf packages"},
-
-		{Name: "yum", NeedsSudo: true, Commands: [][]string{
-			{"yum", "update", "-y"},
-		}, Description: "Update all yum packages"},
-
-		{Name: "pacman", NeedsSudo: true, Commands: [][]string{
-			{"pacman", "-Syu", "--noconfirm"},
-		}, Description: "Synchronize and upgrade all pacman packages"},
-
-		{Name: "brew", NeedsSudo: false, Commands: [][]string{
-			{"brew", "update"},
-			{"brew", "upgrade"},
-		}, Description: "Update Homebrew and upgrade all formulae and casks"},
-
-		{Name: "choco", NeedsSudo: false, Commands: [][]string{
-			{"choco", "upgrade", "all", "-y"},
-		}, Description: "Upgrade all Chocolatey packages"},
-
-		{Name: "winget", NeedsSudo: false, Commands: [][]string{
-			{"winget", "upgrade", "--all", "--accept-source-agreements", "--accept-package-agreements"},
-		}, Description: "Upgrade all winget packages"},
-
-		{Name: "npm", NeedsSudo: false, Commands: [][]string{
-			{"npm", "update", "-g"},
-		}, Description: "Update all globally installed npm packages"},
-
-		{Name: "pipx", NeedsSudo: false, Commands: [][]string{
-			{"pipx", "upgrade-all"},
-		}, Description: "Upgrade all pipx-installed applications"},
-
-		{Name: "gem", NeedsSudo: false, Commands: [][]string{
-			{"gem", "update"},
-		}, Description: "Update all installed Ruby gems"},
+	var managers []packageManagerUpdate
+	for _, m := range pkgmgr.All() {
+		if plan := m.Update(); plan != nil {
+			managers = append(managers, packageManagerUpdate{Name: m.Name(), UpdatePlan: *plan})
+		}
 	}
+	return managers
 }
 
 // filterUpdatableManagers returns only the managers that are both detected on the system
 // and present in the updatable registry, optionally filtered by the --managers flag
-func filterUpdatableManagers() []packageManagerUpdate {
-	detected := getDetectedPackageManagers()
+func filterUpdatableManagers(ctx context.Context) []packageManagerUpdate {
+	detected := getDetectedPackageManagers(ctx)
 	detectedSet := make(map[string]bool, len(detected))
 	for _, m := range detected {
 		detectedSet[m] = true
@@ -172,24 +129,25 @@ func filterUpdatableManagers() []packageManagerUpdate {
 	return result
 }
 
-// runUpdateCommand executes a single update command, optionally with sudo
-func runUpdateCommand(args []string, needsSudo bool) error {
-	if needsSudo {
-		if !exists("sudo") {
-			return fmt.Errorf("sudo is required but not found on PATH")
-		}
-		args = append([]string{"sudo"}, args...)
+// runUpdateCommand executes a single update command, through sudo when it needs root
+func runUpdateCommand(ctx context.Context, command pkgmgr.Command) error {
+	env := pkgmgr.LocalEnv()
+	if command.Sudo && !env.Root && !exists(ctx, "sudo") {
+		return fmt.Errorf("sudo is required but not found on PATH")
 	}
 
-	cmd := exec.Command(args[0], args[1:]...) //nolint:gosec // args are from a hardcoded registry, not user input
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-	return cmd.Run()
+	args := command.Argv(env)
+	return runner.FromContext(ctx).Run(ctx, runner.Cmd{
+		Name:   args[0],
+		Args:   args[1:],
+		Stdin:  os.Stdin,
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	})
 }
 
 func runUpdate(ctx context.Context) {
-	managers := filterUpdatableManagers()
+	managers := filterUpdatableManagers(ctx)
 
 	if len(managers) == 0 {
 		fmt.Println("No updatable package managers detected on this system.")
@@ -201,7 +159,7 @@ func runUpdate(ctx context.Context) {
 	managerNames := make([]string, 0, len(managers))
 	for _, mgr := range managers {
 		managerNames = append(managerNames, mgr.Name)
-		updateCount, _ := checkPackageUpdates(mgr.Name) //nolint:errcheck
+		updateCount, _ := checkPackageUpdates(ctx, mgr.Name) //nolint:errcheck
 		if updateCount > 0 {
 			fmt.Printf("  %-12s %s (%d updates available)\n", mgr.Name+":", mgr.Description, updateCount)
 		} else {
@@ -221,12 +179,8 @@ func runUpdate(ctx context.Context) {
 		fmt.Println()
 		for _, mgr := range managers {
 			fmt.Printf("  # %s\n", mgr.Description)
-			for _, cmdArgs := range mgr.Commands {
-				if mgr.NeedsSudo {
-					fmt.Printf("  sudo %s\n", strings.Join(cmdArgs, " "))
-				} else {
-					fmt.Printf("  %s\n", strings.Join(cmdArgs, " "))
-				}
+			for _, command := range mgr.Commands {
+				fmt.Printf("  %s\n", command)
 			}
 			fmt.Println()
 		}
@@ -243,20 +197,16 @@ func runUpdate(ctx context.Context) {
 			"update.manager",
 			trace.WithAttributes(
 				attribute.String("manager", mgr.Name),
-				attribute.Bool("needs_sudo", mgr.NeedsSudo),
+				attribute.Bool("needs_sudo", mgr.Commands[0].Sudo),
 			),
 		)
 
 		mgrFailed := false
 
-		for _, cmdArgs := range mgr.Commands {
-			if mgr.NeedsSudo {
-				fmt.Printf("  Running: sudo %s\n", strings.Join(cmdArgs, " "))
-			} else {
-				fmt.Printf("  Running: %s\n", strings.Join(cmdArgs, " "))
-			}
+		for _, command := range mgr.Commands {
+			fmt.Printf("  Running: %s\n", command)
 
-			if err := runUpdateCommand(cmdArgs, mgr.NeedsSudo); err != nil {
+			if err := runUpdateCommand(ctx, command); err != nil {
 				fmt.Printf("  Error: %v\n", err)
 				mgrFailed = true
 				mgrSpan.RecordError(err)
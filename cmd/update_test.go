@@ -75,7 +75,7 @@ func TestGetUpdatableManagers(t *testing.T) {
 			t.Errorf("manager %q has no Commands", mgr.Name)
 		}
 		for i, cmd := range mgr.Commands {
-			if len(cmd) == 0 {
+			if len(cmd.Args) == 0 {
 				t.Errorf("manager %q command[%d] is empty", mgr.Name, i)
 			}
 		}
@@ -111,7 +111,7 @@ func TestSudoRequirements(t *testing.T) {
 		if !ok {
 			continue // manager not in registry (ok — it's there, but skip if not)
 		}
-		if !mgr.NeedsSudo {
+		if !mgr.Commands[0].Sudo {
 			t.Errorf("manager %q should require sudo", name)
 		}
 	}
@@ -122,7 +122,7 @@ func TestSudoRequirements(t *testing.T) {
 		if !ok {
 			continue
 		}
-		if mgr.NeedsSudo {
+		if mgr.Commands[0].Sudo {
 			t.Errorf("manager %q should NOT require sudo", name)
 		}
 	}
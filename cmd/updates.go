@@ -3,245 +3,62 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"os/exec"
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/aallbrig/allbctl/pkg/pkgmgr"
+	"github.com/aallbrig/allbctl/pkg/runner"
+	"github.com/aallbrig/allbctl/pkg/telemetry"
+	"github.com/aallbrig/allbctl/pkg/version"
 )
 
 // UpdateInfo holds information about available updates
 type UpdateInfo struct {
-	Current   string
-	Available string
-	IsLTS     bool
-}
-
-// Version comparison and update checking cache
-var versionCache = make(map[string]*UpdateInfo)
-
-// checkPackageUpdates checks if packages have available updates for a given package manager
-func checkPackageUpdates(manager string) (int, error) {
-	switch manager {
-	case "apt":
-		return checkAptUpdates()
-	case "flatpak":
-		return checkFlatpakUpdates()
-	case "snap":
-		return checkSnapUpdates()
-	case "dnf":
-		return checkDnfUpdates()
-	case "yum":
-		return checkYumUpdates()
-	case "pacman":
-		return checkPacmanUpdates()
-	case "brew":
-		return checkBrewUpdates()
-	case "choco":
-		return checkChocoUpdates()
-	case "winget":
-		return checkWingetUpdates()
-	case "npm":
-		return checkNpmUpdates()
-	case "pip":
-		return checkPipUpdates()
-	case "pipx":
-		return checkPipxUpdates()
-	default:
-		return 0, nil
-	}
-}
-
-func checkAptUpdates() (int, error) {
-	// Don't run apt-get update as it requires sudo
-	// Instead, check the existing cache
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	cmd := exec.CommandContext(ctx, "apt", "list", "--upgradable")
-	output, err := cmd.Output()
-	if err != nil {
-		return 0, nil // Silently fail if can't check
-	}
-
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	// First line is "Listing...", so subtract 1
-	count := len(lines) - 1
-	if count < 0 {
-		count = 0
-	}
-	return count, nil
-}
-
-func checkFlatpakUpdates() (int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	cmd := exec.CommandContext(ctx, "flatpak", "remote-ls", "--updates", "--app")
-	output, err := cmd.Output()
-	if err != nil {
-		return 0, nil // Silently fail if flatpak not installed
-	}
-
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	if len(lines) == 1 && lines[0] == "" {
-		return 0, nil
-	}
-	return len(lines), nil
-}
-
-func checkSnapUpdates() (int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	cmd := exec.CommandContext(ctx, "snap", "refresh", "--list")
-	output, err := cmd.Output()
-	if err != nil {
-		return 0, err
-	}
-
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	// Skip header line
-	count := len(lines) - 1
-	if count < 0 {
-		count = 0
-	}
-	return count, nil
-}
-
-func checkDnfUpdates() (int, error) {
-	cmd := exec.Command("dnf", "check-update", "-q")
-	output, err := cmd.Output()
-	// dnf returns exit code 100 if updates are available
-	if err != nil && !strings.Contains(err.Error(), "exit status 100") {
-		return 0, err
-	}
-
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	return len(lines), nil
-}
-
-func checkYumUpdates() (int, error) {
-	cmd := exec.Command("yum", "check-update", "-q")
-	output, err := cmd.Output()
-	// yum returns exit code 100 if updates are available
-	if err != nil && !strings.Contains(err.Error(), "exit status 100") {
-		return 0, err
-	}
-
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	return len(lines), nil
-}
-
-func checkPacmanUpdates() (int, error) {
-	cmd := exec.Command("checkupdates")
-	output, err := cmd.Output()
-	if err != nil {
-		return 0, err
-	}
-
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	if len(lines) == 1 && lines[0] == "" {
-		return 0, nil
-	}
-	return len(lines), nil
-}
-
-func checkBrewUpdates() (int, error) {
-	cmd := exec.Command("brew", "outdated")
-	output, err := cmd.Output()
-	if err != nil {
-		return 0, err
-	}
-
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	if len(lines) == 1 && lines[0] == "" {
-		return 0, nil
-	}
-	return len(lines), nil
+	Current   string `json:"current"`
+	Available string `json:"available"`
+	IsLTS     bool   `json:"is_lts,omitempty"`
 }
 
-func checkChocoUpdates() (int, error) {
-	cmd := exec.Command("choco", "outdated", "-r")
-	output, err := cmd.Output()
-	if err != nil {
-		return 0, err
-	}
+// Version comparison and update checking cache; status sections check
+// updates concurrently
+var (
+	versionCache   = make(map[string]*UpdateInfo)
+	versionCacheMu sync.Mutex
+)
 
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	if len(lines) == 1 && lines[0] == "" {
+// checkPackageUpdates counts the packages with available updates for a given
+// package manager. Counts are best effort: managers that are unknown, cannot
+// check or fail to (offline, say) report none.
+func checkPackageUpdates(ctx context.Context, manager string) (int, error) {
+	pm, ok := pkgmgr.Get(manager)
+	if !ok {
 		return 0, nil
 	}
-	return len(lines), nil
-}
-
-func checkWingetUpdates() (int, error) {
-	cmd := exec.Command("winget", "upgrade", "--accept-source-agreements")
-	output, err := cmd.Output()
+	count, err := pm.PendingUpdates(ctx)
 	if err != nil {
-		return 0, err
-	}
-
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	count := 0
-	for _, line := range lines {
-		if strings.Contains(line, "Available") {
-			count++
+		if !errors.Is(err, pkgmgr.ErrUnsupported) {
+			telemetry.Logger.DebugContext(ctx, "updates.check_failed", "manager", manager, "error", err)
 		}
-	}
-	return count, nil
-}
-
-func checkNpmUpdates() (int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	cmd := exec.CommandContext(ctx, "npm", "outdated", "-g", "--json")
-	output, err := cmd.Output()
-	if err != nil && len(output) == 0 {
-		return 0, err
-	}
-
-	var outdated map[string]interface{}
-	if err := json.Unmarshal(output, &outdated); err != nil {
 		return 0, nil
 	}
-
-	return len(outdated), nil
-}
-
-func checkPipUpdates() (int, error) {
-	pipCmd := "pip3"
-	if !exists("pip3") {
-		pipCmd = "pip"
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	cmd := exec.CommandContext(ctx, pipCmd, "list", "--outdated", "--format=json")
-	output, err := cmd.Output()
-	if err != nil {
-		return 0, nil // Silently fail if pip times out or is unavailable
-	}
-
-	var outdated []interface{}
-	if err := json.Unmarshal(output, &outdated); err != nil {
-		return 0, nil
-	}
-
-	return len(outdated), nil
-}
-
-func checkPipxUpdates() (int, error) {
-	// pipx doesn't have a built-in outdated check
-	// We would need to check each package individually
-	return 0, nil
+	return count, nil
 }
 
 // checkVersionUpdate checks if a newer version is available for a tool
 func checkVersionUpdate(name, current string) *UpdateInfo {
 	// Check cache first
 	cacheKey := strings.ToLower(name) + ":" + current
-	if cached, ok := versionCache[cacheKey]; ok {
+	versionCacheMu.Lock()
+	cached, ok := versionCache[cacheKey]
+	versionCacheMu.Unlock()
+	if ok {
 		return cached
 	}
 
@@ -273,7 +90,9 @@ func checkVersionUpdate(name, current string) *UpdateInfo {
 
 	// Cache the result
 	if update != nil {
+		versionCacheMu.Lock()
 		versionCache[cacheKey] = update
+		versionCacheMu.Unlock()
 	}
 
 	return update
@@ -535,38 +354,18 @@ func checkGitHubRelease(repo, current string) *UpdateInfo {
 // compareVersions compares two semantic version strings
 // Returns: 1 if v1 > v2, -1 if v1 < v2, 0 if equal
 func compareVersions(v1, v2 string) int {
-	v1Parts := strings.Split(v1, ".")
-	v2Parts := strings.Split(v2, ".")
-
-	maxLen := len(v1Parts)
-	if len(v2Parts) > maxLen {
-		maxLen = len(v2Parts)
-	}
-
-	for i := 0; i < maxLen; i++ {
-		var v1Part, v2Part int
-
-		if i < len(v1Parts) {
-			v1Part, _ = strconv.Atoi(strings.TrimSpace(v1Parts[i])) //nolint:errcheck
-		}
-
-		if i < len(v2Parts) {
-			v2Part, _ = strconv.Atoi(strings.TrimSpace(v2Parts[i])) //nolint:errcheck
-		}
-
-		if v1Part > v2Part {
-			return 1
-		} else if v1Part < v2Part {
-			return -1
-		}
-	}
-
-	return 0
+	return version.Compare(v1, v2)
 }
 
 // formatVersionWithUpdate formats version string with update arrow if available
 func formatVersionWithUpdate(name, current string) string {
-	update := checkVersionUpdate(name, current)
+	return formatVersion(current, checkVersionUpdate(name, current))
+}
+
+// formatVersion formats a version with an arrow to the update found for it,
+// if any. Status sections look updates up while collecting, under their
+// deadline, so rendering never waits on the network.
+func formatVersion(current string, update *UpdateInfo) string {
 	if update != nil && update.Available != "" && update.Available != current {
 		if update.IsLTS {
 			return fmt.Sprintf("%s → %s (LTS)", current, update.Available)
@@ -577,25 +376,24 @@ func formatVersionWithUpdate(name, current string) string {
 }
 
 // checkOSUpdates checks for operating system updates
-func checkOSUpdates() (int, error) {
+func checkOSUpdates(ctx context.Context) (int, error) {
 	osType := runtime.GOOS
 
 	switch osType {
 	case "linux":
-		return checkLinuxUpdates()
+		return checkLinuxUpdates(ctx)
 	case "windows":
-		return checkWindowsUpdates()
+		return checkWindowsUpdates(ctx)
 	case "darwin":
-		return checkMacOSUpdates()
+		return checkMacOSUpdates(ctx)
 	default:
 		return 0, nil
 	}
 }
 
-func checkLinuxUpdates() (int, error) {
+func checkLinuxUpdates(ctx context.Context) (int, error) {
 	// Try to detect the distribution
-	cmd := exec.Command("lsb_release", "-is")
-	distOutput, err := cmd.Output()
+	distOutput, err := runner.Output(ctx, "lsb_release", "-is")
 	if err != nil {
 		return 0, nil
 	}
@@ -604,21 +402,20 @@ func checkLinuxUpdates() (int, error) {
 
 	switch {
 	case strings.Contains(distro, "ubuntu"), strings.Contains(distro, "mint"):
-		return checkAptUpdates()
+		return checkPackageUpdates(context.Background(), "apt")
 	case strings.Contains(distro, "fedora"):
-		return checkDnfUpdates()
+		return checkPackageUpdates(context.Background(), "dnf")
 	case strings.Contains(distro, "arch"):
-		return checkPacmanUpdates()
+		return checkPackageUpdates(context.Background(), "pacman")
 	default:
 		return 0, nil
 	}
 }
 
-func checkWindowsUpdates() (int, error) {
+func checkWindowsUpdates(ctx context.Context) (int, error) {
 	// Use PowerShell to check for Windows updates
-	cmd := exec.Command("powershell", "-Command",
+	output, err := runner.Output(ctx, "powershell", "-Command",
 		"Get-WindowsUpdate -AcceptAll -IgnoreReboot | Measure-Object | Select-Object -ExpandProperty Count")
-	output, err := cmd.Output()
 	if err != nil {
 		return 0, nil
 	}
@@ -631,9 +428,8 @@ func checkWindowsUpdates() (int, error) {
 	return count, nil
 }
 
-func checkMacOSUpdates() (int, error) {
-	cmd := exec.Command("softwareupdate", "-l")
-	output, err := cmd.Output()
+func checkMacOSUpdates(ctx context.Context) (int, error) {
+	output, err := runner.Output(ctx, "softwareupdate", "-l")
 	if err != nil {
 		return 0, nil
 	}
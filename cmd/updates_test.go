@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"testing"
 )
 
 func TestCheckPackageUpdates(t *testing.T) {
+	ctx := context.Background()
 	tests := []struct {
 		name    string
 		manager string
@@ -30,7 +32,7 @@ func TestCheckPackageUpdates(t *testing.T) {
 			if bins, ok := requiredBinary[tt.manager]; ok {
 				found := false
 				for _, bin := range bins {
-					if exists(bin) {
+					if exists(ctx, bin) {
 						found = true
 						break
 					}
@@ -39,7 +41,7 @@ func TestCheckPackageUpdates(t *testing.T) {
 					t.Skipf("%s not available on this system", tt.manager)
 				}
 			}
-			count, err := checkPackageUpdates(tt.manager)
+			count, err := checkPackageUpdates(context.Background(), tt.manager)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("checkPackageUpdates() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -50,6 +52,7 @@ func TestCheckPackageUpdates(t *testing.T) {
 }
 
 func TestFormatVersionWithUpdate(t *testing.T) {
+	ctx := context.Background()
 	tests := []struct {
 		name    string
 		tool    string
@@ -73,7 +76,7 @@ func TestFormatVersionWithUpdate(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Skip when the tool is installed — the result depends on the current live version.
-			if tt.tool != "unknown-tool" && exists(tt.tool) {
+			if tt.tool != "unknown-tool" && exists(ctx, tt.tool) {
 				t.Skipf("%s is installed; no-update assertion is version-dependent", tt.tool)
 			}
 			got := formatVersionWithUpdate(tt.tool, tt.current)
@@ -86,61 +89,36 @@ func TestFormatVersionWithUpdate(t *testing.T) {
 }
 
 func TestCheckOSUpdates(t *testing.T) {
-	count, err := checkOSUpdates()
+	ctx := context.Background()
+	count, err := checkOSUpdates(ctx)
 	if err != nil {
-		t.Logf("checkOSUpdates() returned error (may be normal): %v", err)
+		t.Logf("checkOSUpdates(ctx) returned error (may be normal): %v", err)
 	}
 	t.Logf("OS has %d updates available", count)
 }
 
-func TestCheckAptUpdates(t *testing.T) {
-	if !exists("apt") {
-		t.Skip("apt not available on this system")
-	}
-
-	count, err := checkAptUpdates()
-	if err != nil {
-		t.Errorf("checkAptUpdates() error = %v", err)
-		return
-	}
-	t.Logf("apt has %d updates available", count)
-}
-
-func TestCheckFlatpakUpdates(t *testing.T) {
-	if !exists("flatpak") {
-		t.Skip("flatpak not available on this system")
-	}
-
-	count, err := checkFlatpakUpdates()
-	if err != nil {
-		t.Errorf("checkFlatpakUpdates() error = %v", err)
-		return
-	}
-	t.Logf("flatpak has %d updates available", count)
-}
+func TestWithRuntimeUpdates(t *testing.T) {
+	versionCacheMu.Lock()
+	versionCache["go:1.22.0"] = &UpdateInfo{Current: "1.22.0", Available: "1.23.0"}
+	versionCacheMu.Unlock()
+	t.Cleanup(func() {
+		versionCacheMu.Lock()
+		delete(versionCache, "go:1.22.0")
+		versionCacheMu.Unlock()
+	})
 
-func TestCheckNpmUpdates(t *testing.T) {
-	if !exists("npm") {
-		t.Skip("npm not available on this system")
+	runtimes := withRuntimeUpdates([]RuntimeInfo{
+		{Name: "Go", Version: "1.22.0", Category: "language"},
+		{Name: "Docker", Version: "27.0.0", Category: "runtime"},
+	})
+	if runtimes[0].Update == nil || runtimes[0].Update.Available != "1.23.0" {
+		t.Errorf("Go update = %+v, want 1.23.0", runtimes[0].Update)
 	}
-
-	count, err := checkNpmUpdates()
-	if err != nil {
-		t.Errorf("checkNpmUpdates() error = %v", err)
-		return
+	if runtimes[1].Update != nil {
+		t.Errorf("Docker update = %+v, want none for a non-language runtime", runtimes[1].Update)
 	}
-	t.Logf("npm has %d global packages with updates available", count)
-}
-
-func TestCheckPipUpdates(t *testing.T) {
-	if !exists("pip") && !exists("pip3") {
-		t.Skip("pip not available on this system")
-	}
-
-	count, err := checkPipUpdates()
-	if err != nil {
-		t.Errorf("checkPipUpdates() error = %v", err)
-		return
+	// Rendering uses what was collected rather than looking updates up again
+	if got, want := formatRuntimesInline(runtimes), "Go (1.22.0 → 1.23.0)"; got != want {
+		t.Errorf("formatRuntimesInline() = %q, want %q", got, want)
 	}
-	t.Logf("pip has %d packages with updates available", count)
 }
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchMode     bool
+	watchInterval time.Duration
+)
+
+// changedLine highlights lines that were not in the previous frame
+var changedLine = color.New(color.FgYellow, color.Bold)
+
+func init() {
+	for _, cmd := range []*cobra.Command{StatusCmd, PortsCmd, ContainersCmd, SystemctlCmd, NetworkCmd} {
+		addWatchFlags(cmd)
+	}
+}
+
+// addWatchFlags gives a status command --watch and --interval
+func addWatchFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&watchMode, "watch", "w", false, "Re-collect and redraw on an interval, highlighting what changed")
+	cmd.Flags().DurationVar(&watchInterval, "interval", 2*time.Second, "How often --watch refreshes")
+}
+
+// watchCommand runs next every --interval and redraws its output in place
+// until interrupted
+func watchCommand(cmd *cobra.Command, next func(ctx context.Context) string) error {
+	if isStructuredOutput() {
+		return fmt.Errorf("--watch only supports text output")
+	}
+	if watchInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	return runWatch(ctx, os.Stdout, isTerminal(os.Stdout), cmd.CommandPath(), watchInterval, next)
+}
+
+// runWatch draws frames from next to out every interval until ctx is done.
+// On a terminal each frame replaces the last; otherwise frames are appended.
+func runWatch(ctx context.Context, out io.Writer, terminal bool, title string, interval time.Duration, next func(ctx context.Context) string) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var previous []string
+	for {
+		lines := strings.Split(strings.TrimRight(next(ctx), "\n"), "\n")
+		if ctx.Err() != nil {
+			return nil
+		}
+		if terminal {
+			// Move home and clear the screen
+			fmt.Fprint(out, "\033[H\033[2J")
+		}
+		fmt.Fprintf(out, "Every %s: %s    %s\n\n", interval, title, time.Now().Format("15:04:05"))
+		for _, line := range highlightChanges(lines, previous) {
+			fmt.Fprintln(out, line)
+		}
+		if !terminal {
+			fmt.Fprintln(out)
+		}
+		previous = lines
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// highlightChanges marks the lines that did not appear in the previous frame,
+// such as a newly listening port or a changed count. Nothing is marked on
+// the first frame.
+func highlightChanges(lines, previous []string) []string {
+	if previous == nil {
+		return lines
+	}
+	seen := make(map[string]int, len(previous))
+	for _, line := range previous {
+		seen[line]++
+	}
+
+	highlighted := make([]string, len(lines))
+	for i, line := range lines {
+		if seen[line] > 0 {
+			seen[line]--
+			highlighted[i] = line
+		} else if strings.TrimSpace(line) != "" {
+			highlighted[i] = changedLine.Sprint(line)
+		}
+	}
+	return highlighted
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// renderText returns what fn writes to w
+func renderText(fn func(w io.Writer)) string {
+	var sb strings.Builder
+	fn(&sb)
+	return sb.String()
+}
+
+// watchFrame renders a text printer as a --watch frame
+func watchFrame(print func(ctx context.Context, w io.Writer)) func(ctx context.Context) string {
+	return func(ctx context.Context) string {
+		return renderText(func(w io.Writer) { print(ctx, w) })
+	}
+}
+
+// statusWatch re-collects 'allbctl status' sections for --watch. Fast
+// sections are collected on every tick; sections with a refresh cadence are
+// collected in the background and keep their last value in between.
+type statusWatch struct {
+	entries  []statusSectionEntry
+	snapshot *SystemSnapshot
+	outcomes map[string]SectionOutcome
+	lastRun  map[string]time.Time
+	running  map[string]bool
+	results  chan statusSectionResult
+}
+
+type statusSectionResult struct {
+	outcome SectionOutcome
+	store   func(*SystemSnapshot)
+}
+
+func newStatusWatch(entries []statusSectionEntry) *statusWatch {
+	return &statusWatch{
+		entries:  entries,
+		snapshot: newSystemSnapshot(),
+		outcomes: map[string]SectionOutcome{},
+		lastRun:  map[string]time.Time{},
+		running:  map[string]bool{},
+		// One slot per section, so a collector never blocks after the watch ends
+		results: make(chan statusSectionResult, len(entries)),
+	}
+}
+
+// next starts the sections that are due, waits for the fast ones and
+// renders the current state
+func (w *statusWatch) next(ctx context.Context) string {
+	now := time.Now()
+	waiting := map[string]bool{}
+	for _, entry := range w.entries {
+		name := entry.section.Name()
+		if w.running[name] {
+			continue
+		}
+		if last, ok := w.lastRun[name]; ok && now.Sub(last) < entry.refresh {
+			continue
+		}
+		w.running[name] = true
+		w.lastRun[name] = now
+		if entry.refresh == 0 {
+			waiting[name] = true
+		}
+		go func() {
+			outcome, store := runStatusSection(ctx, entry)
+			w.results <- statusSectionResult{outcome: outcome, store: store}
+		}()
+	}
+
+	for len(waiting) > 0 {
+		select {
+		case result := <-w.results:
+			w.apply(result)
+			delete(waiting, result.outcome.Name)
+		case <-ctx.Done():
+			return ""
+		}
+	}
+	for {
+		select {
+		case result := <-w.results:
+			w.apply(result)
+		default:
+			w.snapshot.Sections = w.sections()
+			return renderText(func(out io.Writer) {
+				printSnapshotHeader(out, w.snapshot)
+				renderStatusSections(out, w.entries, w.snapshot)
+			})
+		}
+	}
+}
+
+// apply stores a finished section and its outcome
+func (w *statusWatch) apply(result statusSectionResult) {
+	name := result.outcome.Name
+	w.running[name] = false
+	w.outcomes[name] = result.outcome
+	if result.store != nil {
+		result.store(w.snapshot)
+	}
+}
+
+// sections returns the latest outcome of every section, in display order
+func (w *statusWatch) sections() []SectionOutcome {
+	sections := make([]SectionOutcome, 0, len(w.entries))
+	for _, entry := range w.entries {
+		outcome, ok := w.outcomes[entry.section.Name()]
+		if !ok {
+			outcome = SectionOutcome{Name: entry.section.Name(), Status: sectionPending}
+		}
+		sections = append(sections, outcome)
+	}
+	return sections
+}
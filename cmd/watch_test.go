@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+func Test_HighlightChanges(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	previous := []string{"TCP Ports: 1", "  tcp:22", ""}
+	lines := []string{"TCP Ports: 2", "  tcp:22", "  tcp:8080", ""}
+
+	got := highlightChanges(lines, previous)
+	want := []string{changedLine.Sprint("TCP Ports: 2"), "  tcp:22", changedLine.Sprint("  tcp:8080"), ""}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("highlightChanges()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	if first := highlightChanges(lines, nil); strings.Join(first, "\n") != strings.Join(lines, "\n") {
+		t.Errorf("highlightChanges() marked lines on the first frame: %q", first)
+	}
+}
+
+func Test_RunWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var ticks int
+	next := func(context.Context) string {
+		ticks++
+		if ticks == 3 {
+			cancel()
+		}
+		return fmt.Sprintf("Running: %d\n", ticks)
+	}
+
+	var out bytes.Buffer
+	if err := runWatch(ctx, &out, false, "allbctl status ports", time.Millisecond, next); err != nil {
+		t.Fatalf("runWatch() error = %v", err)
+	}
+
+	output := out.String()
+	if strings.Count(output, "Every 1ms: allbctl status ports") != 2 {
+		t.Errorf("runWatch() should draw two frames before being cancelled\noutput:\n%s", output)
+	}
+	if !strings.Contains(output, "Running: 2") || strings.Contains(output, "\033[2J") {
+		t.Errorf("runWatch() output = %q, want appended frames without screen clears", output)
+	}
+}
+
+func Test_RenderText(t *testing.T) {
+	stdout := os.Stdout
+	got := renderText(func(w io.Writer) {
+		if os.Stdout != stdout {
+			t.Error("renderText() should not redirect os.Stdout")
+		}
+		fmt.Fprintln(w, "hello")
+	})
+	if got != "hello\n" {
+		t.Errorf("renderText() = %q, want %q", got, "hello\n")
+	}
+}
+
+func Test_StatusWatch_Refresh(t *testing.T) {
+	var fastRuns, slowRuns atomic.Int32
+	release := make(chan struct{})
+
+	entries := []statusSectionEntry{
+		{section: statusSection[int32]{
+			name: "terminal", title: "Terminal",
+			collect: func(context.Context) int32 { return fastRuns.Add(1) },
+			store:   func(s *SystemSnapshot, v int32) { s.Terminal = fmt.Sprintf("tick %d", v) },
+			render:  func(w io.Writer, s *SystemSnapshot) { fmt.Fprintf(w, "Terminal:  %s\n", s.Terminal) },
+		}, timeout: time.Second},
+		{section: statusSection[int32]{
+			name: "packages", title: "Packages",
+			collect: func(context.Context) int32 {
+				<-release
+				return slowRuns.Add(1)
+			},
+			store:  func(s *SystemSnapshot, v int32) { s.Hardware = fmt.Sprintf("%d counts", v) },
+			render: func(w io.Writer, s *SystemSnapshot) { fmt.Fprintf(w, "Packages:  %s\n", s.Hardware) },
+		}, timeout: time.Minute, refresh: time.Hour},
+	}
+
+	w := newStatusWatch(entries)
+	frame := w.next(context.Background())
+	if !strings.Contains(frame, "Terminal:  tick 1") || !strings.Contains(frame, "Packages:  collecting...") {
+		t.Fatalf("first frame should show the fast section and a pending slow one\n%s", frame)
+	}
+
+	close(release)
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(frame, "Packages:  1 counts") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+		frame = w.next(context.Background())
+	}
+	if !strings.Contains(frame, "Packages:  1 counts") {
+		t.Fatalf("slow section never showed up\n%s", frame)
+	}
+
+	w.next(context.Background())
+	if got := slowRuns.Load(); got != 1 {
+		t.Errorf("slow section collected %d times, want once within its refresh", got)
+	}
+	if got := fastRuns.Load(); got < 3 {
+		t.Errorf("fast section collected %d times, want every tick", got)
+	}
+}
@@ -0,0 +1,240 @@
+package computersetup
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aallbrig/allbctl/pkg/model"
+)
+
+type nodeState int
+
+const (
+	nodePending nodeState = iota
+	nodeSucceeded
+	nodeFailed
+	nodeSkipped
+)
+
+// configNode is one leaf configuration in the dependency graph
+type configNode struct {
+	config model.IMachineConfiguration
+	group  string // name of the enclosing MachineConfigurationGroup, if any
+	key    string // group/name, identifies the node across runs
+	deps   []*configNode
+	done   chan struct{}
+	result *model.Result
+	err    error
+	state  nodeState
+}
+
+// configurationGraph orders leaf configurations by their declared dependencies.
+// Groups are flattened so unrelated configs in different groups can run together.
+type configurationGraph struct {
+	nodes []*configNode
+}
+
+// buildConfigurationGraph flattens groups into leaf nodes and wires up
+// IDependent prerequisites. A dependency may name a leaf or a whole group.
+func buildConfigurationGraph(configs []model.IMachineConfiguration) (*configurationGraph, error) {
+	g := &configurationGraph{}
+	byName := make(map[string][]*configNode)
+
+	for _, config := range configs {
+		if group, ok := config.(model.MachineConfigurationGroup); ok {
+			for _, child := range group.Configs {
+				node := g.add(child, group.GroupName)
+				byName[child.Name()] = append(byName[child.Name()], node)
+				byName[group.GroupName] = append(byName[group.GroupName], node)
+			}
+			continue
+		}
+		node := g.add(config, "")
+		byName[config.Name()] = append(byName[config.Name()], node)
+	}
+
+	for _, node := range g.nodes {
+		dependent, ok := node.config.(model.IDependent)
+		if !ok {
+			continue
+		}
+		for _, name := range dependent.DependsOn() {
+			for _, dep := range byName[name] {
+				if dep != node {
+					node.deps = append(node.deps, dep)
+				}
+			}
+		}
+	}
+
+	if cycle := g.findCycle(); cycle != nil {
+		names := make([]string, len(cycle))
+		for i, node := range cycle {
+			names[i] = node.config.Name()
+		}
+		return nil, fmt.Errorf("configuration dependency cycle: %s", strings.Join(names, " → "))
+	}
+	return g, nil
+}
+
+func (g *configurationGraph) add(config model.IMachineConfiguration, group string) *configNode {
+	key := config.Name()
+	if group != "" {
+		key = group + "/" + key
+	}
+	node := &configNode{
+		config: config,
+		group:  group,
+		key:    key,
+		done:   make(chan struct{}),
+	}
+	g.nodes = append(g.nodes, node)
+	return node
+}
+
+// findCycle returns the nodes forming a dependency cycle, or nil
+func (g *configurationGraph) findCycle() []*configNode {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	marks := make(map[*configNode]int)
+	var stack []*configNode
+
+	var visit func(node *configNode) []*configNode
+	visit = func(node *configNode) []*configNode {
+		marks[node] = visiting
+		stack = append(stack, node)
+		for _, dep := range node.deps {
+			switch marks[dep] {
+			case visiting:
+				for i, n := range stack {
+					if n == dep {
+						return append(append([]*configNode{}, stack[i:]...), dep)
+					}
+				}
+			case unvisited:
+				if cycle := visit(dep); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		marks[node] = visited
+		return nil
+	}
+
+	for _, node := range g.nodes {
+		if marks[node] == unvisited {
+			if cycle := visit(node); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// run executes fn for every node once its prerequisites have succeeded,
+// running at most workers nodes at a time. Nodes whose prerequisites failed
+// or were skipped are skipped themselves.
+func (g *configurationGraph) run(workers int, fn func(node *configNode) (*model.Result, error)) {
+	if workers < 1 {
+		workers = 1
+	}
+	sem := make(chan struct{}, workers)
+
+	var locksMu sync.Mutex
+	locks := make(map[string]*sync.Mutex)
+	lockFor := func(config model.IMachineConfiguration) *sync.Mutex {
+		exclusive, ok := config.(model.IExclusive)
+		if !ok {
+			return nil
+		}
+		locksMu.Lock()
+		defer locksMu.Unlock()
+		name := exclusive.ExclusiveLock()
+		if locks[name] == nil {
+			locks[name] = &sync.Mutex{}
+		}
+		return locks[name]
+	}
+
+	var wg sync.WaitGroup
+	for _, node := range g.nodes {
+		wg.Add(1)
+		go func(node *configNode) {
+			defer wg.Done()
+			defer close(node.done)
+
+			for _, dep := range node.deps {
+				<-dep.done
+				if dep.state != nodeSucceeded {
+					node.state = nodeSkipped
+					node.err = fmt.Errorf("skipped %s: prerequisite %s did not complete", node.config.Name(), dep.config.Name())
+					node.result = model.NewResult(node.config.Name(), model.StatusSkipped, fmt.Sprintf("requires %s", dep.config.Name()))
+					return
+				}
+			}
+
+			// Take the exclusive lock before a worker slot so waiting on a lock doesn't idle a worker
+			if lock := lockFor(node.config); lock != nil {
+				lock.Lock()
+				defer lock.Unlock()
+			}
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			result, err := fn(node)
+			node.result = model.EnsureResult(node.config.Name(), result, err)
+			if err != nil {
+				node.state = nodeFailed
+				node.err = err
+				return
+			}
+			node.state = nodeSucceeded
+		}(node)
+	}
+	wg.Wait()
+}
+
+// errors returns failures and skips in configuration order
+func (g *configurationGraph) errors() []error {
+	var errs []error
+	for _, node := range g.nodes {
+		if node.err != nil {
+			errs = append(errs, node.err)
+		}
+	}
+	return errs
+}
+
+// results returns one result per top-level configuration, in configuration
+// order. Nodes from the same group are combined into a group result.
+func (g *configurationGraph) results() []*model.Result {
+	var results []*model.Result
+	var groupName string
+	var groupChildren []*model.Result
+	flush := func() {
+		if groupName != "" {
+			group, _ := model.NewGroupResult(groupName, groupChildren)
+			results = append(results, group)
+		}
+		groupName, groupChildren = "", nil
+	}
+
+	for _, node := range g.nodes {
+		if node.group != groupName {
+			flush()
+		}
+		if node.group == "" {
+			results = append(results, node.result)
+			continue
+		}
+		groupName = node.group
+		groupChildren = append(groupChildren, node.result)
+	}
+	flush()
+	return results
+}
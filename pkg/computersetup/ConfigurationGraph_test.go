@@ -0,0 +1,211 @@
+package computersetup
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aallbrig/allbctl/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// namedSpy is a configuration with a name, dependencies and an optional lock.
+// It always fails validation so ApplyConfiguration installs it.
+type namedSpy struct {
+	name      string
+	deps      []string
+	lock      string
+	onInstall func() error
+}
+
+func (s namedSpy) Name() string { return s.name }
+
+func (s namedSpy) Validate() (*model.Result, error) {
+	result := model.NewResult(s.name, model.StatusMissing, "not installed")
+	return result, result.Err()
+}
+
+func (s namedSpy) Install() (*model.Result, error) {
+	result := model.NewResult(s.name, model.StatusOK, "installed "+s.name)
+	if s.onInstall != nil {
+		if err := s.onInstall(); err != nil {
+			result = model.NewResult(s.name, model.StatusError, err.Error())
+		}
+	}
+	return result, result.Err()
+}
+
+func (s namedSpy) Uninstall() (*model.Result, error) {
+	return model.NewResult(s.name, model.StatusOK, ""), nil
+}
+
+func (s namedSpy) DependsOn() []string { return s.deps }
+
+type lockedSpy struct{ namedSpy }
+
+// validSpy passes validation, so it is only installed when forced
+type validSpy struct{ namedSpy }
+
+func (s validSpy) Validate() (*model.Result, error) {
+	return model.NewResult(s.name, model.StatusOK, ""), nil
+}
+
+func (s lockedSpy) ExclusiveLock() string { return s.lock }
+
+// recorder tracks install order and peak concurrency
+type recorder struct {
+	mu      sync.Mutex
+	order   []string
+	running int32
+	peak    int32
+}
+
+func (r *recorder) install(name string, hold time.Duration) func() error {
+	return func() error {
+		n := atomic.AddInt32(&r.running, 1)
+		for {
+			peak := atomic.LoadInt32(&r.peak)
+			if n <= peak || atomic.CompareAndSwapInt32(&r.peak, peak, n) {
+				break
+			}
+		}
+		time.Sleep(hold)
+		atomic.AddInt32(&r.running, -1)
+		r.mu.Lock()
+		r.order = append(r.order, name)
+		r.mu.Unlock()
+		return nil
+	}
+}
+
+func (r *recorder) index(name string) int {
+	for i, n := range r.order {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestTweaker_ApplyRunsIndependentConfigsConcurrently(t *testing.T) {
+	rec := &recorder{}
+	sut := NewMachineTweaker([]model.IMachineConfiguration{
+		namedSpy{name: "a", onInstall: rec.install("a", 50*time.Millisecond)},
+		namedSpy{name: "b", onInstall: rec.install("b", 50*time.Millisecond)},
+		namedSpy{name: "c", onInstall: rec.install("c", 50*time.Millisecond)},
+	})
+	sut.Workers = 2
+
+	_, errs := sut.ApplyConfiguration()
+
+	assert.Empty(t, errs)
+	assert.Len(t, rec.order, 3)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&rec.peak), "expected exactly Workers configs at once")
+}
+
+func TestTweaker_ApplyRespectsDependencies(t *testing.T) {
+	rec := &recorder{}
+	sut := NewMachineTweaker([]model.IMachineConfiguration{
+		namedSpy{name: "dotfiles", deps: []string{"Tools"}, onInstall: rec.install("dotfiles", 0)},
+		model.MachineConfigurationGroup{
+			GroupName: "Tools",
+			Configs: []model.IMachineConfiguration{
+				namedSpy{name: "git", onInstall: rec.install("git", 20*time.Millisecond)},
+				namedSpy{name: "ssh", deps: []string{"gh"}, onInstall: rec.install("ssh", 0)},
+				namedSpy{name: "gh", onInstall: rec.install("gh", 20*time.Millisecond)},
+			},
+		},
+		namedSpy{name: "unrelated", deps: []string{"not configured"}, onInstall: rec.install("unrelated", 0)},
+	})
+
+	_, errs := sut.ApplyConfiguration()
+
+	assert.Empty(t, errs)
+	assert.Less(t, rec.index("gh"), rec.index("ssh"))
+	assert.Less(t, rec.index("git"), rec.index("dotfiles"))
+	assert.Less(t, rec.index("ssh"), rec.index("dotfiles"))
+	assert.NotEqual(t, -1, rec.index("unrelated"), "unknown dependencies should be ignored")
+}
+
+func TestTweaker_ApplySkipsDependentsOfFailures(t *testing.T) {
+	installed := map[string]bool{}
+	var mu sync.Mutex
+	mark := func(name string) func() error {
+		return func() error {
+			mu.Lock()
+			installed[name] = true
+			mu.Unlock()
+			return nil
+		}
+	}
+
+	sut := NewMachineTweaker([]model.IMachineConfiguration{
+		namedSpy{name: "gh", onInstall: func() error { return errors.New("apt failed") }},
+		namedSpy{name: "ssh", deps: []string{"gh"}, onInstall: mark("ssh")},
+		namedSpy{name: "dotfiles", deps: []string{"ssh"}, onInstall: mark("dotfiles")},
+		namedSpy{name: "dir", onInstall: mark("dir")},
+	})
+
+	results, errs := sut.ApplyConfiguration()
+
+	assert.Len(t, errs, 3)
+	assert.False(t, installed["ssh"])
+	assert.False(t, installed["dotfiles"])
+	assert.True(t, installed["dir"])
+	assert.Equal(t, model.StatusError, results[0].Status)
+	assert.Equal(t, model.NewResult("ssh", model.StatusSkipped, "requires gh"), results[1])
+	assert.Equal(t, model.NewResult("dotfiles", model.StatusSkipped, "requires ssh"), results[2])
+	assert.Equal(t, model.StatusOK, results[3].Status)
+}
+
+func TestTweaker_ApplyHonoursExclusiveLocks(t *testing.T) {
+	rec := &recorder{}
+	sut := NewMachineTweaker([]model.IMachineConfiguration{
+		lockedSpy{namedSpy{name: "git", lock: "apt", onInstall: rec.install("git", 30*time.Millisecond)}},
+		lockedSpy{namedSpy{name: "gh", lock: "apt", onInstall: rec.install("gh", 30*time.Millisecond)}},
+	})
+
+	_, errs := sut.ApplyConfiguration()
+
+	assert.Empty(t, errs)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&rec.peak))
+}
+
+func TestTweaker_ApplyDetectsCycles(t *testing.T) {
+	sut := NewMachineTweaker([]model.IMachineConfiguration{
+		namedSpy{name: "a", deps: []string{"b"}},
+		namedSpy{name: "b", deps: []string{"a"}},
+	})
+
+	_, errs := sut.ApplyConfiguration()
+
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "cycle")
+}
+
+func TestTweaker_ApplyResultsGroupedInConfigurationOrder(t *testing.T) {
+	sut := NewMachineTweaker([]model.IMachineConfiguration{
+		model.MachineConfigurationGroup{
+			GroupName: "Tools",
+			Configs: []model.IMachineConfiguration{
+				namedSpy{name: "slow", onInstall: func() error { time.Sleep(30 * time.Millisecond); return nil }},
+				namedSpy{name: "fast"},
+			},
+		},
+		namedSpy{name: "last"},
+	})
+
+	results, errs := sut.ApplyConfiguration()
+
+	assert.Empty(t, errs)
+	assert.Len(t, results, 2)
+	assert.Equal(t, "Tools", results[0].Name)
+	assert.Equal(t, model.StatusOK, results[0].Status)
+	if assert.Len(t, results[0].Children, 2) {
+		assert.Equal(t, "slow", results[0].Children[0].Name)
+		assert.Equal(t, "fast", results[0].Children[1].Name)
+	}
+	assert.Equal(t, "last", results[1].Name)
+}
@@ -0,0 +1,195 @@
+package computersetup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aallbrig/allbctl/pkg/model"
+)
+
+// InstallAttempt is the record of one ApplyConfiguration run
+type InstallAttempt struct {
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt time.Time     `json:"finished_at,omitempty"` // zero when the run never finished
+	Resumed    bool          `json:"resumed"`
+	Steps      []JournalStep `json:"steps"`
+}
+
+// JournalStep records when a configuration started and how it ended
+type JournalStep struct {
+	Key        string       `json:"key"` // group/name, stable across runs
+	Name       string       `json:"name"`
+	StartedAt  time.Time    `json:"started_at"`
+	FinishedAt time.Time    `json:"finished_at,omitempty"` // zero while running or if the run died
+	Status     model.Status `json:"status,omitempty"`
+	Message    string       `json:"message,omitempty"`
+}
+
+// Interrupted reports whether the attempt stopped before finishing
+func (a *InstallAttempt) Interrupted() bool {
+	return a.FinishedAt.IsZero()
+}
+
+// Completed returns the keys of steps that finished successfully, so a
+// resumed install can skip them
+func (a *InstallAttempt) Completed() map[string]bool {
+	completed := make(map[string]bool)
+	for _, step := range a.Steps {
+		if step.Finished() && step.Status == model.StatusOK {
+			completed[step.Key] = true
+		}
+	}
+	return completed
+}
+
+// Incomplete returns the keys of steps that failed, were left outdated or never
+// finished, typically because the install was killed while they ran
+func (a *InstallAttempt) Incomplete() map[string]bool {
+	incomplete := make(map[string]bool)
+	for _, step := range a.Steps {
+		if !step.Finished() || step.Status == model.StatusError || step.Status == model.StatusMissing || step.Status == model.StatusOutdated {
+			incomplete[step.Key] = true
+		}
+	}
+	return incomplete
+}
+
+// Finished reports whether the step ran to completion
+func (s JournalStep) Finished() bool {
+	return !s.FinishedAt.IsZero()
+}
+
+// InstallJournal records an install attempt on disk as it happens. The file
+// is rewritten after every event so an install that dies halfway still
+// leaves a record of which steps finished.
+type InstallJournal struct {
+	path    string
+	mu      sync.Mutex
+	attempt InstallAttempt
+	err     error
+}
+
+// DefaultInstallJournalPath is where bootstrap install keeps its journal,
+// e.g. ~/.cache/allbctl/bootstrap/journal.json on Linux
+func DefaultInstallJournalPath() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine cache directory: %w", err)
+	}
+	return filepath.Join(base, "allbctl", "bootstrap", "journal.json"), nil
+}
+
+// NewInstallJournal creates a journal that writes to path
+func NewInstallJournal(path string) *InstallJournal {
+	return &InstallJournal{path: path}
+}
+
+// ReadInstallAttempt loads the attempt recorded at path. It returns nil and no
+// error when no install has been recorded yet.
+func ReadInstallAttempt(path string) (*InstallAttempt, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read install journal: %w", err)
+	}
+
+	var attempt InstallAttempt
+	if err := json.Unmarshal(data, &attempt); err != nil {
+		return nil, fmt.Errorf("cannot parse install journal %s: %w", path, err)
+	}
+	return &attempt, nil
+}
+
+// Path returns the journal file location
+func (j *InstallJournal) Path() string {
+	return j.path
+}
+
+// Err returns the first error writing the journal. Journal failures never
+// stop an install; callers report them after the fact.
+func (j *InstallJournal) Err() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.err
+}
+
+// Begin starts recording a new attempt, replacing the previous one
+func (j *InstallJournal) Begin(resumed bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.attempt = InstallAttempt{StartedAt: time.Now().UTC(), Resumed: resumed, Steps: []JournalStep{}}
+	j.write()
+}
+
+// StepStarted records that a configuration began
+func (j *InstallJournal) StepStarted(key, name string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.attempt.Steps = append(j.attempt.Steps, JournalStep{Key: key, Name: name, StartedAt: time.Now().UTC()})
+	j.write()
+}
+
+// StepFinished records a configuration's outcome. A step that never started
+// (e.g. skipped because a prerequisite failed) is added as it finishes.
+func (j *InstallJournal) StepFinished(key, name string, result *model.Result) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now().UTC()
+	i := j.find(key)
+	if i < 0 {
+		j.attempt.Steps = append(j.attempt.Steps, JournalStep{Key: key, Name: name, StartedAt: now})
+		i = len(j.attempt.Steps) - 1
+	}
+	j.attempt.Steps[i].FinishedAt = now
+	j.attempt.Steps[i].Status = result.Status
+	j.attempt.Steps[i].Message = result.Message
+	j.write()
+}
+
+// End marks the attempt as finished
+func (j *InstallJournal) End() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.attempt.FinishedAt = time.Now().UTC()
+	j.write()
+}
+
+// find returns the index of the last unfinished step with key, or -1
+func (j *InstallJournal) find(key string) int {
+	for i := len(j.attempt.Steps) - 1; i >= 0; i-- {
+		if j.attempt.Steps[i].Key == key && !j.attempt.Steps[i].Finished() {
+			return i
+		}
+	}
+	return -1
+}
+
+// write saves the attempt via a temporary file and rename, so a crash
+// mid-write never leaves a truncated journal. Callers hold j.mu.
+func (j *InstallJournal) write() {
+	if j.err != nil {
+		return
+	}
+	data, err := json.MarshalIndent(j.attempt, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(j.path), 0755)
+	}
+	if err == nil {
+		tmp := j.path + ".tmp"
+		err = os.WriteFile(tmp, append(data, '\n'), 0644)
+		if err == nil {
+			err = os.Rename(tmp, j.path)
+		}
+	}
+	if err != nil {
+		j.err = fmt.Errorf("cannot write install journal %s: %w", j.path, err)
+	}
+}
@@ -0,0 +1,90 @@
+package computersetup
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aallbrig/allbctl/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstallJournal_RecordsInterruptedAttempt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bootstrap", "journal.json")
+	journal := NewInstallJournal(path)
+
+	journal.Begin(false)
+	journal.StepStarted("Tools/git", "git")
+	journal.StepFinished("Tools/git", "git", model.NewResult("git", model.StatusOK, ""))
+	journal.StepStarted("Dotfiles/Dotfiles Setup", "Dotfiles Setup")
+	// No End(): the process died while the dotfiles script ran
+
+	attempt, err := ReadInstallAttempt(path)
+
+	assert.NoError(t, err)
+	assert.NoError(t, journal.Err())
+	assert.True(t, attempt.Interrupted())
+	assert.Equal(t, map[string]bool{"Tools/git": true}, attempt.Completed())
+	assert.Equal(t, map[string]bool{"Dotfiles/Dotfiles Setup": true}, attempt.Incomplete())
+}
+
+func TestReadInstallAttempt_NoJournal(t *testing.T) {
+	attempt, err := ReadInstallAttempt(filepath.Join(t.TempDir(), "journal.json"))
+
+	assert.NoError(t, err)
+	assert.Nil(t, attempt)
+}
+
+func TestTweaker_ApplyWritesJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.json")
+	sut := NewMachineTweaker([]model.IMachineConfiguration{
+		namedSpy{name: "gh", onInstall: func() error { return errors.New("apt failed") }},
+		namedSpy{name: "ssh", deps: []string{"gh"}},
+		namedSpy{name: "dir"},
+	})
+	sut.Journal = NewInstallJournal(path)
+
+	_, _ = sut.ApplyConfiguration()
+	attempt, err := ReadInstallAttempt(path)
+
+	assert.NoError(t, err)
+	assert.False(t, attempt.Interrupted())
+	assert.Len(t, attempt.Steps, 3)
+	assert.Equal(t, map[string]bool{"dir": true}, attempt.Completed())
+	assert.Equal(t, map[string]bool{"gh": true}, attempt.Incomplete())
+}
+
+func TestTweaker_ApplyResumesIncompleteSteps(t *testing.T) {
+	installed := map[string]bool{}
+	mark := func(name string) func() error {
+		return func() error {
+			installed[name] = true
+			return nil
+		}
+	}
+	started := time.Now()
+	previous := &InstallAttempt{Steps: []JournalStep{
+		{Key: "Tools/git", StartedAt: started, FinishedAt: started, Status: model.StatusOK},
+		{Key: "Tools/dotfiles", StartedAt: started}, // never finished
+	}}
+	sut := NewMachineTweaker([]model.IMachineConfiguration{
+		model.MachineConfigurationGroup{
+			GroupName: "Tools",
+			Configs: []model.IMachineConfiguration{
+				namedSpy{name: "git", onInstall: mark("git")},
+				validSpy{namedSpy{name: "dotfiles", onInstall: mark("dotfiles")}},
+			},
+		},
+	})
+	sut.Workers = 1
+	sut.Resume = previous
+	sut.Journal = NewInstallJournal(filepath.Join(t.TempDir(), "journal.json"))
+
+	results, errs := sut.ApplyConfiguration()
+
+	assert.Empty(t, errs)
+	assert.False(t, installed["git"], "completed steps should not run again")
+	assert.True(t, installed["dotfiles"], "unfinished steps should install even when they validate")
+	assert.Equal(t, "completed by previous install attempt", results[0].Children[0].Message)
+}
@@ -0,0 +1,227 @@
+package computersetup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/aallbrig/allbctl/pkg/model"
+	"github.com/aallbrig/allbctl/pkg/osagnostic"
+	"github.com/aallbrig/allbctl/pkg/runner"
+)
+
+// planFormatVersion is bumped when the plan file format changes incompatibly
+const planFormatVersion = 1
+
+// MachinePlan is the change set computed by MachineTweaker.Plan. It can be
+// saved to a file and applied later with ApplyPlan.
+type MachinePlan struct {
+	FormatVersion int                   `json:"format_version"`
+	CreatedAt     time.Time             `json:"created_at"`
+	OS            string                `json:"os"`
+	Hostname      string                `json:"hostname"`
+	Source        string                `json:"source"` // where the configuration came from
+	Actions       []model.PlannedAction `json:"actions"`
+	Satisfied     []string              `json:"satisfied"` // configurations that need no changes
+}
+
+// Plan walks every configuration and collects the actions needed to bring
+// this machine in line with it, without changing anything. Configurations
+// that cannot be planned are reported as errors.
+func (t MachineTweaker) Plan() (*MachinePlan, []error) {
+	hostname, _ := os.Hostname()
+	plan := &MachinePlan{
+		FormatVersion: planFormatVersion,
+		CreatedAt:     time.Now().UTC(),
+		OS:            runtime.GOOS,
+		Hostname:      hostname,
+		Actions:       []model.PlannedAction{},
+		Satisfied:     []string{},
+	}
+
+	var errs []error
+	for _, configuration := range t.MachineConfiguration {
+		errs = append(errs, planConfiguration(plan, configuration)...)
+	}
+	return plan, errs
+}
+
+func planConfiguration(plan *MachinePlan, configuration model.IMachineConfiguration) []error {
+	if group, ok := configuration.(model.MachineConfigurationGroup); ok {
+		var errs []error
+		for _, config := range group.Configs {
+			errs = append(errs, planConfiguration(plan, config)...)
+		}
+		return errs
+	}
+
+	planner, ok := configuration.(model.IPlannable)
+	if !ok {
+		return []error{fmt.Errorf("%s does not support planning; use 'bootstrap install'", configuration.Name())}
+	}
+
+	actions, err := planner.Plan()
+	if err != nil {
+		return []error{err}
+	}
+	if len(actions) == 0 {
+		plan.Satisfied = append(plan.Satisfied, configuration.Name())
+		return nil
+	}
+	plan.Actions = append(plan.Actions, actions...)
+	return nil
+}
+
+// ManualActionCount returns how many actions allbctl cannot perform itself
+func (p *MachinePlan) ManualActionCount() int {
+	n := 0
+	for _, action := range p.Actions {
+		if action.Type == model.ActionManual {
+			n++
+		}
+	}
+	return n
+}
+
+// WritePlanFile saves a plan as indented JSON
+func WritePlanFile(path string, plan *MachinePlan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot marshal plan: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("cannot write plan file: %w", err)
+	}
+	return nil
+}
+
+// ReadPlanFile loads a plan saved by WritePlanFile and checks it can be applied on this machine
+func ReadPlanFile(path string) (*MachinePlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read plan file: %w", err)
+	}
+
+	var plan MachinePlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("cannot parse plan file %s: %w", path, err)
+	}
+	if plan.FormatVersion != planFormatVersion {
+		return nil, fmt.Errorf("plan file %s has format version %d, expected %d; create a new plan", path, plan.FormatVersion, planFormatVersion)
+	}
+	if plan.OS != runtime.GOOS {
+		return nil, fmt.Errorf("plan file %s was created for %s, this machine is %s", path, plan.OS, runtime.GOOS)
+	}
+	return &plan, nil
+}
+
+// ApplyPlan executes exactly the actions in a plan, in order, returning one
+// result per action. It keeps going after a failed action so one broken
+// package doesn't block the rest.
+func ApplyPlan(plan *MachinePlan) ([]*model.Result, []error) {
+	var results []*model.Result
+	var errs []error
+
+	for i, action := range plan.Actions {
+		name := fmt.Sprintf("[%d/%d] %s", i+1, len(plan.Actions), action.Description)
+		result := applyAction(name, action)
+		results = append(results, result)
+		if result.Status == model.StatusError {
+			errs = append(errs, fmt.Errorf("%s: %s", action.Description, result.Message))
+		}
+	}
+
+	return results, errs
+}
+
+func applyAction(name string, action model.PlannedAction) *model.Result {
+	switch action.Type {
+	case model.ActionCreateDirectory:
+		perm := os.FileMode(action.Permission)
+		if perm == 0 {
+			perm = 0755
+		}
+		if err := os.MkdirAll(action.Target, perm); err != nil {
+			return model.NewResult(name, model.StatusError, err.Error())
+		}
+		return model.NewResult(name, model.StatusOK, fmt.Sprintf("created directory %s", action.Target))
+	case model.ActionInstallPackage:
+		if provides := plannedCommand(action); runner.Available(context.Background(), provides) {
+			// Installed since the plan was made, so it is not allbctl's to remove
+			return model.NewResult(name, model.StatusSkipped, fmt.Sprintf("%s is already installed; leaving it out of the package ledger", provides))
+		}
+		result := runPlannedCommand(name, action)
+		if result.Status == model.StatusOK {
+			recordPlannedInstall(result, action)
+		}
+		return result
+	case model.ActionUpgradePackage, model.ActionCloneRepo:
+		// Upgrades are not recorded in the package ledger; the package predates allbctl
+		return runPlannedCommand(name, action)
+	case model.ActionRunScript:
+		if _, err := os.Stat(action.Dir); os.IsNotExist(err) {
+			return model.NewResult(name, model.StatusError, fmt.Sprintf("directory %s does not exist", action.Dir))
+		}
+		return runPlannedCommand(name, action)
+	case model.ActionRegisterSSHKey:
+		result, _ := osagnostic.SSHKeyGitHubRegistration{KeyPath: action.Target}.Install()
+		if result.Status != model.StatusOK && result.Status != model.StatusError {
+			// Anything short of registered means the action did not complete
+			result.Status = model.StatusError
+		}
+		result.Name = name
+		return result
+	case model.ActionManual:
+		return model.NewResult(name, model.StatusSkipped, fmt.Sprintf("manual step: %s", action.Description))
+	default:
+		return model.NewResult(name, model.StatusError, fmt.Sprintf("unknown action type %q", action.Type))
+	}
+}
+
+// plannedCommand is the command an install_package action provides. Plans
+// saved before actions recorded it fall back to the package name, which is
+// usually the command's.
+func plannedCommand(action model.PlannedAction) string {
+	if action.Provides != "" {
+		return action.Provides
+	}
+	return action.Target
+}
+
+// recordPlannedInstall adds an installed package to the ledger so 'bootstrap reset' can remove it
+func recordPlannedInstall(result *model.Result, action model.PlannedAction) {
+	ledger, err := osagnostic.DefaultPackageLedger()
+	if err == nil {
+		err = ledger.Record(osagnostic.LedgerEntry{
+			Command:     plannedCommand(action),
+			Manager:     action.Manager,
+			Package:     action.Target,
+			InstalledAt: time.Now().UTC(),
+		})
+	}
+	if err != nil {
+		result.WithHints(fmt.Sprintf("%v; 'bootstrap reset' will not remove %s", err, action.Target))
+	}
+}
+
+func runPlannedCommand(name string, action model.PlannedAction) *model.Result {
+	if action.Command == "" {
+		return model.NewResult(name, model.StatusError, "action has no command")
+	}
+
+	shell, args := "sh", []string{"-c", action.Command}
+	if runtime.GOOS == "windows" {
+		shell, args = "cmd", []string{"/c", action.Command}
+	}
+	output, err := runner.CombinedOutputIn(context.Background(), action.Dir, shell, args...)
+	result := model.NewResult(name, model.StatusOK, action.Command)
+	result.Output = string(output)
+	if err != nil {
+		result.Status = model.StatusError
+		result.Message = fmt.Sprintf("%s: %v", action.Command, err)
+	}
+	return result
+}
@@ -0,0 +1,161 @@
+package computersetup
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/aallbrig/allbctl/pkg/model"
+	"github.com/aallbrig/allbctl/pkg/osagnostic"
+	"github.com/stretchr/testify/assert"
+)
+
+type SpyPlannableConfiguration struct {
+	SpyMachineConfiguration
+	OnPlan func() ([]model.PlannedAction, error)
+}
+
+func (s SpyPlannableConfiguration) Plan() ([]model.PlannedAction, error) {
+	return s.OnPlan()
+}
+
+func plannable(actions []model.PlannedAction, err error) SpyPlannableConfiguration {
+	return SpyPlannableConfiguration{OnPlan: func() ([]model.PlannedAction, error) { return actions, err }}
+}
+
+func TestTweaker_PlanCollectsActions(t *testing.T) {
+	dirAction := model.PlannedAction{Type: model.ActionCreateDirectory, Target: "/tmp/x"}
+	pkgAction := model.PlannedAction{Type: model.ActionInstallPackage, Target: "gh"}
+
+	sut := NewMachineTweaker([]model.IMachineConfiguration{
+		model.MachineConfigurationGroup{
+			GroupName: "Group",
+			Configs: []model.IMachineConfiguration{
+				plannable([]model.PlannedAction{dirAction}, nil),
+				plannable(nil, nil),
+			},
+		},
+		plannable([]model.PlannedAction{pkgAction}, nil),
+	})
+
+	plan, errs := sut.Plan()
+
+	assert.Empty(t, errs)
+	assert.Equal(t, []model.PlannedAction{dirAction, pkgAction}, plan.Actions)
+	assert.Len(t, plan.Satisfied, 1)
+	assert.Equal(t, runtime.GOOS, plan.OS)
+}
+
+func TestTweaker_PlanNeverInstalls(t *testing.T) {
+	installed := false
+	spy := plannable([]model.PlannedAction{{Type: model.ActionManual}}, nil)
+	spy.OnInstall = func() error {
+		installed = true
+		return nil
+	}
+
+	_, _ = NewMachineTweaker([]model.IMachineConfiguration{spy}).Plan()
+
+	assert.False(t, installed)
+}
+
+func TestTweaker_PlanReportsErrors(t *testing.T) {
+	sut := NewMachineTweaker([]model.IMachineConfiguration{
+		plannable(nil, errors.New("broken")),
+		new(SpyMachineConfiguration), // does not implement IPlannable
+	})
+
+	_, errs := sut.Plan()
+
+	assert.Len(t, errs, 2)
+}
+
+func TestPlanFile_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	plan, _ := NewMachineTweaker([]model.IMachineConfiguration{
+		plannable([]model.PlannedAction{{Type: model.ActionCreateDirectory, Target: "/tmp/x", Permission: 0700}}, nil),
+	}).Plan()
+
+	assert.NoError(t, WritePlanFile(path, plan))
+	loaded, err := ReadPlanFile(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, plan.Actions, loaded.Actions)
+}
+
+func TestReadPlanFile_RejectsOtherOS(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	plan := &MachinePlan{FormatVersion: planFormatVersion, OS: "plan9"}
+	assert.NoError(t, WritePlanFile(path, plan))
+
+	_, err := ReadPlanFile(path)
+
+	assert.Error(t, err)
+}
+
+func TestApplyPlan_ExecutesActionsInOrder(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	dir := filepath.Join(t.TempDir(), "work")
+	plan := &MachinePlan{Actions: []model.PlannedAction{
+		{Type: model.ActionCreateDirectory, Description: "create", Target: dir},
+		{Type: model.ActionRunScript, Description: "script", Command: "echo hello > out.txt", Dir: dir},
+		{Type: model.ActionManual, Description: "set FOO"},
+	}}
+
+	results, errs := ApplyPlan(plan)
+
+	assert.Empty(t, errs)
+	data, err := os.ReadFile(filepath.Join(dir, "out.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello\n", string(data))
+	assert.Len(t, results, 3)
+	assert.Equal(t, "[3/3] set FOO", results[2].Name)
+	assert.Equal(t, model.StatusSkipped, results[2].Status)
+	assert.Contains(t, results[2].Message, "manual step")
+}
+
+func TestApplyPlan_ContinuesAfterFailure(t *testing.T) {
+	plan := &MachinePlan{Actions: []model.PlannedAction{
+		{Type: "teleport", Description: "unknown"},
+		{Type: model.ActionCreateDirectory, Description: "create", Target: filepath.Join(t.TempDir(), "d")},
+	}}
+
+	results, errs := ApplyPlan(plan)
+
+	assert.Len(t, errs, 1)
+	assert.Equal(t, model.StatusError, results[0].Status)
+	assert.Equal(t, "[2/2] create", results[1].Name)
+	assert.Equal(t, model.StatusOK, results[1].Status)
+}
+
+func TestApplyPlan_InstallPackageRechecksPresence(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("uses sh and XDG_CACHE_HOME")
+	}
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	plan := &MachinePlan{Actions: []model.PlannedAction{
+		// Installed by the user between plan and apply
+		{Type: model.ActionInstallPackage, Description: "install sh", Target: "dash", Manager: "apt", Provides: "sh", Command: "exit 1"},
+		{Type: model.ActionInstallPackage, Description: "install tool", Target: "allbctl-test-pkg", Manager: "apt", Provides: "allbctl-test-tool", Command: "true"},
+	}}
+
+	results, errs := ApplyPlan(plan)
+
+	assert.Empty(t, errs)
+	assert.Equal(t, model.StatusSkipped, results[0].Status)
+	assert.Contains(t, results[0].Message, "already installed")
+	assert.Equal(t, model.StatusOK, results[1].Status)
+
+	ledger, err := osagnostic.DefaultPackageLedger()
+	assert.NoError(t, err)
+	entries, err := ledger.Entries()
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "allbctl-test-tool", entries[0].Command)
+		assert.Equal(t, "allbctl-test-pkg", entries[0].Package)
+	}
+}
@@ -1,43 +1,87 @@
 package computersetup
 
 import (
-	"bytes"
-	"fmt"
 	"github.com/aallbrig/allbctl/pkg/model"
 )
 
+// DefaultWorkers is how many configurations ApplyConfiguration runs at once
+const DefaultWorkers = 4
+
 type MachineTweaker struct {
 	MachineConfiguration []model.IMachineConfiguration
+	// Workers limits concurrent configurations in ApplyConfiguration; 1 runs them one at a time
+	Workers int
+	// Journal, when set, records each configuration's start and outcome during ApplyConfiguration
+	Journal *InstallJournal
+	// Resume, when set, is an earlier attempt to continue: configurations it
+	// completed are not re-run and ones it left incomplete are installed
+	// again even if they now validate (e.g. a dotfiles script that died halfway)
+	Resume *InstallAttempt
 }
 
-func (t MachineTweaker) ApplyConfiguration() ([]error, *bytes.Buffer) {
-	out := bytes.NewBufferString("")
-	var errs []error
-	for _, configuration := range t.MachineConfiguration {
-		out.WriteString(fmt.Sprintf("Applying configuration: %s\n", configuration.Name()))
-		validateOut, err := configuration.Validate()
-		out.WriteString(validateOut.String() + "\n")
+// ApplyConfiguration validates every configuration and installs the ones that
+// fail validation. Configurations run concurrently in dependency order (see
+// model.IDependent); a configuration is skipped when a prerequisite fails.
+// Results are returned in configuration order, one per top-level configuration.
+func (t MachineTweaker) ApplyConfiguration() ([]*model.Result, []error) {
+	graph, err := buildConfigurationGraph(t.MachineConfiguration)
+	if err != nil {
+		return nil, []error{err}
+	}
 
-		if err != nil {
-			installOut, err := configuration.Install()
-			out.WriteString(installOut.String() + "\n")
+	completed, incomplete := map[string]bool{}, map[string]bool{}
+	if t.Resume != nil {
+		completed, incomplete = t.Resume.Completed(), t.Resume.Incomplete()
+	}
+	if t.Journal != nil {
+		t.Journal.Begin(t.Resume != nil)
+	}
+
+	graph.run(t.Workers, func(node *configNode) (*model.Result, error) {
+		configuration := node.config
+		if completed[node.key] {
+			result := model.NewResult(configuration.Name(), model.StatusOK, "completed by previous install attempt")
+			t.journalFinished(node, result)
+			return result, nil
+		}
+
+		if t.Journal != nil {
+			t.Journal.StepStarted(node.key, configuration.Name())
+		}
+		var result *model.Result
+		var err error
+		if incomplete[node.key] {
+			result, err = configuration.Install()
+		} else if result, err = configuration.Validate(); err != nil {
+			result, err = configuration.Install()
+		}
+		t.journalFinished(node, model.EnsureResult(configuration.Name(), result, err))
+		return result, err
+	})
 
-			if err != nil {
-				errs = append(errs, err)
+	if t.Journal != nil {
+		for _, node := range graph.nodes {
+			if node.state == nodeSkipped {
+				t.journalFinished(node, node.result)
 			}
 		}
+		t.Journal.End()
 	}
 
-	return errs, out
+	return graph.results(), graph.errors()
 }
 
-func (t MachineTweaker) ConfigurationStatus() (errs []error, out *bytes.Buffer) {
-	out = bytes.NewBufferString("")
+func (t MachineTweaker) journalFinished(node *configNode, result *model.Result) {
+	if t.Journal != nil {
+		t.Journal.StepFinished(node.key, node.config.Name(), result)
+	}
+}
 
+// ConfigurationStatus validates every configuration without changing anything
+func (t MachineTweaker) ConfigurationStatus() (results []*model.Result, errs []error) {
 	for _, configuration := range t.MachineConfiguration {
-		out.WriteString(fmt.Sprintf("%s\n-----\n", configuration.Name()))
-		validateOut, err := configuration.Validate()
-		out.WriteString(validateOut.String() + "\n")
+		result, err := configuration.Validate()
+		results = append(results, model.EnsureResult(configuration.Name(), result, err))
 
 		if err != nil {
 			errs = append(errs, err)
@@ -47,14 +91,12 @@ func (t MachineTweaker) ConfigurationStatus() (errs []error, out *bytes.Buffer)
 	return
 }
 
-func (t MachineTweaker) ResetConfiguration() (errs []error, out *bytes.Buffer) {
-	out = bytes.NewBufferString("")
-
-	// Uninstall in reverse
+// ResetConfiguration uninstalls every configuration, in reverse order
+func (t MachineTweaker) ResetConfiguration() (results []*model.Result, errs []error) {
 	for i := len(t.MachineConfiguration) - 1; i >= 0; i-- {
 		configuration := t.MachineConfiguration[i]
-		uninstallOut, err := configuration.Uninstall()
-		out.WriteString(uninstallOut.String() + "\n")
+		result, err := configuration.Uninstall()
+		results = append(results, model.EnsureResult(configuration.Name(), result, err))
 
 		if err != nil {
 			errs = append(errs, err)
@@ -67,5 +109,6 @@ func (t MachineTweaker) ResetConfiguration() (errs []error, out *bytes.Buffer) {
 func NewMachineTweaker(configs []model.IMachineConfiguration) *MachineTweaker {
 	return &MachineTweaker{
 		MachineConfiguration: configs,
+		Workers:              DefaultWorkers,
 	}
 }
@@ -1,7 +1,6 @@
 package computersetup
 
 import (
-	"bytes"
 	"github.com/aallbrig/allbctl/pkg/model"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -17,19 +16,19 @@ func (s SpyMachineConfiguration) Name() string {
 	return "Spy Machine Configuration"
 }
 
-func (s SpyMachineConfiguration) Validate() (*bytes.Buffer, error) {
-	out := bytes.NewBufferString("")
-	return out, s.OnValidate()
+func (s SpyMachineConfiguration) Validate() (*model.Result, error) {
+	err := s.OnValidate()
+	return model.EnsureResult(s.Name(), nil, err), err
 }
 
-func (s SpyMachineConfiguration) Install() (*bytes.Buffer, error) {
-	out := bytes.NewBufferString("")
-	return out, s.OnInstall()
+func (s SpyMachineConfiguration) Install() (*model.Result, error) {
+	err := s.OnInstall()
+	return model.EnsureResult(s.Name(), nil, err), err
 }
 
-func (s SpyMachineConfiguration) Uninstall() (*bytes.Buffer, error) {
-	out := bytes.NewBufferString("")
-	return out, s.OnUninstall()
+func (s SpyMachineConfiguration) Uninstall() (*model.Result, error) {
+	err := s.OnUninstall()
+	return model.EnsureResult(s.Name(), nil, err), err
 }
 
 func TestTweaker_CanReport(t *testing.T) {
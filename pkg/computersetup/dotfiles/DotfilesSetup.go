@@ -1,11 +1,13 @@
 package dotfiles
 
 import (
-	"bytes"
+	"context"
 	"fmt"
-	"github.com/fatih/color"
+	"github.com/aallbrig/allbctl/pkg/model"
+	"github.com/aallbrig/allbctl/pkg/osagnostic"
+	"github.com/aallbrig/allbctl/pkg/pkgmgr"
+	"github.com/aallbrig/allbctl/pkg/runner"
 	"os"
-	"os/exec"
 )
 
 type DotfilesSetup struct {
@@ -30,91 +32,121 @@ func (d DotfilesSetup) Name() string {
 	return "Dotfiles Setup"
 }
 
-func (d DotfilesSetup) Validate() (out *bytes.Buffer, err error) {
-	out = bytes.NewBufferString("")
+// DependsOn reports that cloning needs git and, when configured, a registered SSH key
+func (d DotfilesSetup) DependsOn() []string {
+	return []string{
+		osagnostic.NewInstallableCommand("git").Name(),
+		osagnostic.SSHKeyGitHubRegistration{}.Name(),
+	}
+}
 
+// cloneCommand is the shell command that clones the dotfiles repo
+func (d DotfilesSetup) cloneCommand() string {
+	return fmt.Sprintf("git clone %s %s", pkgmgr.ShellQuote(d.RepoURL), pkgmgr.ShellQuote(d.LocalPath))
+}
+
+func (d DotfilesSetup) Validate() (*model.Result, error) {
 	// Check if dotfiles directory exists
 	if _, statErr := os.Stat(d.LocalPath); os.IsNotExist(statErr) {
-		_, _ = color.New(color.FgRed).Fprint(out, "NOT CLONED")
-		out.WriteString(fmt.Sprintf(" %s", d.LocalPath))
-		err = statErr
-		return
+		result := model.NewResult(d.Name(), model.StatusMissing, fmt.Sprintf("not cloned: %s", d.LocalPath)).
+			WithFix("clone the dotfiles", d.cloneCommand())
+		return result, result.Err()
 	}
 
 	// Check if it's a git repo
 	gitDir := d.LocalPath + "/.git"
 	if _, statErr := os.Stat(gitDir); os.IsNotExist(statErr) {
-		_, _ = color.New(color.FgYellow).Fprint(out, "EXISTS BUT NOT A GIT REPO")
-		out.WriteString(fmt.Sprintf(" %s", d.LocalPath))
-		err = fmt.Errorf("directory exists but is not a git repository")
-		return
+		result := model.NewResult(d.Name(), model.StatusError, fmt.Sprintf("%s exists but is not a git repository", d.LocalPath))
+		return result, result.Err()
 	}
 
-	_, _ = color.New(color.FgGreen).Fprint(out, "CLONED")
-	out.WriteString(fmt.Sprintf(" %s", d.LocalPath))
-
-	// Advisory health checks: append warnings only when something is wrong.
-	// These never set err — Install() relies on err==nil here meaning
-	// "the repo is cloned and is a git repo".
-	appendGitWarnings(d, out)
-	appendModularDotfileWarnings(d, out)
-
-	return
+	// Advisory health checks only add drifted children when something is
+	// wrong. Drift never produces an error — Install() relies on err==nil
+	// here meaning "the repo is cloned and is a git repo".
+	warnings := append(gitWarnings(d), modularDotfileWarnings(d)...)
+	result := model.NewResult(d.Name(), model.StatusOK, fmt.Sprintf("cloned: %s", d.LocalPath))
+	if len(warnings) > 0 {
+		result.Status = model.StatusDrifted
+		result.Children = warnings
+	}
+	return result, nil
 }
 
-func (d DotfilesSetup) Install() (*bytes.Buffer, error) {
-	out := bytes.NewBufferString("")
+func (d DotfilesSetup) Install() (*model.Result, error) {
+	var steps []*model.Result
 
 	// Check if already exists
-	validateOut, err := d.Validate()
-	out.WriteString(validateOut.String() + "\n")
+	validateResult, err := d.Validate()
 	if err == nil {
-		out.WriteString("✅ Dotfiles already cloned, skipping clone\n")
+		steps = append(steps, model.NewResult(d.Name(), model.StatusOK, "dotfiles already cloned, skipping clone"))
+	} else if validateResult.Status != model.StatusMissing {
+		return validateResult, err
 	} else {
 		// Clone the repository
-		out.WriteString(fmt.Sprintf("Cloning %s to %s...\n", d.RepoURL, d.LocalPath))
-
-		cmd := exec.Command("git", "clone", d.RepoURL, d.LocalPath)
-		output, cloneErr := cmd.CombinedOutput()
-		out.WriteString(string(output))
+		output, cloneErr := runner.CombinedOutput(context.Background(), "git", "clone", d.RepoURL, d.LocalPath)
 
+		clone := model.NewResult(d.Name(), model.StatusOK, fmt.Sprintf("cloned %s to %s", d.RepoURL, d.LocalPath))
+		clone.Output = string(output)
 		if cloneErr != nil {
-			_, _ = color.New(color.FgRed).Fprint(out, "❌ Failed to clone dotfiles\n")
-			return out, cloneErr
+			clone.Status = model.StatusError
+			clone.Message = fmt.Sprintf("failed to clone %s: %v", d.RepoURL, cloneErr)
+			return clone, clone.Err()
 		}
-
-		_, _ = color.New(color.FgGreen).Fprint(out, "✅ Dotfiles cloned successfully\n")
+		steps = append(steps, clone)
 	}
 
 	// Always run install script if it exists (it's idempotent)
 	if d.InstallScript != "" {
 		scriptPath := d.LocalPath + "/" + d.InstallScript
 		if _, statErr := os.Stat(scriptPath); os.IsNotExist(statErr) {
-			out.WriteString(fmt.Sprintf("⚠️  Install script not found: %s\n", scriptPath))
+			steps = append(steps, model.NewResult(d.Name(), model.StatusSkipped, fmt.Sprintf("install script not found: %s", scriptPath)))
 		} else {
-			out.WriteString(fmt.Sprintf("Running install script: %s\n", d.InstallScript))
-
-			cmd := exec.Command("bash", scriptPath)
-			cmd.Dir = d.LocalPath
-			cmd.Env = os.Environ()
-
-			output, scriptErr := cmd.CombinedOutput()
-			out.WriteString(string(output))
-
+			output, scriptErr := runner.CombinedOutputIn(context.Background(), d.LocalPath, "bash", scriptPath)
+			script := model.NewResult(d.Name(), model.StatusOK, fmt.Sprintf("ran install script %s", d.InstallScript))
+			script.Output = string(output)
 			if scriptErr != nil {
-				_, _ = color.New(color.FgRed).Fprint(out, "❌ Install script failed\n")
-				return out, scriptErr
+				script.Status = model.StatusError
+				script.Message = fmt.Sprintf("install script %s failed: %v", d.InstallScript, scriptErr)
 			}
+			steps = append(steps, script)
+		}
+	}
+
+	return model.NewGroupResult(d.Name(), steps)
+}
+
+// Plan reports the clone and install script run Install would perform
+func (d DotfilesSetup) Plan() ([]model.PlannedAction, error) {
+	var actions []model.PlannedAction
 
-			_, _ = color.New(color.FgGreen).Fprint(out, "✅ Install script completed\n")
+	if _, err := d.Validate(); err != nil {
+		if _, statErr := os.Stat(d.LocalPath); statErr == nil {
+			return nil, fmt.Errorf("%s: %s exists but is not a git repository", d.Name(), d.LocalPath)
 		}
+		actions = append(actions, model.PlannedAction{
+			Type:        model.ActionCloneRepo,
+			Config:      d.Name(),
+			Description: fmt.Sprintf("clone %s to %s", d.RepoURL, d.LocalPath),
+			Target:      d.RepoURL,
+			Command:     d.cloneCommand(),
+		})
+	}
+
+	// Install always re-runs the script (it's idempotent), so the plan does too
+	if d.InstallScript != "" {
+		actions = append(actions, model.PlannedAction{
+			Type:        model.ActionRunScript,
+			Config:      d.Name(),
+			Description: fmt.Sprintf("run %s in %s", d.InstallScript, d.LocalPath),
+			Target:      d.InstallScript,
+			Command:     fmt.Sprintf("bash %s", pkgmgr.ShellQuote(d.InstallScript)),
+			Dir:         d.LocalPath,
+		})
 	}
 
-	return out, nil
+	return actions, nil
 }
 
-func (d DotfilesSetup) Uninstall() (*bytes.Buffer, error) {
-	out := bytes.NewBufferString("")
-	out.WriteString(fmt.Sprintf("❌ Cannot auto-uninstall dotfiles from %s - please remove manually\n", d.LocalPath))
-	return out, nil
+func (d DotfilesSetup) Uninstall() (*model.Result, error) {
+	return model.NewResult(d.Name(), model.StatusSkipped, fmt.Sprintf("cannot auto-uninstall dotfiles from %s - please remove manually", d.LocalPath)), nil
 }
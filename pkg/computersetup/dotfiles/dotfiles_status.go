@@ -1,17 +1,18 @@
 package dotfiles
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"time"
 
-	"github.com/fatih/color"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/aallbrig/allbctl/pkg/model"
+	"github.com/aallbrig/allbctl/pkg/pkgmgr"
 )
 
 // DefaultModularDotfiles lists the dotfiles that, if present in the dotfiles
@@ -36,29 +37,36 @@ var DefaultModularDotfiles = []string{
 // Kept short so `bootstrap status` stays responsive on flaky networks.
 const fetchTimeout = 5 * time.Second
 
-// appendGitWarnings appends warning lines to out for git-state problems:
-// uncommitted changes, unpushed local commits, unpulled upstream commits.
-// It never returns an error and never modifies the caller's err semantics —
-// it only writes advisory lines when something is wrong.
-func appendGitWarnings(d DotfilesSetup, out *bytes.Buffer) {
+// gitWarnings reports git-state problems as drifted results: uncommitted
+// changes, unpushed local commits, unpulled upstream commits. It returns
+// nothing when the repo is in sync.
+func gitWarnings(d DotfilesSetup) []*model.Result {
+	var warnings []*model.Result
+	warn := func(message, hint string) {
+		warnings = append(warnings, driftWarning(d, message, hint))
+	}
+	fix := func(message, description, command string) {
+		warnings = append(warnings, driftWarning(d, message, "").WithFix(description, command))
+	}
+
 	repo, err := git.PlainOpen(d.LocalPath)
 	if err != nil {
-		writeWarning(out, fmt.Sprintf("git open failed: %v", err), "")
-		return
+		warn(fmt.Sprintf("git open failed: %v", err), "")
+		return warnings
 	}
 
 	// Uncommitted changes (dirty worktree).
 	if wt, wtErr := repo.Worktree(); wtErr == nil {
 		if st, stErr := wt.Status(); stErr == nil && !st.IsClean() {
-			writeWarning(out,
+			warn(
 				fmt.Sprintf("%d uncommitted change(s) in dotfiles", len(st)),
-				fmt.Sprintf("cd %s && git status", d.LocalPath))
+				fmt.Sprintf("cd %s && git status", pkgmgr.ShellQuote(d.LocalPath)))
 		}
 	}
 
 	// Fetch origin with a hard timeout so a stuck transport can't freeze us.
 	if fetchErr := fetchWithHardTimeout(repo, fetchTimeout); fetchErr != nil {
-		writeWarning(out,
+		warn(
 			fmt.Sprintf("could not reach origin: %v", fetchErr),
 			"")
 		// Fall through to check cached refs anyway.
@@ -66,44 +74,56 @@ func appendGitWarnings(d DotfilesSetup, out *bytes.Buffer) {
 
 	head, err := repo.Head()
 	if err != nil {
-		writeWarning(out, fmt.Sprintf("could not read local HEAD: %v", err), "")
-		return
+		warn(fmt.Sprintf("could not read local HEAD: %v", err), "")
+		return warnings
 	}
 
 	branch := head.Name().Short()
 	upstreamRef, err := repo.Reference(
 		plumbing.NewRemoteReferenceName("origin", branch), true)
 	if err != nil {
-		writeWarning(out,
+		fix(
 			fmt.Sprintf("no cached upstream ref for origin/%s", branch),
-			fmt.Sprintf("cd %s && git fetch", d.LocalPath))
-		return
+			"fetch the dotfiles",
+			fmt.Sprintf("cd %s && git fetch", pkgmgr.ShellQuote(d.LocalPath)))
+		return warnings
 	}
 
 	ahead, behind, err := commitDistance(repo, head.Hash(), upstreamRef.Hash())
 	if err != nil {
-		writeWarning(out, fmt.Sprintf("could not compute ahead/behind: %v", err), "")
-		return
+		warn(fmt.Sprintf("could not compute ahead/behind: %v", err), "")
+		return warnings
 	}
 	if ahead > 0 {
-		writeWarning(out,
+		fix(
 			fmt.Sprintf("%d local commit(s) not pushed to origin", ahead),
-			fmt.Sprintf("cd %s && git push", d.LocalPath))
+			"push the dotfiles",
+			fmt.Sprintf("cd %s && git push", pkgmgr.ShellQuote(d.LocalPath)))
 	}
 	if behind > 0 {
-		writeWarning(out,
+		fix(
 			fmt.Sprintf("%d upstream commit(s) not pulled into local", behind),
-			fmt.Sprintf("cd %s && git pull", d.LocalPath))
+			"pull the dotfiles",
+			fmt.Sprintf("cd %s && git pull", pkgmgr.ShellQuote(d.LocalPath)))
 	}
+	return warnings
 }
 
-// appendModularDotfileWarnings checks every expected modular file and
-// appends a warning when a file exists in the dotfiles repo but is not
+// modularDotfileWarnings checks every expected modular file and reports a
+// drifted result when a file exists in the dotfiles repo but is not
 // correctly symlinked into $HOME.
-func appendModularDotfileWarnings(d DotfilesSetup, out *bytes.Buffer) {
+func modularDotfileWarnings(d DotfilesSetup) []*model.Result {
+	var warnings []*model.Result
+	warn := func(message, hint string) {
+		warnings = append(warnings, driftWarning(d, message, hint))
+	}
+	install := func(message, command string) {
+		warnings = append(warnings, driftWarning(d, message, "").WithFix("re-run the dotfiles install script", command))
+	}
+
 	home, err := os.UserHomeDir()
 	if err != nil || home == "" {
-		return
+		return nil
 	}
 	repoAbs, err := filepath.Abs(d.LocalPath)
 	if err != nil {
@@ -120,20 +140,22 @@ func appendModularDotfileWarnings(d DotfilesSetup, out *bytes.Buffer) {
 		homeFile := filepath.Join(home, name)
 		info, lstatErr := os.Lstat(homeFile)
 		if lstatErr != nil {
-			writeWarning(out,
+			install(
 				fmt.Sprintf("%s not symlinked into $HOME", name),
-				fmt.Sprintf("cd %s && ./fresh.sh", d.LocalPath))
+				fmt.Sprintf("cd %s && ./fresh.sh", pkgmgr.ShellQuote(d.LocalPath)))
 			continue
 		}
 		if info.Mode()&os.ModeSymlink == 0 {
-			writeWarning(out,
+			// Moved aside rather than deleted: it may hold local changes
+			install(
 				fmt.Sprintf("%s in $HOME is a regular file (shadows dotfiles)", name),
-				fmt.Sprintf("rm %s && cd %s && ./fresh.sh", homeFile, d.LocalPath))
+				fmt.Sprintf("mv %s %s && cd %s && ./fresh.sh",
+					pkgmgr.ShellQuote(homeFile), pkgmgr.ShellQuote(homeFile+".bak"), pkgmgr.ShellQuote(d.LocalPath)))
 			continue
 		}
 		target, readErr := os.Readlink(homeFile)
 		if readErr != nil {
-			writeWarning(out,
+			warn(
 				fmt.Sprintf("%s symlink unreadable: %v", name, readErr),
 				"")
 			continue
@@ -146,21 +168,21 @@ func appendModularDotfileWarnings(d DotfilesSetup, out *bytes.Buffer) {
 			targetAbs = target
 		}
 		if targetAbs != repoFile {
-			writeWarning(out,
+			install(
 				fmt.Sprintf("%s symlink does not point into dotfiles repo", name),
-				fmt.Sprintf("cd %s && ./fresh.sh", d.LocalPath))
+				fmt.Sprintf("cd %s && ./fresh.sh", pkgmgr.ShellQuote(d.LocalPath)))
 		}
 	}
+	return warnings
 }
 
-// writeWarning appends a single indented warning line to out, with an
-// optional remediation hint shown after the message.
-func writeWarning(out *bytes.Buffer, message, hint string) {
-	out.WriteString("\n    ")
-	_, _ = color.New(color.FgYellow).Fprintf(out, "⚠ %s", message)
+// driftWarning is a single advisory finding with an optional remediation hint
+func driftWarning(d DotfilesSetup, message, hint string) *model.Result {
+	result := model.NewResult(d.Name(), model.StatusDrifted, message)
 	if hint != "" {
-		_, _ = color.New(color.Faint).Fprintf(out, "  (run: %s)", hint)
+		result.WithHints(hint)
 	}
+	return result
 }
 
 // commitDistance returns (ahead, behind) between local and upstream by
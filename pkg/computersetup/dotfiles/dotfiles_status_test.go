@@ -12,6 +12,8 @@ import (
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/aallbrig/allbctl/pkg/model"
 )
 
 // ---------------------------------------------------------------------------
@@ -174,6 +176,15 @@ func newDotfilesUnderHome(t *testing.T) *DotfilesSetup {
 	return d
 }
 
+// warnings joins the messages of a result's drifted children
+func warnings(r *model.Result) string {
+	var messages []string
+	for _, child := range r.Children {
+		messages = append(messages, child.Message)
+	}
+	return strings.Join(messages, "\n")
+}
+
 // ---------------------------------------------------------------------------
 // Validate() — existing branches still pass
 // ---------------------------------------------------------------------------
@@ -182,15 +193,15 @@ func TestValidate_MissingDirectory(t *testing.T) {
 	setFakeHome(t)
 	d := NewDotfilesSetup("https://example.invalid/dotfiles.git",
 		filepath.Join(t.TempDir(), "missing"), "./fresh.sh")
-	out, err := d.Validate()
+	result, err := d.Validate()
 	if err == nil {
 		t.Fatal("expected err for missing directory")
 	}
-	if !strings.Contains(out.String(), "NOT CLONED") {
-		t.Errorf("expected NOT CLONED in output, got: %q", out.String())
+	if result.Status != model.StatusMissing {
+		t.Errorf("expected status missing, got: %s", result.Status)
 	}
-	if strings.Contains(out.String(), "⚠") {
-		t.Errorf("missing dir should not produce health warnings, got: %q", out.String())
+	if len(result.Children) != 0 {
+		t.Errorf("missing dir should not produce health warnings, got: %q", warnings(result))
 	}
 }
 
@@ -198,15 +209,15 @@ func TestValidate_NotAGitRepo(t *testing.T) {
 	setFakeHome(t)
 	dir := t.TempDir()
 	d := NewDotfilesSetup("https://example.invalid/dotfiles.git", dir, "./fresh.sh")
-	out, err := d.Validate()
+	result, err := d.Validate()
 	if err == nil {
 		t.Fatal("expected err for non-git directory")
 	}
-	if !strings.Contains(out.String(), "EXISTS BUT NOT A GIT REPO") {
-		t.Errorf("expected 'EXISTS BUT NOT A GIT REPO', got: %q", out.String())
+	if result.Status != model.StatusError || !strings.Contains(result.Message, "not a git repository") {
+		t.Errorf("expected 'not a git repository' error, got: %s %q", result.Status, result.Message)
 	}
-	if strings.Contains(out.String(), "⚠") {
-		t.Errorf("non-git dir should not produce health warnings, got: %q", out.String())
+	if len(result.Children) != 0 {
+		t.Errorf("non-git dir should not produce health warnings, got: %q", warnings(result))
 	}
 }
 
@@ -216,15 +227,15 @@ func TestValidate_NotAGitRepo(t *testing.T) {
 
 func TestValidate_CleanInSync_NoWarnings(t *testing.T) {
 	d := newDotfilesUnderHome(t)
-	out, err := d.Validate()
+	result, err := d.Validate()
 	if err != nil {
 		t.Fatalf("expected nil err, got: %v", err)
 	}
-	if !strings.Contains(out.String(), "CLONED") {
-		t.Errorf("expected CLONED line, got: %q", out.String())
+	if result.Status != model.StatusOK {
+		t.Errorf("expected status ok, got: %s", result.Status)
 	}
-	if strings.Contains(out.String(), "⚠") {
-		t.Errorf("clean repo should produce no warnings, got: %q", out.String())
+	if len(result.Children) != 0 {
+		t.Errorf("clean repo should produce no warnings, got: %q", warnings(result))
 	}
 }
 
@@ -237,12 +248,12 @@ func TestValidate_DirtyWorktree(t *testing.T) {
 	if err := os.WriteFile(filepath.Join(d.LocalPath, "scratch"), []byte("x"), 0644); err != nil {
 		t.Fatal(err)
 	}
-	out, err := d.Validate()
+	result, err := d.Validate()
 	if err != nil {
 		t.Fatalf("nil err expected, got: %v", err)
 	}
-	if !strings.Contains(out.String(), "uncommitted change") {
-		t.Errorf("expected uncommitted change warning, got: %q", out.String())
+	if result.Status != model.StatusDrifted || !strings.Contains(warnings(result), "uncommitted change") {
+		t.Errorf("expected uncommitted change warning, got: %q", warnings(result))
 	}
 }
 
@@ -252,12 +263,12 @@ func TestValidate_AheadOfOrigin(t *testing.T) {
 	commitFile(t, repo, d.LocalPath, "extra.txt", "extra")
 	// Note: do NOT push, do NOT re-fetch — local is now ahead by 1.
 
-	out, err := d.Validate()
+	result, err := d.Validate()
 	if err != nil {
 		t.Fatalf("nil err expected, got: %v", err)
 	}
-	if !strings.Contains(out.String(), "1 local commit(s) not pushed") {
-		t.Errorf("expected ahead warning, got: %q", out.String())
+	if result.Status != model.StatusDrifted || !strings.Contains(warnings(result), "1 local commit(s) not pushed") {
+		t.Errorf("expected ahead warning, got: %q", warnings(result))
 	}
 }
 
@@ -287,12 +298,12 @@ func TestValidate_BehindOrigin(t *testing.T) {
 		t.Fatalf("reset: %v", err)
 	}
 
-	out, err := d.Validate()
+	result, err := d.Validate()
 	if err != nil {
 		t.Fatalf("nil err expected, got: %v", err)
 	}
-	if !strings.Contains(out.String(), "1 upstream commit(s) not pulled") {
-		t.Errorf("expected behind warning, got: %q", out.String())
+	if result.Status != model.StatusDrifted || !strings.Contains(warnings(result), "1 upstream commit(s) not pulled") {
+		t.Errorf("expected behind warning, got: %q", warnings(result))
 	}
 }
 
@@ -304,12 +315,12 @@ func TestValidate_NoCachedUpstream(t *testing.T) {
 	d := NewDotfilesSetup("https://example.invalid/dotfiles.git", worktree, "./fresh.sh")
 	d.ModularFiles = nil
 
-	out, err := d.Validate()
+	result, err := d.Validate()
 	if err != nil {
 		t.Fatalf("nil err expected, got: %v", err)
 	}
-	if !strings.Contains(out.String(), "no cached upstream ref") {
-		t.Errorf("expected 'no cached upstream ref' warning, got: %q", out.String())
+	if result.Status != model.StatusDrifted || !strings.Contains(warnings(result), "no cached upstream ref") {
+		t.Errorf("expected 'no cached upstream ref' warning, got: %q", warnings(result))
 	}
 }
 
@@ -320,12 +331,12 @@ func TestValidate_NoCachedUpstream(t *testing.T) {
 func TestValidate_Modular_FileNotInRepo_Silent(t *testing.T) {
 	d := newDotfilesUnderHome(t)
 	d.ModularFiles = []string{".not-in-repo"}
-	out, err := d.Validate()
+	result, err := d.Validate()
 	if err != nil {
 		t.Fatalf("nil err expected, got: %v", err)
 	}
-	if strings.Contains(out.String(), ".not-in-repo") {
-		t.Errorf("file missing from repo should be silent, got: %q", out.String())
+	if strings.Contains(warnings(result), ".not-in-repo") {
+		t.Errorf("file missing from repo should be silent, got: %q", warnings(result))
 	}
 }
 
@@ -337,12 +348,12 @@ func TestValidate_Modular_NotInHome(t *testing.T) {
 	fetchOrigin(t, d.LocalPath)
 	d.ModularFiles = []string{".zshrc"}
 
-	out, err := d.Validate()
+	result, err := d.Validate()
 	if err != nil {
 		t.Fatalf("nil err expected, got: %v", err)
 	}
-	if !strings.Contains(out.String(), ".zshrc not symlinked into $HOME") {
-		t.Errorf("expected 'not symlinked' warning, got: %q", out.String())
+	if result.Status != model.StatusDrifted || !strings.Contains(warnings(result), ".zshrc not symlinked into $HOME") {
+		t.Errorf("expected 'not symlinked' warning, got: %q", warnings(result))
 	}
 }
 
@@ -359,12 +370,17 @@ func TestValidate_Modular_RegularFileInHome(t *testing.T) {
 	}
 	d.ModularFiles = []string{".zshrc"}
 
-	out, err := d.Validate()
+	result, err := d.Validate()
 	if err != nil {
 		t.Fatalf("nil err expected, got: %v", err)
 	}
-	if !strings.Contains(out.String(), "regular file (shadows dotfiles)") {
-		t.Errorf("expected 'shadows dotfiles' warning, got: %q", out.String())
+	if result.Status != model.StatusDrifted || !strings.Contains(warnings(result), "regular file (shadows dotfiles)") {
+		t.Errorf("expected 'shadows dotfiles' warning, got: %q", warnings(result))
+	}
+	// The fix keeps the shadowing file rather than deleting it
+	fixes := result.Children[len(result.Children)-1].Fixes
+	if len(fixes) != 1 || !strings.HasPrefix(fixes[0].Command, "mv "+homeFile+" "+homeFile+".bak && ") {
+		t.Errorf("expected a fix that moves %s aside, got: %+v", homeFile, fixes)
 	}
 }
 
@@ -384,12 +400,12 @@ func TestValidate_Modular_SymlinkPointsElsewhere(t *testing.T) {
 	mustSymlinkOrSkip(t, other, homeFile)
 	d.ModularFiles = []string{".zshrc"}
 
-	out, err := d.Validate()
+	result, err := d.Validate()
 	if err != nil {
 		t.Fatalf("nil err expected, got: %v", err)
 	}
-	if !strings.Contains(out.String(), "symlink does not point into dotfiles repo") {
-		t.Errorf("expected wrong-target warning, got: %q", out.String())
+	if result.Status != model.StatusDrifted || !strings.Contains(warnings(result), "symlink does not point into dotfiles repo") {
+		t.Errorf("expected wrong-target warning, got: %q", warnings(result))
 	}
 }
 
@@ -405,12 +421,12 @@ func TestValidate_Modular_CorrectSymlink(t *testing.T) {
 	mustSymlinkOrSkip(t, repoFile, homeFile)
 	d.ModularFiles = []string{".zshrc"}
 
-	out, err := d.Validate()
+	result, err := d.Validate()
 	if err != nil {
 		t.Fatalf("nil err expected, got: %v", err)
 	}
-	if strings.Contains(out.String(), ".zshrc") {
-		t.Errorf("correct symlink should be silent, got: %q", out.String())
+	if strings.Contains(warnings(result), ".zshrc") {
+		t.Errorf("correct symlink should be silent, got: %q", warnings(result))
 	}
 }
 
@@ -0,0 +1,225 @@
+package providers
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aallbrig/allbctl/pkg/computersetup/dotfiles"
+	"github.com/aallbrig/allbctl/pkg/model"
+	"github.com/aallbrig/allbctl/pkg/osagnostic"
+)
+
+// MachineConfig is the `bootstrap:` section of ~/.allbctl.yaml. It declares a
+// machine the same way the built-in providers do, without recompiling allbctl.
+type MachineConfig struct {
+	Directories      []string               `mapstructure:"directories"`
+	Tools            []ToolConfig           `mapstructure:"tools"`
+	Dotfiles         []DotfilesConfig       `mapstructure:"dotfiles"`
+	EnvVars          []EnvVarConfig         `mapstructure:"env"`
+	RegisterSSHKey   bool                   `mapstructure:"register_ssh_key"`
+	ShellConfigTools bool                   `mapstructure:"shell_config_tools"`
+	Extra            map[string]interface{} `mapstructure:",remain"`
+}
+
+// ToolConfig is a command that should be on PATH. Packages maps a package
+// manager (apt, dnf, pacman, brew, winget, choco, scoop, generic, ...) to the
+// package that provides the command; when empty the command name is used.
+// MinVersion or Version constrain the version reported by running the command
+// with VersionArgs (default --version).
+type ToolConfig struct {
+	Command     string            `mapstructure:"command"`
+	Packages    map[string]string `mapstructure:"packages"`
+	MinVersion  string            `mapstructure:"min_version"`
+	Version     string            `mapstructure:"version"`
+	VersionArgs []string          `mapstructure:"version_args"`
+}
+
+// DotfilesConfig is a dotfiles repo to clone and install
+type DotfilesConfig struct {
+	Repo          string `mapstructure:"repo"`
+	Path          string `mapstructure:"path"`
+	InstallScript string `mapstructure:"install_script"`
+}
+
+// EnvVarConfig is an environment variable that should be set, with a hint shown when it is missing
+type EnvVarConfig struct {
+	Name string `mapstructure:"name"`
+	Help string `mapstructure:"help"`
+}
+
+// windowsPackageManagers and macOSPackageManagers route per-manager package
+// names to the matching InstallableCommand setter; everything else is Linux.
+var (
+	windowsPackageManagers = map[string]bool{"winget": true, "choco": true, "scoop": true}
+	macOSPackageManagers   = map[string]bool{"brew": true}
+)
+
+// Validate reports configuration mistakes before anything is installed
+func (c MachineConfig) Validate() error {
+	var problems []string
+	for i, tool := range c.Tools {
+		if strings.TrimSpace(tool.Command) == "" {
+			problems = append(problems, fmt.Sprintf("tools[%d]: command is required", i))
+		}
+		if tool.MinVersion != "" && tool.Version != "" {
+			problems = append(problems, fmt.Sprintf("tools[%d]: set either min_version or version, not both", i))
+		}
+	}
+	for i, d := range c.Dotfiles {
+		if strings.TrimSpace(d.Repo) == "" {
+			problems = append(problems, fmt.Sprintf("dotfiles[%d]: repo is required", i))
+		}
+	}
+	for i, e := range c.EnvVars {
+		if strings.TrimSpace(e.Name) == "" {
+			problems = append(problems, fmt.Sprintf("env[%d]: name is required", i))
+		}
+	}
+	if len(c.Extra) > 0 {
+		keys := make([]string, 0, len(c.Extra))
+		for k := range c.Extra {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		problems = append(problems, fmt.Sprintf("unknown keys: %s", strings.Join(keys, ", ")))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid bootstrap configuration: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// ConfigFileProvider builds machine configuration from a MachineConfig
+type ConfigFileProvider struct {
+	Config MachineConfig
+}
+
+func NewConfigFileProvider(config MachineConfig) ConfigFileProvider {
+	return ConfigFileProvider{Config: config}
+}
+
+func (c ConfigFileProvider) GetConfiguration() []model.IMachineConfiguration {
+	var configs []model.IMachineConfiguration
+
+	if len(c.Config.Directories) > 0 {
+		var dirs []model.IMachineConfiguration
+		for _, dir := range c.Config.Directories {
+			dirs = append(dirs, osagnostic.NewExpectedDirectory(expandHome(dir)))
+		}
+		configs = append(configs, model.MachineConfigurationGroup{GroupName: "Expected Directories", Configs: dirs})
+	}
+
+	if len(c.Config.Tools) > 0 {
+		var tools []model.IMachineConfiguration
+		for _, tool := range c.Config.Tools {
+			tools = append(tools, newInstallableCommandFromConfig(tool))
+		}
+		configs = append(configs, model.MachineConfigurationGroup{GroupName: "Required Tools", Configs: tools})
+	}
+
+	if c.Config.RegisterSSHKey {
+		configs = append(configs, model.MachineConfigurationGroup{
+			GroupName: "SSH Configuration",
+			Configs: []model.IMachineConfiguration{
+				osagnostic.NewSSHKeyGitHubRegistration(),
+			},
+		})
+	}
+
+	if len(c.Config.EnvVars) > 0 {
+		var envVars []model.IMachineConfiguration
+		for _, e := range c.Config.EnvVars {
+			envVars = append(envVars, newExpectedEnvVarFromConfig(e))
+		}
+		configs = append(configs, model.MachineConfigurationGroup{GroupName: "Expected Environment Variables", Configs: envVars})
+	}
+
+	if len(c.Config.Dotfiles) > 0 {
+		var repos []model.IMachineConfiguration
+		for _, d := range c.Config.Dotfiles {
+			repos = append(repos, newDotfilesSetupFromConfig(d))
+		}
+		configs = append(configs, model.MachineConfigurationGroup{GroupName: "Dotfiles", Configs: repos})
+	}
+
+	if c.Config.ShellConfigTools {
+		configs = append(configs, osagnostic.NewShellConfigTools())
+	}
+
+	return configs
+}
+
+func newInstallableCommandFromConfig(tool ToolConfig) *osagnostic.InstallableCommand {
+	ic := osagnostic.NewInstallableCommand(tool.Command)
+	if tool.MinVersion != "" || tool.Version != "" {
+		ic.SetVersionConstraint(osagnostic.VersionConstraint{
+			Minimum:   tool.MinVersion,
+			Exact:     tool.Version,
+			ProbeArgs: tool.VersionArgs,
+		})
+	}
+	if len(tool.Packages) == 0 {
+		// Same package name everywhere
+		return ic.SetLinuxPackage("generic", tool.Command).
+			SetMacOSPackage(tool.Command).
+			SetWindowsPackage("winget", tool.Command).
+			SetWindowsPackage("choco", tool.Command).
+			SetWindowsPackage("scoop", tool.Command)
+	}
+
+	for manager, pkg := range tool.Packages {
+		switch {
+		case macOSPackageManagers[manager]:
+			ic.SetMacOSPackage(pkg)
+		case windowsPackageManagers[manager]:
+			ic.SetWindowsPackage(manager, pkg)
+		default:
+			ic.SetLinuxPackage(manager, pkg)
+		}
+	}
+	return ic
+}
+
+func newExpectedEnvVarFromConfig(e EnvVarConfig) osagnostic.ExpectedEnvVar {
+	help := e.Help
+	if help == "" {
+		help = fmt.Sprintf("Set %s in your shell profile", e.Name)
+	}
+	return osagnostic.ExpectedEnvVar{
+		Key:  e.Name,
+		Hint: help,
+		OnInstall: func() error {
+			log.Println(help)
+			return nil
+		},
+		OnUninstall: func() error {
+			log.Println("❌ It is up to the user to uninstall this environment variable")
+			return nil
+		},
+	}
+}
+
+func newDotfilesSetupFromConfig(d DotfilesConfig) *dotfiles.DotfilesSetup {
+	path := expandHome(d.Path)
+	if path == "" {
+		// Default to ~/src/<repo name>, matching the built-in providers
+		name := strings.TrimSuffix(filepath.Base(strings.TrimRight(d.Repo, "/")), ".git")
+		path = filepath.Join(os.HomeDirectoryPath, "src", name)
+	}
+	return dotfiles.NewDotfilesSetup(d.Repo, path, d.InstallScript)
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) string {
+	if path == "~" {
+		return os.HomeDirectoryPath
+	}
+	if strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		return filepath.Join(os.HomeDirectoryPath, path[2:])
+	}
+	return path
+}
@@ -0,0 +1,163 @@
+package providers
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+
+	"github.com/aallbrig/allbctl/pkg/computersetup/dotfiles"
+	"github.com/aallbrig/allbctl/pkg/model"
+	"github.com/aallbrig/allbctl/pkg/osagnostic"
+)
+
+const exampleConfig = `
+bootstrap:
+  directories:
+    - ~/src
+    - /opt/work
+  tools:
+    - command: git
+    - command: gh
+      packages:
+        apt: gh
+        pacman: github-cli
+        brew: gh
+        winget: GitHub.cli
+      min_version: "2.40"
+  dotfiles:
+    - repo: https://github.com/someone/dotfiles.git
+      install_script: ./install.sh
+  env:
+    - name: GITHUB_TOKEN
+      help: Create a token at https://github.com/settings/tokens
+  shell_config_tools: true
+`
+
+func loadExampleConfig(t *testing.T, yaml string) MachineConfig {
+	t.Helper()
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(bytes.NewBufferString(yaml)); err != nil {
+		t.Fatalf("ReadConfig failed: %v", err)
+	}
+	var config MachineConfig
+	if err := v.UnmarshalKey("bootstrap", &config); err != nil {
+		t.Fatalf("UnmarshalKey failed: %v", err)
+	}
+	return config
+}
+
+func groupByName(configs []model.IMachineConfiguration, name string) (model.MachineConfigurationGroup, bool) {
+	for _, c := range configs {
+		if group, ok := c.(model.MachineConfigurationGroup); ok && group.GroupName == name {
+			return group, true
+		}
+	}
+	return model.MachineConfigurationGroup{}, false
+}
+
+func TestConfigFileProvider_GetConfiguration(t *testing.T) {
+	config := loadExampleConfig(t, exampleConfig)
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	configs := NewConfigFileProvider(config).GetConfiguration()
+
+	dirs, ok := groupByName(configs, "Expected Directories")
+	if !ok || len(dirs.Configs) != 2 {
+		t.Fatalf("Expected 2 directories, got %+v", dirs)
+	}
+	if got := dirs.Configs[0].(*osagnostic.ExpectedDirectory).Path; got != filepath.Join(os.HomeDirectoryPath, "src") {
+		t.Errorf("Expected ~ to be expanded, got %s", got)
+	}
+	if got := dirs.Configs[1].(*osagnostic.ExpectedDirectory).Path; got != "/opt/work" {
+		t.Errorf("Expected absolute path unchanged, got %s", got)
+	}
+
+	tools, ok := groupByName(configs, "Required Tools")
+	if !ok || len(tools.Configs) != 2 {
+		t.Fatalf("Expected 2 tools, got %+v", tools)
+	}
+	gh := tools.Configs[1].(*osagnostic.InstallableCommand)
+	if gh.LinuxPackages["pacman"] != "github-cli" || gh.LinuxPackages["apt"] != "gh" {
+		t.Errorf("Linux packages not routed: %v", gh.LinuxPackages)
+	}
+	if gh.MacOSPackage != "gh" {
+		t.Errorf("brew package not routed to macOS: %q", gh.MacOSPackage)
+	}
+	if gh.WindowsPackages["winget"] != "GitHub.cli" {
+		t.Errorf("winget package not routed to Windows: %v", gh.WindowsPackages)
+	}
+	if _, isLinux := gh.LinuxPackages["winget"]; isLinux {
+		t.Error("winget package should not be a Linux package")
+	}
+	if gh.Version == nil || gh.Version.Minimum != "2.40" {
+		t.Errorf("min_version not applied: %+v", gh.Version)
+	}
+
+	git := tools.Configs[0].(*osagnostic.InstallableCommand)
+	if git.LinuxPackages["generic"] != "git" || git.MacOSPackage != "git" || git.WindowsPackages["winget"] != "git" {
+		t.Errorf("Tool without packages should default to the command name: %+v", git)
+	}
+	if git.Version != nil {
+		t.Errorf("Tool without a version should not be constrained: %+v", git.Version)
+	}
+
+	dots, ok := groupByName(configs, "Dotfiles")
+	if !ok || len(dots.Configs) != 1 {
+		t.Fatalf("Expected 1 dotfiles repo, got %+v", dots)
+	}
+	setup := dots.Configs[0].(*dotfiles.DotfilesSetup)
+	if setup.LocalPath != filepath.Join(os.HomeDirectoryPath, "src", "dotfiles") {
+		t.Errorf("Expected default path ~/src/dotfiles, got %s", setup.LocalPath)
+	}
+	if setup.InstallScript != "./install.sh" {
+		t.Errorf("Expected install script ./install.sh, got %s", setup.InstallScript)
+	}
+
+	env, ok := groupByName(configs, "Expected Environment Variables")
+	if !ok || env.Configs[0].(osagnostic.ExpectedEnvVar).Key != "GITHUB_TOKEN" {
+		t.Errorf("Expected GITHUB_TOKEN env var, got %+v", env)
+	}
+
+	if _, ok := groupByName(configs, "SSH Configuration"); ok {
+		t.Error("SSH Configuration should only be present when register_ssh_key is set")
+	}
+	if configs[len(configs)-1].Name() != "Shell Config Tools" {
+		t.Errorf("Expected Shell Config Tools last, got %s", configs[len(configs)-1].Name())
+	}
+}
+
+func TestConfigFileProvider_EmptyConfig(t *testing.T) {
+	configs := NewConfigFileProvider(MachineConfig{}).GetConfiguration()
+	if len(configs) != 0 {
+		t.Errorf("Expected no configuration for an empty config, got %d entries", len(configs))
+	}
+}
+
+func TestMachineConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr bool
+	}{
+		{"valid", exampleConfig, false},
+		{"tool without command", "bootstrap:\n  tools:\n    - packages: {apt: git}\n", true},
+		{"dotfiles without repo", "bootstrap:\n  dotfiles:\n    - path: ~/dotfiles\n", true},
+		{"env without name", "bootstrap:\n  env:\n    - help: set it\n", true},
+		{"min_version and version", "bootstrap:\n  tools:\n    - command: go\n      min_version: \"1.21\"\n      version: \"1.22.0\"\n", true},
+		{"unknown key", "bootstrap:\n  directorys: [~/src]\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := loadExampleConfig(t, tt.yaml).Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
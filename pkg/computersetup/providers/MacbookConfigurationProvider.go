@@ -42,7 +42,8 @@ func (m MacbookConfigurationProvider) GetConfiguration() []model.IMachineConfigu
 			GroupName: "Expected Environment Variables",
 			Configs: []model.IMachineConfiguration{
 				osagnostic.ExpectedEnvVar{
-					Key: externalapi.GithubAuthTokenEnvVar,
+					Key:  externalapi.GithubAuthTokenEnvVar,
+					Hint: "see https://cli.github.com/manual/gh_help_environment",
 					OnInstall: func() error {
 						log.Println("Read documentation: https://cli.github.com/manual/gh_help_environment")
 						return nil
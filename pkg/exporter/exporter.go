@@ -0,0 +1,178 @@
+// Package exporter serves machine status as Prometheus metrics. Each Source
+// is refreshed in the background on its own interval, so a scrape only reads
+// the latest cached values and an expensive check (such as pending package
+// updates) never slows it down.
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Namespace prefixes every metric name
+const Namespace = "allbctl"
+
+// Source collects a group of metrics
+type Source struct {
+	// Name identifies the source in the allbctl_collector_* metrics, e.g. "ports"
+	Name string
+	// Interval is how often the source is refreshed
+	Interval time.Duration
+	// Timeout bounds a refresh; a source that does not finish in time is
+	// reported as failed and keeps its previous metrics
+	Timeout time.Duration
+	// Descs describes every metric Collect can return
+	Descs []*prometheus.Desc
+	// Collect gathers the current values
+	Collect func(ctx context.Context) ([]prometheus.Metric, error)
+}
+
+// Exporter is a prometheus.Collector serving the cached metrics of its sources
+type Exporter struct {
+	sources []Source
+
+	mu    sync.Mutex
+	state map[string]*sourceState
+}
+
+// sourceState is the result of a source's latest refreshes
+type sourceState struct {
+	metrics     []prometheus.Metric
+	up          bool
+	lastSuccess time.Time
+	duration    time.Duration
+}
+
+var (
+	upDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "collector", "up"),
+		"Whether the collector's latest refresh succeeded.",
+		[]string{"collector"}, nil,
+	)
+	lastSuccessDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "collector", "last_success_timestamp_seconds"),
+		"Unix time of the collector's latest successful refresh.",
+		[]string{"collector"}, nil,
+	)
+	durationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "collector", "duration_seconds"),
+		"How long the collector's latest refresh took.",
+		[]string{"collector"}, nil,
+	)
+)
+
+// New returns an exporter for sources. Call Run to start refreshing them.
+func New(sources ...Source) *Exporter {
+	return &Exporter{
+		sources: sources,
+		state:   map[string]*sourceState{},
+	}
+}
+
+// Run refreshes every source right away and then on its interval, until ctx
+// is done
+func (e *Exporter) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, source := range e.sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(source.Interval)
+			defer ticker.Stop()
+			for {
+				e.Refresh(ctx, source)
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Refresh collects source once and caches the result
+func (e *Exporter) Refresh(ctx context.Context, source Source) {
+	ctx, cancel := context.WithTimeout(ctx, source.Timeout)
+	defer cancel()
+
+	type collected struct {
+		metrics []prometheus.Metric
+		err     error
+	}
+	// Buffered so a collector that finishes after the timeout does not block forever
+	done := make(chan collected, 1)
+	start := time.Now()
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- collected{err: fmt.Errorf("panic: %v", r)}
+			}
+		}()
+		metrics, err := source.Collect(ctx)
+		done <- collected{metrics: metrics, err: err}
+	}()
+
+	var result collected
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		result.err = ctx.Err()
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	state, ok := e.state[source.Name]
+	if !ok {
+		state = &sourceState{}
+		e.state[source.Name] = state
+	}
+	state.duration = time.Since(start)
+	state.up = result.err == nil
+	if result.err == nil {
+		state.metrics = result.metrics
+		state.lastSuccess = time.Now()
+	}
+}
+
+// Describe implements prometheus.Collector
+func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- upDesc
+	ch <- lastSuccessDesc
+	ch <- durationDesc
+	for _, source := range e.sources {
+		for _, desc := range source.Descs {
+			ch <- desc
+		}
+	}
+}
+
+// Collect implements prometheus.Collector. Sources that have not finished a
+// refresh yet are left out.
+func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, source := range e.sources {
+		state, ok := e.state[source.Name]
+		if !ok {
+			continue
+		}
+		up := 0.0
+		if state.up {
+			up = 1
+		}
+		ch <- prometheus.MustNewConstMetric(upDesc, prometheus.GaugeValue, up, source.Name)
+		ch <- prometheus.MustNewConstMetric(durationDesc, prometheus.GaugeValue, state.duration.Seconds(), source.Name)
+		if !state.lastSuccess.IsZero() {
+			ch <- prometheus.MustNewConstMetric(lastSuccessDesc, prometheus.GaugeValue, float64(state.lastSuccess.Unix()), source.Name)
+		}
+		for _, metric := range state.metrics {
+			ch <- metric
+		}
+	}
+}
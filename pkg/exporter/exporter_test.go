@@ -0,0 +1,122 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var portsDesc = prometheus.NewDesc("allbctl_listening_ports", "Listening ports.", []string{"protocol"}, nil)
+
+func portsSource(tcp float64, err error) Source {
+	return Source{
+		Name:     "ports",
+		Interval: time.Minute,
+		Timeout:  time.Second,
+		Descs:    []*prometheus.Desc{portsDesc},
+		Collect: func(context.Context) ([]prometheus.Metric, error) {
+			if err != nil {
+				return nil, err
+			}
+			return []prometheus.Metric{
+				prometheus.MustNewConstMetric(portsDesc, prometheus.GaugeValue, tcp, "tcp"),
+			}, nil
+		},
+	}
+}
+
+func TestExporter_Refresh(t *testing.T) {
+	source := portsSource(3, nil)
+	e := New(source)
+
+	if n := testutil.CollectAndCount(e); n != 0 {
+		t.Errorf("before the first refresh got %d metrics, want 0", n)
+	}
+
+	e.Refresh(context.Background(), source)
+	want := `
+# HELP allbctl_collector_up Whether the collector's latest refresh succeeded.
+# TYPE allbctl_collector_up gauge
+allbctl_collector_up{collector="ports"} 1
+# HELP allbctl_listening_ports Listening ports.
+# TYPE allbctl_listening_ports gauge
+allbctl_listening_ports{protocol="tcp"} 3
+`
+	if err := testutil.CollectAndCompare(e, strings.NewReader(want), "allbctl_collector_up", "allbctl_listening_ports"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestExporter_FailedRefreshKeepsMetrics(t *testing.T) {
+	e := New(portsSource(3, nil))
+	e.Refresh(context.Background(), portsSource(3, nil))
+	e.Refresh(context.Background(), portsSource(0, errors.New("ss: not found")))
+
+	want := `
+# HELP allbctl_collector_up Whether the collector's latest refresh succeeded.
+# TYPE allbctl_collector_up gauge
+allbctl_collector_up{collector="ports"} 0
+# HELP allbctl_listening_ports Listening ports.
+# TYPE allbctl_listening_ports gauge
+allbctl_listening_ports{protocol="tcp"} 3
+`
+	if err := testutil.CollectAndCompare(e, strings.NewReader(want), "allbctl_collector_up", "allbctl_listening_ports"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestExporter_RefreshTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	source := Source{
+		Name:    "packages",
+		Timeout: 20 * time.Millisecond,
+		Collect: func(context.Context) ([]prometheus.Metric, error) {
+			<-release
+			return nil, nil
+		},
+	}
+	e := New(source)
+
+	start := time.Now()
+	e.Refresh(context.Background(), source)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Refresh() waited %s for a hung collector", elapsed)
+	}
+	want := `
+# HELP allbctl_collector_up Whether the collector's latest refresh succeeded.
+# TYPE allbctl_collector_up gauge
+allbctl_collector_up{collector="packages"} 0
+`
+	if err := testutil.CollectAndCompare(e, strings.NewReader(want), "allbctl_collector_up"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestExporter_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	source := portsSource(2, nil)
+	source.Interval = time.Millisecond
+	e := New(source)
+
+	done := make(chan struct{})
+	go func() {
+		e.Run(ctx)
+		close(done)
+	}()
+	deadline := time.Now().Add(time.Second)
+	for testutil.CollectAndCount(e, "allbctl_listening_ports") == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if n := testutil.CollectAndCount(e, "allbctl_listening_ports"); n != 1 {
+		t.Errorf("after Run got %d allbctl_listening_ports series, want 1", n)
+	}
+}
@@ -1,7 +1,9 @@
 package externalcmd
 
 import (
+	"context"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/google/go-github/github"
 	"os"
@@ -24,6 +26,21 @@ func (gitClient *GitClient) PlainClone(dir string, url string) (repo *git.Reposi
 	return
 }
 
+// PlainCloneContext is a facade for git plain clone that checks out branch,
+// or the remote's default branch when branch is empty
+func (gitClient *GitClient) PlainCloneContext(ctx context.Context, dir string, url string, branch string) (repo *git.Repository, err error) {
+	options := &git.CloneOptions{
+		URL:  url,
+		Auth: Auth,
+	}
+	if branch != "" {
+		options.ReferenceName = plumbing.NewBranchReferenceName(branch)
+	}
+	repo, err = git.PlainCloneContext(ctx, dir, false, options)
+
+	return
+}
+
 type gitClientProvider interface {
 	GetGitClient() (GitClient, error)
 }
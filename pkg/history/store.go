@@ -0,0 +1,143 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// timestampFormat names record files so lexical order matches chronological order.
+const timestampFormat = "20060102T150405.000000000Z"
+
+// Store keeps timestamped JSON records in a directory, one file per record,
+// under os.UserCacheDir like pkg/cache.FileCache.
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// Entry identifies a stored record.
+type Entry struct {
+	Time time.Time
+	Path string
+}
+
+// NewStore creates a history store in the given subdirectory under os.UserCacheDir.
+// For example, NewStore("allbctl", "history") uses ~/.cache/allbctl/history/ on Linux.
+func NewStore(subDirs ...string) (*Store, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine cache directory: %w", err)
+	}
+
+	parts := append([]string{base}, subDirs...)
+	return NewStoreInDir(filepath.Join(parts...))
+}
+
+// NewStoreInDir creates a history store in an explicit directory.
+// Useful for testing.
+func NewStoreInDir(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("cannot create history directory %s: %w", dir, err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Save writes v as a record taken at t and returns its entry.
+func (s *Store) Save(t time.Time, v interface{}) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return Entry{}, fmt.Errorf("cannot marshal history record: %w", err)
+	}
+
+	t = t.UTC()
+	entry := Entry{Time: t, Path: filepath.Join(s.dir, t.Format(timestampFormat)+".json")}
+	if err := os.WriteFile(entry.Path, data, 0644); err != nil {
+		return Entry{}, fmt.Errorf("cannot write history record: %w", err)
+	}
+	return entry, nil
+}
+
+// List returns all records, oldest first. Files that do not look like records are ignored.
+func (s *Store) List() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.list()
+}
+
+func (s *Store) list() ([]Entry, error) {
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read history directory: %w", err)
+	}
+
+	var entries []Entry
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		t, err := time.Parse(timestampFormat, strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			continue
+		}
+		entries = append(entries, Entry{Time: t, Path: filepath.Join(s.dir, name)})
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Time.Before(entries[j].Time) })
+	return entries, nil
+}
+
+// Load decodes the record for entry into v.
+func (s *Store) Load(entry Entry, v interface{}) error {
+	data, err := os.ReadFile(entry.Path)
+	if err != nil {
+		return fmt.Errorf("cannot read history record: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("cannot parse history record %s: %w", filepath.Base(entry.Path), err)
+	}
+	return nil
+}
+
+// Prune removes the oldest records so that at most keep remain.
+func (s *Store) Prune(keep int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.list()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(entries)-keep; i++ {
+		if err := os.Remove(entries[i].Path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("cannot remove history record: %w", err)
+		}
+	}
+	return nil
+}
+
+// Before returns the newest entry taken at or before t.
+// Returns false when every record is newer than t.
+func Before(entries []Entry, t time.Time) (Entry, bool) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if !entries[i].Time.After(t) {
+			return entries[i], true
+		}
+	}
+	return Entry{}, false
+}
+
+// Dir returns the history directory path.
+func (s *Store) Dir() string {
+	return s.dir
+}
@@ -0,0 +1,151 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type record struct {
+	Name  string `json:"name"`
+	Value int    `json:"value"`
+}
+
+func TestNewStoreInDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "history")
+
+	s, err := NewStoreInDir(dir)
+	if err != nil {
+		t.Fatalf("NewStoreInDir failed: %v", err)
+	}
+	if s.Dir() != dir {
+		t.Errorf("Expected dir %s, got %s", dir, s.Dir())
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Fatalf("History directory not created: %v", err)
+	}
+}
+
+func TestSaveListLoad(t *testing.T) {
+	s, err := NewStoreInDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	// Save out of order to check List sorts chronologically
+	for _, offset := range []int{2, 0, 1} {
+		if _, err := s.Save(base.Add(time.Duration(offset)*time.Hour), record{Name: "r", Value: offset}); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	entries, err := s.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+	for i, e := range entries {
+		want := base.Add(time.Duration(i) * time.Hour)
+		if !e.Time.Equal(want) {
+			t.Errorf("entries[%d].Time = %v, want %v", i, e.Time, want)
+		}
+		var r record
+		if err := s.Load(e, &r); err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if r.Value != i {
+			t.Errorf("entries[%d] value = %d, want %d", i, r.Value, i)
+		}
+	}
+}
+
+func TestListIgnoresForeignFiles(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewStoreInDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "notes.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("hi"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Save(time.Now(), record{}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected 1 entry, got %d", len(entries))
+	}
+}
+
+func TestPrune(t *testing.T) {
+	s, err := NewStoreInDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		if _, err := s.Save(base.Add(time.Duration(i)*time.Minute), record{Value: i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.Prune(2); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+
+	entries, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries after prune, got %d", len(entries))
+	}
+	if !entries[0].Time.Equal(base.Add(3 * time.Minute)) {
+		t.Errorf("Prune kept the wrong records: oldest is %v", entries[0].Time)
+	}
+}
+
+func TestBefore(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Time: base},
+		{Time: base.Add(24 * time.Hour)},
+		{Time: base.Add(48 * time.Hour)},
+	}
+
+	tests := []struct {
+		name   string
+		at     time.Time
+		want   time.Time
+		wantOK bool
+	}{
+		{"before all", base.Add(-time.Hour), time.Time{}, false},
+		{"exact match", base.Add(24 * time.Hour), base.Add(24 * time.Hour), true},
+		{"between", base.Add(30 * time.Hour), base.Add(24 * time.Hour), true},
+		{"after all", base.Add(72 * time.Hour), base.Add(48 * time.Hour), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Before(entries, tt.at)
+			if ok != tt.wantOK {
+				t.Fatalf("Before() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && !got.Time.Equal(tt.want) {
+				t.Errorf("Before() = %v, want %v", got.Time, tt.want)
+			}
+		})
+	}
+}
@@ -1,12 +1,14 @@
 package languages
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+
+	"github.com/aallbrig/allbctl/pkg/runner"
 )
 
 // LanguageBreakdown represents one language's share of a repository.
@@ -60,8 +62,8 @@ func fileBase(path string) string {
 // DetectLanguages analyzes a git repository at the given path and returns
 // a sorted list of language breakdowns (most bytes first).
 // It uses `git ls-tree -r -l HEAD` to enumerate tracked files with their sizes.
-func DetectLanguages(repoPath string) ([]LanguageBreakdown, error) {
-	output, err := exec.Command("git", "-C", repoPath, "ls-tree", "-r", "-l", "HEAD").Output()
+func DetectLanguages(ctx context.Context, repoPath string) ([]LanguageBreakdown, error) {
+	output, err := runner.Output(ctx, "git", "-C", repoPath, "ls-tree", "-r", "-l", "HEAD")
 	if err != nil {
 		return nil, fmt.Errorf("git ls-tree failed: %w", err)
 	}
@@ -189,8 +191,8 @@ func formatBytes(bytes int64) string {
 }
 
 // GetHeadCommit returns the HEAD commit SHA for a git repository.
-func GetHeadCommit(repoPath string) (string, error) {
-	output, err := exec.Command("git", "-C", repoPath, "rev-parse", "HEAD").Output()
+func GetHeadCommit(ctx context.Context, repoPath string) (string, error) {
+	output, err := runner.Output(ctx, "git", "-C", repoPath, "rev-parse", "HEAD")
 	if err != nil {
 		return "", fmt.Errorf("git rev-parse HEAD failed: %w", err)
 	}
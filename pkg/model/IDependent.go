@@ -0,0 +1,14 @@
+package model
+
+// IDependent is implemented by configurations that must run after others.
+// DependsOn returns the Name() of each prerequisite configuration or group;
+// names that are not part of the current configuration are ignored.
+type IDependent interface {
+	DependsOn() []string
+}
+
+// IExclusive is implemented by configurations that must not run at the same
+// time as others holding the same lock, e.g. two installs through one package manager.
+type IExclusive interface {
+	ExclusiveLock() string
+}
@@ -1,10 +1,11 @@
 package model
 
-import "bytes"
-
+// IMachineConfiguration is one piece of machine setup. Each method reports what
+// it found or did as a Result; the error is non-nil when the configuration is
+// missing or could not be applied.
 type IMachineConfiguration interface {
 	Name() string
-	Validate() (out *bytes.Buffer, err error)
-	Install() (out *bytes.Buffer, err error)
-	Uninstall() (out *bytes.Buffer, err error)
+	Validate() (*Result, error)
+	Install() (*Result, error)
+	Uninstall() (*Result, error)
 }
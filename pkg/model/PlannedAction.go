@@ -0,0 +1,36 @@
+package model
+
+// ActionType identifies what a PlannedAction does when applied
+type ActionType string
+
+const (
+	ActionCreateDirectory ActionType = "create_directory"
+	ActionInstallPackage  ActionType = "install_package"
+	ActionUpgradePackage  ActionType = "upgrade_package"
+	ActionCloneRepo       ActionType = "clone_repo"
+	ActionRunScript       ActionType = "run_script"
+	ActionRegisterSSHKey  ActionType = "register_ssh_key"
+	// ActionManual is a step allbctl cannot perform; applying it only prints the description
+	ActionManual ActionType = "manual"
+)
+
+// PlannedAction is one change a configuration would make to the machine.
+// Actions are self-contained so a saved plan can be applied later without
+// re-reading the configuration that produced it.
+type PlannedAction struct {
+	Type        ActionType `json:"type"`
+	Config      string     `json:"config"`               // Name() of the configuration that planned it
+	Description string     `json:"description"`          // human-readable summary
+	Target      string     `json:"target,omitempty"`     // directory, package, repo URL or key path
+	Manager     string     `json:"manager,omitempty"`    // package manager for install_package and upgrade_package
+	Provides    string     `json:"provides,omitempty"`   // command an install_package action's package provides
+	Command     string     `json:"command,omitempty"`    // shell command run by apply
+	Dir         string     `json:"dir,omitempty"`        // working directory for Command
+	Permission  uint32     `json:"permission,omitempty"` // mode for create_directory
+}
+
+// IPlannable is implemented by configurations that can describe what Install
+// would change without changing anything. An empty plan means nothing to do.
+type IPlannable interface {
+	Plan() ([]PlannedAction, error)
+}
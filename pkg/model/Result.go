@@ -0,0 +1,148 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Status is the outcome of checking or applying a configuration
+type Status string
+
+const (
+	// StatusOK means the configuration is in place
+	StatusOK Status = "ok"
+	// StatusMissing means the configuration is absent; Install can add it
+	StatusMissing Status = "missing"
+	// StatusDrifted means the configuration is present but differs from what is
+	// expected. Drift is advisory: see Hints for how to fix it.
+	StatusDrifted Status = "drifted"
+	// StatusOutdated means the configuration is present at a version that does
+	// not meet its constraint; Install can upgrade it
+	StatusOutdated Status = "outdated"
+	// StatusError means the configuration could not be checked or applied
+	StatusError Status = "error"
+	// StatusSkipped means the configuration was not checked or applied
+	StatusSkipped Status = "skipped"
+)
+
+// statusSeverity orders statuses so a group reports its worst child
+var statusSeverity = map[Status]int{
+	StatusOK:       0,
+	StatusSkipped:  1,
+	StatusDrifted:  2,
+	StatusOutdated: 3,
+	StatusMissing:  4,
+	StatusError:    5,
+}
+
+// Result describes the state of a configuration, or what happened when it was
+// installed or uninstalled. Rendering is left to the caller.
+type Result struct {
+	Name     string    `json:"name"`
+	Status   Status    `json:"status"`
+	Message  string    `json:"message,omitempty"`  // one-line summary, e.g. the path or command checked
+	Hints    []string  `json:"hints,omitempty"`    // remediation steps
+	Fixes    []Fix     `json:"fixes,omitempty"`    // hints that can be run as-is
+	Output   string    `json:"output,omitempty"`   // output of commands that were run
+	Children []*Result `json:"children,omitempty"` // results of grouped configurations
+}
+
+// NewResult creates a result for the named configuration
+func NewResult(name string, status Status, message string) *Result {
+	return &Result{Name: name, Status: status, Message: message}
+}
+
+// WithHints appends remediation hints and returns the result
+func (r *Result) WithHints(hints ...string) *Result {
+	r.Hints = append(r.Hints, hints...)
+	return r
+}
+
+// Fix is a remediation hint that can be run as a shell command, such as
+// "cd ~/src/dotfiles && ./fresh.sh"
+type Fix struct {
+	Description string `json:"description"`
+	Command     string `json:"command"`
+	// Installs is the package the command installs, if any, so whoever runs
+	// the fix can record it the way Install would
+	Installs *PackageInstall `json:"installs,omitempty"`
+}
+
+// PackageInstall is a package a fix installs
+type PackageInstall struct {
+	Command string `json:"command,omitempty"` // command the package provides
+	Manager string `json:"manager"`
+	Package string `json:"package"`
+}
+
+// WithFix records a runnable fix, and shows its command as a hint
+func (r *Result) WithFix(description, command string) *Result {
+	r.Fixes = append(r.Fixes, Fix{Description: description, Command: command})
+	r.Hints = append(r.Hints, command)
+	return r
+}
+
+// WithInstallFix records a runnable fix that installs a package
+func (r *Result) WithInstallFix(description, command string, install PackageInstall) *Result {
+	r.WithFix(description, command)
+	r.Fixes[len(r.Fixes)-1].Installs = &install
+	return r
+}
+
+// OK reports whether nothing needs to be done
+func (r *Result) OK() bool {
+	return r.Status == StatusOK
+}
+
+// Err returns a *ResultError when the configuration is missing, outdated or in
+// error, and nil otherwise. Drift is advisory and does not produce an error.
+func (r *Result) Err() error {
+	if r.Status != StatusMissing && r.Status != StatusOutdated && r.Status != StatusError {
+		return nil
+	}
+	return &ResultError{Result: r}
+}
+
+// ResultError is the error form of a missing, outdated or failed Result
+type ResultError struct {
+	Result *Result
+}
+
+func (e *ResultError) Error() string {
+	if e.Result.Message == "" {
+		return fmt.Sprintf("%s: %s", e.Result.Name, e.Result.Status)
+	}
+	return fmt.Sprintf("%s: %s (%s)", e.Result.Name, e.Result.Status, e.Result.Message)
+}
+
+// NewGroupResult combines child results. The group takes the most severe
+// child status and its error joins the child errors.
+func NewGroupResult(name string, children []*Result) (*Result, error) {
+	group := &Result{Name: name, Status: StatusOK, Children: children}
+	var errs []error
+	for _, child := range children {
+		if statusSeverity[child.Status] > statusSeverity[group.Status] {
+			group.Status = child.Status
+		}
+		if err := child.Err(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return group, errors.Join(errs...)
+}
+
+// EnsureResult returns a non-nil result that agrees with err. It covers
+// configurations that return a nil result, or an error alongside a result
+// that does not report one.
+func EnsureResult(name string, result *Result, err error) *Result {
+	if result == nil {
+		result = NewResult(name, StatusOK, "")
+	}
+	if err != nil && result.Err() == nil {
+		result.Status = StatusError
+		if result.Message == "" {
+			result.Message = err.Error()
+		}
+	}
+	return result
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestResult_Err(t *testing.T) {
+	tests := []struct {
+		status  Status
+		wantErr bool
+	}{
+		{StatusOK, false},
+		{StatusSkipped, false},
+		{StatusDrifted, false},
+		{StatusMissing, true},
+		{StatusError, true},
+	}
+	for _, tc := range tests {
+		t.Run(string(tc.status), func(t *testing.T) {
+			err := NewResult("dir", tc.status, "/tmp/x").Err()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Err() = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewGroupResult_TakesWorstStatus(t *testing.T) {
+	group, err := NewGroupResult("Tools", []*Result{
+		NewResult("git", StatusOK, ""),
+		NewResult("dotfiles", StatusDrifted, ""),
+		NewResult("gh", StatusMissing, ""),
+	})
+
+	if group.Status != StatusMissing {
+		t.Errorf("group status = %s, want %s", group.Status, StatusMissing)
+	}
+	var resultErr *ResultError
+	if !errors.As(err, &resultErr) || resultErr.Result.Name != "gh" {
+		t.Errorf("group error = %v, want the error for gh", err)
+	}
+}
+
+func TestNewGroupResult_DriftIsNotAnError(t *testing.T) {
+	group, err := NewGroupResult("Dotfiles", []*Result{NewResult("dotfiles", StatusDrifted, "")})
+
+	if group.Status != StatusDrifted || err != nil {
+		t.Errorf("NewGroupResult() = %s, %v; want drifted with no error", group.Status, err)
+	}
+}
+
+func TestEnsureResult(t *testing.T) {
+	if r := EnsureResult("x", nil, nil); r.Status != StatusOK {
+		t.Errorf("EnsureResult(nil, nil) status = %s, want ok", r.Status)
+	}
+	r := EnsureResult("x", NewResult("x", StatusOK, ""), errors.New("boom"))
+	if r.Status != StatusError || r.Message != "boom" {
+		t.Errorf("EnsureResult(ok, err) = %s %q, want error \"boom\"", r.Status, r.Message)
+	}
+}
+
+func TestMachineConfigurationGroup_EmptyIsError(t *testing.T) {
+	result, err := MachineConfigurationGroup{GroupName: "Empty"}.Validate()
+
+	if err == nil || result.Status != StatusError {
+		t.Errorf("Validate() = %s, %v; want error", result.Status, err)
+	}
+}
+
+func TestResult_WithFix(t *testing.T) {
+	r := NewResult("dir", StatusMissing, "not found").WithFix("create /tmp/x", "mkdir -p /tmp/x")
+
+	if len(r.Fixes) != 1 || r.Fixes[0].Command != "mkdir -p /tmp/x" || r.Fixes[0].Description != "create /tmp/x" {
+		t.Errorf("Fixes = %+v, want the mkdir fix", r.Fixes)
+	}
+	if len(r.Hints) != 1 || r.Hints[0] != "mkdir -p /tmp/x" {
+		t.Errorf("Hints = %v, want the fix command shown as a hint", r.Hints)
+	}
+}
@@ -1,9 +1,9 @@
 package osagnostic
 
 import (
-	"bytes"
 	"fmt"
-	"github.com/fatih/color"
+	"github.com/aallbrig/allbctl/pkg/model"
+	"github.com/aallbrig/allbctl/pkg/pkgmgr"
 	"os"
 )
 
@@ -20,61 +20,72 @@ func (e ExpectedDirectory) Name() string {
 	return fmt.Sprintf("Expected Directory %s", e.Path)
 }
 
-func (e ExpectedDirectory) Validate() (out *bytes.Buffer, err error) {
-	out = bytes.NewBufferString("")
+func (e ExpectedDirectory) Validate() (*model.Result, error) {
 	stat, statErr := os.Stat(e.Path)
 
-	if statErr != nil {
-		if os.IsNotExist(statErr) {
-			_, _ = color.New(color.FgRed).Fprint(out, "NOT FOUND")
-		} else {
-			_, _ = color.New(color.FgRed).Fprint(out, "stat error")
-			err = statErr
-		}
-	} else if !stat.IsDir() {
-		_, _ = color.New(color.FgRed).Fprint(out, "expected directory is file")
-		err = fmt.Errorf("directory %s cannot be created due to conflict", e.Path)
-	} else {
-		_, _ = color.New(color.FgGreen).Fprint(out, "PRESENT")
+	var result *model.Result
+	switch {
+	case os.IsNotExist(statErr):
+		result = model.NewResult(e.Name(), model.StatusMissing, "not found").
+			WithFix(fmt.Sprintf("create %s", e.Path), fmt.Sprintf("mkdir -p %s", pkgmgr.ShellQuote(e.Path)))
+	case statErr != nil:
+		result = model.NewResult(e.Name(), model.StatusError, fmt.Sprintf("%s: %v", e.Path, statErr))
+	case !stat.IsDir():
+		result = model.NewResult(e.Name(), model.StatusError, fmt.Sprintf("%s is a file, expected a directory", e.Path)).
+			WithHints(fmt.Sprintf("move %s out of the way so the directory can be created", e.Path))
+	default:
+		result = model.NewResult(e.Name(), model.StatusOK, "present")
 	}
-	out.WriteString(fmt.Sprintf(" %s", e.Path))
 
-	return
+	return result, result.Err()
 }
 
-func (e ExpectedDirectory) Install() (*bytes.Buffer, error) {
-	out := bytes.NewBufferString("")
-
+func (e ExpectedDirectory) Install() (*model.Result, error) {
 	// Check if already exists
-	validateOut, err := e.Validate()
-	if err == nil {
-		// Already exists
-		out.WriteString(validateOut.String() + "\n")
-		_, _ = color.New(color.FgGreen).Fprint(out, "✅ Directory already exists\n")
-		return out, nil
+	validateResult, err := e.Validate()
+	if err == nil || validateResult.Status != model.StatusMissing {
+		return validateResult, err
 	}
 
-	out.WriteString(validateOut.String() + "\n")
-
 	// Create directory with MkdirAll for idempotency
-	err = os.MkdirAll(e.Path, e.Permission)
-	if err != nil {
-		_, _ = color.New(color.FgRed).Fprint(out, fmt.Sprintf("❌ Failed to create %s: %v\n", e.Path, err))
-	} else {
-		_, _ = color.New(color.FgGreen).Fprint(out, fmt.Sprintf("✅ Created directory %s\n", e.Path))
+	result := model.NewResult(e.Name(), model.StatusOK, fmt.Sprintf("created %s", e.Path))
+	if err := os.MkdirAll(e.Path, e.Permission); err != nil {
+		result = model.NewResult(e.Name(), model.StatusError, fmt.Sprintf("failed to create %s: %v", e.Path, err))
 	}
-
-	return out, err
+	return result, result.Err()
 }
 
-func (e ExpectedDirectory) Uninstall() (*bytes.Buffer, error) {
-	out := bytes.NewBufferString("")
-	_, err := e.Validate()
-	if err != nil {
-		return out, nil
+// Plan reports the directory Install would create. It checks the path itself
+// because Validate returns an error both for a missing directory
+// (StatusMissing) and for a file in its place, and only the first can be fixed.
+func (e ExpectedDirectory) Plan() ([]model.PlannedAction, error) {
+	stat, err := os.Stat(e.Path)
+	if err == nil {
+		if !stat.IsDir() {
+			return nil, fmt.Errorf("directory %s cannot be created due to conflict", e.Path)
+		}
+		return nil, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
 	}
+	return []model.PlannedAction{{
+		Type:        model.ActionCreateDirectory,
+		Config:      e.Name(),
+		Description: fmt.Sprintf("create directory %s", e.Path),
+		Target:      e.Path,
+		Permission:  uint32(e.Permission),
+	}}, nil
+}
 
-	err = os.RemoveAll(e.Path)
+func (e ExpectedDirectory) Uninstall() (*model.Result, error) {
+	if _, err := e.Validate(); err != nil {
+		return model.NewResult(e.Name(), model.StatusSkipped, fmt.Sprintf("%s does not exist", e.Path)), nil
+	}
 
-	return out, err
+	result := model.NewResult(e.Name(), model.StatusOK, fmt.Sprintf("removed %s", e.Path))
+	if err := os.RemoveAll(e.Path); err != nil {
+		result = model.NewResult(e.Name(), model.StatusError, fmt.Sprintf("failed to remove %s: %v", e.Path, err))
+	}
+	return result, result.Err()
 }
@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/aallbrig/allbctl/pkg/model"
 )
 
 func TestExpectedDirectory_Validate_NonExistentDirectory(t *testing.T) {
@@ -12,18 +14,20 @@ func TestExpectedDirectory_Validate_NonExistentDirectory(t *testing.T) {
 	nonExistentPath := filepath.Join(tmpDir, "does-not-exist")
 
 	ed := NewExpectedDirectory(nonExistentPath)
-	out, err := ed.Validate()
+	result, err := ed.Validate()
 
-	if err != nil {
-		t.Errorf("Validate() should not return error for non-existent directory, got: %v", err)
+	// Missing is reported as an error so ApplyConfiguration installs it
+	if err == nil {
+		t.Error("Validate() should return error for non-existent directory")
 	}
-
-	output := out.String()
-	if strings.Contains(output, "PRESENT") {
-		t.Errorf("Validate() should not report PRESENT for non-existent directory, got: %s", output)
+	if result.Status != model.StatusMissing {
+		t.Errorf("Validate() should report missing for non-existent directory, got: %s", result.Status)
 	}
-	if !strings.Contains(output, "NOT FOUND") && !strings.Contains(output, "MISSING") {
-		t.Errorf("Validate() should report NOT FOUND or MISSING for non-existent directory, got: %s", output)
+	if len(result.Hints) == 0 {
+		t.Error("Validate() should hint how to create a missing directory")
+	}
+	if len(result.Fixes) != 1 || result.Fixes[0].Command != "mkdir -p "+nonExistentPath {
+		t.Errorf("Validate() fixes = %+v, want mkdir -p %s", result.Fixes, nonExistentPath)
 	}
 }
 
@@ -35,15 +39,13 @@ func TestExpectedDirectory_Validate_ExistingDirectory(t *testing.T) {
 	}
 
 	ed := NewExpectedDirectory(existingDir)
-	out, err := ed.Validate()
+	result, err := ed.Validate()
 
 	if err != nil {
 		t.Errorf("Validate() should not return error for existing directory, got: %v", err)
 	}
-
-	output := out.String()
-	if !strings.Contains(output, "PRESENT") {
-		t.Errorf("Validate() should report PRESENT for existing directory, got: %s", output)
+	if result.Status != model.StatusOK {
+		t.Errorf("Validate() should report ok for existing directory, got: %s", result.Status)
 	}
 }
 
@@ -55,14 +57,51 @@ func TestExpectedDirectory_Validate_FileInsteadOfDirectory(t *testing.T) {
 	}
 
 	ed := NewExpectedDirectory(filePath)
-	out, err := ed.Validate()
+	result, err := ed.Validate()
 
 	if err == nil {
 		t.Error("Validate() should return error when path is a file, not a directory")
 	}
+	if result.Status != model.StatusError || !strings.Contains(result.Message, "is a file") {
+		t.Errorf("Validate() should report the path is a file, got: %s %q", result.Status, result.Message)
+	}
+}
+
+func TestExpectedDirectory_Install_CreatesMissingDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "new")
+
+	result, err := NewExpectedDirectory(path).Install()
 
-	output := out.String()
-	if !strings.Contains(output, "expected directory is file") {
-		t.Errorf("Validate() should report 'expected directory is file', got: %s", output)
+	if err != nil || result.Status != model.StatusOK {
+		t.Fatalf("Install() = %s, %v; want ok", result.Status, err)
+	}
+	if stat, statErr := os.Stat(path); statErr != nil || !stat.IsDir() {
+		t.Errorf("Install() did not create %s", path)
+	}
+}
+
+func TestExpectedDirectory_Plan(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	missing := filepath.Join(tmpDir, "missing")
+	actions, err := NewExpectedDirectory(missing).Plan()
+	if err != nil {
+		t.Fatalf("Plan() unexpected error: %v", err)
+	}
+	if len(actions) != 1 || actions[0].Target != missing || actions[0].Permission != 0755 {
+		t.Errorf("Plan() for missing directory = %+v, want one create action", actions)
+	}
+
+	actions, err = NewExpectedDirectory(tmpDir).Plan()
+	if err != nil || len(actions) != 0 {
+		t.Errorf("Plan() for existing directory = %+v, %v; want no actions", actions, err)
+	}
+
+	filePath := filepath.Join(tmpDir, "file.txt")
+	if err := os.WriteFile(filePath, []byte("test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewExpectedDirectory(filePath).Plan(); err == nil {
+		t.Error("Plan() should return error when path is a file")
 	}
 }
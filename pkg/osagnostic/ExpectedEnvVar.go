@@ -1,9 +1,8 @@
 package osagnostic
 
 import (
-	"bytes"
 	"fmt"
-	"github.com/fatih/color"
+	"github.com/aallbrig/allbctl/pkg/model"
 	"os"
 )
 
@@ -11,6 +10,7 @@ type EnvVarInstall func() error
 type EnvVarUninstall func() error
 type ExpectedEnvVar struct {
 	Key         string
+	Hint        string // shown in plans, where OnInstall cannot run
 	OnInstall   EnvVarInstall
 	OnUninstall EnvVarUninstall
 }
@@ -19,42 +19,64 @@ func (e ExpectedEnvVar) Name() string {
 	return fmt.Sprintf("Envvar %s", e.Key)
 }
 
-func (e ExpectedEnvVar) Validate() (out *bytes.Buffer, err error) {
-	out = bytes.NewBufferString("")
-	_, exists := os.LookupEnv(e.Key)
-	if !exists {
-		_, _ = color.New(color.FgRed).Fprint(out, "MISSING")
-		err = fmt.Errorf("envvar does not exist")
-	} else {
-		_, _ = color.New(color.FgGreen).Fprint(out, "PRESENT")
-	}
-	out.WriteString(fmt.Sprintf(" %s", e.Name()))
-	return out, err
-}
+func (e ExpectedEnvVar) Validate() (*model.Result, error) {
+	if _, exists := os.LookupEnv(e.Key); exists {
+		return model.NewResult(e.Name(), model.StatusOK, "set"), nil
+	}
 
-func (e ExpectedEnvVar) Install() (*bytes.Buffer, error) {
-	out := bytes.NewBufferString("")
-	_, err := e.Validate()
-	if err == nil {
-		return out, err
+	result := model.NewResult(e.Name(), model.StatusMissing, "not set")
+	if e.Hint != "" {
+		result.WithHints(e.Hint)
 	}
-	if e.OnInstall != nil {
-		return out, e.OnInstall()
+	return result, result.Err()
+}
+
+// Install runs OnInstall, which can only point the user in the right
+// direction; the variable still has to be set by hand, so the result is skipped.
+func (e ExpectedEnvVar) Install() (*model.Result, error) {
+	if result, err := e.Validate(); err == nil {
+		return result, nil
 	}
+	return e.runHook(e.OnInstall, "install", fmt.Sprintf("set %s manually", e.Key))
+}
 
-	return out, fmt.Errorf("no install lambda defined for envvar %s", e.Key)
+func (e ExpectedEnvVar) Uninstall() (*model.Result, error) {
+	if _, err := e.Validate(); err != nil {
+		return model.NewResult(e.Name(), model.StatusSkipped, fmt.Sprintf("%s is not set", e.Key)), nil
+	}
+	return e.runHook(e.OnUninstall, "uninstall", fmt.Sprintf("unset %s manually", e.Key))
 }
 
-func (e ExpectedEnvVar) Uninstall() (*bytes.Buffer, error) {
-	out := bytes.NewBufferString("")
-	_, err := e.Validate()
-	if err == nil {
-		return out, err
+func (e ExpectedEnvVar) runHook(hook func() error, action, message string) (*model.Result, error) {
+	if hook == nil {
+		result := model.NewResult(e.Name(), model.StatusError, fmt.Sprintf("no %s lambda defined for envvar %s", action, e.Key))
+		return result, result.Err()
+	}
+	if err := hook(); err != nil {
+		result := model.NewResult(e.Name(), model.StatusError, err.Error())
+		return result, result.Err()
 	}
 
-	if e.OnUninstall != nil {
-		return out, e.OnUninstall()
+	result := model.NewResult(e.Name(), model.StatusSkipped, message)
+	if e.Hint != "" {
+		result.WithHints(e.Hint)
 	}
+	return result, nil
+}
 
-	return out, fmt.Errorf("no uninstall lambda defined for envvar %s", e.Key)
+// Plan reports a manual step when the variable is missing; allbctl cannot set it for you
+func (e ExpectedEnvVar) Plan() ([]model.PlannedAction, error) {
+	if _, exists := os.LookupEnv(e.Key); exists {
+		return nil, nil
+	}
+	description := fmt.Sprintf("set environment variable %s", e.Key)
+	if e.Hint != "" {
+		description += " (" + e.Hint + ")"
+	}
+	return []model.PlannedAction{{
+		Type:        model.ActionManual,
+		Config:      e.Name(),
+		Description: description,
+		Target:      e.Key,
+	}}, nil
 }
@@ -1,13 +1,17 @@
 package osagnostic
 
 import (
-	"bytes"
+	"context"
 	"fmt"
-	"os/exec"
+	"os"
 	"runtime"
 	"strings"
+	"time"
 
-	"github.com/fatih/color"
+	"github.com/aallbrig/allbctl/pkg/model"
+	"github.com/aallbrig/allbctl/pkg/pkgmgr"
+	"github.com/aallbrig/allbctl/pkg/runner"
+	"github.com/aallbrig/allbctl/pkg/version"
 )
 
 type InstallableCommand struct {
@@ -15,6 +19,62 @@ type InstallableCommand struct {
 	LinuxPackages   map[string]string // package manager -> package name
 	MacOSPackage    string            // homebrew package name
 	WindowsPackages map[string]string // package manager -> package name
+	// Ledger records what Install installed so Uninstall only removes that;
+	// nil uses DefaultPackageLedger
+	Ledger *PackageLedger
+	// Version, when set, is the version the command must be at; a command
+	// on PATH that does not meet it is reported as outdated
+	Version *VersionConstraint
+}
+
+// VersionConstraint requires a minimum or exact version of a command. The
+// version is read by running the command with ProbeArgs and parsing the output.
+type VersionConstraint struct {
+	Minimum   string                     // e.g. "2.40"; this version or newer is accepted
+	Exact     string                     // e.g. "1.21.5"; only this version is accepted
+	ProbeArgs []string                   // arguments that print the version; defaults to --version
+	Parse     func(output string) string // extracts the version from the probe output; defaults to version.Extract
+}
+
+// SatisfiedBy reports whether installed meets the constraint. Versions are
+// compared numerically, so "2.40.1" satisfies a minimum of "2.40".
+func (c VersionConstraint) SatisfiedBy(installed string) bool {
+	if c.Exact != "" && version.Compare(installed, c.Exact) != 0 {
+		return false
+	}
+	if c.Minimum != "" && version.Compare(installed, c.Minimum) < 0 {
+		return false
+	}
+	return true
+}
+
+func (c VersionConstraint) String() string {
+	if c.Exact != "" {
+		return c.Exact
+	}
+	return fmt.Sprintf(">= %s", c.Minimum)
+}
+
+// probe runs the command and returns the version it reports
+func (c VersionConstraint) probe(path string) (string, error) {
+	args := c.ProbeArgs
+	if len(args) == 0 {
+		args = []string{"--version"}
+	}
+	parse := c.Parse
+	if parse == nil {
+		parse = version.Extract
+	}
+
+	output, err := runner.CombinedOutput(context.Background(), path, args...)
+	if err != nil {
+		return "", fmt.Errorf("%s %s failed: %w", path, strings.Join(args, " "), err)
+	}
+	installed := parse(string(output))
+	if installed == "" {
+		return "", fmt.Errorf("no version found in output of %s %s", path, strings.Join(args, " "))
+	}
+	return installed, nil
 }
 
 func NewInstallableCommand(commandName string) *InstallableCommand {
@@ -41,147 +101,362 @@ func (i *InstallableCommand) SetWindowsPackage(packageManager, packageName strin
 	return i
 }
 
+// SetVersionConstraint requires a minimum or exact version of the command
+func (i *InstallableCommand) SetVersionConstraint(constraint VersionConstraint) *InstallableCommand {
+	i.Version = &constraint
+	return i
+}
+
 func (i InstallableCommand) Name() string {
 	return fmt.Sprintf("Installable Command: %s", i.CommandName)
 }
 
-func (i InstallableCommand) Validate() (out *bytes.Buffer, err error) {
-	out = bytes.NewBufferString("")
+// packageManagerLock serializes installs; package managers hold a system-wide lock
+const packageManagerLock = "package-manager"
+
+// ExclusiveLock keeps installs from running concurrently with each other
+func (i InstallableCommand) ExclusiveLock() string {
+	return packageManagerLock
+}
 
-	_, err = exec.LookPath(i.CommandName)
+func (i InstallableCommand) Validate() (*model.Result, error) {
+	path, err := runner.LookPath(context.Background(), i.CommandName)
 	if err != nil {
-		_, _ = color.New(color.FgRed).Fprint(out, "NOT FOUND")
-	} else {
-		_, _ = color.New(color.FgGreen).Fprint(out, "INSTALLED")
+		result := model.NewResult(i.Name(), model.StatusMissing, "not found on PATH")
+		if manager, pkg, fullCommand, resolveErr := i.InstallCommand(); resolveErr == nil {
+			result.WithInstallFix(fmt.Sprintf("install %s with %s", i.CommandName, manager), fullCommand,
+				model.PackageInstall{Command: i.CommandName, Manager: manager, Package: pkg})
+		}
+		return result, result.Err()
+	}
+	if i.Version == nil {
+		return model.NewResult(i.Name(), model.StatusOK, path), nil
 	}
-	out.WriteString(fmt.Sprintf(" %s", i.CommandName))
 
-	return
-}
+	installed, err := i.Version.probe(path)
+	if err != nil {
+		result := model.NewResult(i.Name(), model.StatusError, fmt.Sprintf("cannot determine version: %v", err))
+		return result, result.Err()
+	}
+	if i.Version.SatisfiedBy(installed) {
+		return model.NewResult(i.Name(), model.StatusOK, fmt.Sprintf("%s (%s)", path, installed)), nil
+	}
 
-func (i InstallableCommand) Install() (*bytes.Buffer, error) {
-	out := bytes.NewBufferString("")
+	result := model.NewResult(i.Name(), model.StatusOutdated, fmt.Sprintf("%s %s, want %s", i.CommandName, installed, i.Version))
+	if manager, _, fullCommand, resolveErr := i.UpgradeCommand(); resolveErr == nil {
+		result.WithFix(fmt.Sprintf("upgrade %s with %s", i.CommandName, manager), fullCommand)
+	}
+	return result, result.Err()
+}
 
+func (i InstallableCommand) Install() (*model.Result, error) {
 	// Check if already installed
-	_, err := exec.LookPath(i.CommandName)
+	validateResult, err := i.Validate()
 	if err == nil {
-		_, _ = color.New(color.FgGreen).Fprint(out, "✅ Already installed")
-		out.WriteString(fmt.Sprintf(" %s\n", i.CommandName))
-		return out, nil
+		validateResult.Message = fmt.Sprintf("%s already installed", i.CommandName)
+		return validateResult, nil
+	}
+	switch validateResult.Status {
+	case model.StatusOutdated:
+		return i.upgrade()
+	case model.StatusError:
+		// The command is on PATH but its version could not be read; installing again will not help
+		return validateResult, err
 	}
 
-	out.WriteString(fmt.Sprintf("Installing %s...\n", i.CommandName))
+	manager, pkg, fullCommand, err := i.InstallCommand()
+	if err != nil {
+		result := model.NewResult(i.Name(), model.StatusError, installErrorHint(err))
+		return result, err
+	}
 
-	switch runtime.GOOS {
-	case "linux":
-		return i.installOnLinux(out)
-	case "darwin":
-		return i.installOnMacOS(out)
-	case "windows":
-		return i.installOnWindows(out)
-	default:
-		out.WriteString(fmt.Sprintf("❌ Unsupported OS: %s\n", runtime.GOOS))
-		return out, fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
+	result, err := i.runInstallCommand(fullCommand)
+	if err != nil {
+		return result, err
 	}
-}
 
-func (i InstallableCommand) installOnLinux(out *bytes.Buffer) (*bytes.Buffer, error) {
-	// Try package managers in order of preference
-	packageManagers := []string{"apt", "dnf", "yum", "pacman", "zypper", "apk"}
+	// Only packages installed here are recorded; anything already present is left alone by Uninstall
+	entry := LedgerEntry{Command: i.CommandName, Manager: manager, Package: pkg, InstalledAt: time.Now().UTC()}
+	ledger, ledgerErr := i.ledger()
+	if ledgerErr == nil {
+		ledgerErr = ledger.Record(entry)
+	}
+	if ledgerErr != nil {
+		result.WithHints(fmt.Sprintf("%v; 'bootstrap reset' will not remove %s", ledgerErr, pkg))
+	}
+	return result, nil
+}
 
-	for _, pm := range packageManagers {
-		if _, err := exec.LookPath(pm); err == nil {
-			if packageName, exists := i.LinuxPackages[pm]; exists {
-				return i.installWithPackageManager(out, pm, packageName)
-			}
-		}
+// upgrade moves an outdated command to the newest version its package manager
+// offers, then checks the constraint again. Upgrades are not recorded in the
+// ledger: the package was there before allbctl touched it.
+func (i InstallableCommand) upgrade() (*model.Result, error) {
+	_, _, fullCommand, err := i.UpgradeCommand()
+	if err != nil {
+		result := model.NewResult(i.Name(), model.StatusError, installErrorHint(err))
+		return result, err
 	}
 
-	// Fallback to generic package name if available
-	if genericPackage, exists := i.LinuxPackages["generic"]; exists {
-		// Try common package managers with generic package name
-		for _, pm := range packageManagers {
-			if _, err := exec.LookPath(pm); err == nil {
-				return i.installWithPackageManager(out, pm, genericPackage)
-			}
-		}
+	output, err := runShellCommand(fullCommand)
+	if err != nil {
+		result := model.NewResult(i.Name(), model.StatusError, fmt.Sprintf("failed to upgrade %s: %s", i.CommandName, fullCommand))
+		result.Output = string(output)
+		return result, err
 	}
 
-	out.WriteString("❌ No supported package manager found for Linux\n")
-	return out, fmt.Errorf("no supported package manager found")
+	result, err := i.Validate()
+	result.Output = string(output)
+	if err != nil {
+		// The package manager has nothing newer than what is installed
+		result.WithHints(fmt.Sprintf("%s did not bring %s to %s", fullCommand, i.CommandName, i.Version))
+		return result, err
+	}
+	result.Message = fmt.Sprintf("upgraded %s: %s", i.CommandName, fullCommand)
+	return result, nil
 }
 
-func (i InstallableCommand) installOnMacOS(out *bytes.Buffer) (*bytes.Buffer, error) {
-	if i.MacOSPackage == "" {
-		out.WriteString("❌ No macOS package configured\n")
-		return out, fmt.Errorf("no macOS package configured for %s", i.CommandName)
+// ledger returns the ledger Install and Uninstall use
+func (i InstallableCommand) ledger() (*PackageLedger, error) {
+	if i.Ledger != nil {
+		return i.Ledger, nil
 	}
+	return DefaultPackageLedger()
+}
 
-	// Check for Homebrew
-	if _, err := exec.LookPath("brew"); err == nil {
-		return i.installWithPackageManager(out, "brew install", i.MacOSPackage)
-	}
+// linuxPackageManagers are tried in order of preference
+var linuxPackageManagers = []string{"apt", "dnf", "yum", "pacman", "zypper", "apk"}
+
+// windowsPackageManagers are tried in order of preference
+var windowsPackageManagers = []string{"winget", "choco", "scoop"}
+
+var (
+	errNoLinuxPackageManager   = fmt.Errorf("no supported package manager found")
+	errNoWindowsPackageManager = fmt.Errorf("no supported package manager found for Windows (winget, choco, scoop)")
+	errHomebrewNotAvailable    = fmt.Errorf("homebrew not available")
+)
+
+// InstallCommand resolves which package manager and package Install would use
+// on this machine, and the full shell command it would run.
+func (i InstallableCommand) InstallCommand() (manager, packageName, fullCommand string, err error) {
+	return i.resolveInstallCommand(runtime.GOOS, os.Geteuid() == 0, commandAvailable)
+}
 
-	out.WriteString("❌ Homebrew not found. Please install Homebrew first: https://brew.sh/\n")
-	return out, fmt.Errorf("homebrew not available")
+// UpgradeCommand resolves the package manager command that upgrades the
+// command's package on this machine
+func (i InstallableCommand) UpgradeCommand() (manager, packageName, fullCommand string, err error) {
+	return i.resolveUpgradeCommand(runtime.GOOS, os.Geteuid() == 0, commandAvailable)
 }
 
-func (i InstallableCommand) installOnWindows(out *bytes.Buffer) (*bytes.Buffer, error) {
-	// Try package managers in order of preference
-	packageManagers := []struct {
-		command string
-		lookup  string
-	}{
-		{"winget install", "winget"},
-		{"choco install", "choco"},
-		{"scoop install", "scoop"},
+func commandAvailable(name string) bool {
+	return runner.Available(context.Background(), name)
+}
+
+func (i InstallableCommand) resolveInstallCommand(goos string, isRoot bool, available func(string) bool) (manager, packageName, fullCommand string, err error) {
+	if manager, packageName, err = i.resolvePackage(goos, available); err != nil {
+		return "", "", "", err
 	}
+	fullCommand, err = packageCommand(pkgmgr.PackageManager.Install, manager, packageName, isRoot, available)
+	return manager, packageName, fullCommand, err
+}
 
-	for _, pm := range packageManagers {
-		if _, err := exec.LookPath(pm.lookup); err == nil {
-			if packageName, exists := i.WindowsPackages[pm.lookup]; exists {
-				return i.installWithPackageManager(out, pm.command, packageName)
+func (i InstallableCommand) resolveUpgradeCommand(goos string, isRoot bool, available func(string) bool) (manager, packageName, fullCommand string, err error) {
+	if manager, packageName, err = i.resolvePackage(goos, available); err != nil {
+		return "", "", "", err
+	}
+	fullCommand, err = packageCommand(pkgmgr.PackageManager.Upgrade, manager, packageName, isRoot, available)
+	return manager, packageName, fullCommand, err
+}
+
+// resolvePackage picks the package manager and package that provide the command on goos
+func (i InstallableCommand) resolvePackage(goos string, available func(string) bool) (manager, packageName string, err error) {
+	switch goos {
+	case "linux":
+		// Prefer a package manager with an explicit package name, then the generic name
+		for _, pm := range linuxPackageManagers {
+			if pkg, exists := i.LinuxPackages[pm]; exists && available(pm) {
+				return pm, pkg, nil
+			}
+		}
+		if pkg, exists := i.LinuxPackages["generic"]; exists {
+			for _, pm := range linuxPackageManagers {
+				if available(pm) {
+					return pm, pkg, nil
+				}
+			}
+		}
+		return "", "", errNoLinuxPackageManager
+	case "darwin":
+		if i.MacOSPackage == "" {
+			return "", "", fmt.Errorf("no macOS package configured for %s", i.CommandName)
+		}
+		if !available("brew") {
+			return "", "", errHomebrewNotAvailable
+		}
+		return "brew", i.MacOSPackage, nil
+	case "windows":
+		for _, pm := range windowsPackageManagers {
+			if pkg, exists := i.WindowsPackages[pm]; exists && available(pm) {
+				return pm, pkg, nil
 			}
 		}
+		return "", "", errNoWindowsPackageManager
+	default:
+		return "", "", fmt.Errorf("unsupported operating system: %s", goos)
 	}
+}
 
-	out.WriteString("❌ No supported package manager found for Windows (winget, choco, scoop)\n")
-	return out, fmt.Errorf("no supported package manager found")
+// packageCommand builds the command that runs a package manager action on
+// pkg, using sudo for the managers that need root when not running as root
+func packageCommand(action func(pkgmgr.PackageManager, string) (pkgmgr.Command, error), manager, pkg string, isRoot bool, available func(string) bool) (string, error) {
+	pm, ok := pkgmgr.Get(manager)
+	if !ok {
+		return "", fmt.Errorf("unsupported package manager: %s", manager)
+	}
+	command, err := action(pm, pkg)
+	if err != nil {
+		return "", err
+	}
+	return command.Line(pkgmgr.Env{Root: isRoot, Available: available}), nil
 }
 
-func (i InstallableCommand) installWithPackageManager(out *bytes.Buffer, pmCommand, packageName string) (*bytes.Buffer, error) {
-	// Add non-interactive flags for package managers that require them
-	fullCommand := fmt.Sprintf("%s %s", pmCommand, packageName)
+// uninstallCommand builds the command that removes pkg through the manager that installed it
+func uninstallCommand(manager, pkg string, isRoot bool, available func(string) bool) (string, error) {
+	return packageCommand(pkgmgr.PackageManager.Remove, manager, pkg, isRoot, available)
+}
 
-	// winget requires --accept-source-agreements to avoid interactive prompts
-	if strings.HasPrefix(pmCommand, "winget") {
-		fullCommand = fmt.Sprintf("%s --accept-source-agreements %s", pmCommand, packageName)
+// installErrorHint turns a resolve error into the message shown to the user
+func installErrorHint(err error) string {
+	switch err {
+	case errNoLinuxPackageManager:
+		return "No supported package manager found for Linux"
+	case errHomebrewNotAvailable:
+		return "Homebrew not found. Please install Homebrew first: https://brew.sh/"
+	case errNoWindowsPackageManager:
+		return "No supported package manager found for Windows (winget, choco, scoop)"
+	default:
+		return err.Error()
 	}
+}
 
-	out.WriteString(fmt.Sprintf("Using: %s\n", fullCommand))
+// Plan reports the package install or upgrade Install would run, or nothing
+// when the command is on PATH at an acceptable version
+func (i InstallableCommand) Plan() ([]model.PlannedAction, error) {
+	if runner.Available(context.Background(), i.CommandName) {
+		return i.planUpgrade()
+	}
 
-	// Split the command for exec
-	var cmd *exec.Cmd
-	if runtime.GOOS == "windows" {
-		cmd = exec.Command("cmd", "/c", fullCommand)
-	} else {
-		cmd = exec.Command("sh", "-c", fullCommand)
+	manager, pkg, fullCommand, err := i.InstallCommand()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", i.Name(), err)
+	}
+	return []model.PlannedAction{{
+		Type:        model.ActionInstallPackage,
+		Config:      i.Name(),
+		Description: fmt.Sprintf("install %s via %s (provides %s)", pkg, manager, i.CommandName),
+		Target:      pkg,
+		Manager:     manager,
+		Provides:    i.CommandName,
+		Command:     fullCommand,
+	}}, nil
+}
+
+func (i InstallableCommand) runInstallCommand(fullCommand string) (*model.Result, error) {
+	output, err := runShellCommand(fullCommand)
+	if err != nil {
+		result := model.NewResult(i.Name(), model.StatusError, fmt.Sprintf("failed to install %s: %s", i.CommandName, fullCommand))
+		result.Output = string(output)
+		return result, err
+	}
+
+	result := model.NewResult(i.Name(), model.StatusOK, fmt.Sprintf("installed %s: %s", i.CommandName, fullCommand))
+	result.Output = string(output)
+	return result, nil
+}
+
+// Uninstall removes the package only when the ledger shows allbctl installed
+// it, using the same package manager. Anything else is left in place.
+func (i InstallableCommand) Uninstall() (*model.Result, error) {
+	ledger, err := i.ledger()
+	var entry LedgerEntry
+	var found bool
+	if err == nil {
+		entry, found, err = ledger.Find(i.installedBy)
+	}
+	if err != nil {
+		result := model.NewResult(i.Name(), model.StatusError, err.Error())
+		return result, err
+	}
+	if !found {
+		return model.NewResult(i.Name(), model.StatusSkipped, fmt.Sprintf("%s was not installed by allbctl; leaving it in place", i.CommandName)), nil
 	}
 
-	output, err := cmd.CombinedOutput()
-	out.WriteString(string(output))
+	fullCommand, err := uninstallCommand(entry.Manager, entry.Package, os.Geteuid() == 0, commandAvailable)
+	if err != nil {
+		result := model.NewResult(i.Name(), model.StatusError, err.Error())
+		return result, err
+	}
 
+	output, err := runShellCommand(fullCommand)
 	if err != nil {
-		_, _ = color.New(color.FgRed).Fprintf(out, "❌ Failed to install %s\n", i.CommandName)
-		return out, err
+		result := model.NewResult(i.Name(), model.StatusError, fmt.Sprintf("failed to uninstall %s: %s", entry.Package, fullCommand))
+		result.Output = string(output)
+		return result, err
+	}
+
+	result := model.NewResult(i.Name(), model.StatusOK, fmt.Sprintf("uninstalled %s: %s", entry.Package, fullCommand))
+	result.Output = string(output)
+	if err := ledger.Remove(entry.Manager, entry.Package); err != nil {
+		result.WithHints(err.Error())
 	}
+	return result, nil
+}
 
-	_, _ = color.New(color.FgGreen).Fprintf(out, "✅ Successfully installed %s\n", i.CommandName)
-	return out, nil
+// installedBy reports whether a ledger entry is this command's package. Plan
+// files record only manager and package, so both are matched as well as the command.
+func (i InstallableCommand) installedBy(entry LedgerEntry) bool {
+	if entry.Command == i.CommandName {
+		return true
+	}
+	switch {
+	case entry.Manager == "brew":
+		return entry.Package == i.MacOSPackage
+	case i.WindowsPackages[entry.Manager] != "":
+		return entry.Package == i.WindowsPackages[entry.Manager]
+	case i.LinuxPackages[entry.Manager] != "":
+		return entry.Package == i.LinuxPackages[entry.Manager]
+	default:
+		return entry.Package == i.LinuxPackages["generic"]
+	}
+}
+
+// runShellCommand runs a package manager command through the platform shell
+func runShellCommand(fullCommand string) ([]byte, error) {
+	if runtime.GOOS == "windows" {
+		return runner.CombinedOutput(context.Background(), "cmd", "/c", fullCommand)
+	}
+	return runner.CombinedOutput(context.Background(), "sh", "-c", fullCommand)
 }
 
-func (i InstallableCommand) Uninstall() (*bytes.Buffer, error) {
-	out := bytes.NewBufferString("")
-	out.WriteString(fmt.Sprintf("❌ Cannot auto-uninstall %s - please uninstall manually\n", i.CommandName))
-	return out, nil
+// planUpgrade reports the upgrade Install would run for an outdated command
+func (i InstallableCommand) planUpgrade() ([]model.PlannedAction, error) {
+	result, err := i.Validate()
+	if err == nil {
+		return nil, nil
+	}
+	if result.Status != model.StatusOutdated {
+		return nil, fmt.Errorf("%s: %w", i.Name(), err)
+	}
+
+	manager, pkg, fullCommand, err := i.UpgradeCommand()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", i.Name(), err)
+	}
+	return []model.PlannedAction{{
+		Type:        model.ActionUpgradePackage,
+		Config:      i.Name(),
+		Description: fmt.Sprintf("upgrade %s via %s (%s)", pkg, manager, result.Message),
+		Target:      pkg,
+		Manager:     manager,
+		Command:     fullCommand,
+	}}, nil
 }
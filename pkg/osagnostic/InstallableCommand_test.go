@@ -1,9 +1,13 @@
 package osagnostic
 
 import (
+	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
+
+	"github.com/aallbrig/allbctl/pkg/model"
 )
 
 func TestInstallableCommand_WindowsWingetCommand(t *testing.T) {
@@ -22,8 +26,8 @@ func TestInstallableCommand_WindowsWingetCommand(t *testing.T) {
 	t.Logf("Testing that winget install commands include %s flag", expectedFlags)
 
 	// Verify that our fix includes the necessary flag for non-interactive execution
-	// The actual command construction happens in installWithPackageManager
-	// which adds the flag when pmCommand starts with "winget"
+	// The actual command construction happens in resolveInstallCommand
+	// which adds the flag for winget
 	if ic.WindowsPackages["winget"] != "Git.Git" {
 		t.Error("Expected Windows package to be set")
 	}
@@ -59,3 +63,239 @@ func TestInstallableCommand_SetPackages(t *testing.T) {
 		t.Error("Failed to set Windows package")
 	}
 }
+
+func TestInstallableCommand_ResolveInstallCommand(t *testing.T) {
+	ic := NewInstallableCommand("gh").
+		SetLinuxPackage("pacman", "github-cli").
+		SetLinuxPackage("generic", "gh").
+		SetMacOSPackage("gh").
+		SetWindowsPackage("choco", "gh").
+		SetWindowsPackage("winget", "GitHub.cli")
+
+	tests := []struct {
+		name        string
+		goos        string
+		isRoot      bool
+		available   []string
+		wantManager string
+		wantCommand string
+		wantErr     bool
+	}{
+		{"explicit linux package", "linux", true, []string{"pacman"}, "pacman", "pacman -S --noconfirm github-cli", false},
+		{"generic fallback", "linux", true, []string{"apt"}, "apt", "apt-get install -y gh", false},
+		{"sudo when not root", "linux", false, []string{"dnf", "sudo"}, "dnf", "sudo dnf install -y gh", false},
+		{"no sudo available", "linux", false, []string{"apk"}, "apk", "apk add gh", false},
+		{"no linux package manager", "linux", true, nil, "", "", true},
+		{"homebrew", "darwin", false, []string{"brew"}, "brew", "brew install gh", false},
+		{"no homebrew", "darwin", false, nil, "", "", true},
+		{"winget preferred", "windows", false, []string{"choco", "winget"}, "winget", "winget install --accept-source-agreements GitHub.cli", false},
+		{"choco", "windows", false, []string{"choco", "scoop"}, "choco", "choco install gh", false},
+		{"unsupported os", "plan9", false, nil, "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			available := func(name string) bool {
+				for _, a := range tt.available {
+					if a == name {
+						return true
+					}
+				}
+				return false
+			}
+			manager, _, command, err := ic.resolveInstallCommand(tt.goos, tt.isRoot, available)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %s %q", manager, command)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if manager != tt.wantManager || command != tt.wantCommand {
+				t.Errorf("got (%s, %q), want (%s, %q)", manager, command, tt.wantManager, tt.wantCommand)
+			}
+		})
+	}
+}
+
+func TestUninstallCommand(t *testing.T) {
+	available := func(name string) bool { return name == "sudo" }
+	tests := []struct {
+		manager string
+		isRoot  bool
+		want    string
+	}{
+		{"apt", false, "sudo apt-get remove -y gh"},
+		{"apt", true, "apt-get remove -y gh"},
+		{"dnf", true, "dnf remove -y gh"},
+		{"pacman", true, "pacman -R --noconfirm gh"},
+		{"apk", true, "apk del gh"},
+		{"brew", false, "brew uninstall gh"},
+		{"choco", false, "choco uninstall -y gh"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.manager, func(t *testing.T) {
+			got, err := uninstallCommand(tc.manager, "gh", tc.isRoot, available)
+			if err != nil || got != tc.want {
+				t.Errorf("uninstallCommand(%s) = %q, %v; want %q", tc.manager, got, err, tc.want)
+			}
+		})
+	}
+
+	if _, err := uninstallCommand("nix", "gh", true, available); err == nil {
+		t.Error("uninstallCommand() should fail for an unknown package manager")
+	}
+}
+
+func TestInstallableCommand_UninstallLeavesUnrecordedPackages(t *testing.T) {
+	ledger := NewPackageLedger(filepath.Join(t.TempDir(), "packages.json"))
+	if err := ledger.Record(LedgerEntry{Command: "other", Manager: "apt", Package: "other"}); err != nil {
+		t.Fatal(err)
+	}
+	ic := NewInstallableCommand("git").SetLinuxPackage("apt", "git")
+	ic.Ledger = ledger
+
+	result, err := ic.Uninstall()
+
+	if err != nil || result.Status != model.StatusSkipped {
+		t.Errorf("Uninstall() = %s, %v; want skipped for a package allbctl did not install", result.Status, err)
+	}
+}
+
+func TestInstallableCommand_InstalledBy(t *testing.T) {
+	ic := NewInstallableCommand("gh").
+		SetLinuxPackage("pacman", "github-cli").
+		SetLinuxPackage("generic", "gh").
+		SetMacOSPackage("gh").
+		SetWindowsPackage("winget", "GitHub.cli")
+
+	tests := []struct {
+		entry LedgerEntry
+		want  bool
+	}{
+		{LedgerEntry{Command: "gh", Manager: "apt", Package: "anything"}, true},
+		{LedgerEntry{Manager: "pacman", Package: "github-cli"}, true},
+		{LedgerEntry{Manager: "apt", Package: "gh"}, true},
+		{LedgerEntry{Manager: "brew", Package: "gh"}, true},
+		{LedgerEntry{Manager: "winget", Package: "GitHub.cli"}, true},
+		{LedgerEntry{Manager: "pacman", Package: "gh"}, false},
+		{LedgerEntry{Manager: "apt", Package: "git"}, false},
+	}
+	for _, tc := range tests {
+		if got := ic.installedBy(tc.entry); got != tc.want {
+			t.Errorf("installedBy(%+v) = %v, want %v", tc.entry, got, tc.want)
+		}
+	}
+}
+
+func TestInstallableCommand_ResolveUpgradeCommand(t *testing.T) {
+	ic := NewInstallableCommand("gh").
+		SetLinuxPackage("generic", "gh").
+		SetMacOSPackage("gh").
+		SetWindowsPackage("winget", "GitHub.cli")
+
+	tests := []struct {
+		goos        string
+		isRoot      bool
+		available   string
+		wantCommand string
+	}{
+		{"linux", false, "apt", "sudo apt-get install --only-upgrade -y gh"},
+		{"linux", true, "dnf", "dnf upgrade -y gh"},
+		{"darwin", false, "brew", "brew upgrade gh"},
+		{"windows", false, "winget", "winget upgrade --accept-source-agreements GitHub.cli"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.goos+"/"+tt.available, func(t *testing.T) {
+			available := func(name string) bool { return name == tt.available || name == "sudo" }
+			_, _, command, err := ic.resolveUpgradeCommand(tt.goos, tt.isRoot, available)
+			if err != nil || command != tt.wantCommand {
+				t.Errorf("resolveUpgradeCommand() = %q, %v; want %q", command, err, tt.wantCommand)
+			}
+		})
+	}
+}
+
+func TestVersionConstraint_SatisfiedBy(t *testing.T) {
+	tests := []struct {
+		constraint VersionConstraint
+		installed  string
+		want       bool
+	}{
+		{VersionConstraint{Minimum: "2.40"}, "2.40.1", true},
+		{VersionConstraint{Minimum: "2.40"}, "2.40", true},
+		{VersionConstraint{Minimum: "2.40"}, "2.4.0", false},
+		{VersionConstraint{Exact: "1.21.5"}, "1.21.5", true},
+		{VersionConstraint{Exact: "1.21.5"}, "1.22.0", false},
+		{VersionConstraint{}, "0.1", true},
+	}
+	for _, tt := range tests {
+		if got := tt.constraint.SatisfiedBy(tt.installed); got != tt.want {
+			t.Errorf("%s SatisfiedBy(%s) = %v, want %v", tt.constraint, tt.installed, got, tt.want)
+		}
+	}
+}
+
+// fakeCommand puts an executable named name on PATH that prints output
+func fakeCommand(t *testing.T, name, output string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake commands are shell scripts")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho '" + output + "'\n"
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestInstallableCommand_ValidateVersion(t *testing.T) {
+	fakeCommand(t, "allbctl-fake-gh", "gh version 2.4.0+dfsg1 (2022-03-23 Debian 2.4.0+dfsg1-2)")
+
+	tests := []struct {
+		name       string
+		constraint VersionConstraint
+		wantStatus model.Status
+		wantErr    bool
+	}{
+		{"minimum met", VersionConstraint{Minimum: "2.0"}, model.StatusOK, false},
+		{"minimum not met", VersionConstraint{Minimum: "2.40"}, model.StatusOutdated, true},
+		{"exact mismatch", VersionConstraint{Exact: "2.40.1"}, model.StatusOutdated, true},
+		{"custom parser", VersionConstraint{Minimum: "3", Parse: func(string) string { return "3.1" }}, model.StatusOK, false},
+		{"unparseable", VersionConstraint{Minimum: "1", Parse: func(string) string { return "" }}, model.StatusError, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ic := NewInstallableCommand("allbctl-fake-gh").SetVersionConstraint(tt.constraint)
+			result, err := ic.Validate()
+			if result.Status != tt.wantStatus || (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %s (%s), %v; want %s", result.Status, result.Message, err, tt.wantStatus)
+			}
+		})
+	}
+
+	result, _ := NewInstallableCommand("allbctl-fake-gh").SetVersionConstraint(VersionConstraint{Minimum: "2.40"}).Validate()
+	if !strings.Contains(result.Message, "2.4.0") || !strings.Contains(result.Message, ">= 2.40") {
+		t.Errorf("outdated message should name both versions, got %q", result.Message)
+	}
+}
+
+func TestInstallableCommand_ValidateInstallFix(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("resolves a Linux package manager")
+	}
+	fakeCommand(t, "apk", "")
+	ic := NewInstallableCommand("allbctl-missing-tool").SetLinuxPackage("apk", "allbctl-missing-pkg")
+
+	result, err := ic.Validate()
+	if err == nil || len(result.Fixes) != 1 {
+		t.Fatalf("Validate() = %+v, %v; want missing with an install fix", result, err)
+	}
+	want := model.PackageInstall{Command: "allbctl-missing-tool", Manager: "apk", Package: "allbctl-missing-pkg"}
+	if got := result.Fixes[0].Installs; got == nil || *got != want {
+		t.Errorf("install fix Installs = %+v, want %+v so 'allbctl fix' can ledger it", got, want)
+	}
+}
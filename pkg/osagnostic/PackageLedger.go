@@ -0,0 +1,139 @@
+package osagnostic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// LedgerEntry is a package allbctl installed
+type LedgerEntry struct {
+	Command     string    `json:"command,omitempty"` // command the package provides, when known
+	Manager     string    `json:"manager"`
+	Package     string    `json:"package"`
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// PackageLedger records the packages allbctl installed, so 'bootstrap reset'
+// removes exactly those and leaves anything installed by other means alone.
+type PackageLedger struct {
+	path string
+	mu   sync.Mutex
+}
+
+// DefaultPackageLedgerPath is where bootstrap keeps its ledger,
+// e.g. ~/.cache/allbctl/bootstrap/packages.json on Linux
+func DefaultPackageLedgerPath() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine cache directory: %w", err)
+	}
+	return filepath.Join(base, "allbctl", "bootstrap", "packages.json"), nil
+}
+
+// DefaultPackageLedger opens the ledger at DefaultPackageLedgerPath
+func DefaultPackageLedger() (*PackageLedger, error) {
+	path, err := DefaultPackageLedgerPath()
+	if err != nil {
+		return nil, err
+	}
+	return NewPackageLedger(path), nil
+}
+
+// NewPackageLedger creates a ledger stored at path. The file is created on the first Record.
+func NewPackageLedger(path string) *PackageLedger {
+	return &PackageLedger{path: path}
+}
+
+// Entries returns every recorded package, oldest first
+func (l *PackageLedger) Entries() ([]LedgerEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.load()
+}
+
+// Record adds a package, replacing any earlier entry for the same manager and package
+func (l *PackageLedger) Record(entry LedgerEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries, err := l.load()
+	if err != nil {
+		return err
+	}
+	entries = removeEntry(entries, entry.Manager, entry.Package)
+	return l.save(append(entries, entry))
+}
+
+// Remove forgets a package, e.g. after it has been uninstalled
+func (l *PackageLedger) Remove(manager, pkg string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries, err := l.load()
+	if err != nil {
+		return err
+	}
+	return l.save(removeEntry(entries, manager, pkg))
+}
+
+// Find returns the most recent entry that matches
+func (l *PackageLedger) Find(match func(LedgerEntry) bool) (LedgerEntry, bool, error) {
+	entries, err := l.Entries()
+	if err != nil {
+		return LedgerEntry{}, false, err
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if match(entries[i]) {
+			return entries[i], true, nil
+		}
+	}
+	return LedgerEntry{}, false, nil
+}
+
+func removeEntry(entries []LedgerEntry, manager, pkg string) []LedgerEntry {
+	kept := entries[:0]
+	for _, e := range entries {
+		if e.Manager != manager || e.Package != pkg {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+func (l *PackageLedger) load() ([]LedgerEntry, error) {
+	data, err := os.ReadFile(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read package ledger: %w", err)
+	}
+
+	var entries []LedgerEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("cannot parse package ledger %s: %w", l.path, err)
+	}
+	return entries, nil
+}
+
+func (l *PackageLedger) save(entries []LedgerEntry) error {
+	if entries == nil {
+		entries = []LedgerEntry{}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot marshal package ledger: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("cannot create package ledger directory: %w", err)
+	}
+	if err := os.WriteFile(l.path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("cannot write package ledger: %w", err)
+	}
+	return nil
+}
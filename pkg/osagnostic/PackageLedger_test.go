@@ -0,0 +1,42 @@
+package osagnostic
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestPackageLedger_RecordFindRemove(t *testing.T) {
+	ledger := NewPackageLedger(filepath.Join(t.TempDir(), "bootstrap", "packages.json"))
+
+	entries, err := ledger.Entries()
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Entries() on a new ledger = %v, %v; want empty", entries, err)
+	}
+
+	for _, e := range []LedgerEntry{
+		{Command: "gh", Manager: "apt", Package: "gh"},
+		{Command: "jq", Manager: "apt", Package: "jq"},
+		{Command: "gh", Manager: "apt", Package: "gh"}, // re-install replaces the entry
+	} {
+		if err := ledger.Record(e); err != nil {
+			t.Fatalf("Record(%+v): %v", e, err)
+		}
+	}
+
+	entries, _ = ledger.Entries()
+	if len(entries) != 2 {
+		t.Errorf("Entries() = %+v, want 2 entries", entries)
+	}
+
+	entry, found, err := ledger.Find(func(e LedgerEntry) bool { return e.Command == "jq" })
+	if err != nil || !found || entry.Package != "jq" {
+		t.Errorf("Find(jq) = %+v, %v, %v", entry, found, err)
+	}
+
+	if err := ledger.Remove("apt", "jq"); err != nil {
+		t.Fatal(err)
+	}
+	if _, found, _ := ledger.Find(func(e LedgerEntry) bool { return e.Command == "jq" }); found {
+		t.Error("Find(jq) after Remove should not find it")
+	}
+}
@@ -1,14 +1,15 @@
 package osagnostic
 
 import (
-	"bytes"
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
-	"github.com/fatih/color"
+	"github.com/aallbrig/allbctl/pkg/model"
+	"github.com/aallbrig/allbctl/pkg/pkgmgr"
+	"github.com/aallbrig/allbctl/pkg/runner"
 )
 
 type SSHKeyGitHubRegistration struct {
@@ -27,130 +28,123 @@ func (s SSHKeyGitHubRegistration) Name() string {
 	return "SSH Key GitHub Registration"
 }
 
-func (s SSHKeyGitHubRegistration) Validate() (out *bytes.Buffer, err error) {
-	out = bytes.NewBufferString("")
+// DependsOn reports that registration needs the GitHub CLI
+func (s SSHKeyGitHubRegistration) DependsOn() []string {
+	return []string{NewInstallableCommand("gh").Name()}
+}
+
+func (s SSHKeyGitHubRegistration) Validate() (*model.Result, error) {
+	result := s.check()
+	return result, result.Err()
+}
 
+func (s SSHKeyGitHubRegistration) check() *model.Result {
 	// Check if SSH key exists
 	if _, statErr := os.Stat(s.KeyPath); os.IsNotExist(statErr) {
-		_, _ = color.New(color.FgRed).Fprint(out, "SSH KEY NOT FOUND")
-		out.WriteString(fmt.Sprintf(" %s", s.KeyPath))
-		err = statErr
-		return
+		return model.NewResult(s.Name(), model.StatusMissing, fmt.Sprintf("SSH key not found: %s", s.KeyPath)).
+			WithFix("generate an SSH key and register it with GitHub", "allbctl bootstrap install --register-ssh-keys")
 	}
 
 	// Check if GitHub CLI is available
-	if _, ghErr := exec.LookPath("gh"); ghErr != nil {
-		_, _ = color.New(color.FgRed).Fprint(out, "GITHUB CLI NOT FOUND")
-		out.WriteString(" (required for SSH key registration)")
-		err = ghErr
-		return
+	if !runner.Available(context.Background(), "gh") {
+		return model.NewResult(s.Name(), model.StatusError, "GitHub CLI not found (required for SSH key registration)").
+			WithHints("install the GitHub CLI: https://cli.github.com/")
 	}
 
 	// Check if SSH key is already registered
 	keyContent, readErr := os.ReadFile(s.KeyPath)
 	if readErr != nil {
-		_, _ = color.New(color.FgRed).Fprint(out, "CANNOT READ SSH KEY")
-		err = readErr
-		return
+		return model.NewResult(s.Name(), model.StatusError, fmt.Sprintf("cannot read SSH key: %v", readErr))
 	}
 
 	// Extract just the key part (without comment)
 	keyParts := strings.Fields(string(keyContent))
 	if len(keyParts) < 2 {
-		_, _ = color.New(color.FgRed).Fprint(out, "INVALID SSH KEY FORMAT")
-		err = fmt.Errorf("invalid SSH key format")
-		return
+		return model.NewResult(s.Name(), model.StatusError, fmt.Sprintf("invalid SSH key format: %s", s.KeyPath))
 	}
 	publicKey := keyParts[1]
 
 	// List registered SSH keys (this implicitly checks auth status)
-	cmd := exec.Command("gh", "ssh-key", "list")
-	output, listErr := cmd.CombinedOutput() // Use CombinedOutput to capture stderr warnings
+	output, listErr := runner.CombinedOutput(context.Background(), "gh", "ssh-key", "list") // Use CombinedOutput to capture stderr warnings
 	if listErr != nil {
 		// If listing fails, it's likely due to auth issues
 		if strings.Contains(listErr.Error(), "exit status") {
-			_, _ = color.New(color.FgYellow).Fprint(out, "NOT AUTHENTICATED WITH GITHUB CLI")
-		} else {
-			_, _ = color.New(color.FgRed).Fprint(out, "CANNOT LIST GITHUB SSH KEYS")
+			return model.NewResult(s.Name(), model.StatusError, "not authenticated with GitHub CLI").
+				WithFix("log in to GitHub", "gh auth login")
 		}
-		err = listErr
-		return
+		return model.NewResult(s.Name(), model.StatusError, fmt.Sprintf("cannot list GitHub SSH keys: %v", listErr))
 	}
 
 	if strings.Contains(string(output), publicKey) {
-		_, _ = color.New(color.FgGreen).Fprint(out, "SSH KEY REGISTERED WITH GITHUB")
-	} else {
-		_, _ = color.New(color.FgYellow).Fprint(out, "SSH KEY NOT REGISTERED WITH GITHUB")
-		err = fmt.Errorf("SSH key not registered")
+		return model.NewResult(s.Name(), model.StatusOK, fmt.Sprintf("%s registered with GitHub", s.KeyPath))
 	}
-
-	return
+	return model.NewResult(s.Name(), model.StatusMissing, fmt.Sprintf("%s not registered with GitHub", s.KeyPath)).
+		WithFix("register the SSH key with GitHub", fmt.Sprintf("gh ssh-key add %s", pkgmgr.ShellQuote(s.KeyPath)))
 }
 
-func (s SSHKeyGitHubRegistration) Install() (*bytes.Buffer, error) {
-	out := bytes.NewBufferString("")
+func (s SSHKeyGitHubRegistration) Install() (*model.Result, error) {
+	var steps []*model.Result
 
 	// Generate SSH key if it doesn't exist
 	if _, statErr := os.Stat(s.KeyPath); os.IsNotExist(statErr) {
-		out.WriteString("Generating SSH key...\n")
-
 		keyDir := filepath.Dir(s.KeyPath)
 		if err := os.MkdirAll(keyDir, 0700); err != nil {
-			_, _ = color.New(color.FgRed).Fprintf(out, "❌ Failed to create .ssh directory: %v\n", err)
-			return out, err
+			result := model.NewResult(s.Name(), model.StatusError, fmt.Sprintf("failed to create .ssh directory: %v", err))
+			return result, err
 		}
 
 		privateKeyPath := strings.TrimSuffix(s.KeyPath, ".pub")
-		cmd := exec.Command("ssh-keygen", "-t", "rsa", "-b", "4096", "-f", privateKeyPath, "-N", "")
-		if keyGenErr := cmd.Run(); keyGenErr != nil {
-			_, _ = color.New(color.FgRed).Fprintf(out, "❌ Failed to generate SSH key: %v\n", keyGenErr)
-			return out, keyGenErr
+		if keyGenErr := runner.Run(context.Background(), "ssh-keygen", "-t", "rsa", "-b", "4096", "-f", privateKeyPath, "-N", ""); keyGenErr != nil {
+			result := model.NewResult(s.Name(), model.StatusError, fmt.Sprintf("failed to generate SSH key: %v", keyGenErr))
+			return result, keyGenErr
 		}
-
-		_, _ = color.New(color.FgGreen).Fprint(out, "✅ SSH key generated\n")
+		steps = append(steps, model.NewResult("ssh-keygen", model.StatusOK, fmt.Sprintf("generated %s", s.KeyPath)))
 	}
 
 	// Check validation again
-	validateOut, validationErr := s.Validate()
-	if validateOut != nil {
-		out.WriteString(validateOut.String() + "\n")
-	}
-
-	if validationErr != nil && strings.Contains(validationErr.Error(), "not registered") {
-		// Register the SSH key with GitHub
-		out.WriteString("Registering SSH key with GitHub...\n")
-
-		// Get hostname for key title
+	validation := s.check()
+	switch validation.Status {
+	case model.StatusOK:
+		steps = append(steps, validation)
+	case model.StatusMissing:
+		// The key exists now, so missing means not registered: register it with GitHub
 		hostname, hostnameErr := os.Hostname()
 		if hostnameErr != nil || hostname == "" {
 			hostname = "allbctl-generated"
 		}
 
-		cmd := exec.Command("gh", "ssh-key", "add", s.KeyPath, "--title", fmt.Sprintf("allbctl-%s", hostname))
-		output, addErr := cmd.CombinedOutput()
-		out.WriteString(string(output))
-
+		output, addErr := runner.CombinedOutput(context.Background(), "gh", "ssh-key", "add", s.KeyPath, "--title", fmt.Sprintf("allbctl-%s", hostname))
+		step := model.NewResult("gh ssh-key add", model.StatusOK, fmt.Sprintf("registered %s with GitHub", s.KeyPath))
 		if addErr != nil {
-			_, _ = color.New(color.FgRed).Fprintf(out, "❌ Failed to register SSH key: %v\n", addErr)
-			return out, addErr
+			step = model.NewResult("gh ssh-key add", model.StatusError, fmt.Sprintf("failed to register SSH key: %v", addErr))
 		}
-
-		_, _ = color.New(color.FgGreen).Fprint(out, "✅ SSH key registered with GitHub\n")
-	} else if validationErr != nil && strings.Contains(validationErr.Error(), "NOT AUTHENTICATED") {
-		out.WriteString("⚠️  Please authenticate with GitHub CLI first:\n")
-		out.WriteString("   gh auth login\n")
-		return out, validationErr
-	} else if validationErr != nil && strings.Contains(validationErr.Error(), "GITHUB CLI NOT FOUND") {
-		out.WriteString("❌ GitHub CLI is required for SSH key registration\n")
-		out.WriteString("Please install GitHub CLI first\n")
-		return out, validationErr
+		step.Output = string(output)
+		steps = append(steps, step)
+	default:
+		// Not authenticated, no gh, unreadable key: the hints say what to do
+		steps = append(steps, validation)
 	}
 
-	return out, nil
+	return model.NewGroupResult(s.Name(), steps)
+}
+
+// Plan reports the key generation and registration Install would perform
+func (s SSHKeyGitHubRegistration) Plan() ([]model.PlannedAction, error) {
+	if _, err := s.Validate(); err == nil {
+		return nil, nil
+	}
+	description := fmt.Sprintf("register SSH key %s with GitHub", s.KeyPath)
+	if _, statErr := os.Stat(s.KeyPath); os.IsNotExist(statErr) {
+		description = fmt.Sprintf("generate SSH key %s and register it with GitHub", s.KeyPath)
+	}
+	return []model.PlannedAction{{
+		Type:        model.ActionRegisterSSHKey,
+		Config:      s.Name(),
+		Description: description,
+		Target:      s.KeyPath,
+	}}, nil
 }
 
-func (s SSHKeyGitHubRegistration) Uninstall() (*bytes.Buffer, error) {
-	out := bytes.NewBufferString("")
-	out.WriteString("❌ Cannot auto-uninstall SSH key registration - please remove manually from GitHub\n")
-	return out, nil
+func (s SSHKeyGitHubRegistration) Uninstall() (*model.Result, error) {
+	return model.NewResult(s.Name(), model.StatusSkipped, "cannot auto-uninstall SSH key registration - please remove manually from GitHub"), nil
 }
@@ -2,11 +2,13 @@ package osagnostic
 
 import (
 	"bufio"
+	"context"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/aallbrig/allbctl/pkg/runner"
 )
 
 type ShellConfigToolChecker struct {
@@ -191,6 +193,5 @@ func (c *ShellConfigToolChecker) extractToolsFromLine(line string) []string {
 }
 
 func (c *ShellConfigToolChecker) isToolAvailable(tool string) bool {
-	_, err := exec.LookPath(tool)
-	return err == nil
+	return runner.Available(context.Background(), tool)
 }
@@ -1,13 +1,12 @@
 package osagnostic
 
 import (
-	"bytes"
 	"fmt"
 	"os"
 	"sort"
 	"strings"
 
-	"github.com/fatih/color"
+	"github.com/aallbrig/allbctl/pkg/model"
 )
 
 type ShellConfigTools struct {
@@ -24,14 +23,11 @@ func (s *ShellConfigTools) Name() string {
 	return "Shell Config Tools"
 }
 
-func (s *ShellConfigTools) Validate() (*bytes.Buffer, error) {
-	out := bytes.NewBufferString("")
-
+func (s *ShellConfigTools) Validate() (*model.Result, error) {
 	tools := s.checker.ExtractTools()
 
 	if len(tools) == 0 {
-		out.WriteString("No tools found in shell config files\n")
-		return out, nil
+		return model.NewResult(s.Name(), model.StatusOK, "No tools found in shell config files"), nil
 	}
 
 	// Group tools by source file
@@ -48,9 +44,9 @@ func (s *ShellConfigTools) Validate() (*bytes.Buffer, error) {
 	sort.Strings(files)
 
 	homeDir := os.Getenv("HOME")
-	hasErrors := false
+	var fileResults []*model.Result
+	missingCount := 0
 
-	// Output grouped by file
 	for _, file := range files {
 		// Replace home directory with $HOME for display
 		displayPath := file
@@ -58,43 +54,47 @@ func (s *ShellConfigTools) Validate() (*bytes.Buffer, error) {
 			displayPath = "$HOME" + strings.TrimPrefix(file, homeDir)
 		}
 
-		out.WriteString(fmt.Sprintf("%s:\n", displayPath))
-
 		// Sort tools within each file
 		fileTools := toolsByFile[file]
 		sort.Slice(fileTools, func(i, j int) bool {
 			return fileTools[i].Tool < fileTools[j].Tool
 		})
 
+		var toolResults []*model.Result
 		for _, tool := range fileTools {
-			if tool.Available {
-				color.New(color.FgGreen).Fprint(out, "INSTALLED")
-				out.WriteString(fmt.Sprintf(" %s\n", tool.Tool))
-			} else {
-				color.New(color.FgRed).Fprint(out, "MISSING")
-				out.WriteString(fmt.Sprintf("   %s\n", tool.Tool))
-				hasErrors = true
-			}
-		}
-	}
-
-	if hasErrors {
-		// Count missing tools
-		missingCount := 0
-		for _, tool := range tools {
+			toolResult := model.NewResult(tool.Tool, model.StatusOK, "available")
 			if !tool.Available {
+				toolResult = model.NewResult(tool.Tool, model.StatusMissing, "not available")
+				if manager, pkg, command, err := shellToolInstaller(tool.Tool).InstallCommand(); err == nil {
+					toolResult.WithInstallFix(fmt.Sprintf("install %s with %s", tool.Tool, manager), command,
+						model.PackageInstall{Command: tool.Tool, Manager: manager, Package: pkg})
+				}
 				missingCount++
 			}
+			toolResults = append(toolResults, toolResult)
 		}
-		return out, fmt.Errorf("%d tool(s) referenced in shell config are not available", missingCount)
+		fileResult, _ := model.NewGroupResult(displayPath, toolResults)
+		fileResults = append(fileResults, fileResult)
 	}
 
-	return out, nil
+	result, _ := model.NewGroupResult(s.Name(), fileResults)
+	result.Message = "All shell config tools are available"
+	if missingCount > 0 {
+		result.Message = fmt.Sprintf("%d tool(s) referenced in shell config are not available", missingCount)
+		result.WithHints("install the missing tools with your package manager")
+	}
+	return result, result.Err()
 }
 
-func (s *ShellConfigTools) Install() (*bytes.Buffer, error) {
-	out := bytes.NewBufferString("")
+// shellToolInstaller guesses how to install a tool from shell config: most
+// are packaged under their command name
+func shellToolInstaller(tool string) *InstallableCommand {
+	return NewInstallableCommand(tool).
+		SetLinuxPackage("generic", tool).
+		SetMacOSPackage(tool)
+}
 
+func (s *ShellConfigTools) Install() (*model.Result, error) {
 	tools := s.checker.ExtractTools()
 	var missingTools []string
 
@@ -105,19 +105,33 @@ func (s *ShellConfigTools) Install() (*bytes.Buffer, error) {
 	}
 
 	if len(missingTools) == 0 {
-		out.WriteString("All shell config tools are available\n")
-		return out, nil
+		return model.NewResult(s.Name(), model.StatusOK, "All shell config tools are available"), nil
 	}
 
-	out.WriteString("The following tools are referenced in your shell config but not installed:\n")
-	out.WriteString(fmt.Sprintf("  %s\n", strings.Join(missingTools, ", ")))
-	out.WriteString("\nPlease install them manually using your package manager.\n")
+	result := model.NewResult(s.Name(), model.StatusError, "cannot automatically install shell config tools").
+		WithHints(fmt.Sprintf("install manually using your package manager: %s", strings.Join(missingTools, ", ")))
+	return result, result.Err()
+}
 
-	return out, fmt.Errorf("cannot automatically install shell config tools")
+// Plan reports a manual install step for each missing tool
+func (s *ShellConfigTools) Plan() ([]model.PlannedAction, error) {
+	var actions []model.PlannedAction
+	seen := make(map[string]bool)
+	for _, tool := range s.checker.ExtractTools() {
+		if tool.Available || seen[tool.Tool] {
+			continue
+		}
+		seen[tool.Tool] = true
+		actions = append(actions, model.PlannedAction{
+			Type:        model.ActionManual,
+			Config:      s.Name(),
+			Description: fmt.Sprintf("install %s (referenced in %s)", tool.Tool, tool.Source),
+			Target:      tool.Tool,
+		})
+	}
+	return actions, nil
 }
 
-func (s *ShellConfigTools) Uninstall() (*bytes.Buffer, error) {
-	out := bytes.NewBufferString("")
-	out.WriteString("Shell config tools checker does not support uninstall\n")
-	return out, nil
+func (s *ShellConfigTools) Uninstall() (*model.Result, error) {
+	return model.NewResult(s.Name(), model.StatusSkipped, "Shell config tools checker does not support uninstall"), nil
 }
@@ -0,0 +1,30 @@
+package pkgmgr
+
+import "context"
+
+func init() {
+	register(&manager{
+		name:     "apk",
+		category: System,
+		goos:     "linux",
+		listArgs: []string{"info"},
+		pending: func(ctx context.Context, m *manager) (int, error) {
+			out, err := output(ctx, "apk", "version", "-l", "<")
+			if err != nil {
+				return 0, err
+			}
+			return countLines(out, 1), nil // "Installed: Available:"
+		},
+		update: &UpdatePlan{
+			Description: "Update apk indexes and upgrade all packages",
+			Commands: []Command{
+				{Args: []string{"apk", "update"}, Sudo: true},
+				{Args: []string{"apk", "upgrade"}, Sudo: true},
+			},
+		},
+		sudo:    true,
+		install: []string{"add"},
+		upgrade: []string{"add", "--upgrade"},
+		remove:  []string{"del"},
+	})
+}
@@ -0,0 +1,39 @@
+package pkgmgr
+
+import "context"
+
+func init() {
+	register(&manager{
+		name:     "apt",
+		category: System,
+		goos:     "linux",
+		bins:     []string{"apt-get"},
+		// Only manually installed packages, not the dependencies they pulled in
+		list: func(ctx context.Context, m *manager) ([]string, error) {
+			out, err := output(ctx, "apt-mark", "showmanual")
+			return firstFields(string(out)), err
+		},
+		listCommand: "apt-mark showmanual",
+		recentArgs:  dpkgLogTail,
+		parseRecent: parseDpkgLog,
+		// Reads the existing package cache; refreshing it needs root
+		pending: func(ctx context.Context, m *manager) (int, error) {
+			out, err := output(ctx, "apt", "list", "--upgradable")
+			if err != nil {
+				return 0, err
+			}
+			return countLines(out, 1), nil // "Listing..."
+		},
+		update: &UpdatePlan{
+			Description: "Update apt package lists and upgrade all packages",
+			Commands: []Command{
+				{Args: []string{"apt-get", "update"}, Sudo: true},
+				{Args: []string{"apt-get", "upgrade", "-y"}, Sudo: true},
+			},
+		},
+		sudo:    true,
+		install: []string{"install", "-y"},
+		upgrade: []string{"install", "--only-upgrade", "-y"},
+		remove:  []string{"remove", "-y"},
+	})
+}
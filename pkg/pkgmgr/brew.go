@@ -0,0 +1,48 @@
+package pkgmgr
+
+import (
+	"context"
+	"strings"
+)
+
+func init() {
+	register(&manager{
+		name:     "brew",
+		category: System,
+		goos:     "darwin",
+		// "Homebrew 4.0.0"
+		parseVersion: func(output string) string {
+			return strings.TrimPrefix(versionNumber(output), "Homebrew ")
+		},
+		// Top-level formulae and casks, not dependencies
+		list: func(ctx context.Context, m *manager) ([]string, error) {
+			formulae, err := output(ctx, "brew", "leaves")
+			if err != nil {
+				return nil, err
+			}
+			casks, err := output(ctx, "brew", "list", "--cask")
+			if err != nil {
+				return nil, err
+			}
+			return firstFields(string(formulae) + "\n" + string(casks)), nil
+		},
+		listCommand: "brew leaves && brew list --cask",
+		pending: func(ctx context.Context, m *manager) (int, error) {
+			out, err := output(ctx, "brew", "outdated")
+			if err != nil {
+				return 0, err
+			}
+			return countLines(out, 0), nil
+		},
+		update: &UpdatePlan{
+			Description: "Update Homebrew and upgrade all formulae and casks",
+			Commands: []Command{
+				{Args: []string{"brew", "update"}},
+				{Args: []string{"brew", "upgrade"}},
+			},
+		},
+		install: []string{"install"},
+		upgrade: []string{"upgrade"},
+		remove:  []string{"uninstall"},
+	})
+}
@@ -0,0 +1,25 @@
+package pkgmgr
+
+import "strings"
+
+// cargo has no way to upgrade everything it installed
+func init() {
+	register(&manager{
+		name:     "cargo",
+		category: Runtime,
+		listArgs: []string{"install", "--list"},
+		// "crate v1.0.0:" lines with the crate's binaries indented below
+		parseList: func(output string) []string {
+			var names []string
+			for _, line := range strings.Split(output, "\n") {
+				if line == strings.TrimLeft(line, " \t") && strings.TrimSpace(line) != "" {
+					names = append(names, strings.Fields(line)[0])
+				}
+			}
+			return names
+		},
+		install: []string{"install"},
+		upgrade: []string{"install"},
+		remove:  []string{"uninstall"},
+	})
+}
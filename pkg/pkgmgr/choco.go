@@ -0,0 +1,40 @@
+package pkgmgr
+
+import (
+	"context"
+	"strings"
+)
+
+func init() {
+	register(&manager{
+		name:     "choco",
+		category: System,
+		goos:     "windows",
+		listArgs: []string{"list"},
+		// A header and a "N packages installed." footer around "name version" lines
+		parseList: func(output string) []string {
+			var names []string
+			for _, line := range lines(output) {
+				if strings.Contains(line, "packages installed") || !strings.Contains(line, " ") || strings.HasSuffix(line, ":") {
+					continue
+				}
+				names = append(names, strings.Fields(line)[0])
+			}
+			return names
+		},
+		pending: func(ctx context.Context, m *manager) (int, error) {
+			out, err := output(ctx, "choco", "outdated", "-r")
+			if err != nil {
+				return 0, err
+			}
+			return countLines(out, 0), nil
+		},
+		update: &UpdatePlan{
+			Description: "Upgrade all Chocolatey packages",
+			Commands:    []Command{{Args: []string{"choco", "upgrade", "all", "-y"}}},
+		},
+		install: []string{"install"},
+		upgrade: []string{"upgrade", "-y"},
+		remove:  []string{"uninstall", "-y"},
+	})
+}
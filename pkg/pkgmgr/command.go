@@ -0,0 +1,61 @@
+package pkgmgr
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/aallbrig/allbctl/pkg/runner"
+)
+
+// Command is a package manager command line
+type Command struct {
+	Args []string `json:"args"`
+	// Sudo is set for commands that need root. They run through sudo when
+	// allbctl is not root and sudo is available.
+	Sudo bool `json:"sudo,omitempty"`
+}
+
+// Env decides how a Command runs: whether allbctl is root and which programs
+// are on PATH
+type Env struct {
+	Root      bool
+	Available func(name string) bool
+}
+
+// LocalEnv is the environment of this process
+func LocalEnv() Env {
+	return Env{Root: os.Geteuid() == 0, Available: func(name string) bool {
+		return runner.Available(context.Background(), name)
+	}}
+}
+
+// Argv is the command line to run in env
+func (c Command) Argv(env Env) []string {
+	if c.Sudo && !env.Root && env.Available != nil && env.Available("sudo") {
+		return append([]string{"sudo"}, c.Args...)
+	}
+	return c.Args
+}
+
+// Line is the command line to run in env, quoted for a POSIX shell
+func (c Command) Line(env Env) string {
+	argv := c.Argv(env)
+	quoted := make([]string, len(argv))
+	for i, arg := range argv {
+		quoted[i] = ShellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func (c Command) String() string {
+	return c.Line(LocalEnv())
+}
+
+// ShellQuote quotes an argument that a POSIX shell would otherwise split or expand
+func ShellQuote(arg string) string {
+	if arg != "" && strings.Trim(arg, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_=+@%:,./") == "" {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
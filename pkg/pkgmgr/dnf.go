@@ -0,0 +1,35 @@
+package pkgmgr
+
+import (
+	"context"
+
+	"github.com/aallbrig/allbctl/pkg/runner"
+)
+
+func init() {
+	register(&manager{
+		name:     "dnf",
+		category: System,
+		goos:     "linux",
+		// User-installed packages, not dependencies
+		listArgs: []string{"repoquery", "--userinstalled", "--qf", "%{name}"},
+		pending:  checkUpdate,
+		update: &UpdatePlan{
+			Description: "Upgrade all dnf packages",
+			Commands:    []Command{{Args: []string{"dnf", "upgrade", "-y"}, Sudo: true}},
+		},
+		sudo:    true,
+		install: []string{"install", "-y"},
+		upgrade: []string{"upgrade", "-y"},
+		remove:  []string{"remove", "-y"},
+	})
+}
+
+// checkUpdate counts dnf or yum updates. Both exit 100 when updates are available.
+func checkUpdate(ctx context.Context, m *manager) (int, error) {
+	out, err := output(ctx, m.bin(ctx), "check-update", "-q")
+	if code, _ := runner.ExitCode(err); err != nil && code != 100 {
+		return 0, err
+	}
+	return countLines(out, 0), nil
+}
@@ -0,0 +1,50 @@
+package pkgmgr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// dpkg is the Debian package database underneath apt
+func init() {
+	register(&manager{
+		name:        "dpkg",
+		category:    System,
+		goos:        "linux",
+		listArgs:    []string{"--get-selections"},
+		parseList:   parseDpkgSelections,
+		recentArgs:  dpkgLogTail,
+		parseRecent: parseDpkgLog,
+	})
+}
+
+// parseDpkgSelections reads "package<TAB>install" lines, skipping "deinstall" selections
+func parseDpkgSelections(output string) []string {
+	var names []string
+	for _, line := range lines(output) {
+		fields := strings.Fields(line)
+		if fields[len(fields)-1] == "install" {
+			names = append(names, fields[0])
+		}
+	}
+	return names
+}
+
+// dpkgLogTail reads the last n installs from the dpkg logs, oldest log first
+func dpkgLogTail(n int) []string {
+	return []string{"sh", "-c", fmt.Sprintf("cat /var/log/dpkg.log.1 /var/log/dpkg.log 2>/dev/null | grep ' install ' | tail -%d", n)}
+}
+
+// parseDpkgLog reads "2026-01-06 17:08:49 install sqlite3:amd64 <none> 3.45.1-1ubuntu2.5" lines
+func parseDpkgLog(output string) []string {
+	var recent []string
+	for _, line := range lines(output) {
+		parts := strings.Fields(line)
+		if len(parts) < 5 {
+			continue
+		}
+		pkg, _, _ := strings.Cut(parts[3], ":") // drop the architecture
+		recent = append(recent, fmt.Sprintf("%s %s - %s", parts[0], parts[1], pkg))
+	}
+	return recent
+}
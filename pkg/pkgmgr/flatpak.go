@@ -0,0 +1,41 @@
+package pkgmgr
+
+import (
+	"context"
+	"strings"
+)
+
+func init() {
+	register(&manager{
+		name:     "flatpak",
+		category: System,
+		goos:     "linux",
+		// User-installed apps; the application ID is unique, the name is not
+		listArgs: []string{"list", "--app", "--columns=name,application"},
+		parseList: func(output string) []string {
+			var names []string
+			for _, line := range lines(output) {
+				if strings.HasPrefix(line, "Name") {
+					continue
+				}
+				fields := strings.Fields(line)
+				names = append(names, fields[len(fields)-1])
+			}
+			return names
+		},
+		pending: func(ctx context.Context, m *manager) (int, error) {
+			out, err := output(ctx, "flatpak", "remote-ls", "--updates", "--app")
+			if err != nil {
+				return 0, err
+			}
+			return countLines(out, 0), nil
+		},
+		update: &UpdatePlan{
+			Description: "Update all Flatpak applications",
+			Commands:    []Command{{Args: []string{"flatpak", "update", "-y"}}},
+		},
+		install: []string{"install", "-y"},
+		upgrade: []string{"update", "-y"},
+		remove:  []string{"uninstall", "-y"},
+	})
+}
@@ -0,0 +1,17 @@
+package pkgmgr
+
+func init() {
+	register(&manager{
+		name:     "gem",
+		category: Runtime,
+		// Dependencies are not listed by default
+		listArgs: []string{"list", "--local"},
+		update: &UpdatePlan{
+			Description: "Update all installed Ruby gems",
+			Commands:    []Command{{Args: []string{"gem", "update"}}},
+		},
+		install: []string{"install"},
+		upgrade: []string{"update"},
+		remove:  []string{"uninstall", "-x"},
+	})
+}
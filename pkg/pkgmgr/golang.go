@@ -0,0 +1,46 @@
+package pkgmgr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// go installs binaries into GOPATH/bin and has no way to upgrade them all
+func init() {
+	register(&manager{
+		name:        "go",
+		category:    Runtime,
+		versionArgs: []string{"version"},
+		// "go version go1.25.5 linux/amd64"
+		parseVersion: func(output string) string {
+			if fields := strings.Fields(output); len(fields) >= 3 && fields[1] == "version" {
+				return strings.TrimPrefix(fields[2], "go")
+			}
+			return versionNumber(output)
+		},
+		list: func(ctx context.Context, m *manager) ([]string, error) {
+			out, err := output(ctx, "go", "env", "GOPATH")
+			if err != nil {
+				return nil, err
+			}
+			paths := filepath.SplitList(strings.TrimSpace(string(out)))
+			if len(paths) == 0 {
+				return nil, nil
+			}
+			entries, err := os.ReadDir(filepath.Join(paths[0], "bin"))
+			if os.IsNotExist(err) {
+				return nil, nil
+			}
+			var names []string
+			for _, entry := range entries {
+				names = append(names, entry.Name())
+			}
+			return names, err
+		},
+		listCommand: "ls -1 $(go env GOPATH)/bin",
+		install:     []string{"install"},
+		upgrade:     []string{"install"},
+	})
+}
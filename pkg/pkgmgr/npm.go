@@ -0,0 +1,69 @@
+package pkgmgr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+func init() {
+	register(&manager{
+		name:     "npm",
+		category: Runtime,
+		// Globally installed packages; depth 0 leaves out their dependencies
+		listArgs:  []string{"list", "-g", "--depth=0"},
+		parseList: parseNpmTree,
+		// npm records no install dates; the package directories' times stand in
+		recentArgs: func(n int) []string {
+			return []string{"sh", "-c", fmt.Sprintf("ls -lt $(npm root -g 2>/dev/null) 2>/dev/null | grep '^d' | head -%d", n)}
+		},
+		parseRecent: func(output string) []string {
+			var recent []string
+			for _, line := range lines(output) {
+				// "drwxrwxr-x 4 user user 4096 Dec 31 13:54 puppeteer-mcp-server"
+				parts := strings.Fields(line)
+				if len(parts) >= 9 {
+					recent = append(recent, fmt.Sprintf("%s %s %s - %s", parts[5], parts[6], parts[7], parts[8]))
+				}
+			}
+			return recent
+		},
+		pending: func(ctx context.Context, m *manager) (int, error) {
+			// npm outdated exits 1 when anything is outdated
+			out, err := output(ctx, "npm", "outdated", "-g", "--json")
+			if err != nil && len(out) == 0 {
+				return 0, err
+			}
+			var outdated map[string]any
+			if err := json.Unmarshal(out, &outdated); err != nil {
+				return 0, err
+			}
+			return len(outdated), nil
+		},
+		update: &UpdatePlan{
+			Description: "Update all globally installed npm packages",
+			Commands:    []Command{{Args: []string{"npm", "update", "-g"}}},
+		},
+		install: []string{"install", "-g"},
+		upgrade: []string{"update", "-g"},
+		remove:  []string{"uninstall", "-g"},
+	})
+}
+
+// parseNpmTree reads "├── package@version" lines, or "+-- package@version"
+// when npm draws the tree in ASCII. Scoped packages start with "@".
+func parseNpmTree(output string) []string {
+	var names []string
+	for _, line := range lines(output) {
+		if !strings.HasPrefix(line, "├──") && !strings.HasPrefix(line, "└──") && !strings.HasPrefix(line, "+--") && !strings.HasPrefix(line, "`--") {
+			continue
+		}
+		pkg := strings.TrimSpace(strings.TrimLeft(line, "├└─+`- "))
+		if at := strings.LastIndex(pkg, "@"); at > 0 {
+			pkg = pkg[:at]
+		}
+		names = append(names, pkg)
+	}
+	return names
+}
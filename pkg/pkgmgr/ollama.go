@@ -0,0 +1,14 @@
+package pkgmgr
+
+// ollama manages models rather than packages
+func init() {
+	register(&manager{
+		name:      "ollama",
+		category:  Infrastructure,
+		listArgs:  []string{"list"},
+		parseList: skipHeader(1), // "NAME ID SIZE MODIFIED"
+		install:   []string{"pull"},
+		upgrade:   []string{"pull"},
+		remove:    []string{"rm"},
+	})
+}
@@ -0,0 +1,38 @@
+package pkgmgr
+
+import (
+	"context"
+	"strings"
+)
+
+func init() {
+	register(&manager{
+		name:     "pacman",
+		category: System,
+		goos:     "linux",
+		// "Pacman v6.0.1 - libalpm v13.0.1" below some ASCII art
+		parseVersion: func(output string) string {
+			if _, rest, found := strings.Cut(output, "Pacman v"); found {
+				return strings.Fields(rest)[0]
+			}
+			return versionNumber(output)
+		},
+		// Explicitly installed packages, not dependencies
+		listArgs: []string{"-Qe"},
+		pending: func(ctx context.Context, m *manager) (int, error) {
+			out, err := output(ctx, "checkupdates")
+			if err != nil {
+				return 0, err
+			}
+			return countLines(out, 0), nil
+		},
+		update: &UpdatePlan{
+			Description: "Synchronize and upgrade all pacman packages",
+			Commands:    []Command{{Args: []string{"pacman", "-Syu", "--noconfirm"}, Sudo: true}},
+		},
+		sudo:    true,
+		install: []string{"-S", "--noconfirm"},
+		upgrade: []string{"-S", "--noconfirm"},
+		remove:  []string{"-R", "--noconfirm"},
+	})
+}
@@ -0,0 +1,40 @@
+package pkgmgr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// pip is left out of 'allbctl update': upgrading every package can break the system Python
+func init() {
+	register(&manager{
+		name:      "pip",
+		category:  Runtime,
+		bins:      []string{"pip3", "pip"},
+		listArgs:  []string{"list", "--format=columns"},
+		parseList: skipHeader(2), // "Package Version" and "-------"
+		// pip records no install dates; the package locations' times stand in
+		recentArgs: func(n int) []string {
+			return []string{"python3", "-W", "ignore::DeprecationWarning", "-c", fmt.Sprintf(
+				"import pkg_resources, os, time; "+
+					"pkgs = sorted(((p.project_name, p.version, os.stat(p.location).st_mtime) for p in pkg_resources.working_set), key=lambda p: p[2], reverse=True); "+
+					"[print(time.strftime('%%Y-%%m-%%d %%H:%%M:%%S', time.localtime(p[2])) + ' - ' + p[0] + ' (' + p[1] + ')') for p in pkgs[:%d]]", n)}
+		},
+		parseRecent: lines,
+		pending: func(ctx context.Context, m *manager) (int, error) {
+			out, err := output(ctx, m.bin(ctx), "list", "--outdated", "--format=json")
+			if err != nil {
+				return 0, err
+			}
+			var outdated []any
+			if err := json.Unmarshal(out, &outdated); err != nil {
+				return 0, err
+			}
+			return len(outdated), nil
+		},
+		install: []string{"install", "--user"},
+		upgrade: []string{"install", "--user", "--upgrade"},
+		remove:  []string{"uninstall", "-y"},
+	})
+}
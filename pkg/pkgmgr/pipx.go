@@ -0,0 +1,29 @@
+package pkgmgr
+
+import "strings"
+
+// pipx has no outdated check short of checking each package
+func init() {
+	register(&manager{
+		name:     "pipx",
+		category: Runtime,
+		listArgs: []string{"list"},
+		// "   package black 24.1.0, installed using Python 3.12"
+		parseList: func(output string) []string {
+			var names []string
+			for _, line := range lines(output) {
+				if fields := strings.Fields(line); strings.HasPrefix(line, "package ") && len(fields) >= 2 {
+					names = append(names, fields[1])
+				}
+			}
+			return names
+		},
+		update: &UpdatePlan{
+			Description: "Upgrade all pipx-installed applications",
+			Commands:    []Command{{Args: []string{"pipx", "upgrade-all"}}},
+		},
+		install: []string{"install"},
+		upgrade: []string{"upgrade"},
+		remove:  []string{"uninstall"},
+	})
+}
@@ -0,0 +1,327 @@
+// Package pkgmgr knows the package managers allbctl works with: whether one is
+// installed, what it has installed, what it can update, and the commands that
+// install, upgrade and remove packages. Each manager lives in its own file and
+// registers itself, so supporting a new one means adding one file.
+package pkgmgr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aallbrig/allbctl/pkg/runner"
+)
+
+// Category groups package managers by what they manage
+type Category string
+
+const (
+	System         Category = "system"
+	Runtime        Category = "runtime"
+	Infrastructure Category = "infrastructure"
+)
+
+// categoryOrder is the order All lists categories in
+var categoryOrder = map[Category]int{System: 0, Runtime: 1, Infrastructure: 2}
+
+// ErrUnsupported is returned for operations a package manager has no command for
+var ErrUnsupported = errors.New("not supported")
+
+// PackageManager is one package manager
+type PackageManager interface {
+	// Name is the manager's key, e.g. "apt" or "brew"
+	Name() string
+	Category() Category
+	// Detect reports whether the manager is usable on this machine
+	Detect(ctx context.Context) bool
+	Version(ctx context.Context) (string, error)
+	// List returns the names of the packages installed explicitly, leaving out dependencies
+	List(ctx context.Context) ([]string, error)
+	// ListRecent returns the n most recently installed packages, newest last,
+	// one "<when> - <package>" line each
+	ListRecent(ctx context.Context, n int) ([]string, error)
+	Count(ctx context.Context) (int, error)
+	// PendingUpdates counts the packages with updates available
+	PendingUpdates(ctx context.Context) (int, error)
+	// Update is how everything the manager installed is brought up to date, or
+	// nil when there is no safe way to do that
+	Update() *UpdatePlan
+	Install(pkg string) (Command, error)
+	Upgrade(pkg string) (Command, error)
+	Remove(pkg string) (Command, error)
+	// ListCommand and RecentCommand are the commands List and ListRecent run, for display
+	ListCommand() string
+	RecentCommand(n int) string
+}
+
+// UpdatePlan updates every package of a manager
+type UpdatePlan struct {
+	Description string
+	Commands    []Command
+}
+
+// pendingTimeout bounds update checks, which may reach out to the network
+const pendingTimeout = 10 * time.Second
+
+var registry = map[string]PackageManager{}
+
+func register(m PackageManager) {
+	registry[m.Name()] = m
+}
+
+// Get returns the package manager with the given name
+func Get(name string) (PackageManager, bool) {
+	m, ok := registry[name]
+	return m, ok
+}
+
+// All returns every known package manager, by category and then name
+func All() []PackageManager {
+	all := make([]PackageManager, 0, len(registry))
+	for _, m := range registry {
+		all = append(all, m)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if ci, cj := categoryOrder[all[i].Category()], categoryOrder[all[j].Category()]; ci != cj {
+			return ci < cj
+		}
+		return all[i].Name() < all[j].Name()
+	})
+	return all
+}
+
+// Detected returns the package managers usable on this machine, in All order
+func Detected(ctx context.Context) []PackageManager {
+	var detected []PackageManager
+	for _, m := range All() {
+		if m.Detect(ctx) {
+			detected = append(detected, m)
+		}
+	}
+	return detected
+}
+
+// output runs a program with ctx's runner and returns its standard output
+func output(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return runner.Output(ctx, name, args...)
+}
+
+// manager implements PackageManager from a description of its commands; each
+// manager's file registers one
+type manager struct {
+	name     string
+	category Category
+	goos     string   // the only OS the manager is looked for on; empty for every OS
+	bins     []string // the first one on PATH runs the manager's commands; defaults to name
+
+	versionArgs  []string                   // defaults to --version
+	parseVersion func(output string) string // defaults to versionNumber
+
+	listArgs  []string                     // run with the manager's binary
+	parseList func(output string) []string // defaults to the first field of each line
+	// list replaces listArgs when listing takes more than one command
+	list        func(ctx context.Context, m *manager) ([]string, error)
+	listCommand string // shown for list
+
+	recentArgs  func(n int) []string // the full command line ListRecent runs
+	parseRecent func(output string) []string
+
+	pending func(ctx context.Context, m *manager) (int, error)
+	update  *UpdatePlan
+
+	sudo                     bool     // install, upgrade and remove need root
+	install, upgrade, remove []string // run with the manager's binary, followed by the package
+}
+
+func (m *manager) Name() string       { return m.name }
+func (m *manager) Category() Category { return m.category }
+
+func (m *manager) Detect(ctx context.Context) bool {
+	if m.goos != "" && m.goos != runtime.GOOS {
+		return false
+	}
+	for _, bin := range m.binaries() {
+		if runner.Available(ctx, bin) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *manager) binaries() []string {
+	if len(m.bins) == 0 {
+		return []string{m.name}
+	}
+	return m.bins
+}
+
+// bin is the program that runs the manager's commands
+func (m *manager) bin(ctx context.Context) string {
+	for _, bin := range m.binaries() {
+		if runner.Available(ctx, bin) {
+			return bin
+		}
+	}
+	return m.binaries()[0]
+}
+
+func (m *manager) Version(ctx context.Context) (string, error) {
+	args := m.versionArgs
+	if args == nil {
+		args = []string{"--version"}
+	}
+	bin := m.bin(ctx)
+	out, err := output(ctx, bin, args...)
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %w", bin, strings.Join(args, " "), err)
+	}
+	parse := m.parseVersion
+	if parse == nil {
+		parse = versionNumber
+	}
+	if v := parse(string(out)); v != "" {
+		return v, nil
+	}
+	return "", fmt.Errorf("no version in output of %s %s", bin, strings.Join(args, " "))
+}
+
+func (m *manager) List(ctx context.Context) ([]string, error) {
+	if m.list != nil {
+		return m.list(ctx, m)
+	}
+	if m.listArgs == nil {
+		return nil, m.unsupported("list packages")
+	}
+	out, err := output(ctx, m.bin(ctx), m.listArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", m.ListCommand(), err)
+	}
+	parse := m.parseList
+	if parse == nil {
+		parse = firstFields
+	}
+	return parse(string(out)), nil
+}
+
+func (m *manager) ListCommand() string {
+	if m.listCommand != "" || m.listArgs == nil {
+		return m.listCommand
+	}
+	return Command{Args: append([]string{m.bin(context.Background())}, m.listArgs...)}.Line(Env{})
+}
+
+func (m *manager) ListRecent(ctx context.Context, n int) ([]string, error) {
+	if m.recentArgs == nil {
+		return nil, m.unsupported("list recent packages")
+	}
+	args := m.recentArgs(n)
+	out, err := output(ctx, args[0], args[1:]...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", m.RecentCommand(n), err)
+	}
+	return m.parseRecent(string(out)), nil
+}
+
+func (m *manager) RecentCommand(n int) string {
+	if m.recentArgs == nil {
+		return ""
+	}
+	return Command{Args: m.recentArgs(n)}.Line(Env{})
+}
+
+func (m *manager) Count(ctx context.Context) (int, error) {
+	names, err := m.List(ctx)
+	return len(names), err
+}
+
+func (m *manager) PendingUpdates(ctx context.Context) (int, error) {
+	if m.pending == nil {
+		return 0, m.unsupported("check for updates")
+	}
+	ctx, cancel := context.WithTimeout(ctx, pendingTimeout)
+	defer cancel()
+	return m.pending(ctx, m)
+}
+
+func (m *manager) Update() *UpdatePlan { return m.update }
+
+func (m *manager) Install(pkg string) (Command, error) {
+	return m.command(m.install, pkg, "install packages")
+}
+
+func (m *manager) Upgrade(pkg string) (Command, error) {
+	return m.command(m.upgrade, pkg, "upgrade packages")
+}
+
+func (m *manager) Remove(pkg string) (Command, error) {
+	return m.command(m.remove, pkg, "remove packages")
+}
+
+func (m *manager) command(args []string, pkg, action string) (Command, error) {
+	if args == nil {
+		return Command{}, m.unsupported(action)
+	}
+	argv := append([]string{m.bin(context.Background())}, args...)
+	return Command{Args: append(argv, pkg), Sudo: m.sudo}, nil
+}
+
+func (m *manager) unsupported(action string) error {
+	return fmt.Errorf("%s cannot %s: %w", m.name, action, ErrUnsupported)
+}
+
+// lines splits command output into trimmed, non-blank lines
+func lines(output string) []string {
+	var result []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+// firstFields returns the first field of each line: one package per line
+func firstFields(output string) []string {
+	var names []string
+	for _, line := range lines(output) {
+		names = append(names, strings.Fields(line)[0])
+	}
+	return names
+}
+
+// skipHeader returns a parser that drops the first n lines of a table and
+// takes the first field of the rest
+func skipHeader(n int) func(string) []string {
+	return func(output string) []string {
+		rows := lines(output)
+		if len(rows) <= n {
+			return nil
+		}
+		return firstFields(strings.Join(rows[n:], "\n"))
+	}
+}
+
+// countLines counts the non-blank lines after the first skip
+func countLines(out []byte, skip int) int {
+	return max(len(lines(string(out)))-skip, 0)
+}
+
+// versionNumber extracts a version from the first line of a version output:
+// the first field that contains a dot and starts with a digit, or the whole line
+func versionNumber(output string) string {
+	output = strings.TrimSpace(output)
+	if idx := strings.Index(output, "\n"); idx >= 0 {
+		output = strings.TrimSpace(output[:idx])
+	}
+	for _, field := range strings.Fields(output) {
+		field = strings.Trim(field, "()[]{}\"',")
+		if strings.Contains(field, ".") && field != "" && field[0] >= '0' && field[0] <= '9' {
+			return field
+		}
+	}
+	return output
+}
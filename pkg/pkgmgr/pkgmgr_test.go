@@ -0,0 +1,275 @@
+package pkgmgr
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aallbrig/allbctl/pkg/runner"
+)
+
+func getManager(t *testing.T, name string) *manager {
+	t.Helper()
+	m, ok := Get(name)
+	if !ok {
+		t.Fatalf("Get(%q) found no package manager", name)
+	}
+	return m.(*manager)
+}
+
+// replayOutput returns a context whose commands print canned output, keyed
+// by their command line
+func replayOutput(outputs map[string]string) context.Context {
+	var fixture runner.Fixture
+	for line, out := range outputs {
+		fixture.Commands = append(fixture.Commands, runner.Recording{Argv: strings.Fields(line), Stdout: out})
+	}
+	return runner.WithRunner(context.Background(), runner.NewReplay(fixture))
+}
+
+func TestAll(t *testing.T) {
+	all := All()
+	var names []string
+	for i, m := range all {
+		names = append(names, m.Name())
+		if i > 0 && categoryOrder[all[i-1].Category()] > categoryOrder[m.Category()] {
+			t.Errorf("All() lists %s (%s) after %s (%s)", m.Name(), m.Category(), all[i-1].Name(), all[i-1].Category())
+		}
+	}
+	for _, want := range []string{"apt", "dpkg", "rpm", "snap", "flatpak", "dnf", "yum", "pacman", "zypper", "apk", "brew", "choco", "winget", "scoop",
+		"npm", "pip", "pipx", "gem", "cargo", "go", "ollama", "vagrant", "vboxmanage"} {
+		if !slices.Contains(names, want) {
+			t.Errorf("All() is missing %q: %v", want, names)
+		}
+	}
+	if _, ok := Get("unknown"); ok {
+		t.Error("Get(\"unknown\") found a package manager")
+	}
+}
+
+func TestParseList(t *testing.T) {
+	tests := []struct {
+		manager string
+		output  string
+		want    []string
+	}{
+		{"apt", "package1\npackage2\npackage3\n", []string{"package1", "package2", "package3"}},
+		{"dpkg", "git\tinstall\ncurl\tinstall\nold\tdeinstall", []string{"git", "curl"}},
+		{"snap", "Name  Version  Rev\ncore  16  100\nlxd  5.0  200", []string{"core", "lxd"}},
+		{"flatpak", "Name Application ID\nFirefox org.mozilla.firefox", []string{"org.mozilla.firefox"}},
+		{"npm", "/home/user/.nvm/versions/node/v20.0.0/lib\n├── @angular/cli@17.0.0\n└── npm@10.2.0", []string{"@angular/cli", "npm"}},
+		{"npm", "/usr/lib\n+-- corepack@0.33.0\n`-- npm@10.8.2", []string{"corepack", "npm"}},
+		{"pip", "Package    Version\n---------- -------\npkg1       1.0.0\npkg2       2.0.0\n", []string{"pkg1", "pkg2"}},
+		{"pipx", "venvs are in /x\n   package black 24.1.0, installed using Python 3.12\n   package pkg2", []string{"black", "pkg2"}},
+		{"brew", "git\nwget", []string{"git", "wget"}},
+		{"cargo", "ripgrep v14.0.0:\n    rg\nbat v0.24.0:\n    bat", []string{"ripgrep", "bat"}},
+		{"ollama", "NAME                      ID              SIZE      MODIFIED\nllama3.2:latest          a80c4f17acd5    2.0 GB    3 days ago\ncodellama:latest         8fdf8f752f6e    3.8 GB    2 weeks ago\n", []string{"llama3.2:latest", "codellama:latest"}},
+		{"ollama", "NAME    ID    SIZE    MODIFIED\n", nil},
+		{"vagrant", "gusztavvargadr/windows-10 (virtualbox, 2511.0.0, (amd64))\nubuntu/focal64            (virtualbox, 20240821.0.0)\n", []string{"gusztavvargadr/windows-10", "ubuntu/focal64"}},
+		{"vboxmanage", "\"VM1\" {12345678-1234-1234-1234-123456789012}\n\"My VM\" {87654321-4321-4321-4321-210987654321}", []string{"VM1", "My VM"}},
+		{"zypper", "S  | Name | Summary | Type\n---+------+---------+--------\ni+ | vim  | Vi IMproved | package", []string{"vim"}},
+		{"winget", "Name   Id   Version\n------------------\nGit  Git.Git  2.43.0", []string{"Git"}},
+		{"apt", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.manager, func(t *testing.T) {
+			m := getManager(t, tt.manager)
+			parse := m.parseList
+			if parse == nil {
+				parse = firstFields
+			}
+			if got := parse(tt.output); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s parseList() = %q, want %q", tt.manager, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestList(t *testing.T) {
+	ctx := replayOutput(map[string]string{
+		"apt-mark showmanual":    "git\ncurl\n",
+		"brew leaves":            "git\n",
+		"brew list --cask":       "firefox\n",
+		"vagrant box list":       "ubuntu/focal64 (virtualbox, 20240821.0.0)\n",
+		"apt list --upgradable":  "Listing...\ngit/noble 1:2.43 amd64 [upgradable from: 1:2.42]\n",
+		"npm outdated -g --json": `{"npm": {"current": "10.0.0"}}`,
+		"pacman --version":       "\n .--.                  Pacman v6.0.1 - libalpm v13.0.1\n",
+		"VBoxManage --version":   "7.0.14r161095\n",
+	})
+
+	if got, err := getManager(t, "apt").List(ctx); err != nil || !reflect.DeepEqual(got, []string{"git", "curl"}) {
+		t.Errorf("apt List() = %q, %v", got, err)
+	}
+	if got, err := getManager(t, "brew").Count(ctx); err != nil || got != 2 {
+		t.Errorf("brew Count() = %d, %v; want formulae and casks", got, err)
+	}
+	if got, err := getManager(t, "vagrant").Count(ctx); err != nil || got != 1 {
+		t.Errorf("vagrant Count() = %d, %v", got, err)
+	}
+	if got, err := getManager(t, "apt").PendingUpdates(ctx); err != nil || got != 1 {
+		t.Errorf("apt PendingUpdates() = %d, %v; want the Listing... header left out", got, err)
+	}
+	if got, err := getManager(t, "npm").PendingUpdates(ctx); err != nil || got != 1 {
+		t.Errorf("npm PendingUpdates() = %d, %v", got, err)
+	}
+	if got, err := getManager(t, "pacman").Version(ctx); err != nil || got != "6.0.1" {
+		t.Errorf("pacman Version() = %q, %v", got, err)
+	}
+	if _, err := getManager(t, "dnf").List(ctx); err == nil || !strings.Contains(err.Error(), "dnf repoquery --userinstalled --qf '%{name}'") {
+		t.Errorf("dnf List() error = %v, want the failing command", err)
+	}
+	if _, err := getManager(t, "gem").PendingUpdates(ctx); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("gem PendingUpdates() error = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestVersionNumber(t *testing.T) {
+	tests := []struct {
+		manager string
+		output  string
+		want    string
+	}{
+		{"apt", "apt 2.8.3 (amd64)\nSupported modules:", "2.8.3"},
+		{"flatpak", "Flatpak 1.14.6", "1.14.6"},
+		{"snap", "snap    2.63\nsnapd   2.63", "2.63"},
+		{"pip", "pip 24.0 from /usr/lib/python3/dist-packages/pip (python 3.12)", "24.0"},
+		{"npm", "10.2.0", "10.2.0"},
+		{"cargo", "cargo 1.70.0 (7c2f85da6 2023-05-31)", "1.70.0"},
+		{"go", "go version go1.25.5 linux/amd64", "1.25.5"},
+		{"brew", "Homebrew 4.0.0\nHomebrew/homebrew-core", "4.0.0"},
+		{"pacman", " .--.   Pacman v6.0.1 - libalpm v13.0.1", "6.0.1"},
+		{"winget", "v1.6.3482", "v1.6.3482"},
+		{"vboxmanage", "7.0.14r161095", "7.0.14r161095"},
+		{"vboxmanage", "  7.1.0r164728  \n", "7.1.0r164728"},
+		{"vboxmanage", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.manager, func(t *testing.T) {
+			parse := getManager(t, tt.manager).parseVersion
+			if parse == nil {
+				parse = versionNumber
+			}
+			if got := parse(tt.output); got != tt.want {
+				t.Errorf("%s version of %q = %q, want %q", tt.manager, tt.output, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	for _, m := range All() {
+		plan := m.Update()
+		if plan == nil {
+			continue
+		}
+		if plan.Description == "" || len(plan.Commands) == 0 {
+			t.Errorf("%s Update() = %+v, want a description and commands", m.Name(), plan)
+		}
+		for _, c := range plan.Commands {
+			if len(c.Args) == 0 {
+				t.Errorf("%s Update() has an empty command", m.Name())
+			}
+		}
+	}
+
+	// Upgrading everything is risky or impossible for these
+	for _, name := range []string{"pip", "cargo", "go", "dpkg", "rpm", "vboxmanage"} {
+		if plan := getManager(t, name).Update(); plan != nil {
+			t.Errorf("%s Update() = %+v, want none", name, plan)
+		}
+	}
+
+	for name, wantSudo := range map[string]bool{
+		"apt": true, "snap": true, "dnf": true, "yum": true, "pacman": true,
+		"brew": false, "flatpak": false, "npm": false, "pipx": false, "gem": false, "choco": false, "winget": false,
+	} {
+		for _, c := range getManager(t, name).Update().Commands {
+			if c.Sudo != wantSudo {
+				t.Errorf("%s update %v Sudo = %v, want %v", name, c.Args, c.Sudo, wantSudo)
+			}
+		}
+	}
+}
+
+func TestCommandLine(t *testing.T) {
+	withSudo := Env{Available: func(name string) bool { return name == "sudo" }}
+	noSudo := Env{Available: func(string) bool { return false }}
+
+	tests := []struct {
+		name string
+		cmd  func() (Command, error)
+		env  Env
+		want string
+	}{
+		{"sudo when not root", func() (Command, error) { return getManager(t, "dnf").Install("gh") }, withSudo, "sudo dnf install -y gh"},
+		{"no sudo as root", func() (Command, error) { return getManager(t, "dnf").Install("gh") }, Env{Root: true, Available: withSudo.Available}, "dnf install -y gh"},
+		{"no sudo without sudo", func() (Command, error) { return getManager(t, "apk").Install("gh") }, noSudo, "apk add gh"},
+		{"apt upgrade", func() (Command, error) { return getManager(t, "apt").Upgrade("gh") }, withSudo, "sudo apt-get install --only-upgrade -y gh"},
+		{"pacman remove", func() (Command, error) { return getManager(t, "pacman").Remove("gh") }, withSudo, "sudo pacman -R --noconfirm gh"},
+		{"never sudo for winget", func() (Command, error) { return getManager(t, "winget").Install("GitHub.cli") }, withSudo, "winget install --accept-source-agreements GitHub.cli"},
+		{"quoting", func() (Command, error) { return Command{Args: []string{"sh", "-c", "echo 'hi' | wc -l"}}, nil }, noSudo, `sh -c 'echo '\''hi'\'' | wc -l'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := tt.cmd()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := cmd.Line(tt.env); got != tt.want {
+				t.Errorf("Line() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := getManager(t, "vboxmanage").Install("vm"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("vboxmanage Install() error = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestListCommands(t *testing.T) {
+	for name, want := range map[string]string{
+		"vagrant":    "vagrant box list",
+		"vboxmanage": "VBoxManage list vms",
+		"apt":        "apt-mark showmanual",
+		"brew":       "brew leaves && brew list --cask",
+		"dnf":        "dnf repoquery --userinstalled --qf '%{name}'",
+	} {
+		if got := getManager(t, name).ListCommand(); got != want {
+			t.Errorf("%s ListCommand() = %q, want %q", name, got, want)
+		}
+	}
+
+	for name, want := range map[string]string{"apt": "dpkg.log", "dpkg": "tail -5", "npm": "npm root", "pip": "pkg_resources"} {
+		if got := getManager(t, name).RecentCommand(5); !strings.Contains(got, want) {
+			t.Errorf("%s RecentCommand(5) = %q, want it to contain %q", name, got, want)
+		}
+	}
+	if got := getManager(t, "gem").RecentCommand(5); got != "" {
+		t.Errorf("gem RecentCommand(5) = %q, want none", got)
+	}
+}
+
+func TestParseRecent(t *testing.T) {
+	got := parseDpkgLog("2026-01-06 17:08:49 install sqlite3:amd64 <none> 3.45.1-1ubuntu2.5\nshort line")
+	if want := []string{"2026-01-06 17:08:49 - sqlite3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseDpkgLog() = %q, want %q", got, want)
+	}
+	got = getManager(t, "npm").parseRecent("drwxrwxr-x 4 user user 4096 Dec 31 13:54 puppeteer-mcp-server")
+	if want := []string{"Dec 31 13:54 - puppeteer-mcp-server"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("npm parseRecent() = %q, want %q", got, want)
+	}
+}
+
+func TestDetected(t *testing.T) {
+	// Whatever is installed here, every detected manager can report its packages without panicking
+	for _, m := range Detected(context.Background()) {
+		if _, err := m.Count(context.Background()); err != nil {
+			t.Logf("%s Count() error = %v", m.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package pkgmgr
+
+// rpm is the package database underneath dnf, yum and zypper
+func init() {
+	register(&manager{
+		name:     "rpm",
+		category: System,
+		goos:     "linux",
+		listArgs: []string{"-qa"},
+	})
+}
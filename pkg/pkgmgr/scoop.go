@@ -0,0 +1,14 @@
+package pkgmgr
+
+func init() {
+	register(&manager{
+		name:      "scoop",
+		category:  System,
+		goos:      "windows",
+		listArgs:  []string{"list"},
+		parseList: skipHeader(2), // headers and separator
+		install:   []string{"install"},
+		upgrade:   []string{"update"},
+		remove:    []string{"uninstall"},
+	})
+}
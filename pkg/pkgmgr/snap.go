@@ -0,0 +1,29 @@
+package pkgmgr
+
+import "context"
+
+func init() {
+	register(&manager{
+		name:     "snap",
+		category: System,
+		goos:     "linux",
+		// Snap does not track dependencies separately
+		listArgs:  []string{"list", "--color=never"},
+		parseList: skipHeader(1),
+		pending: func(ctx context.Context, m *manager) (int, error) {
+			out, err := output(ctx, "snap", "refresh", "--list")
+			if err != nil {
+				return 0, err
+			}
+			return countLines(out, 1), nil
+		},
+		update: &UpdatePlan{
+			Description: "Refresh all snap packages",
+			Commands:    []Command{{Args: []string{"snap", "refresh"}, Sudo: true}},
+		},
+		sudo:    true,
+		install: []string{"install"},
+		upgrade: []string{"refresh"},
+		remove:  []string{"remove"},
+	})
+}
@@ -0,0 +1,14 @@
+package pkgmgr
+
+// vagrant manages boxes: "ubuntu/focal64 (virtualbox, 20240821.0.0)". Boxes
+// are updated per Vagrantfile, so there is no update for all of them.
+func init() {
+	register(&manager{
+		name:     "vagrant",
+		category: Infrastructure,
+		listArgs: []string{"box", "list"},
+		install:  []string{"box", "add"},
+		upgrade:  []string{"box", "update", "--box"},
+		remove:   []string{"box", "remove"},
+	})
+}
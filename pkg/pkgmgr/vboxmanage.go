@@ -0,0 +1,24 @@
+package pkgmgr
+
+import "strings"
+
+// vboxmanage lists VirtualBox VMs; it does not install anything
+func init() {
+	register(&manager{
+		name:     "vboxmanage",
+		category: Infrastructure,
+		bins:     []string{"VBoxManage"},
+		listArgs: []string{"list", "vms"},
+		// "VM name" {uuid}
+		parseList: func(output string) []string {
+			var names []string
+			for _, line := range lines(output) {
+				if end := strings.LastIndex(line, " {"); end > 0 {
+					line = strings.Trim(line[:end], "\"")
+				}
+				names = append(names, line)
+			}
+			return names
+		},
+	})
+}
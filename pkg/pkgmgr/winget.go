@@ -0,0 +1,37 @@
+package pkgmgr
+
+import (
+	"context"
+	"strings"
+)
+
+// winget needs --accept-source-agreements to avoid interactive prompts
+func init() {
+	register(&manager{
+		name:      "winget",
+		category:  System,
+		goos:      "windows",
+		listArgs:  []string{"list", "--accept-source-agreements"},
+		parseList: skipHeader(2), // headers and separator
+		pending: func(ctx context.Context, m *manager) (int, error) {
+			out, err := output(ctx, "winget", "upgrade", "--accept-source-agreements")
+			if err != nil {
+				return 0, err
+			}
+			count := 0
+			for _, line := range lines(string(out)) {
+				if strings.Contains(line, "Available") {
+					count++
+				}
+			}
+			return count, nil
+		},
+		update: &UpdatePlan{
+			Description: "Upgrade all winget packages",
+			Commands:    []Command{{Args: []string{"winget", "upgrade", "--all", "--accept-source-agreements", "--accept-package-agreements"}}},
+		},
+		install: []string{"install", "--accept-source-agreements"},
+		upgrade: []string{"upgrade", "--accept-source-agreements"},
+		remove:  []string{"uninstall"},
+	})
+}
@@ -0,0 +1,19 @@
+package pkgmgr
+
+func init() {
+	register(&manager{
+		name:     "yum",
+		category: System,
+		goos:     "linux",
+		listArgs: []string{"history", "userinstalled"},
+		pending:  checkUpdate,
+		update: &UpdatePlan{
+			Description: "Update all yum packages",
+			Commands:    []Command{{Args: []string{"yum", "update", "-y"}, Sudo: true}},
+		},
+		sudo:    true,
+		install: []string{"install", "-y"},
+		upgrade: []string{"update", "-y"},
+		remove:  []string{"remove", "-y"},
+	})
+}
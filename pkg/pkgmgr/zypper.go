@@ -0,0 +1,50 @@
+package pkgmgr
+
+import (
+	"context"
+	"strings"
+)
+
+func init() {
+	register(&manager{
+		name:      "zypper",
+		category:  System,
+		goos:      "linux",
+		listArgs:  []string{"--quiet", "search", "--installed-only", "--type", "package"},
+		parseList: parseZypperTable,
+		pending: func(ctx context.Context, m *manager) (int, error) {
+			out, err := output(ctx, "zypper", "--quiet", "list-updates")
+			if err != nil {
+				return 0, err
+			}
+			count := 0
+			for _, line := range lines(string(out)) {
+				if strings.HasPrefix(line, "v ") {
+					count++
+				}
+			}
+			return count, nil
+		},
+		update: &UpdatePlan{
+			Description: "Update all zypper packages",
+			Commands:    []Command{{Args: []string{"zypper", "--non-interactive", "update"}, Sudo: true}},
+		},
+		sudo:    true,
+		install: []string{"install", "-y"},
+		upgrade: []string{"update", "-y"},
+		remove:  []string{"remove", "-y"},
+	})
+}
+
+// parseZypperTable reads "i+ | name | summary | type" rows
+func parseZypperTable(output string) []string {
+	var names []string
+	for _, line := range lines(output) {
+		columns := strings.Split(line, "|")
+		if len(columns) < 2 || !strings.HasPrefix(strings.TrimSpace(columns[0]), "i") {
+			continue
+		}
+		names = append(names, strings.TrimSpace(columns[1]))
+	}
+	return names
+}
@@ -0,0 +1,119 @@
+// Package plugin discovers external allbctl commands. Any executable named
+// allbctl-<name> on PATH becomes 'allbctl <name>', the same way git and
+// kubectl find their plugins.
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Prefix is the file name prefix that marks an executable as a plugin
+const Prefix = "allbctl-"
+
+// Environment variables set for every plugin invocation
+const (
+	EnvConfig = "ALLBCTL_CONFIG" // config file allbctl resolved; empty when none was found
+	EnvDebug  = "ALLBCTL_DEBUG"  // "true" when --debug was given
+)
+
+// windowsExtensions are the file types Windows can run as plugins; the
+// extension is not part of the plugin name
+var windowsExtensions = map[string]bool{".exe": true, ".bat": true, ".cmd": true}
+
+// Plugin is an allbctl-<name> executable found on PATH
+type Plugin struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	// ShadowedBy explains why the plugin cannot be run, e.g. a built-in
+	// command or an earlier PATH entry with the same name; empty when usable
+	ShadowedBy string `json:"shadowed_by,omitempty"`
+}
+
+// Usable reports whether 'allbctl <name>' runs this plugin
+func (p Plugin) Usable() bool {
+	return p.ShadowedBy == ""
+}
+
+// Discover lists the plugins in the directories of pathList (a PATH-style
+// list), sorted by name. When a name appears in more than one directory the
+// first one wins, as it would for the shell; builtin names are never replaced.
+func Discover(pathList string, builtin map[string]bool) []Plugin {
+	var plugins []Plugin
+	first := map[string]string{}
+	seenDirs := map[string]bool{}
+
+	for _, dir := range filepath.SplitList(pathList) {
+		if dir == "" || seenDirs[dir] {
+			continue
+		}
+		seenDirs[dir] = true
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			// PATH often names directories that do not exist
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name())
+			if !ok {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+
+			p := Plugin{Name: name, Path: path}
+			switch {
+			case builtin[name]:
+				p.ShadowedBy = "built-in command"
+			case first[name] != "":
+				p.ShadowedBy = first[name]
+			default:
+				first[name] = path
+			}
+			plugins = append(plugins, p)
+		}
+	}
+
+	sort.SliceStable(plugins, func(i, j int) bool {
+		return plugins[i].Name < plugins[j].Name
+	})
+	return plugins
+}
+
+// pluginName returns the command name for a plugin file name
+func pluginName(fileName string) (string, bool) {
+	if !strings.HasPrefix(fileName, Prefix) {
+		return "", false
+	}
+	name := strings.TrimPrefix(fileName, Prefix)
+	if runtime.GOOS == "windows" {
+		ext := filepath.Ext(name)
+		if !windowsExtensions[strings.ToLower(ext)] {
+			return "", false
+		}
+		name = strings.TrimSuffix(name, ext)
+	}
+	if name == "" || strings.HasPrefix(name, "-") {
+		return "", false
+	}
+	return name, true
+}
+
+// isExecutable reports whether path is a regular file the user can run
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		// Windows has no execute bit; pluginName already checked the extension
+		return true
+	}
+	return info.Mode().Perm()&0111 != 0
+}
@@ -0,0 +1,73 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir, name string, perm os.FileMode) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), perm); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses execute bits")
+	}
+	first, second := t.TempDir(), t.TempDir()
+	writeFile(t, first, "allbctl-deploy", 0755)
+	writeFile(t, first, "allbctl-status", 0755)
+	writeFile(t, first, "allbctl-notes.txt", 0644)
+	writeFile(t, first, "kubectl-foo", 0755)
+	writeFile(t, second, "allbctl-deploy", 0755)
+	writeFile(t, second, "allbctl-team-report", 0755)
+	if err := os.Mkdir(filepath.Join(second, "allbctl-dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	pathList := first + string(os.PathListSeparator) + filepath.Join(first, "missing") + string(os.PathListSeparator) + second
+	plugins := Discover(pathList, map[string]bool{"status": true})
+
+	want := []Plugin{
+		{Name: "deploy", Path: filepath.Join(first, "allbctl-deploy")},
+		{Name: "deploy", Path: filepath.Join(second, "allbctl-deploy"), ShadowedBy: filepath.Join(first, "allbctl-deploy")},
+		{Name: "status", Path: filepath.Join(first, "allbctl-status"), ShadowedBy: "built-in command"},
+		{Name: "team-report", Path: filepath.Join(second, "allbctl-team-report")},
+	}
+	if len(plugins) != len(want) {
+		t.Fatalf("Discover() = %+v, want %+v", plugins, want)
+	}
+	for i := range want {
+		if plugins[i] != want[i] {
+			t.Errorf("plugin %d = %+v, want %+v", i, plugins[i], want[i])
+		}
+	}
+	if plugins[1].Usable() || !plugins[0].Usable() {
+		t.Error("only the first plugin of a name should be usable")
+	}
+}
+
+func TestPluginName(t *testing.T) {
+	tests := map[string]string{
+		"allbctl-deploy":  "deploy",
+		"allbctl-a-b":     "a-b",
+		"allbctl-":        "",
+		"allbctl--x":      "",
+		"allbctl":         "",
+		"not-allbctl-foo": "",
+	}
+	if runtime.GOOS == "windows" {
+		tests["allbctl-deploy.exe"] = "deploy"
+		tests["allbctl-deploy"] = ""
+	}
+	for fileName, want := range tests {
+		got, ok := pluginName(fileName)
+		if got != want || ok != (want != "") {
+			t.Errorf("pluginName(%q) = %q, %v; want %q", fileName, got, ok, want)
+		}
+	}
+}
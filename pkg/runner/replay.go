@@ -0,0 +1,223 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"sync"
+)
+
+// Fixture is a set of recorded commands and PATH lookups
+type Fixture struct {
+	// Paths maps programs to where LookPath found them. An empty path records
+	// a program that was not found.
+	Paths    map[string]string `json:"paths,omitempty"`
+	Commands []Recording       `json:"commands"`
+}
+
+// Recording is one recorded command and its outcome
+type Recording struct {
+	Argv []string `json:"argv"`
+	// Dir, when set, must match the command's working directory too
+	Dir      string `json:"dir,omitempty"`
+	Stdout   string `json:"stdout,omitempty"`
+	Stderr   string `json:"stderr,omitempty"`
+	ExitCode int    `json:"exit_code,omitempty"`
+	// NotFound records a program that is not installed
+	NotFound bool `json:"not_found,omitempty"`
+}
+
+func (r Recording) matches(cmd Cmd) bool {
+	return slices.Equal(r.Argv, cmd.Argv()) && (r.Dir == "" || r.Dir == cmd.Dir)
+}
+
+// ExitError is how Replay reports a recorded command that failed
+type ExitError struct {
+	Code   int
+	Stderr []byte
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+func (e *ExitError) ExitCode() int {
+	return e.Code
+}
+
+// Replay serves a Fixture: each command gets the output recorded for it, and
+// commands that were not recorded fail as if the program was not installed
+type Replay struct {
+	fixture Fixture
+
+	mu        sync.Mutex
+	unmatched []string
+}
+
+// NewReplay replays f
+func NewReplay(f Fixture) *Replay {
+	return &Replay{fixture: f}
+}
+
+// LoadReplay replays the fixture in a JSON file written by Recorder.Save
+func LoadReplay(path string) (*Replay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read command fixture: %w", err)
+	}
+	var f Fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("cannot parse command fixture %s: %w", path, err)
+	}
+	return NewReplay(f), nil
+}
+
+func (r *Replay) Run(_ context.Context, cmd Cmd) error {
+	rec, ok := r.find(cmd)
+	if !ok {
+		r.mu.Lock()
+		r.unmatched = append(r.unmatched, commandLine(cmd))
+		r.mu.Unlock()
+		return &exec.Error{Name: cmd.Name, Err: exec.ErrNotFound}
+	}
+	if rec.NotFound {
+		return &exec.Error{Name: cmd.Name, Err: exec.ErrNotFound}
+	}
+	if cmd.Stdout != nil {
+		if _, err := io.WriteString(cmd.Stdout, rec.Stdout); err != nil {
+			return err
+		}
+	}
+	if cmd.Stderr != nil {
+		if _, err := io.WriteString(cmd.Stderr, rec.Stderr); err != nil {
+			return err
+		}
+	}
+	if rec.ExitCode != 0 {
+		return &ExitError{Code: rec.ExitCode, Stderr: []byte(rec.Stderr)}
+	}
+	return nil
+}
+
+// find returns the last recording that matches cmd
+func (r *Replay) find(cmd Cmd) (Recording, bool) {
+	for i := len(r.fixture.Commands) - 1; i >= 0; i-- {
+		if r.fixture.Commands[i].matches(cmd) {
+			return r.fixture.Commands[i], true
+		}
+	}
+	return Recording{}, false
+}
+
+// LookPath finds the recorded path of a program. Programs without a recorded
+// lookup are found when a command of theirs was recorded.
+func (r *Replay) LookPath(name string) (string, error) {
+	if path, ok := r.fixture.Paths[name]; ok {
+		if path == "" {
+			return "", &exec.Error{Name: name, Err: exec.ErrNotFound}
+		}
+		return path, nil
+	}
+	for _, rec := range r.fixture.Commands {
+		if rec.Argv[0] == name && !rec.NotFound {
+			return name, nil
+		}
+	}
+	return "", &exec.Error{Name: name, Err: exec.ErrNotFound}
+}
+
+// Unmatched lists the commands run that the fixture has no recording for
+func (r *Replay) Unmatched() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.unmatched)
+}
+
+// Recorder runs commands with another Runner and records them into a
+// Fixture, for Replay to serve later
+type Recorder struct {
+	next Runner
+
+	mu      sync.Mutex
+	fixture Fixture
+}
+
+// Record wraps r, recording what it runs
+func Record(r Runner) *Recorder {
+	return &Recorder{next: r, fixture: Fixture{Paths: map[string]string{}, Commands: []Recording{}}}
+}
+
+func (r *Recorder) Run(ctx context.Context, cmd Cmd) error {
+	var stdout, stderr bytes.Buffer
+	recorded := cmd
+	recorded.Stdout = tee(cmd.Stdout, &stdout)
+	recorded.Stderr = tee(cmd.Stderr, &stderr)
+	err := r.next.Run(ctx, recorded)
+	if ctx != nil && ctx.Err() != nil {
+		// A command cut short by a timeout says nothing about the machine
+		return err
+	}
+
+	rec := Recording{Argv: cmd.Argv(), Dir: cmd.Dir, Stdout: stdout.String(), Stderr: stderr.String()}
+	if err != nil {
+		code, exited := ExitCode(err)
+		switch {
+		case exited:
+			rec.ExitCode = code
+		case errors.Is(err, exec.ErrNotFound):
+			rec.NotFound = true
+		default:
+			// Timeouts and the like say nothing about the machine
+			return err
+		}
+	}
+	r.mu.Lock()
+	r.fixture.Commands = append(r.fixture.Commands, rec)
+	r.mu.Unlock()
+	return err
+}
+
+func (r *Recorder) LookPath(name string) (string, error) {
+	path, err := r.next.LookPath(name)
+	r.mu.Lock()
+	r.fixture.Paths[name] = path
+	r.mu.Unlock()
+	return path, err
+}
+
+// Fixture returns what has been recorded so far
+func (r *Recorder) Fixture() Fixture {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f := Fixture{Paths: map[string]string{}, Commands: slices.Clone(r.fixture.Commands)}
+	for name, path := range r.fixture.Paths {
+		f.Paths[name] = path
+	}
+	return f
+}
+
+// Save writes what has been recorded to a JSON file
+func (r *Recorder) Save(path string) error {
+	data, err := json.MarshalIndent(r.Fixture(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("cannot write command fixture: %w", err)
+	}
+	return nil
+}
+
+// tee also writes to w when the command's output has somewhere to go
+func tee(w io.Writer, buf *bytes.Buffer) io.Writer {
+	if w == nil {
+		return buf
+	}
+	return io.MultiWriter(w, buf)
+}
@@ -0,0 +1,142 @@
+// Package runner runs external programs. Collectors take a Runner from their
+// context instead of calling os/exec, so tests can serve canned output from
+// fixtures (see Replay) and --trace-commands can log every invocation (see
+// Trace).
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"time"
+)
+
+// Cmd is one invocation of an external program
+type Cmd struct {
+	Name string
+	Args []string
+	// Dir is the working directory; empty for the current one
+	Dir string
+	// Env is the environment; nil inherits this process's
+	Env    []string
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Argv is the program followed by its arguments
+func (c Cmd) Argv() []string {
+	return append([]string{c.Name}, c.Args...)
+}
+
+// Runner runs external programs
+type Runner interface {
+	// Run runs the program to completion. A program that ran and failed is
+	// reported with an error that has an ExitCode method (see ExitCode).
+	Run(ctx context.Context, cmd Cmd) error
+	// LookPath finds a program on PATH
+	LookPath(name string) (string, error)
+}
+
+// Exec runs programs on this machine
+type Exec struct{}
+
+// Run runs cmd with os/exec. When ctx ends the program is killed; WaitDelay
+// keeps children of wrapper scripts, like pyenv shims, from holding the
+// output open after that.
+func (Exec) Run(ctx context.Context, cmd Cmd) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c := exec.CommandContext(ctx, cmd.Name, cmd.Args...)
+	c.Dir = cmd.Dir
+	c.Env = cmd.Env
+	c.Stdin = cmd.Stdin
+	c.Stdout = cmd.Stdout
+	c.Stderr = cmd.Stderr
+	c.WaitDelay = time.Second
+	return c.Run()
+}
+
+func (Exec) LookPath(name string) (string, error) {
+	return exec.LookPath(name)
+}
+
+// Default is the Runner used when a context carries none. --trace-commands
+// wraps it, which also covers code that has no context to carry a Runner.
+var Default Runner = Exec{}
+
+type contextKey struct{}
+
+// WithRunner returns a context whose programs run with r
+func WithRunner(ctx context.Context, r Runner) context.Context {
+	return context.WithValue(ctx, contextKey{}, r)
+}
+
+// FromContext returns the Runner carried by ctx, or Default
+func FromContext(ctx context.Context) Runner {
+	if ctx != nil {
+		if r, ok := ctx.Value(contextKey{}).(Runner); ok {
+			return r
+		}
+	}
+	return Default
+}
+
+// Output runs a program and returns its standard output, like
+// exec.Cmd.Output
+func Output(ctx context.Context, name string, args ...string) ([]byte, error) {
+	var stdout bytes.Buffer
+	err := FromContext(ctx).Run(ctx, Cmd{Name: name, Args: args, Stdout: &stdout})
+	return stdout.Bytes(), err
+}
+
+// OutputIn is Output with the program running in dir
+func OutputIn(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+	var stdout bytes.Buffer
+	err := FromContext(ctx).Run(ctx, Cmd{Name: name, Args: args, Dir: dir, Stdout: &stdout})
+	return stdout.Bytes(), err
+}
+
+// CombinedOutput runs a program and returns its standard output and standard
+// error together, like exec.Cmd.CombinedOutput
+func CombinedOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	var out bytes.Buffer
+	err := FromContext(ctx).Run(ctx, Cmd{Name: name, Args: args, Stdout: &out, Stderr: &out})
+	return out.Bytes(), err
+}
+
+// CombinedOutputIn is CombinedOutput with the program running in dir
+func CombinedOutputIn(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+	var out bytes.Buffer
+	err := FromContext(ctx).Run(ctx, Cmd{Name: name, Args: args, Dir: dir, Stdout: &out, Stderr: &out})
+	return out.Bytes(), err
+}
+
+// Run runs a program, discarding its output
+func Run(ctx context.Context, name string, args ...string) error {
+	return FromContext(ctx).Run(ctx, Cmd{Name: name, Args: args})
+}
+
+// LookPath finds a program on PATH
+func LookPath(ctx context.Context, name string) (string, error) {
+	return FromContext(ctx).LookPath(name)
+}
+
+// Available reports whether a program is on PATH
+func Available(ctx context.Context, name string) bool {
+	_, err := LookPath(ctx, name)
+	return err == nil
+}
+
+// ExitCode returns the exit code of a program that ran and failed. ok is
+// false for other errors, like the program not being found.
+func ExitCode(err error) (code int, ok bool) {
+	var exitErr interface{ ExitCode() int }
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), true
+	}
+	return 0, false
+}
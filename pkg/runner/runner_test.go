@@ -0,0 +1,158 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFromContext(t *testing.T) {
+	if _, ok := FromContext(context.Background()).(Exec); !ok {
+		t.Errorf("FromContext() without a runner should return Default")
+	}
+	replay := NewReplay(Fixture{})
+	if got := FromContext(WithRunner(context.Background(), replay)); got != replay {
+		t.Errorf("FromContext() = %v, want the context's runner", got)
+	}
+}
+
+func TestExec(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	ctx := context.Background()
+
+	out, err := Output(ctx, "sh", "-c", "echo out; echo err >&2")
+	if err != nil || string(out) != "out\n" {
+		t.Errorf("Output() = %q, %v, want %q", out, err, "out\n")
+	}
+	out, _ = CombinedOutput(ctx, "sh", "-c", "echo out; echo err >&2")
+	if string(out) != "out\nerr\n" {
+		t.Errorf("CombinedOutput() = %q", out)
+	}
+	out, _ = OutputIn(ctx, "/", "pwd")
+	if strings.TrimSpace(string(out)) != "/" {
+		t.Errorf("OutputIn() = %q, want /", out)
+	}
+
+	err = Run(ctx, "sh", "-c", "exit 3")
+	if code, ok := ExitCode(err); !ok || code != 3 {
+		t.Errorf("ExitCode() = %d, %v, want 3, true", code, ok)
+	}
+	err = Run(ctx, "allbctl-no-such-program")
+	if _, ok := ExitCode(err); ok || !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("Run() of a missing program = %v, want exec.ErrNotFound", err)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	replay := NewReplay(Fixture{
+		Paths: map[string]string{"git": "/usr/bin/git", "docker": ""},
+		Commands: []Recording{
+			{Argv: []string{"git", "--version"}, Stdout: "git version 2.43.0\n"},
+			{Argv: []string{"git", "status"}, Dir: "/src/a", Stdout: "clean\n"},
+			{Argv: []string{"dnf", "check-update", "-q"}, Stdout: "vim.x86_64\n", ExitCode: 100},
+			{Argv: []string{"podman", "--version"}, NotFound: true},
+		},
+	})
+	ctx := WithRunner(context.Background(), replay)
+
+	out, err := Output(ctx, "git", "--version")
+	if err != nil || string(out) != "git version 2.43.0\n" {
+		t.Errorf("Output(git --version) = %q, %v", out, err)
+	}
+	if out, err := OutputIn(ctx, "/src/a", "git", "status"); err != nil || string(out) != "clean\n" {
+		t.Errorf("OutputIn(/src/a) = %q, %v", out, err)
+	}
+	if _, err := OutputIn(ctx, "/src/b", "git", "status"); err == nil {
+		t.Errorf("OutputIn(/src/b) should not match a recording for /src/a")
+	}
+
+	out, err = Output(ctx, "dnf", "check-update", "-q")
+	if code, ok := ExitCode(err); !ok || code != 100 || string(out) != "vim.x86_64\n" {
+		t.Errorf("Output(dnf check-update) = %q, %v, want output and exit 100", out, err)
+	}
+	if _, err := Output(ctx, "podman", "--version"); !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("Output(podman) = %v, want exec.ErrNotFound", err)
+	}
+	if _, err := Output(ctx, "kubectl", "version"); !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("Output(kubectl) = %v, want exec.ErrNotFound", err)
+	}
+	if got := replay.Unmatched(); len(got) != 2 || got[1] != "kubectl version" {
+		t.Errorf("Unmatched() = %q", got)
+	}
+
+	for name, want := range map[string]bool{"git": true, "docker": false, "dnf": true, "podman": false, "kubectl": false} {
+		if got := Available(ctx, name); got != want {
+			t.Errorf("Available(%s) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	source := NewReplay(Fixture{Commands: []Recording{
+		{Argv: []string{"uname", "-s"}, Stdout: "Linux\n"},
+		{Argv: []string{"false"}, ExitCode: 1, Stderr: "failed\n"},
+	}})
+	recorder := Record(source)
+	ctx := WithRunner(context.Background(), recorder)
+
+	if out, _ := Output(ctx, "uname", "-s"); string(out) != "Linux\n" {
+		t.Errorf("Output() through the recorder = %q", out)
+	}
+	_ = Run(ctx, "false")
+	_ = Run(ctx, "missing")
+	_, _ = LookPath(ctx, "uname")
+
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	if err := recorder.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	replay, err := LoadReplay(path)
+	if err != nil {
+		t.Fatalf("LoadReplay() error = %v", err)
+	}
+	ctx = WithRunner(context.Background(), replay)
+	if out, _ := Output(ctx, "uname", "-s"); string(out) != "Linux\n" {
+		t.Errorf("replayed Output() = %q", out)
+	}
+	var stderr bytes.Buffer
+	err = replay.Run(ctx, Cmd{Name: "false", Stderr: &stderr})
+	if code, _ := ExitCode(err); code != 1 || stderr.String() != "failed\n" {
+		t.Errorf("replayed Run(false) = %v, stderr %q", err, stderr.String())
+	}
+	if err := Run(ctx, "missing"); !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("replayed Run(missing) = %v, want exec.ErrNotFound", err)
+	}
+	if len(replay.Unmatched()) != 0 {
+		t.Errorf("Unmatched() = %q, want everything recorded", replay.Unmatched())
+	}
+}
+
+func TestTrace(t *testing.T) {
+	var log bytes.Buffer
+	r := Trace(NewReplay(Fixture{Commands: []Recording{
+		{Argv: []string{"git", "status"}, Dir: "/src"},
+		{Argv: []string{"dnf", "check-update"}, ExitCode: 100},
+	}}), &log)
+	ctx := WithRunner(context.Background(), r)
+
+	_ = Run(ctx, "dnf", "check-update")
+	_, _ = OutputIn(ctx, "/src", "git", "status")
+	_, _ = LookPath(ctx, "docker")
+
+	lines := strings.Split(strings.TrimSpace(log.String()), "\n")
+	want := []string{"exit 100   dnf check-update", "exit 0     git status (in /src)", "not found  lookpath docker"}
+	if len(lines) != len(want) {
+		t.Fatalf("Trace() logged %d lines, want %d:\n%s", len(lines), len(want), log.String())
+	}
+	for i := range want {
+		if !strings.HasPrefix(lines[i], "[trace] ") || !strings.HasSuffix(lines[i], want[i]) {
+			t.Errorf("line %d = %q, want it to end with %q", i, lines[i], want[i])
+		}
+	}
+}
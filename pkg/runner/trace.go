@@ -0,0 +1,67 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tracer logs each command its Runner runs
+type tracer struct {
+	next Runner
+	mu   sync.Mutex
+	w    io.Writer
+}
+
+// Trace wraps r so that every program it runs, and every PATH lookup, is
+// logged to w with how long it took and how it ended
+func Trace(r Runner, w io.Writer) Runner {
+	return &tracer{next: r, w: w}
+}
+
+func (t *tracer) Run(ctx context.Context, cmd Cmd) error {
+	start := time.Now()
+	err := t.next.Run(ctx, cmd)
+	t.log(time.Since(start), result(err), commandLine(cmd))
+	return err
+}
+
+func (t *tracer) LookPath(name string) (string, error) {
+	start := time.Now()
+	path, err := t.next.LookPath(name)
+	if err != nil {
+		t.log(time.Since(start), "not found", "lookpath "+name)
+	} else {
+		t.log(time.Since(start), "found", fmt.Sprintf("lookpath %s (%s)", name, path))
+	}
+	return path, err
+}
+
+func (t *tracer) log(d time.Duration, outcome, what string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fmt.Fprintf(t.w, "[trace] %8s  %-10s %s\n", d.Round(time.Microsecond*100), outcome, what)
+}
+
+// result describes how a command ended
+func result(err error) string {
+	if err == nil {
+		return "exit 0"
+	}
+	if code, ok := ExitCode(err); ok {
+		return fmt.Sprintf("exit %d", code)
+	}
+	return "error"
+}
+
+// commandLine is cmd as it would be typed, with its directory when it has one
+func commandLine(cmd Cmd) string {
+	line := strings.Join(cmd.Argv(), " ")
+	if cmd.Dir != "" {
+		line = fmt.Sprintf("%s (in %s)", line, cmd.Dir)
+	}
+	return line
+}
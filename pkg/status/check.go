@@ -2,13 +2,15 @@ package status
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"runtime"
 
 	"github.com/fatih/color"
+
+	"github.com/aallbrig/allbctl/pkg/runner"
 )
 
 // CheckForDirectory adds to output buffer based on if directory exists or not
@@ -58,8 +60,7 @@ func SystemInfo(buf *bytes.Buffer) (err error) {
 }
 
 func commandExists(cmd string) bool {
-	_, err := exec.LookPath(cmd)
-	return err == nil
+	return runner.Available(context.Background(), cmd)
 }
 
 // PackageManager adds package manager information to output
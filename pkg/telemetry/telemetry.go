@@ -23,6 +23,7 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"strings"
 	"time"
 
 	"go.opentelemetry.io/otel"
@@ -32,6 +33,7 @@ import (
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/metric"
 	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/propagation"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.opentelemetry.io/otel/sdk/resource"
@@ -259,3 +261,18 @@ func RecordCommandMetrics(ctx context.Context, commandPath string, duration time
 		)
 	}
 }
+
+// TraceEnv returns the W3C trace context of ctx as TRACEPARENT and TRACESTATE
+// environment variables, so a child process that reads them (as OTel SDKs
+// do for the env carrier) records its spans under the current span. It
+// returns nothing when ctx carries no sampled span, e.g. with no-op providers.
+func TraceEnv(ctx context.Context) []string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+
+	var env []string
+	for _, key := range carrier.Keys() {
+		env = append(env, fmt.Sprintf("%s=%s", strings.ToUpper(key), carrier.Get(key)))
+	}
+	return env
+}
@@ -8,6 +8,7 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 
 	"github.com/aallbrig/allbctl/pkg/telemetry"
 	"github.com/stretchr/testify/assert"
@@ -84,3 +85,16 @@ func TestRecordCommandMetrics_RealProviders(t *testing.T) {
 	// Should not panic and metrics should be recorded
 	telemetry.RecordCommandMetrics(context.Background(), "allbctl status", 200*time.Millisecond, true)
 }
+
+func TestTraceEnv(t *testing.T) {
+	assert.Empty(t, telemetry.TraceEnv(context.Background()))
+
+	tp := sdktrace.NewTracerProvider()
+	t.Cleanup(func() { require.NoError(t, tp.Shutdown(context.Background())) })
+	ctx, span := tp.Tracer("test").Start(context.Background(), "parent")
+	defer span.End()
+
+	env := telemetry.TraceEnv(ctx)
+	require.Len(t, env, 1)
+	assert.Equal(t, "TRACEPARENT=00-"+span.SpanContext().TraceID().String()+"-"+span.SpanContext().SpanID().String()+"-01", env[0])
+}
@@ -0,0 +1,37 @@
+package version
+
+import "testing"
+
+func TestExtract(t *testing.T) {
+	tests := map[string]string{
+		"go version go1.21.5 linux/amd64":               "1.21.5",
+		"gh version 2.40.1 (2023-12-13)":                "2.40.1",
+		"Python 3.11.4":                                 "3.11.4",
+		"v18.17.0":                                      "18.17.0",
+		"rustc 1.70.0 (90c541806 2023-05-31)":           "1.70.0",
+		"ruby 3.0.0p0 (2020-12-25 revision 95aff21468)": "3.0.0",
+		"6.0.100": "6.0.100",
+	}
+	for output, want := range tests {
+		if got := Extract(output); got != want {
+			t.Errorf("Extract(%q) = %q, want %q", output, got, want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		v1, v2 string
+		want   int
+	}{
+		{"2.40.1", "2.40", 1},
+		{"2.4.0", "2.40", -1},
+		{"1.21", "1.21.0", 0},
+		{"10.0", "9.9.9", 1},
+	}
+	for _, tt := range tests {
+		if got := Compare(tt.v1, tt.v2); got != tt.want {
+			t.Errorf("Compare(%s, %s) = %d, want %d", tt.v1, tt.v2, got, tt.want)
+		}
+	}
+}